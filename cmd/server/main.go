@@ -11,14 +11,12 @@ import (
 	"os/signal"
 	"syscall"
 
+	"bigswapenergy/internal/app"
 	"bigswapenergy/internal/infrastructure/ethereum"
-	"bigswapenergy/internal/infrastructure/uniswap_v2"
-	"bigswapenergy/internal/presentation/http"
+	"bigswapenergy/internal/shared/clock"
 	"bigswapenergy/internal/shared/config"
 	"bigswapenergy/internal/shared/logger"
-	estimate "bigswapenergy/internal/usecases"
 
-	"github.com/valyala/fasthttp"
 	"go.uber.org/zap"
 )
 
@@ -31,11 +29,11 @@ func main() {
 	}
 }
 
-// run initializes configuration, logging, network clients and HTTP routes,
-// then runs the server until a shutdown signal is received.
+// run loads configuration and the Ethereum RPC client, then runs the app until
+// a shutdown signal is received. The app owns the client and the logger's final
+// sync from then on, so it can stop them in order.
 func run() error {
 	log := logger.NewLogger()
-	defer log.Sync()
 
 	configPath := os.Getenv("CONFIG_PATH")
 	if configPath == "" {
@@ -44,57 +42,95 @@ func run() error {
 
 	cfg, err := config.LoadConfig(configPath)
 	if err != nil {
+		log.Sync()
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
-	ethClient, err := ethereum.NewEthereumClient(cfg.Blockchain.EthereumRPCURL, log)
+	ethClient, err := newEthereumClient(ctx, cfg, log)
 	if err != nil {
+		log.Sync()
 		return fmt.Errorf("failed to create Ethereum client: %w", err)
 	}
-	defer ethClient.Close()
 
-	uniswapV2Client := uniswap_v2.NewUniswapV2Client(ethClient, log)
-	estimateService := estimate.NewEstimateService(uniswapV2Client, log)
-	estimateHandler := http.NewEstimateHandler(estimateService, log, cfg)
+	application, err := app.New(cfg, ethClient, log)
+	if err != nil {
+		ethClient.Close()
+		log.Sync()
+		return err
+	}
 
-	handler := http.ApplyMiddleware(
-		estimateHandler.EstimateSwapAmount,
-		log,
-		estimateHandler,
-	)
+	go reloadOnSIGHUP(ctx, configPath, application, log)
+
+	return application.Run(ctx)
+}
 
-	server := &fasthttp.Server{
-		Handler: handler,
+// reloadOnSIGHUP re-reads the config file on each SIGHUP and applies it to the
+// running app until ctx is done. An invalid file is logged and ignored.
+func reloadOnSIGHUP(ctx context.Context, configPath string, application *app.App, log *zap.Logger) {
+	hangups := make(chan os.Signal, 1)
+	signal.Notify(hangups, syscall.SIGHUP)
+	defer signal.Stop(hangups)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangups:
+			cfg, err := config.LoadConfig(configPath)
+			if err != nil {
+				log.Error("Config reload failed, keeping the running config", zap.Error(err))
+				continue
+			}
+			application.Reload(cfg)
+		}
 	}
+}
 
-	errCh := make(chan error, 1)
-	go func() {
-		log.Info("Starting server", zap.String("address", cfg.Server.Address))
-		errCh <- server.ListenAndServe(cfg.Server.Address)
-	}()
+// newEthereumClient connects to the primary RPC endpoint and to each fallback,
+// behind a circuit-breaking failover client so calls fail fast while they're down
+func newEthereumClient(ctx context.Context, cfg *config.Config, log *zap.Logger) (ethereum.EthereumClient, error) {
+	log.Info("Connecting to RPC endpoints", zap.Int("pool_size", cfg.Blockchain.PoolSize))
+	primary, err := dialEndpoint(ctx, cfg.Blockchain.EthereumRPCURL, cfg.Blockchain.PoolSize, log)
+	if err != nil {
+		return nil, err
+	}
 
-	select {
-	case <-ctx.Done():
-		log.Info("Received shutdown signal, starting graceful shutdown")
-	case err := <-errCh:
+	endpoints := []ethereum.Endpoint{{Name: "primary", URL: cfg.Blockchain.EthereumRPCURL, Client: primary}}
+	for i, url := range cfg.Blockchain.FallbackRPCURLs {
+		client, err := dialEndpoint(ctx, url, cfg.Blockchain.PoolSize, log)
 		if err != nil {
-			log.Error("Server error occurred", zap.Error(err))
-			return fmt.Errorf("server error: %w", err)
+			for _, endpoint := range endpoints {
+				endpoint.Client.Close()
+			}
+			return nil, fmt.Errorf("fallback %d: %w", i+1, err)
 		}
-		return nil
+		endpoints = append(endpoints, ethereum.Endpoint{Name: fmt.Sprintf("fallback%d", i+1), URL: url, Client: client})
 	}
+	if len(cfg.Blockchain.FallbackRPCURLs) > 0 {
+		log.Info("RPC failover enabled", zap.Int("fallbacks", len(cfg.Blockchain.FallbackRPCURLs)))
+	}
+	return ethereum.NewFailoverEthereumClient(endpoints, cfg.Blockchain.Circuit, clock.New(), log), nil
+}
 
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
-	defer cancel()
-
-	if err := server.ShutdownWithContext(shutdownCtx); err != nil {
-		log.Error("Error during server shutdown", zap.Error(err))
-	} else {
-		log.Info("Server shutdown completed successfully")
+// dialEndpoint connects to url, through a pool of poolSize connections when more
+// than one is configured, logging each pooled connection's health
+func dialEndpoint(ctx context.Context, url string, poolSize int, log *zap.Logger) (ethereum.EthereumClient, error) {
+	if poolSize <= 1 {
+		return ethereum.NewEthereumClient(url, log)
 	}
 
-	return nil
+	pool, err := ethereum.NewEthereumClientPool(url, poolSize, log)
+	if err != nil {
+		return nil, err
+	}
+	for i, healthy := range pool.CheckConnectionsHealth(ctx) {
+		if !healthy {
+			log.Warn("RPC connection unhealthy at startup", zap.Int("connection", i))
+		}
+	}
+	log.Info("RPC connection pool ready", zap.Int("connections", pool.GetConnectionCount()))
+	return pool, nil
 }
@@ -0,0 +1,299 @@
+// Package app wires the service's components together and owns their lifecycle,
+// including the order they are stopped in on shutdown.
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"bigswapenergy/internal/infrastructure/ethereum"
+	"bigswapenergy/internal/infrastructure/uniswap_v2"
+	"bigswapenergy/internal/presentation/http"
+	"bigswapenergy/internal/shared/clock"
+	"bigswapenergy/internal/shared/config"
+	"bigswapenergy/internal/shared/janitor"
+	"bigswapenergy/internal/shared/metrics"
+	"bigswapenergy/internal/shared/ringbuffer"
+	"bigswapenergy/internal/shared/utils"
+	estimate "bigswapenergy/internal/usecases"
+
+	"go.uber.org/zap"
+)
+
+// Stopper is a component running background goroutines that must be stopped on shutdown
+type Stopper interface {
+	Stop()
+}
+
+// App is the assembled service: the HTTP server plus every component it depends on
+type App struct {
+	cfg       *config.Config
+	log       *zap.Logger
+	ethClient ethereum.EthereumClient
+	server    http.Server
+	// metricsServer serves /metrics on its own address; nil when disabled
+	metricsServer http.Server
+
+	// janitors are stopped in order after the server has drained
+	janitors []Stopper
+
+	// rateLimiter is nil without rate limiting. running is the config in effect,
+	// cfg plus any reloaded rate limits; both are guarded by reloadMu.
+	rateLimiter *http.RateLimitMiddleware
+	reloadMu    sync.Mutex
+	running     *config.Config
+}
+
+// New builds the service around ethClient. On success the App owns ethClient and
+// closes it during shutdown.
+func New(cfg *config.Config, ethClient ethereum.EthereumClient, log *zap.Logger) (*App, error) {
+	factories, err := uniswap_v2.NewFactoryRegistry(cfg.Factories)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load factory registry: %w", err)
+	}
+
+	features, err := http.NewFeatureFlags(cfg.Features)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load feature flags: %w", err)
+	}
+	if disabled := features.Disabled(); len(disabled) > 0 {
+		log.Info("Features disabled", zap.Strings("features", disabled))
+	}
+
+	rpcClient := ethClient
+	if window := cfg.Blockchain.MonotonicHeadWindow; window > 0 {
+		rpcClient = ethereum.NewMonotonicHeadEthereumClient(rpcClient, window, clock.New())
+	}
+	rpcClient = ethereum.NewMeteredEthereumClient(rpcClient, metrics.GlobalExporter)
+	if cfg.Logging.RPCCalls {
+		rpcClient = ethereum.NewLoggingEthereumClient(rpcClient, log)
+	}
+	// Budgeted outside logging, so calls refused by the budget aren't logged as
+	// made, and inside retries, so each attempt is charged
+	rpcClient = ethereum.NewBudgetedEthereumClient(rpcClient)
+	if cfg.Blockchain.Retry.MaxAttempts > 1 {
+		rpcClient = ethereum.NewRetryingEthereumClient(rpcClient, cfg.Blockchain.Retry, log)
+	}
+	var baseClient uniswap_v2.UniswapV2Client = uniswap_v2.NewUniswapV2Client(rpcClient, log)
+	if cfg.Blockchain.DetectReservesSlot {
+		baseClient = uniswap_v2.NewSlotDetectingUniswapV2Client(baseClient, cfg.Blockchain.MaxProbeSlot, log)
+	}
+	var reservesCache *uniswap_v2.ReservesCachingUniswapV2Client
+	if cfg.Cache.ReservesTTL > 0 {
+		reservesCache = uniswap_v2.NewReservesCachingUniswapV2Client(baseClient, cfg.Cache.ReservesTTL, cfg.Cache.ReservesMaxEntries, clock.New())
+		baseClient = reservesCache
+	}
+
+	uniswapV2Client := uniswap_v2.NewCachedUniswapV2Client(
+		baseClient,
+		cfg.Cache.TokenTTL,
+		clock.New(),
+		log,
+	)
+	uniswapV2Client.SetTTLJitter(cfg.Cache.TTLJitter)
+	if cfg.Cache.SingleFlight {
+		uniswapV2Client.EnableSingleFlight()
+	}
+	if cfg.Cache.ReservesMaxAgeBlocks > 0 {
+		uniswapV2Client.EnableReservesCache(cfg.Cache.ReservesMaxAgeBlocks)
+	}
+	uniswapV2Client.StartJanitor(cfg.Cache.TokenTTL)
+
+	estimateService := estimate.NewEstimateService(uniswapV2Client, factories, log)
+	if cfg.Cache.SingleFlight {
+		estimateService.(*estimate.EstimateServiceImpl).EnableSingleFlight()
+	}
+	estimateHandler := http.NewEstimateHandler(estimateService, log, cfg)
+	statsHandler := http.NewStatsHandler(metrics.Global)
+	http.RegisterBigIntPoolMetrics(metrics.Global, utils.GlobalBigIntPool)
+	http.ExportBigIntPoolMetrics(metrics.GlobalExporter, utils.GlobalBigIntPool)
+	if reservesCache != nil {
+		http.RegisterReservesCacheMetrics(metrics.Global, reservesCache)
+	}
+	endpoints, hasEndpoints := ethClient.(http.EndpointStatusSource)
+	if hasEndpoints {
+		http.RegisterCircuitMetrics(metrics.Global, endpoints)
+	}
+	readinessHandler := http.NewReadinessHandler(ethClient, estimateService, cfg.Readiness, log)
+
+	healthHandler := http.NewHealthHandler(ethClient, cfg.Readiness.Timeout, clock.New())
+
+	router := setupRouter(features, estimateHandler, statsHandler, readinessHandler, healthHandler)
+
+	if cfg.Server.TrustedReserves {
+		router.Handle("/estimate/local", estimateHandler.QuoteFromReserves)
+		log.Warn("Trusted reserves endpoint enabled")
+	}
+
+	routerHandler := router.Handler
+	if cfg.Server.StrictAddresses {
+		routerHandler = estimateHandler.StrictAddresses(routerHandler)
+	}
+	if cfg.Debug.Enabled {
+		recent := ringbuffer.New[http.RecentRequest](cfg.Debug.RecentRequests)
+		router.Handle("/debug/recent", http.NewRecentRequestsHandler(recent).GetRecent)
+		if hasEndpoints {
+			router.Handle("/debug/pool", http.NewEndpointPoolHandler(endpoints).GetPool)
+		}
+		routerHandler = http.NewRecentRequestsMiddleware(recent).Apply(routerHandler)
+		log.Warn("Debug endpoints enabled", zap.Int("recent_requests", cfg.Debug.RecentRequests))
+	}
+
+	handler, rateLimiter := http.ApplyMiddleware(
+		routerHandler,
+		log,
+		estimateHandler,
+	)
+
+	app := &App{
+		cfg:       cfg,
+		log:       log,
+		ethClient: ethClient,
+		server:    http.NewServer(handler, cfg.Server),
+		janitors:  []Stopper{uniswapV2Client},
+
+		rateLimiter: rateLimiter,
+		running:     cfg,
+	}
+	if rateLimiter != nil {
+		app.janitors = append(app.janitors, rateLimiter)
+	}
+	if checker, ok := ethClient.(http.ConnectionsHealthChecker); ok && cfg.Server.HealthCheckPeriod > 0 {
+		app.janitors = append(app.janitors, janitor.Start(cfg.Server.HealthCheckPeriod, func() {
+			logUnhealthyConnections(checker, cfg.Readiness.Timeout, log)
+		}))
+	}
+	if cfg.Server.MetricsAddress != "" {
+		app.metricsServer = http.NewServer(http.NewMetricsHandler(metrics.GlobalExporter).GetMetrics, config.ServerConfig{})
+	}
+	return app, nil
+}
+
+// Run serves until ctx is cancelled or the server fails, then shuts down
+func (a *App) Run(ctx context.Context) error {
+	errCh := make(chan error, 1)
+	go func() {
+		a.log.Info("Starting server", zap.String("address", a.cfg.Server.Address), zap.Bool("h2c", a.cfg.Server.H2C))
+		errCh <- a.server.ListenAndServe(a.cfg.Server.Address)
+	}()
+	// However the server stops, the metrics server goes first and background work
+	// last, after anything that might still log
+	defer a.stopBackground()
+	if a.metricsServer != nil {
+		// Listening here rather than in the goroutine leaves the deferred shutdown a
+		// listener to close even if Run returns before the metrics server serves.
+		// Losing metrics shouldn't stop quoting, so failures are only logged.
+		listener, err := net.Listen("tcp", a.cfg.Server.MetricsAddress)
+		if err != nil {
+			a.log.Error("Metrics server error occurred", zap.Error(err))
+		} else {
+			a.log.Info("Starting metrics server", zap.String("address", a.cfg.Server.MetricsAddress))
+			go func() {
+				if err := a.metricsServer.Serve(listener); err != nil {
+					a.log.Error("Metrics server error occurred", zap.Error(err))
+				}
+			}()
+			defer a.shutdownMetricsServer(listener)
+		}
+	}
+
+	select {
+	case <-ctx.Done():
+		a.log.Info("Received shutdown signal, starting graceful shutdown")
+	case err := <-errCh:
+		if err != nil {
+			a.log.Error("Server error occurred", zap.Error(err))
+			return fmt.Errorf("server error: %w", err)
+		}
+		return nil
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), a.cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	// Stop accepting connections and drain in-flight requests first, so nothing
+	// still serving a request sees a stopped janitor or a closed RPC client
+	if err := a.server.ShutdownWithContext(shutdownCtx); err != nil {
+		a.log.Error("Error during server shutdown", zap.Error(err))
+	} else {
+		a.log.Info("Server shutdown completed successfully")
+	}
+	select {
+	case <-errCh:
+	case <-shutdownCtx.Done():
+	}
+	return nil
+}
+
+// shutdownMetricsServer stops the metrics server within the shutdown timeout,
+// then closes listener in case the server never started serving on it
+func (a *App) shutdownMetricsServer(listener net.Listener) {
+	ctx, cancel := context.WithTimeout(context.Background(), a.cfg.Server.ShutdownTimeout)
+	defer cancel()
+	if err := a.metricsServer.ShutdownWithContext(ctx); err != nil {
+		a.log.Error("Error during metrics server shutdown", zap.Error(err))
+	}
+	if err := listener.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+		a.log.Error("Error closing metrics listener", zap.Error(err))
+	}
+}
+
+// stopBackground stops the janitors, closes the RPC client and finally syncs the
+// logger, after which nothing may log
+func (a *App) stopBackground() {
+	for _, j := range a.janitors {
+		j.Stop()
+	}
+	a.log.Info("Stopped background janitors", zap.Int("count", len(a.janitors)))
+
+	if err := a.ethClient.Close(); err != nil {
+		a.log.Error("Error closing Ethereum client", zap.Error(err))
+	}
+	a.log.Info("Closed Ethereum client")
+
+	a.log.Sync()
+}
+
+// setupRouter registers every HTTP route served by the application, skipping
+// optional endpoints whose feature is disabled.
+func setupRouter(features *http.FeatureFlags, estimateHandler *http.EstimateHandler, statsHandler *http.StatsHandler, readinessHandler *http.ReadinessHandler, healthHandler *http.HealthHandler) *http.Router {
+	router := http.NewRouter()
+	router.Handle("/estimate", estimateHandler.EstimateSwapAmount)
+	router.HandleFeature(features, http.FeatureEstimateIn, "/estimate-in", estimateHandler.EstimateSwapAmountIn)
+	router.HandleFeature(features, http.FeatureBatch, "/estimate/batch", estimateHandler.EstimateBatch)
+	router.HandleFeature(features, http.FeatureArbitrage, "/estimate/arb", estimateHandler.EstimateArbitrage)
+	router.HandleFeature(features, http.FeatureQuote, "/estimate/quote", estimateHandler.EstimateTwoWayQuote)
+	router.HandleFeature(features, http.FeatureMaxImpact, "/estimate/max-for-impact", estimateHandler.EstimateMaxForImpact)
+	router.HandleFeature(features, http.FeatureCheck, "/estimate/check", estimateHandler.CheckPool)
+	router.HandleFeature(features, http.FeatureRoute, "/estimate/route", estimateHandler.EstimateRoute)
+	router.HandleFeature(features, http.FeatureRoute, "/estimate-path", estimateHandler.EstimateRoute)
+	router.HandleFeature(features, http.FeaturePool, "/pool", estimateHandler.GetPool)
+	router.HandleFeature(features, http.FeaturePoolRaw, "/pool/raw", estimateHandler.GetRawPoolStorage)
+	router.HandleFeature(features, http.FeaturePoolTokens, "/pool/tokens", estimateHandler.GetPoolTokens)
+	router.HandleFeature(features, http.FeatureInvariant, "/pool/invariant", estimateHandler.GetPoolInvariant)
+	router.HandleFeature(features, http.FeaturePrice, "/price", estimateHandler.GetPoolPrice)
+	router.HandleFeature(features, http.FeaturePools, "/pools", estimateHandler.ReadPools)
+	router.Handle("/stats", statsHandler.GetStats)
+	router.Handle("/ready", readinessHandler.GetReady)
+	router.Handle("/health", healthHandler.GetHealth)
+	return router
+}
+
+// logUnhealthyConnections checks every RPC connection, logging those that fail
+func logUnhealthyConnections(checker http.ConnectionsHealthChecker, timeout time.Duration, log *zap.Logger) {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	for i, healthy := range checker.CheckConnectionsHealth(ctx) {
+		if !healthy {
+			log.Warn("RPC connection unhealthy", zap.Int("connection", i))
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package app
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"bigswapenergy/internal/presentation/http"
+	"bigswapenergy/internal/shared/config"
+
+	"go.uber.org/zap"
+)
+
+// Reload applies cfg's rate limits to the running service. Every other setting,
+// including the RPC URLs and rate_limit.cleanup_interval, needs a restart; those
+// that differ are logged by name only, since some are secrets.
+func (a *App) Reload(cfg *config.Config) {
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+
+	applied := *a.running
+	applied.RateLimit = cfg.RateLimit
+	applied.RateLimit.CleanupInterval = a.running.RateLimit.CleanupInterval
+
+	changed := changedSettings("rate_limit", a.running.RateLimit, applied.RateLimit, true)
+	if a.rateLimiter != nil {
+		a.rateLimiter.UpdateConfig(http.NewHTTPRateLimitConfig(applied.RateLimit))
+	}
+	a.running = &applied
+
+	var restart []string
+	current, reloaded := reflect.ValueOf(applied), reflect.ValueOf(*cfg)
+	for i := 0; i < current.NumField(); i++ {
+		name := settingName(current.Type().Field(i))
+		if current.Field(i).Kind() == reflect.Struct {
+			restart = append(restart, changedSettings(name, current.Field(i).Interface(), reloaded.Field(i).Interface(), false)...)
+		} else if !reflect.DeepEqual(current.Field(i).Interface(), reloaded.Field(i).Interface()) {
+			restart = append(restart, name)
+		}
+	}
+
+	a.log.Info("Configuration reloaded", zap.Strings("changed", changed))
+	if len(restart) > 0 {
+		a.log.Warn("Configuration changes require a restart", zap.Strings("settings", restart))
+	}
+}
+
+// changedSettings lists the fields of two structs of one type that differ,
+// with their old and new values when withValues is set
+func changedSettings(section string, old, new any, withValues bool) []string {
+	oldValue, newValue := reflect.ValueOf(old), reflect.ValueOf(new)
+	var changed []string
+	for i := 0; i < oldValue.NumField(); i++ {
+		if reflect.DeepEqual(oldValue.Field(i).Interface(), newValue.Field(i).Interface()) {
+			continue
+		}
+		name := section + "." + settingName(oldValue.Type().Field(i))
+		if withValues {
+			name = fmt.Sprintf("%s: %v -> %v", name, oldValue.Field(i).Interface(), newValue.Field(i).Interface())
+		}
+		changed = append(changed, name)
+	}
+	return changed
+}
+
+// settingName returns a field's YAML key, or its Go name when it has none
+func settingName(field reflect.StructField) string {
+	tag, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	switch tag {
+	case "":
+		return strings.ToLower(field.Name)
+	case "-":
+		return field.Name
+	}
+	return tag
+}
@@ -0,0 +1,56 @@
+package ethereum
+
+import (
+	"context"
+	"math/big"
+
+	"bigswapenergy/internal/shared/rpcbudget"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// BudgetedEthereumClient charges every RPC call to the budget carried by the
+// request context, refusing calls once it is spent
+type BudgetedEthereumClient struct {
+	EthereumClient
+}
+
+// NewBudgetedEthereumClient wraps client so its calls count against the request's RPC budget
+func NewBudgetedEthereumClient(client EthereumClient) EthereumClient {
+	return &BudgetedEthereumClient{EthereumClient: client}
+}
+
+// GetLatestBlockNumber spends one call, then delegates
+func (c *BudgetedEthereumClient) GetLatestBlockNumber(ctx context.Context) (uint64, error) {
+	if err := rpcbudget.Spend(ctx); err != nil {
+		return 0, err
+	}
+	return c.EthereumClient.GetLatestBlockNumber(ctx)
+}
+
+// ReadContractStorage spends one call, then delegates
+func (c *BudgetedEthereumClient) ReadContractStorage(ctx context.Context, contractAddress common.Address, storageKey common.Hash, blockNumber *big.Int) ([]byte, error) {
+	if err := rpcbudget.Spend(ctx); err != nil {
+		return nil, err
+	}
+	return c.EthereumClient.ReadContractStorage(ctx, contractAddress, storageKey, blockNumber)
+}
+
+// ReadContractStorageBatch spends one call per slot, since providers bill each
+// request in a batch, then delegates
+func (c *BudgetedEthereumClient) ReadContractStorageBatch(ctx context.Context, contractAddress common.Address, storageKeys []common.Hash, blockNumber *big.Int) ([][]byte, error) {
+	for range storageKeys {
+		if err := rpcbudget.Spend(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return c.EthereumClient.ReadContractStorageBatch(ctx, contractAddress, storageKeys, blockNumber)
+}
+
+// CallContract spends one call, then delegates
+func (c *BudgetedEthereumClient) CallContract(ctx context.Context, contractAddress common.Address, data []byte, blockNumber *big.Int) ([]byte, error) {
+	if err := rpcbudget.Spend(ctx); err != nil {
+		return nil, err
+	}
+	return c.EthereumClient.CallContract(ctx, contractAddress, data, blockNumber)
+}
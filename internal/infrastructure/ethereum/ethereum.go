@@ -2,12 +2,17 @@ package ethereum
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/big"
+	"net"
 	"net/http"
+	"strings"
 	"time"
 
+	geth "github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/rpc"
 	"go.uber.org/zap"
@@ -18,6 +23,8 @@ var (
 	ErrInvalidAddress    = fmt.Errorf("Invalid Ethereum address")
 	ErrRPCTimeout        = fmt.Errorf("Blockchain network timeout")
 	ErrStorageReadFailed = fmt.Errorf("Unable to read contract data")
+	ErrCallFailed        = fmt.Errorf("Contract call failed")
+	ErrExecutionReverted = fmt.Errorf("Contract call reverted")
 )
 
 type EthereumClient interface {
@@ -27,6 +34,14 @@ type EthereumClient interface {
 	// ReadContractStorage reads data from contract storage at specific slot
 	ReadContractStorage(ctx context.Context, contractAddress common.Address, storageKey common.Hash, blockNumber *big.Int) ([]byte, error)
 
+	// ReadContractStorageBatch reads several storage slots of one contract in a single
+	// JSON-RPC batch, returning the words in storageKeys order. It fails as a whole
+	// if any slot cannot be read.
+	ReadContractStorageBatch(ctx context.Context, contractAddress common.Address, storageKeys []common.Hash, blockNumber *big.Int) ([][]byte, error)
+
+	// CallContract executes a read-only eth_call against the contract; a nil blockNumber means latest
+	CallContract(ctx context.Context, contractAddress common.Address, data []byte, blockNumber *big.Int) ([]byte, error)
+
 	// Close gracefully closes the connection
 	Close() error
 
@@ -92,14 +107,58 @@ func (c *OptimizedEthereumClient) GetLatestBlockNumber(ctx context.Context) (uin
 func (c *OptimizedEthereumClient) ReadContractStorage(ctx context.Context, contractAddress common.Address, storageKey common.Hash, blockNumber *big.Int) ([]byte, error) {
 	data, err := c.client.StorageAt(ctx, contractAddress, storageKey, blockNumber)
 	if err != nil {
-		if isTimeoutError(err) {
-			return nil, fmt.Errorf("%w: %v", ErrRPCTimeout, err)
+		return nil, storageError(err)
+	}
+	return data, nil
+}
+
+// ReadContractStorageBatch reads several storage slots with one eth_getStorageAt batch.
+// A slot the node fails to read is reported as ErrStorageReadFailed, like a single read.
+func (c *OptimizedEthereumClient) ReadContractStorageBatch(ctx context.Context, contractAddress common.Address, storageKeys []common.Hash, blockNumber *big.Int) ([][]byte, error) {
+	block := "latest"
+	if blockNumber != nil {
+		block = hexutil.EncodeBig(blockNumber)
+	}
+
+	results := make([]hexutil.Bytes, len(storageKeys))
+	batch := make([]rpc.BatchElem, len(storageKeys))
+	for i, key := range storageKeys {
+		batch[i] = rpc.BatchElem{
+			Method: "eth_getStorageAt",
+			Args:   []any{contractAddress, key, block},
+			Result: &results[i],
 		}
-		return nil, fmt.Errorf("%w: %v", ErrStorageReadFailed, err)
+	}
+
+	if err := c.client.Client().BatchCallContext(ctx, batch); err != nil {
+		return nil, storageError(err)
+	}
+
+	data := make([][]byte, len(storageKeys))
+	for i, elem := range batch {
+		if elem.Error != nil {
+			return nil, fmt.Errorf("%w: slot %s: %v", ErrStorageReadFailed, storageKeys[i].Hex(), elem.Error)
+		}
+		data[i] = results[i]
 	}
 	return data, nil
 }
 
+// CallContract executes a read-only eth_call against the contract
+func (c *OptimizedEthereumClient) CallContract(ctx context.Context, contractAddress common.Address, data []byte, blockNumber *big.Int) ([]byte, error) {
+	result, err := c.client.CallContract(ctx, geth.CallMsg{To: &contractAddress, Data: data}, blockNumber)
+	if err != nil {
+		if transportErr := transportError(err); transportErr != nil {
+			return nil, transportErr
+		}
+		if isRevertError(err) {
+			return nil, fmt.Errorf("%w: %v", ErrExecutionReverted, err)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrCallFailed, err)
+	}
+	return result, nil
+}
+
 // Close gracefully closes the connection
 func (c *OptimizedEthereumClient) Close() error {
 	c.client.Close()
@@ -123,3 +182,35 @@ func isTimeoutError(err error) bool {
 	}
 	return err == context.DeadlineExceeded || err == context.Canceled
 }
+
+// storageError classifies a failed storage read: timeouts and failures to reach
+// the node, which a retry may fix, apart from the node failing the read
+func storageError(err error) error {
+	if transportErr := transportError(err); transportErr != nil {
+		return transportErr
+	}
+	return fmt.Errorf("%w: %v", ErrStorageReadFailed, err)
+}
+
+// transportError wraps err as ErrRPCTimeout or ErrConnectionFailed when the call
+// timed out or never reached the node, and returns nil for anything else
+func transportError(err error) error {
+	var netErr net.Error
+	switch {
+	case isTimeoutError(err):
+		return fmt.Errorf("%w: %v", ErrRPCTimeout, err)
+	case errors.As(err, &netErr):
+		if netErr.Timeout() {
+			return fmt.Errorf("%w: %v", ErrRPCTimeout, err)
+		}
+		return fmt.Errorf("%w: %v", ErrConnectionFailed, err)
+	}
+	return nil
+}
+
+// isRevertError reports whether the node executed the call and it reverted, as
+// opposed to the call never reaching the contract
+func isRevertError(err error) bool {
+	var rpcErr rpc.Error
+	return errors.As(err, &rpcErr) && strings.Contains(rpcErr.Error(), "execution reverted")
+}
@@ -0,0 +1,210 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"time"
+
+	"bigswapenergy/internal/shared/circuit"
+	"bigswapenergy/internal/shared/clock"
+	"bigswapenergy/internal/shared/config"
+	"bigswapenergy/internal/shared/logger"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+// ErrCircuitOpen is returned, along with ErrConnectionFailed, without calling
+// any endpoint while every endpoint's circuit is open
+var ErrCircuitOpen = fmt.Errorf("RPC circuit open")
+
+// Endpoint is one RPC provider behind a FailoverEthereumClient
+type Endpoint struct {
+	// Name labels the endpoint in logs, metrics and /debug/pool
+	Name string
+	// URL is shown redacted to its scheme and host, as paths often carry API keys
+	URL    string
+	Client EthereumClient
+}
+
+// EndpointStatus reports an endpoint's circuit
+type EndpointStatus struct {
+	Name    string
+	Host    string
+	Circuit circuit.Snapshot
+}
+
+// FailoverEthereumClient sends each call to the first endpoint whose circuit is
+// not open, moving on to the next when it can't be reached or times out. An
+// endpoint whose calls keep failing that way has its circuit opened, so a
+// provider-wide outage costs one failed call per cooldown instead of one per
+// request.
+type FailoverEthereumClient struct {
+	endpoints []failoverEndpoint
+	cooldown  time.Duration
+	logger    *zap.Logger
+}
+
+type failoverEndpoint struct {
+	Endpoint
+	breaker *circuit.Breaker
+}
+
+// NewFailoverEthereumClient creates a client over endpoints, tried in order
+func NewFailoverEthereumClient(endpoints []Endpoint, cfg config.CircuitConfig, clk clock.Clock, logger *zap.Logger) *FailoverEthereumClient {
+	c := &FailoverEthereumClient{cooldown: cfg.Cooldown, logger: logger}
+	for _, endpoint := range endpoints {
+		c.endpoints = append(c.endpoints, failoverEndpoint{Endpoint: endpoint, breaker: circuit.NewBreaker(cfg, clk)})
+	}
+	return c
+}
+
+// GetLatestBlockNumber returns the latest block from the first available endpoint
+func (c *FailoverEthereumClient) GetLatestBlockNumber(ctx context.Context) (uint64, error) {
+	return failover(ctx, c, func(client EthereumClient) (uint64, error) {
+		return client.GetLatestBlockNumber(ctx)
+	})
+}
+
+// ReadContractStorage reads a storage slot from the first available endpoint
+func (c *FailoverEthereumClient) ReadContractStorage(ctx context.Context, contractAddress common.Address, storageKey common.Hash, blockNumber *big.Int) ([]byte, error) {
+	return failover(ctx, c, func(client EthereumClient) ([]byte, error) {
+		return client.ReadContractStorage(ctx, contractAddress, storageKey, blockNumber)
+	})
+}
+
+// ReadContractStorageBatch reads a batch of storage slots from the first available endpoint
+func (c *FailoverEthereumClient) ReadContractStorageBatch(ctx context.Context, contractAddress common.Address, storageKeys []common.Hash, blockNumber *big.Int) ([][]byte, error) {
+	return failover(ctx, c, func(client EthereumClient) ([][]byte, error) {
+		return client.ReadContractStorageBatch(ctx, contractAddress, storageKeys, blockNumber)
+	})
+}
+
+// CallContract executes an eth_call on the first available endpoint
+func (c *FailoverEthereumClient) CallContract(ctx context.Context, contractAddress common.Address, data []byte, blockNumber *big.Int) ([]byte, error) {
+	return failover(ctx, c, func(client EthereumClient) ([]byte, error) {
+		return client.CallContract(ctx, contractAddress, data, blockNumber)
+	})
+}
+
+// Close closes every endpoint, returning the first error
+func (c *FailoverEthereumClient) Close() error {
+	var firstErr error
+	for _, endpoint := range c.endpoints {
+		if err := endpoint.Client.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// CheckConnectionHealth reports whether any endpoint with a non-open circuit is healthy
+func (c *FailoverEthereumClient) CheckConnectionHealth(ctx context.Context) bool {
+	for _, endpoint := range c.endpoints {
+		if endpoint.breaker.State() != circuit.Open && endpoint.Client.CheckConnectionHealth(ctx) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckConnectionsHealth checks every connection of every endpoint, numbered in
+// failover order with a pooled endpoint's connections numbered consecutively.
+// Connections of an endpoint whose circuit is open are unhealthy without a check.
+func (c *FailoverEthereumClient) CheckConnectionsHealth(ctx context.Context) map[int]bool {
+	health := make(map[int]bool)
+	for _, endpoint := range c.endpoints {
+		offset := len(health)
+		pool, pooled := endpoint.Client.(connectionPool)
+		switch {
+		case endpoint.breaker.State() == circuit.Open:
+			connections := 1
+			if pooled {
+				connections = pool.GetConnectionCount()
+			}
+			for i := 0; i < connections; i++ {
+				health[offset+i] = false
+			}
+		case pooled:
+			for i, healthy := range pool.CheckConnectionsHealth(ctx) {
+				health[offset+i] = healthy
+			}
+		default:
+			health[offset] = endpoint.Client.CheckConnectionHealth(ctx)
+		}
+	}
+	return health
+}
+
+// EndpointStatuses returns every endpoint's circuit, in failover order
+func (c *FailoverEthereumClient) EndpointStatuses() []EndpointStatus {
+	statuses := make([]EndpointStatus, len(c.endpoints))
+	for i, endpoint := range c.endpoints {
+		statuses[i] = EndpointStatus{
+			Name:    endpoint.Name,
+			Host:    redactURL(endpoint.URL),
+			Circuit: endpoint.breaker.Snapshot(),
+		}
+	}
+	return statuses
+}
+
+// failover runs call against each endpoint in turn until one answers. Only
+// ErrConnectionFailed and ErrRPCTimeout move on to the next endpoint and count
+// against the circuit; any other error is the endpoint's answer and is returned
+// as is. Each failover is logged, and the endpoint that served the call at Debug.
+func failover[T any](ctx context.Context, c *FailoverEthereumClient, call func(EthereumClient) (T, error)) (T, error) {
+	var zero T
+	var lastErr error
+	log := logger.FromContext(ctx, c.logger)
+	for _, endpoint := range c.endpoints {
+		if !endpoint.breaker.Allow() {
+			continue
+		}
+
+		result, err := call(endpoint.Client)
+		switch {
+		case err == nil || errors.Is(err, ErrExecutionReverted):
+			// A revert is the contract's answer, which every endpoint would give
+			endpoint.breaker.Record(true)
+			log.Debug("RPC call served", zap.String("endpoint", endpoint.Name))
+			return result, err
+		case ctx.Err() != nil:
+			endpoint.breaker.Release()
+			return zero, err
+		case !errors.Is(err, ErrConnectionFailed) && !errors.Is(err, ErrRPCTimeout):
+			endpoint.breaker.Release()
+			return zero, err
+		}
+		log.Warn("RPC call failed, trying next endpoint",
+			zap.String("endpoint", endpoint.Name),
+			zap.Error(err),
+		)
+
+		if endpoint.breaker.Record(false) {
+			log.Warn("RPC endpoint circuit opened",
+				zap.String("endpoint", endpoint.Name),
+				zap.Duration("cooldown", c.cooldown),
+				zap.Error(err),
+			)
+		}
+		lastErr = err
+	}
+
+	if lastErr == nil {
+		return zero, fmt.Errorf("%w: %w: every RPC endpoint's circuit is open", ErrConnectionFailed, ErrCircuitOpen)
+	}
+	return zero, lastErr
+}
+
+// redactURL keeps only the scheme and host of an RPC URL
+func redactURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return "invalid"
+	}
+	return parsed.Scheme + "://" + parsed.Hostname()
+}
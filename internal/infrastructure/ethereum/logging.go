@@ -0,0 +1,81 @@
+package ethereum
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"bigswapenergy/internal/shared/logger"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+// LoggingEthereumClient logs every RPC call with its target and latency at Debug,
+// to show which reads dominate a slow request. It is verbose, so it is only
+// installed when logging.rpc_calls is set.
+type LoggingEthereumClient struct {
+	EthereumClient
+	logger *zap.Logger
+}
+
+// NewLoggingEthereumClient wraps client so each call is logged via the request's logger
+func NewLoggingEthereumClient(client EthereumClient, logger *zap.Logger) EthereumClient {
+	return &LoggingEthereumClient{EthereumClient: client, logger: logger}
+}
+
+// GetLatestBlockNumber delegates and logs the call
+func (c *LoggingEthereumClient) GetLatestBlockNumber(ctx context.Context) (uint64, error) {
+	start := time.Now()
+	blockNumber, err := c.EthereumClient.GetLatestBlockNumber(ctx)
+	c.logCall(ctx, "eth_blockNumber", start, err, zap.Uint64("block", blockNumber))
+	return blockNumber, err
+}
+
+// ReadContractStorage delegates and logs the call
+func (c *LoggingEthereumClient) ReadContractStorage(ctx context.Context, contractAddress common.Address, storageKey common.Hash, blockNumber *big.Int) ([]byte, error) {
+	start := time.Now()
+	data, err := c.EthereumClient.ReadContractStorage(ctx, contractAddress, storageKey, blockNumber)
+	c.logCall(ctx, "eth_getStorageAt", start, err,
+		zap.String("address", contractAddress.Hex()),
+		zap.String("slot", storageKey.Hex()),
+		zap.Stringer("block", blockNumber),
+	)
+	return data, err
+}
+
+// ReadContractStorageBatch delegates and logs the batch as one call
+func (c *LoggingEthereumClient) ReadContractStorageBatch(ctx context.Context, contractAddress common.Address, storageKeys []common.Hash, blockNumber *big.Int) ([][]byte, error) {
+	start := time.Now()
+	data, err := c.EthereumClient.ReadContractStorageBatch(ctx, contractAddress, storageKeys, blockNumber)
+	c.logCall(ctx, "eth_getStorageAt_batch", start, err,
+		zap.String("address", contractAddress.Hex()),
+		zap.Int("slots", len(storageKeys)),
+		zap.Stringer("block", blockNumber),
+	)
+	return data, err
+}
+
+// CallContract delegates and logs the call
+func (c *LoggingEthereumClient) CallContract(ctx context.Context, contractAddress common.Address, data []byte, blockNumber *big.Int) ([]byte, error) {
+	start := time.Now()
+	result, err := c.EthereumClient.CallContract(ctx, contractAddress, data, blockNumber)
+	c.logCall(ctx, "eth_call", start, err,
+		zap.String("address", contractAddress.Hex()),
+		zap.Stringer("block", blockNumber),
+	)
+	return result, err
+}
+
+// logCall emits one entry for a finished call. The request logger is forced to
+// Debug so enabling the flag is enough, without lowering the global level.
+func (c *LoggingEthereumClient) logCall(ctx context.Context, method string, start time.Time, err error, fields ...zap.Field) {
+	fields = append(fields,
+		zap.String("method", method),
+		zap.Duration("latency", time.Since(start)),
+	)
+	if err != nil {
+		fields = append(fields, zap.Error(err))
+	}
+	logger.WithDebug(logger.FromContext(ctx, c.logger)).Debug("RPC call", fields...)
+}
@@ -0,0 +1,56 @@
+package ethereum
+
+import (
+	"context"
+	"math/big"
+
+	"bigswapenergy/internal/shared/metrics"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// MeteredEthereumClient counts RPC calls and failed calls by method on an exporter
+type MeteredEthereumClient struct {
+	EthereumClient
+	exporter *metrics.Exporter
+}
+
+// NewMeteredEthereumClient wraps client so its calls are counted on exporter
+func NewMeteredEthereumClient(client EthereumClient, exporter *metrics.Exporter) EthereumClient {
+	return &MeteredEthereumClient{EthereumClient: client, exporter: exporter}
+}
+
+// GetLatestBlockNumber delegates and counts the call
+func (c *MeteredEthereumClient) GetLatestBlockNumber(ctx context.Context) (uint64, error) {
+	blockNumber, err := c.EthereumClient.GetLatestBlockNumber(ctx)
+	c.count("eth_blockNumber", err)
+	return blockNumber, err
+}
+
+// ReadContractStorage delegates and counts the call
+func (c *MeteredEthereumClient) ReadContractStorage(ctx context.Context, contractAddress common.Address, storageKey common.Hash, blockNumber *big.Int) ([]byte, error) {
+	data, err := c.EthereumClient.ReadContractStorage(ctx, contractAddress, storageKey, blockNumber)
+	c.count("eth_getStorageAt", err)
+	return data, err
+}
+
+// ReadContractStorageBatch delegates and counts the batch as one call
+func (c *MeteredEthereumClient) ReadContractStorageBatch(ctx context.Context, contractAddress common.Address, storageKeys []common.Hash, blockNumber *big.Int) ([][]byte, error) {
+	data, err := c.EthereumClient.ReadContractStorageBatch(ctx, contractAddress, storageKeys, blockNumber)
+	c.count("eth_getStorageAt_batch", err)
+	return data, err
+}
+
+// CallContract delegates and counts the call
+func (c *MeteredEthereumClient) CallContract(ctx context.Context, contractAddress common.Address, data []byte, blockNumber *big.Int) ([]byte, error) {
+	result, err := c.EthereumClient.CallContract(ctx, contractAddress, data, blockNumber)
+	c.count("eth_call", err)
+	return result, err
+}
+
+func (c *MeteredEthereumClient) count(method string, err error) {
+	c.exporter.RPCCalls.WithLabelValues(method).Inc()
+	if err != nil {
+		c.exporter.RPCErrors.WithLabelValues(method).Inc()
+	}
+}
@@ -0,0 +1,50 @@
+package ethereum
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"bigswapenergy/internal/shared/clock"
+)
+
+// MonotonicHeadEthereumClient never reports a head lower than the highest seen
+// within window. Load-balanced RPCs can briefly answer from a backend a block or
+// two behind; without smoothing, a quote pinned to the earlier, higher head could
+// fail to find state on the lagging backend. A lower head is accepted once the
+// highest has not been seen again for window, so a node that stays behind
+// doesn't pin the head forever.
+type MonotonicHeadEthereumClient struct {
+	EthereumClient
+
+	window time.Duration
+	clock  clock.Clock
+
+	mu      sync.Mutex
+	highest uint64
+	seenAt  time.Time
+}
+
+// NewMonotonicHeadEthereumClient wraps client so its head only moves backwards
+// after window
+func NewMonotonicHeadEthereumClient(client EthereumClient, window time.Duration, clk clock.Clock) *MonotonicHeadEthereumClient {
+	return &MonotonicHeadEthereumClient{EthereumClient: client, window: window, clock: clk}
+}
+
+// GetLatestBlockNumber returns the larger of the reported head and the highest
+// head seen within the window
+func (c *MonotonicHeadEthereumClient) GetLatestBlockNumber(ctx context.Context) (uint64, error) {
+	head, err := c.EthereumClient.GetLatestBlockNumber(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.clock.Now()
+	if head < c.highest && now.Sub(c.seenAt) < c.window {
+		return c.highest, nil
+	}
+	c.highest, c.seenAt = head, now
+	return head, nil
+}
@@ -0,0 +1,120 @@
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+// connectionPool is a client made of several connections, each checked separately
+type connectionPool interface {
+	CheckConnectionsHealth(ctx context.Context) map[int]bool
+	GetConnectionCount() int
+}
+
+// EthereumClientPool spreads calls round-robin over several clients of one RPC
+// endpoint, so a slow response on one connection doesn't queue the others
+type EthereumClientPool struct {
+	clients []EthereumClient
+	next    atomic.Uint64
+}
+
+// NewEthereumClientPool dials poolSize clients to rpcURL
+func NewEthereumClientPool(rpcURL string, poolSize int, logger *zap.Logger) (*EthereumClientPool, error) {
+	if poolSize < 1 {
+		return nil, fmt.Errorf("%w: pool size must be at least 1, got %d", ErrConnectionFailed, poolSize)
+	}
+
+	clients := make([]EthereumClient, 0, poolSize)
+	for i := 0; i < poolSize; i++ {
+		client, err := NewEthereumClient(rpcURL, logger)
+		if err != nil {
+			for _, dialed := range clients {
+				dialed.Close()
+			}
+			return nil, fmt.Errorf("connection %d: %w", i, err)
+		}
+		clients = append(clients, client)
+	}
+	return NewEthereumClientPoolOf(clients), nil
+}
+
+// NewEthereumClientPoolOf creates a pool over already connected clients
+func NewEthereumClientPoolOf(clients []EthereumClient) *EthereumClientPool {
+	return &EthereumClientPool{clients: clients}
+}
+
+// GetLatestBlockNumber returns the latest block from the next connection
+func (p *EthereumClientPool) GetLatestBlockNumber(ctx context.Context) (uint64, error) {
+	return p.pick().GetLatestBlockNumber(ctx)
+}
+
+// ReadContractStorage reads a storage slot through the next connection
+func (p *EthereumClientPool) ReadContractStorage(ctx context.Context, contractAddress common.Address, storageKey common.Hash, blockNumber *big.Int) ([]byte, error) {
+	return p.pick().ReadContractStorage(ctx, contractAddress, storageKey, blockNumber)
+}
+
+// ReadContractStorageBatch reads a batch of storage slots through the next connection
+func (p *EthereumClientPool) ReadContractStorageBatch(ctx context.Context, contractAddress common.Address, storageKeys []common.Hash, blockNumber *big.Int) ([][]byte, error) {
+	return p.pick().ReadContractStorageBatch(ctx, contractAddress, storageKeys, blockNumber)
+}
+
+// CallContract executes an eth_call through the next connection
+func (p *EthereumClientPool) CallContract(ctx context.Context, contractAddress common.Address, data []byte, blockNumber *big.Int) ([]byte, error) {
+	return p.pick().CallContract(ctx, contractAddress, data, blockNumber)
+}
+
+// Close closes every connection, returning the first error
+func (p *EthereumClientPool) Close() error {
+	var firstErr error
+	for _, client := range p.clients {
+		if err := client.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// CheckConnectionHealth reports whether any connection is healthy
+func (p *EthereumClientPool) CheckConnectionHealth(ctx context.Context) bool {
+	for _, healthy := range p.CheckConnectionsHealth(ctx) {
+		if healthy {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckConnectionsHealth checks every connection concurrently, keyed by its index
+func (p *EthereumClientPool) CheckConnectionsHealth(ctx context.Context) map[int]bool {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	health := make(map[int]bool, len(p.clients))
+	for i, client := range p.clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			healthy := client.CheckConnectionHealth(ctx)
+			mu.Lock()
+			health[i] = healthy
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return health
+}
+
+// GetConnectionCount returns the number of connections in the pool
+func (p *EthereumClientPool) GetConnectionCount() int {
+	return len(p.clients)
+}
+
+// pick returns the next connection in round-robin order
+func (p *EthereumClientPool) pick() EthereumClient {
+	return p.clients[(p.next.Add(1)-1)%uint64(len(p.clients))]
+}
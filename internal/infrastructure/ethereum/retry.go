@@ -0,0 +1,100 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"math/rand"
+	"time"
+
+	"bigswapenergy/internal/shared/config"
+	"bigswapenergy/internal/shared/logger"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+// RetryingEthereumClient retries block number and storage reads that timed out
+// or failed to reach the node, with exponential backoff. Other failures, such as
+// a node rejecting the call, are returned at once. A retry is never started when
+// its wait would run past the request's deadline.
+type RetryingEthereumClient struct {
+	EthereumClient
+	policy config.RetryConfig
+	logger *zap.Logger
+}
+
+// NewRetryingEthereumClient wraps client so transient failures are retried per policy
+func NewRetryingEthereumClient(client EthereumClient, policy config.RetryConfig, logger *zap.Logger) EthereumClient {
+	return &RetryingEthereumClient{EthereumClient: client, policy: policy, logger: logger}
+}
+
+// GetLatestBlockNumber delegates, retrying transient failures
+func (c *RetryingEthereumClient) GetLatestBlockNumber(ctx context.Context) (uint64, error) {
+	return retry(ctx, c, "eth_blockNumber", func() (uint64, error) {
+		return c.EthereumClient.GetLatestBlockNumber(ctx)
+	})
+}
+
+// ReadContractStorage delegates, retrying transient failures
+func (c *RetryingEthereumClient) ReadContractStorage(ctx context.Context, contractAddress common.Address, storageKey common.Hash, blockNumber *big.Int) ([]byte, error) {
+	return retry(ctx, c, "eth_getStorageAt", func() ([]byte, error) {
+		return c.EthereumClient.ReadContractStorage(ctx, contractAddress, storageKey, blockNumber)
+	})
+}
+
+// ReadContractStorageBatch delegates, retrying the whole batch on transient failures
+func (c *RetryingEthereumClient) ReadContractStorageBatch(ctx context.Context, contractAddress common.Address, storageKeys []common.Hash, blockNumber *big.Int) ([][]byte, error) {
+	return retry(ctx, c, "eth_getStorageAt_batch", func() ([][]byte, error) {
+		return c.EthereumClient.ReadContractStorageBatch(ctx, contractAddress, storageKeys, blockNumber)
+	})
+}
+
+// retry runs call until it succeeds, fails permanently, or runs out of attempts
+// or time, returning the last error
+func retry[T any](ctx context.Context, c *RetryingEthereumClient, method string, call func() (T, error)) (T, error) {
+	delay := c.policy.BaseDelay
+	for attempt := 1; ; attempt++ {
+		result, err := call()
+		if err == nil || !isTransient(err) || attempt >= c.policy.MaxAttempts || ctx.Err() != nil {
+			return result, err
+		}
+
+		wait := c.jittered(delay)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= wait {
+			return result, err
+		}
+		logger.FromContext(ctx, c.logger).Debug("Retrying RPC call",
+			zap.String("method", method),
+			zap.Int("attempt", attempt+1),
+			zap.Duration("delay", wait),
+			zap.Error(err),
+		)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return result, err
+		case <-timer.C:
+		}
+		delay *= 2
+	}
+}
+
+// jittered varies delay by up to the policy's jitter fraction either way
+func (c *RetryingEthereumClient) jittered(delay time.Duration) time.Duration {
+	if c.policy.Jitter == 0 {
+		return delay
+	}
+	return time.Duration(float64(delay) * (1 + c.policy.Jitter*(2*rand.Float64()-1)))
+}
+
+// isTransient reports whether a failed call may succeed if simply sent again.
+// An open circuit is left to its cooldown rather than retried.
+func isTransient(err error) bool {
+	if errors.Is(err, ErrCircuitOpen) {
+		return false
+	}
+	return errors.Is(err, ErrRPCTimeout) || errors.Is(err, ErrConnectionFailed)
+}
@@ -0,0 +1,416 @@
+package uniswap_v2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"bigswapenergy/internal/shared/clock"
+	"bigswapenergy/internal/shared/janitor"
+	"bigswapenergy/internal/shared/rpcbudget"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+// tokenCacheEntry holds a pool's token pair and when it stops being served
+type tokenCacheEntry struct {
+	token0    common.Address
+	token1    common.Address
+	expiresAt time.Time
+}
+
+// decimalsCacheEntry holds a token's decimals, or why it has none usable
+type decimalsCacheEntry struct {
+	decimals uint8
+	err      error
+}
+
+// feeCacheEntry holds a pool's on-chain fee, or why it has none, and when it stops being served
+type feeCacheEntry struct {
+	fee       *big.Int
+	err       error
+	expiresAt time.Time
+}
+
+// reservesCacheEntry holds a pool's reserves, their blockTimestampLast and the
+// block they were read at
+type reservesCacheEntry struct {
+	reserve0  *big.Int
+	reserve1  *big.Int
+	timestamp uint32
+	block     uint64
+}
+
+// ReservesBlockLoader is implemented by clients that may serve reserves read at
+// an earlier block than the one requested. readAt is the block they were read at.
+type ReservesBlockLoader interface {
+	LoadReservesWithBlock(ctx context.Context, pool common.Address, blockNum *big.Int) (reserve0, reserve1 *big.Int, readAt uint64, err error)
+}
+
+// tokenCall is an in-flight token read that concurrent misses for the same pool wait on
+type tokenCall struct {
+	done   chan struct{}
+	token0 common.Address
+	token1 common.Address
+	err    error
+	// leaderSpent is set when err came from the leader's own request being
+	// cancelled, timing out or running out of RPC budget
+	leaderSpent bool
+}
+
+// CachedUniswapV2Client wraps a UniswapV2Client and caches pool token addresses.
+// token0/token1 are immutable for a genuine pair, but the cache still honours a
+// finite TTL so proxy or upgradeable contracts can't serve stale tokens forever.
+type CachedUniswapV2Client struct {
+	UniswapV2Client
+
+	tokenTTL time.Duration
+	clock    clock.Clock
+	logger   *zap.Logger
+
+	tokensMux sync.RWMutex
+	tokens    map[common.Address]tokenCacheEntry
+
+	// decimals never expire: a token's decimals() is immutable
+	decimalsMux sync.RWMutex
+	decimals    map[common.Address]decimalsCacheEntry
+
+	// fees follow the token TTL, since some forks let governance change them
+	feesMux sync.RWMutex
+	fees    map[common.Address]feeCacheEntry
+
+	// reserves are served for up to reservesMaxAge blocks after the block they
+	// were read at; 0 disables reserves caching
+	reservesMaxAge uint64
+	reservesMux    sync.RWMutex
+	reserves       map[common.Address]reservesCacheEntry
+	// reservesHead is the highest block reserves were requested at, for the janitor
+	reservesHead atomic.Uint64
+
+	// ttlJitter spreads each entry's TTL uniformly within ±ttlJitter of tokenTTL
+	ttlJitter float64
+	// inflight holds the reads being shared when single-flight is enabled; nil otherwise
+	inflightMux sync.Mutex
+	inflight    map[common.Address]*tokenCall
+
+	janitor *janitor.Janitor
+}
+
+// NewCachedUniswapV2Client creates a caching decorator around client.
+// A non-positive tokenTTL disables token caching.
+func NewCachedUniswapV2Client(client UniswapV2Client, tokenTTL time.Duration, clk clock.Clock, logger *zap.Logger) *CachedUniswapV2Client {
+	return &CachedUniswapV2Client{
+		UniswapV2Client: client,
+		tokenTTL:        tokenTTL,
+		clock:           clk,
+		logger:          logger,
+		tokens:          make(map[common.Address]tokenCacheEntry),
+		decimals:        make(map[common.Address]decimalsCacheEntry),
+		fees:            make(map[common.Address]feeCacheEntry),
+		reserves:        make(map[common.Address]reservesCacheEntry),
+	}
+}
+
+// SetTTLJitter spreads expirations by giving each entry a TTL drawn uniformly from
+// tokenTTL ± fraction, so pools loaded together don't all expire together.
+// Must be called before the client is used.
+func (c *CachedUniswapV2Client) SetTTLJitter(fraction float64) {
+	c.ttlJitter = fraction
+}
+
+// EnableSingleFlight makes concurrent misses for the same pool share one
+// underlying read. Waiters stop on their own context, and get the leader's
+// result unless the read failed only because the leader's request was
+// cancelled, timed out or ran out of RPC budget; then they read the tokens
+// themselves. Must be called before the client is used.
+func (c *CachedUniswapV2Client) EnableSingleFlight() {
+	c.inflight = make(map[common.Address]*tokenCall)
+}
+
+// EnableReservesCache serves a pool's reserves for up to maxAgeBlocks blocks after
+// the block they were read at. Quotes from cached reserves may be stale; the service
+// reports their age. Must be called before the client is used.
+func (c *CachedUniswapV2Client) EnableReservesCache(maxAgeBlocks uint64) {
+	c.reservesMaxAge = maxAgeBlocks
+}
+
+// LoadTokens returns the cached token pair for pool, reading it from storage on a miss
+func (c *CachedUniswapV2Client) LoadTokens(ctx context.Context, pool common.Address, blockNum *big.Int) (common.Address, common.Address, error) {
+	if c.tokenTTL <= 0 {
+		return c.UniswapV2Client.LoadTokens(ctx, pool, blockNum)
+	}
+
+	if entry, ok := c.freshTokens(pool); ok {
+		return entry.token0, entry.token1, nil
+	}
+
+	if c.inflight == nil {
+		return c.loadAndStore(ctx, pool, blockNum)
+	}
+
+	c.inflightMux.Lock()
+	if call, ok := c.inflight[pool]; ok {
+		c.inflightMux.Unlock()
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return common.Address{}, common.Address{}, ctx.Err()
+		}
+		if call.leaderSpent && !requestSpent(ctx) {
+			return c.LoadTokens(ctx, pool, blockNum)
+		}
+		return call.token0, call.token1, call.err
+	}
+	call := &tokenCall{done: make(chan struct{})}
+	c.inflight[pool] = call
+	c.inflightMux.Unlock()
+
+	call.token0, call.token1, call.err = c.loadAndStore(ctx, pool, blockNum)
+	call.leaderSpent = call.err != nil && requestSpent(ctx)
+
+	c.inflightMux.Lock()
+	delete(c.inflight, pool)
+	c.inflightMux.Unlock()
+	close(call.done)
+
+	return call.token0, call.token1, call.err
+}
+
+// requestSpent reports whether ctx's request can no longer make RPC calls: it is
+// done or has exceeded its RPC budget
+func requestSpent(ctx context.Context) bool {
+	if ctx.Err() != nil {
+		return true
+	}
+	budget := rpcbudget.FromContext(ctx)
+	return budget != nil && budget.Exceeded()
+}
+
+// freshTokens returns the cached token pair for pool if it has not expired
+func (c *CachedUniswapV2Client) freshTokens(pool common.Address) (tokenCacheEntry, bool) {
+	c.tokensMux.RLock()
+	entry, ok := c.tokens[pool]
+	c.tokensMux.RUnlock()
+	return entry, ok && c.clock.Now().Before(entry.expiresAt)
+}
+
+// loadAndStore reads the pool's tokens and caches them with a jittered TTL
+func (c *CachedUniswapV2Client) loadAndStore(ctx context.Context, pool common.Address, blockNum *big.Int) (common.Address, common.Address, error) {
+	token0, token1, err := c.UniswapV2Client.LoadTokens(ctx, pool, blockNum)
+	if err != nil {
+		return common.Address{}, common.Address{}, err
+	}
+	c.storeTokens(pool, token0, token1)
+	return token0, token1, nil
+}
+
+// storeTokens caches the pool's token pair with a jittered TTL
+func (c *CachedUniswapV2Client) storeTokens(pool, token0, token1 common.Address) {
+	c.tokensMux.Lock()
+	c.tokens[pool] = tokenCacheEntry{
+		token0:    token0,
+		token1:    token1,
+		expiresAt: c.clock.Now().Add(c.entryTTL()),
+	}
+	c.tokensMux.Unlock()
+}
+
+// LoadPoolState returns the pool's tokens and reserves. When the tokens are
+// cached, or reserves may be served from the reserves cache, they are loaded
+// separately as LoadTokens and LoadReservesWithBlock would. Otherwise both are
+// read together through the wrapped client, without single-flight, and the
+// tokens are cached.
+func (c *CachedUniswapV2Client) LoadPoolState(ctx context.Context, pool common.Address, blockNum *big.Int) (*PoolState, error) {
+	_, cached := c.freshTokens(pool)
+	if cached || c.reservesMaxAge > 0 {
+		token0, token1, err := c.LoadTokens(ctx, pool, blockNum)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrPoolTokensUnavailable, err)
+		}
+		entry, err := c.loadReserves(ctx, pool, blockNum)
+		if err != nil {
+			return nil, err
+		}
+		return &PoolState{Token0: token0, Token1: token1, Reserve0: entry.reserve0, Reserve1: entry.reserve1, ReadAt: entry.block, BlockTimestampLast: entry.timestamp}, nil
+	}
+
+	state, err := loadPoolState(ctx, c.UniswapV2Client, pool, blockNum)
+	if err != nil {
+		return nil, err
+	}
+	if c.tokenTTL > 0 {
+		c.storeTokens(pool, state.Token0, state.Token1)
+	}
+	return state, nil
+}
+
+// LoadTokenDecimals returns the cached decimals for token, calling decimals() on a
+// miss. Entries are kept for the life of the process, regardless of the token
+// TTL. A token without usable decimals() is cached too; other errors are not.
+func (c *CachedUniswapV2Client) LoadTokenDecimals(ctx context.Context, token common.Address) (uint8, error) {
+	c.decimalsMux.RLock()
+	entry, ok := c.decimals[token]
+	c.decimalsMux.RUnlock()
+	if ok {
+		return entry.decimals, entry.err
+	}
+
+	decimals, err := c.UniswapV2Client.LoadTokenDecimals(ctx, token)
+	if err != nil && !errors.Is(err, ErrNoDecimals) && !errors.Is(err, ErrInvalidDecimals) {
+		return 0, err
+	}
+
+	c.decimalsMux.Lock()
+	c.decimals[token] = decimalsCacheEntry{decimals: decimals, err: err}
+	c.decimalsMux.Unlock()
+
+	return decimals, err
+}
+
+// LoadPoolFee returns the cached fee for pool, calling the fee method on a miss.
+// A pool without the method is cached too; other errors are not. The cache is
+// keyed by pool alone, as a pool belongs to a single factory and fee method.
+func (c *CachedUniswapV2Client) LoadPoolFee(ctx context.Context, pool common.Address, selector []byte) (*big.Int, error) {
+	if c.tokenTTL <= 0 {
+		return c.UniswapV2Client.LoadPoolFee(ctx, pool, selector)
+	}
+
+	c.feesMux.RLock()
+	entry, ok := c.fees[pool]
+	c.feesMux.RUnlock()
+	if ok && c.clock.Now().Before(entry.expiresAt) {
+		return entry.fee, entry.err
+	}
+
+	fee, err := c.UniswapV2Client.LoadPoolFee(ctx, pool, selector)
+	if err != nil && !errors.Is(err, ErrNoFeeMethod) {
+		return nil, err
+	}
+
+	c.feesMux.Lock()
+	c.fees[pool] = feeCacheEntry{fee: fee, err: err, expiresAt: c.clock.Now().Add(c.entryTTL())}
+	c.feesMux.Unlock()
+
+	return fee, err
+}
+
+// LoadReserves returns the pool's reserves, from the cache when reserves caching
+// is enabled and an entry is recent enough
+func (c *CachedUniswapV2Client) LoadReserves(ctx context.Context, pool common.Address, blockNum *big.Int) (*big.Int, *big.Int, error) {
+	reserve0, reserve1, _, err := c.LoadReservesWithBlock(ctx, pool, blockNum)
+	return reserve0, reserve1, err
+}
+
+// LoadReservesWithBlock returns the pool's reserves and the block they were read at.
+// A cached entry is served when it was read at most reservesMaxAge blocks before
+// blockNum; a request for an earlier block than the entry's always reads. Errors
+// are not cached.
+func (c *CachedUniswapV2Client) LoadReservesWithBlock(ctx context.Context, pool common.Address, blockNum *big.Int) (*big.Int, *big.Int, uint64, error) {
+	entry, err := c.loadReserves(ctx, pool, blockNum)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return entry.reserve0, entry.reserve1, entry.block, nil
+}
+
+// loadReserves implements LoadReservesWithBlock, also returning blockTimestampLast
+// when the wrapped client reports it
+func (c *CachedUniswapV2Client) loadReserves(ctx context.Context, pool common.Address, blockNum *big.Int) (reservesCacheEntry, error) {
+	if c.reservesMaxAge == 0 || blockNum == nil {
+		reserve0, reserve1, timestamp, err := loadReservesWithTimestamp(ctx, c.UniswapV2Client, pool, blockNum)
+		if err != nil {
+			return reservesCacheEntry{}, err
+		}
+		return reservesCacheEntry{reserve0: reserve0, reserve1: reserve1, timestamp: timestamp, block: blockUint64(blockNum)}, nil
+	}
+
+	block := blockNum.Uint64()
+	for {
+		head := c.reservesHead.Load()
+		if block <= head || c.reservesHead.CompareAndSwap(head, block) {
+			break
+		}
+	}
+
+	c.reservesMux.RLock()
+	entry, ok := c.reserves[pool]
+	c.reservesMux.RUnlock()
+	if ok && entry.block <= block && block-entry.block <= c.reservesMaxAge {
+		return entry, nil
+	}
+
+	reserve0, reserve1, timestamp, err := loadReservesWithTimestamp(ctx, c.UniswapV2Client, pool, blockNum)
+	if err != nil {
+		return reservesCacheEntry{}, err
+	}
+	entry = reservesCacheEntry{reserve0: reserve0, reserve1: reserve1, timestamp: timestamp, block: block}
+
+	c.reservesMux.Lock()
+	if current, ok := c.reserves[pool]; !ok || current.block < block {
+		c.reserves[pool] = entry
+	}
+	c.reservesMux.Unlock()
+
+	return entry, nil
+}
+
+// entryTTL returns tokenTTL, jittered when SetTTLJitter was called
+func (c *CachedUniswapV2Client) entryTTL() time.Duration {
+	if c.ttlJitter <= 0 {
+		return c.tokenTTL
+	}
+	factor := 1 + c.ttlJitter*(2*rand.Float64()-1)
+	return time.Duration(float64(c.tokenTTL) * factor)
+}
+
+// StartJanitor evicts expired token, fee and reserves entries every interval. It
+// does nothing when token caching is disabled.
+func (c *CachedUniswapV2Client) StartJanitor(interval time.Duration) {
+	if c.tokenTTL <= 0 {
+		return
+	}
+	c.janitor = janitor.Start(interval, c.evictExpiredTokens)
+}
+
+// Stop stops the janitor, if started, and waits for it to exit
+func (c *CachedUniswapV2Client) Stop() {
+	if c.janitor != nil {
+		c.janitor.Stop()
+	}
+}
+
+// evictExpiredTokens drops token and fee entries past their TTL, and reserves too
+// old to be served at the latest requested block, so pools queried once don't
+// stay cached forever
+func (c *CachedUniswapV2Client) evictExpiredTokens() {
+	c.tokensMux.Lock()
+	for pool, entry := range c.tokens {
+		if !c.clock.Now().Before(entry.expiresAt) {
+			delete(c.tokens, pool)
+		}
+	}
+	c.tokensMux.Unlock()
+
+	c.feesMux.Lock()
+	for pool, entry := range c.fees {
+		if !c.clock.Now().Before(entry.expiresAt) {
+			delete(c.fees, pool)
+		}
+	}
+	c.feesMux.Unlock()
+
+	head := c.reservesHead.Load()
+	c.reservesMux.Lock()
+	for pool, entry := range c.reserves {
+		if entry.block+c.reservesMaxAge < head {
+			delete(c.reserves, pool)
+		}
+	}
+	c.reservesMux.Unlock()
+}
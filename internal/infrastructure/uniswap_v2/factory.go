@@ -0,0 +1,168 @@
+package uniswap_v2
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"bigswapenergy/internal/shared/config"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	ErrUnknownFactory = fmt.Errorf("Unknown factory")
+	ErrInvalidFactory = fmt.Errorf("Invalid factory configuration")
+)
+
+// Factory describes a Uniswap V2 style factory whose pair addresses are derived with CREATE2
+type Factory struct {
+	Name           string
+	Address        common.Address
+	InitCodeHash   common.Hash
+	FeeBasisPoints int
+
+	// ProtocolCut is the fraction of the fee taken from swap output; nil for standard V2
+	ProtocolCut *big.Rat
+
+	// FeeMethod reads the pair's fee from chain; nil when the fee is only configured
+	FeeMethod *FeeMethod
+}
+
+// FeeMethod is a pair view returning the pool fee as a fraction of Denominator
+type FeeMethod struct {
+	Signature   string
+	Selector    []byte
+	Denominator uint64
+}
+
+// BasisPoints converts an on-chain fee to the basis points of FeeBasisPoints. ok is
+// false when the fee is not a whole number of basis points or is 100% or more.
+func (m FeeMethod) BasisPoints(fee *big.Int) (basisPoints int, ok bool) {
+	scaled := new(big.Int).Mul(fee, big.NewInt(10000))
+	quotient, remainder := scaled.QuoRem(scaled, new(big.Int).SetUint64(m.Denominator), new(big.Int))
+	if remainder.Sign() != 0 || quotient.Sign() < 0 || quotient.Cmp(big.NewInt(10000)) >= 0 {
+		return 0, false
+	}
+	return int(quotient.Int64()), true
+}
+
+// PairFor derives the pair address for two tokens the same way the factory's
+// CREATE2 deployment does: keccak256(0xff ++ factory ++ keccak256(token0 ++ token1) ++ initCodeHash)
+func (f Factory) PairFor(tokenA, tokenB common.Address) common.Address {
+	token0, token1 := SortTokens(tokenA, tokenB)
+	salt := crypto.Keccak256Hash(token0.Bytes(), token1.Bytes())
+	return crypto.CreateAddress2(f.Address, salt, f.InitCodeHash.Bytes())
+}
+
+// SortTokens orders two token addresses the way Uniswap V2 assigns token0 and token1
+func SortTokens(tokenA, tokenB common.Address) (common.Address, common.Address) {
+	if bytes.Compare(tokenA[:], tokenB[:]) < 0 {
+		return tokenA, tokenB
+	}
+	return tokenB, tokenA
+}
+
+// FactoryRegistry resolves factories by their configured name
+type FactoryRegistry struct {
+	factories map[string]Factory
+}
+
+// NewFactoryRegistry builds a registry from the factory configuration, validating
+// every address and init code hash
+func NewFactoryRegistry(factories map[string]config.FactoryConfig) (*FactoryRegistry, error) {
+	registry := &FactoryRegistry{
+		factories: make(map[string]Factory, len(factories)),
+	}
+
+	for name, factoryConfig := range factories {
+		if !common.IsHexAddress(factoryConfig.Address) {
+			return nil, fmt.Errorf("%w: factory %s has invalid address %q", ErrInvalidFactory, name, factoryConfig.Address)
+		}
+		initCodeHash := common.FromHex(factoryConfig.InitCodeHash)
+		if len(initCodeHash) != common.HashLength {
+			return nil, fmt.Errorf("%w: factory %s has invalid init code hash %q", ErrInvalidFactory, name, factoryConfig.InitCodeHash)
+		}
+		if factoryConfig.FeeBasisPoints < 0 || factoryConfig.FeeBasisPoints >= 10000 {
+			return nil, fmt.Errorf("%w: factory %s has invalid fee %d", ErrInvalidFactory, name, factoryConfig.FeeBasisPoints)
+		}
+
+		protocolCut, err := parseProtocolCut(factoryConfig.ProtocolCut)
+		if err != nil {
+			return nil, fmt.Errorf("%w: factory %s: %v", ErrInvalidFactory, name, err)
+		}
+
+		feeMethod, err := parseFeeMethod(factoryConfig.FeeMethod, factoryConfig.FeeMethodDenominator)
+		if err != nil {
+			return nil, fmt.Errorf("%w: factory %s: %v", ErrInvalidFactory, name, err)
+		}
+
+		registry.factories[name] = Factory{
+			Name:           name,
+			Address:        common.HexToAddress(factoryConfig.Address),
+			InitCodeHash:   common.BytesToHash(initCodeHash),
+			FeeBasisPoints: factoryConfig.FeeBasisPoints,
+			ProtocolCut:    protocolCut,
+			FeeMethod:      feeMethod,
+		}
+	}
+
+	return registry, nil
+}
+
+// parseProtocolCut parses a fraction such as "1/6"; empty or zero means no cut
+func parseProtocolCut(value string) (*big.Rat, error) {
+	if value == "" {
+		return nil, nil
+	}
+	cut, ok := new(big.Rat).SetString(value)
+	if !ok || cut.Sign() < 0 || cut.Cmp(big.NewRat(1, 1)) >= 0 {
+		return nil, fmt.Errorf("protocol cut must be a fraction in [0, 1), got %q", value)
+	}
+	if cut.Sign() == 0 {
+		return nil, nil
+	}
+	return cut, nil
+}
+
+// parseFeeMethod parses a no-argument method signature such as "swapFee()"; empty
+// means the fee is not read from chain
+func parseFeeMethod(signature string, denominator uint64) (*FeeMethod, error) {
+	if signature == "" {
+		return nil, nil
+	}
+	name, ok := strings.CutSuffix(signature, "()")
+	if !ok || name == "" || strings.ContainsAny(name, "() ,") {
+		return nil, fmt.Errorf("fee method must be a no-argument signature such as \"swapFee()\", got %q", signature)
+	}
+	if denominator == 0 {
+		return nil, fmt.Errorf("fee method %s requires a positive fee_method_denominator", signature)
+	}
+	return &FeeMethod{
+		Signature:   signature,
+		Selector:    crypto.Keccak256([]byte(signature))[:4],
+		Denominator: denominator,
+	}, nil
+}
+
+// Get returns the factory registered under name
+func (r *FactoryRegistry) Get(name string) (Factory, error) {
+	factory, ok := r.factories[name]
+	if !ok {
+		return Factory{}, fmt.Errorf("%w: %s (known: %v)", ErrUnknownFactory, name, r.Names())
+	}
+	return factory, nil
+}
+
+// Names returns the registered factory names in sorted order
+func (r *FactoryRegistry) Names() []string {
+	names := make([]string, 0, len(r.factories))
+	for name := range r.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
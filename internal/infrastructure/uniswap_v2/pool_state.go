@@ -0,0 +1,102 @@
+package uniswap_v2
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// PoolState is a pool's tokens and reserves. ReadAt is the block the reserves
+// were read at, earlier than the one requested when they came from a cache.
+// BlockTimestampLast is the pair's last reserves update, 0 when the client
+// could not report it.
+type PoolState struct {
+	Token0             common.Address
+	Token1             common.Address
+	Reserve0           *big.Int
+	Reserve1           *big.Int
+	ReadAt             uint64
+	BlockTimestampLast uint32
+}
+
+// ReservesTimestampLoader is implemented by clients that can also return the
+// pair's blockTimestampLast, packed into the same storage word as the reserves
+type ReservesTimestampLoader interface {
+	LoadReservesWithTimestamp(ctx context.Context, pool common.Address, blockNum *big.Int) (reserve0, reserve1 *big.Int, blockTimestampLast uint32, err error)
+}
+
+// PoolStateLoader is implemented by clients that can read a pool's tokens and
+// reserves together, in fewer round trips than LoadTokens followed by
+// LoadReserves. Failures reading or validating the tokens match
+// ErrPoolTokensUnavailable; reserves failures are returned as LoadReserves would.
+type PoolStateLoader interface {
+	LoadPoolState(ctx context.Context, pool common.Address, blockNum *big.Int) (*PoolState, error)
+}
+
+// LoadPoolState reads token0, token1 and the reserves in a single storage batch
+func (c *UniswapV2ClientImpl) LoadPoolState(ctx context.Context, pool common.Address, blockNum *big.Int) (*PoolState, error) {
+	words, err := c.client.ReadContractStorageBatch(ctx, pool, []common.Hash{
+		slotKey(UniswapV2Token0StorageSlot),
+		slotKey(UniswapV2Token1StorageSlot),
+		slotKey(UniswapV2ReservesStorageSlot),
+	}, blockNum)
+	if err != nil {
+		// Read one by one, the token0 read would have failed first
+		return nil, fmt.Errorf("%w: failed to read token0: %w", ErrPoolTokensUnavailable, err)
+	}
+
+	token0, token1, err := tokensFromWords(pool, words[0], words[1])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrPoolTokensUnavailable, err)
+	}
+	reserve0, reserve1, timestamp, err := reservesFromWord(pool, words[2])
+	if err != nil {
+		return nil, err
+	}
+	return &PoolState{Token0: token0, Token1: token1, Reserve0: reserve0, Reserve1: reserve1, ReadAt: blockUint64(blockNum), BlockTimestampLast: timestamp}, nil
+}
+
+// loadPoolState reads the pool's state through client in one batch when it
+// supports it, and with separate token and reserves reads otherwise
+func loadPoolState(ctx context.Context, client UniswapV2Client, pool common.Address, blockNum *big.Int) (*PoolState, error) {
+	if loader, ok := client.(PoolStateLoader); ok {
+		return loader.LoadPoolState(ctx, pool, blockNum)
+	}
+
+	token0, token1, err := client.LoadTokens(ctx, pool, blockNum)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrPoolTokensUnavailable, err)
+	}
+	reserve0, reserve1, timestamp, err := loadReservesWithTimestamp(ctx, client, pool, blockNum)
+	if err != nil {
+		return nil, err
+	}
+	return &PoolState{Token0: token0, Token1: token1, Reserve0: reserve0, Reserve1: reserve1, ReadAt: blockUint64(blockNum), BlockTimestampLast: timestamp}, nil
+}
+
+// loadReservesWithTimestamp reads the pool's reserves through client, with
+// blockTimestampLast when it supports that and 0 otherwise
+func loadReservesWithTimestamp(ctx context.Context, client UniswapV2Client, pool common.Address, blockNum *big.Int) (*big.Int, *big.Int, uint32, error) {
+	if loader, ok := client.(ReservesTimestampLoader); ok {
+		return loader.LoadReservesWithTimestamp(ctx, pool, blockNum)
+	}
+	reserve0, reserve1, err := client.LoadReserves(ctx, pool, blockNum)
+	return reserve0, reserve1, 0, err
+}
+
+// slotKey returns the storage key of a low-numbered slot
+func slotKey(slot uint64) common.Hash {
+	var key common.Hash
+	key[31] = byte(slot)
+	return key
+}
+
+// blockUint64 returns blockNum as a uint64, or 0 for latest
+func blockUint64(blockNum *big.Int) uint64 {
+	if blockNum == nil {
+		return 0
+	}
+	return blockNum.Uint64()
+}
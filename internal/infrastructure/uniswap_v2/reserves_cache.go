@@ -0,0 +1,199 @@
+package uniswap_v2
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"bigswapenergy/internal/shared/clock"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// blockReservesKey identifies one pool's reserves at one block
+type blockReservesKey struct {
+	pool  common.Address
+	block uint64
+}
+
+// blockReservesEntry is an LRU element of ReservesCachingUniswapV2Client
+type blockReservesEntry struct {
+	key       blockReservesKey
+	reserve0  *big.Int
+	reserve1  *big.Int
+	timestamp uint32
+	expiresAt time.Time
+}
+
+// ReservesCacheStats counts lookups served from, and missed by, the reserves cache
+type ReservesCacheStats struct {
+	Hits    uint64
+	Misses  uint64
+	Entries int
+}
+
+// ReservesCachingUniswapV2Client wraps a UniswapV2Client and caches reserves read
+// at an explicit block, keyed by pool and block, so concurrent quotes for a hot
+// pool share one storage read. Unlike CachedUniswapV2Client's reserves cache it
+// never serves reserves from another block. Entries live for ttl and at most
+// maxEntries are kept, least recently used first out. Only the highest block
+// requested so far is cached: entries for earlier blocks are dropped once a later
+// block is requested. Reads at latest (a nil block) and errors are not cached.
+type ReservesCachingUniswapV2Client struct {
+	UniswapV2Client
+
+	ttl        time.Duration
+	maxEntries int
+	clock      clock.Clock
+
+	mu      sync.Mutex
+	entries map[blockReservesKey]*list.Element
+	lru     *list.List
+	// head is the highest block requested; entries below it are stale
+	head uint64
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+// NewReservesCachingUniswapV2Client creates a reserves caching decorator around
+// client holding up to maxEntries entries for ttl each
+func NewReservesCachingUniswapV2Client(client UniswapV2Client, ttl time.Duration, maxEntries int, clk clock.Clock) *ReservesCachingUniswapV2Client {
+	return &ReservesCachingUniswapV2Client{
+		UniswapV2Client: client,
+		ttl:             ttl,
+		maxEntries:      maxEntries,
+		clock:           clk,
+		entries:         make(map[blockReservesKey]*list.Element),
+		lru:             list.New(),
+	}
+}
+
+// LoadReserves returns the pool's reserves at blockNum, from the cache when an
+// unexpired entry exists
+func (c *ReservesCachingUniswapV2Client) LoadReserves(ctx context.Context, pool common.Address, blockNum *big.Int) (*big.Int, *big.Int, error) {
+	reserve0, reserve1, _, err := c.LoadReservesWithTimestamp(ctx, pool, blockNum)
+	return reserve0, reserve1, err
+}
+
+// LoadReservesWithTimestamp is LoadReserves that also returns blockTimestampLast,
+// 0 when the wrapped client cannot report it
+func (c *ReservesCachingUniswapV2Client) LoadReservesWithTimestamp(ctx context.Context, pool common.Address, blockNum *big.Int) (*big.Int, *big.Int, uint32, error) {
+	if blockNum == nil {
+		return loadReservesWithTimestamp(ctx, c.UniswapV2Client, pool, blockNum)
+	}
+
+	key := blockReservesKey{pool: pool, block: blockNum.Uint64()}
+	if entry, ok := c.lookup(key); ok {
+		c.hits.Add(1)
+		return entry.reserve0, entry.reserve1, entry.timestamp, nil
+	}
+	c.misses.Add(1)
+
+	reserve0, reserve1, timestamp, err := loadReservesWithTimestamp(ctx, c.UniswapV2Client, pool, blockNum)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	c.store(key, reserve0, reserve1, timestamp)
+	return reserve0, reserve1, timestamp, nil
+}
+
+// LoadPoolState reads only the tokens when the reserves are cached. On a miss it
+// reads the whole state through the wrapped client, batched when it supports
+// that, and caches the reserves.
+func (c *ReservesCachingUniswapV2Client) LoadPoolState(ctx context.Context, pool common.Address, blockNum *big.Int) (*PoolState, error) {
+	if blockNum == nil {
+		return loadPoolState(ctx, c.UniswapV2Client, pool, blockNum)
+	}
+
+	key := blockReservesKey{pool: pool, block: blockNum.Uint64()}
+	if entry, ok := c.lookup(key); ok {
+		c.hits.Add(1)
+		token0, token1, err := c.UniswapV2Client.LoadTokens(ctx, pool, blockNum)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrPoolTokensUnavailable, err)
+		}
+		return &PoolState{Token0: token0, Token1: token1, Reserve0: entry.reserve0, Reserve1: entry.reserve1, ReadAt: key.block, BlockTimestampLast: entry.timestamp}, nil
+	}
+	c.misses.Add(1)
+
+	state, err := loadPoolState(ctx, c.UniswapV2Client, pool, blockNum)
+	if err != nil {
+		return nil, err
+	}
+	c.store(key, state.Reserve0, state.Reserve1, state.BlockTimestampLast)
+	return state, nil
+}
+
+// Stats returns the cache's hit and miss counts and current size
+func (c *ReservesCachingUniswapV2Client) Stats() ReservesCacheStats {
+	c.mu.Lock()
+	entries := c.lru.Len()
+	c.mu.Unlock()
+	return ReservesCacheStats{Hits: c.hits.Load(), Misses: c.misses.Load(), Entries: entries}
+}
+
+// lookup returns the entry for key when it has not expired, advancing the head
+// and marking the entry recently used
+func (c *ReservesCachingUniswapV2Client) lookup(key blockReservesKey) (*blockReservesEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.advanceHead(key.block)
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*blockReservesEntry)
+	if !c.clock.Now().Before(entry.expiresAt) {
+		c.remove(element)
+		return nil, false
+	}
+	c.lru.MoveToFront(element)
+	return entry, true
+}
+
+// store caches reserves for key, evicting the least recently used entries past maxEntries
+func (c *ReservesCachingUniswapV2Client) store(key blockReservesKey, reserve0, reserve1 *big.Int, timestamp uint32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Only the head is cached; an earlier block is historical or was overtaken mid-read
+	if key.block < c.head {
+		return
+	}
+	entry := &blockReservesEntry{key: key, reserve0: reserve0, reserve1: reserve1, timestamp: timestamp, expiresAt: c.clock.Now().Add(c.ttl)}
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.lru.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.maxEntries {
+		c.remove(c.lru.Back())
+	}
+}
+
+// advanceHead records block as the head when it is newer, dropping entries for
+// earlier blocks. Callers hold c.mu.
+func (c *ReservesCachingUniswapV2Client) advanceHead(block uint64) {
+	if block <= c.head {
+		return
+	}
+	c.head = block
+	for key, element := range c.entries {
+		if key.block < block {
+			c.remove(element)
+		}
+	}
+}
+
+// remove drops element from the cache. Callers hold c.mu.
+func (c *ReservesCachingUniswapV2Client) remove(element *list.Element) {
+	delete(c.entries, element.Value.(*blockReservesEntry).key)
+	c.lru.Remove(element)
+}
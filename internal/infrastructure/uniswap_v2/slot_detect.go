@@ -0,0 +1,113 @@
+package uniswap_v2
+
+import (
+	"context"
+	"math/big"
+	"sync"
+
+	"bigswapenergy/internal/shared/utils"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+// SlotDetectingUniswapV2Client wraps a UniswapV2Client and reads reserves from a
+// per-pool detected slot, for forks whose pair layout does not keep them at slot 8.
+//
+// Detection is heuristic: the first slot, trying slot 8 before 0..maxSlot, whose
+// word has both reserves and blockTimestampLast nonzero is taken to be the reserves
+// slot. A token address never matches because its top 96 bits are zero. Pools with
+// no match are not cached, so empty pools are probed again once they are funded.
+type SlotDetectingUniswapV2Client struct {
+	UniswapV2Client
+
+	maxSlot uint64
+	logger  *zap.Logger
+
+	slotsMux sync.RWMutex
+	slots    map[common.Address]uint64
+}
+
+// NewSlotDetectingUniswapV2Client creates a slot detecting decorator around client
+// that probes slots 0 through maxSlot
+func NewSlotDetectingUniswapV2Client(client UniswapV2Client, maxSlot uint64, logger *zap.Logger) *SlotDetectingUniswapV2Client {
+	return &SlotDetectingUniswapV2Client{
+		UniswapV2Client: client,
+		maxSlot:         maxSlot,
+		logger:          logger,
+		slots:           make(map[common.Address]uint64),
+	}
+}
+
+// LoadReserves reads reserves from the pool's detected slot, probing for it on first use
+func (c *SlotDetectingUniswapV2Client) LoadReserves(ctx context.Context, pool common.Address, blockNum *big.Int) (*big.Int, *big.Int, error) {
+	reserve0, reserve1, _, err := c.LoadReservesWithTimestamp(ctx, pool, blockNum)
+	return reserve0, reserve1, err
+}
+
+// LoadReservesWithTimestamp is LoadReserves that also returns blockTimestampLast
+func (c *SlotDetectingUniswapV2Client) LoadReservesWithTimestamp(ctx context.Context, pool common.Address, blockNum *big.Int) (*big.Int, *big.Int, uint32, error) {
+	c.slotsMux.RLock()
+	slot, ok := c.slots[pool]
+	c.slotsMux.RUnlock()
+	if ok {
+		word, err := c.ReadStorageSlot(ctx, pool, blockNum, slot)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		return reservesFromWord(pool, word)
+	}
+
+	// Standard pools match on the first read, so they cost no more than before
+	defaultWord, err := c.ReadStorageSlot(ctx, pool, blockNum, UniswapV2ReservesStorageSlot)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if looksLikeReserves(defaultWord) {
+		c.remember(pool, UniswapV2ReservesStorageSlot)
+		return reservesFromWord(pool, defaultWord)
+	}
+
+	for candidate := uint64(0); candidate <= c.maxSlot; candidate++ {
+		if candidate == UniswapV2ReservesStorageSlot {
+			continue
+		}
+		word, err := c.ReadStorageSlot(ctx, pool, blockNum, candidate)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		if looksLikeReserves(word) {
+			c.logger.Info("Detected non-standard reserves slot",
+				zap.String("pool", pool.Hex()),
+				zap.Uint64("slot", candidate),
+			)
+			c.remember(pool, candidate)
+			return reservesFromWord(pool, word)
+		}
+	}
+
+	return reservesFromWord(pool, defaultWord)
+}
+
+// remember caches the reserves slot detected for pool
+func (c *SlotDetectingUniswapV2Client) remember(pool common.Address, slot uint64) {
+	c.slotsMux.Lock()
+	c.slots[pool] = slot
+	c.slotsMux.Unlock()
+}
+
+// looksLikeReserves reports whether word is plausibly a packed
+// (reserve0, reserve1, blockTimestampLast) of a funded, synced pair
+func looksLikeReserves(word []byte) bool {
+	if utils.ValidateStorageWord(word) != nil {
+		return false
+	}
+	word = utils.NormalizeStorageWord(word)
+
+	// blockTimestampLast is the top 4 bytes
+	if new(big.Int).SetBytes(word[:4]).Sign() == 0 {
+		return false
+	}
+	reserve0, reserve1 := utils.ParseReserves(word)
+	return reserve0.Sign() > 0 && reserve1.Sign() > 0
+}
@@ -3,6 +3,7 @@ package uniswap_v2
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"math/big"
 
@@ -19,6 +20,9 @@ const (
 	UniswapV2ReservesStorageSlot = 8
 )
 
+// erc20DecimalsSelector is the 4-byte selector of ERC-20 decimals()
+var erc20DecimalsSelector = []byte{0x31, 0x3c, 0xe5, 0x67}
+
 var (
 	ZeroAddress = common.Address{}
 )
@@ -26,8 +30,15 @@ var (
 var (
 	ErrPoolNotFound          = fmt.Errorf("Pool not found")
 	ErrInsufficientLiquidity = fmt.Errorf("Insufficient liquidity in pool")
+	ErrPoolNotInitialized    = fmt.Errorf("Pool not initialized: both reserves are zero")
+	ErrPoolDrained           = fmt.Errorf("Pool drained: exactly one reserve is zero")
 	ErrTokenPairMismatch     = fmt.Errorf("Token pair does not match pool")
 	ErrInvalidPoolAddress    = fmt.Errorf("Invalid pool address")
+	ErrInvalidDecimals       = fmt.Errorf("Invalid token decimals")
+	ErrNoDecimals            = fmt.Errorf("Token has no decimals() method")
+	ErrNoFeeMethod           = fmt.Errorf("Pool has no fee method")
+	// ErrPoolTokensUnavailable marks a PoolStateLoader failure in the token part
+	ErrPoolTokensUnavailable = fmt.Errorf("Unable to read pool tokens")
 )
 
 // UniswapV2Client defines the interface for Uniswap V2 operations
@@ -44,6 +55,12 @@ type UniswapV2Client interface {
 	// LoadReserves reads reserves from Uniswap V2 pair storage
 	LoadReserves(ctx context.Context, pool common.Address, blockNum *big.Int) (*big.Int, *big.Int, error)
 
+	// LoadTokenDecimals reads an ERC-20 token's decimals() at the latest block
+	LoadTokenDecimals(ctx context.Context, token common.Address) (uint8, error)
+
+	// LoadPoolFee calls the pool's no-argument fee view identified by selector at the latest block
+	LoadPoolFee(ctx context.Context, pool common.Address, selector []byte) (*big.Int, error)
+
 	// DetermineReserveOrder determines which reserve corresponds to src and dst tokens
 	DetermineReserveOrder(src, dst, token0, token1 common.Address, reserve0, reserve1 *big.Int) (*big.Int, *big.Int, error)
 }
@@ -64,9 +81,7 @@ func NewUniswapV2Client(client ethereum.EthereumClient, logger *zap.Logger) Unis
 
 // ReadStorageSlot reads a storage slot from the contract
 func (c *UniswapV2ClientImpl) ReadStorageSlot(ctx context.Context, pool common.Address, blockNum *big.Int, slot uint64) ([]byte, error) {
-	var key common.Hash
-	key[31] = byte(slot)
-	return c.client.ReadContractStorage(ctx, pool, key, blockNum)
+	return c.client.ReadContractStorage(ctx, pool, slotKey(slot), blockNum)
 }
 
 // GetLatestBlockNumber returns the number of the latest block
@@ -80,46 +95,143 @@ func (c *UniswapV2ClientImpl) LoadTokens(ctx context.Context, pool common.Addres
 	if err != nil {
 		return common.Address{}, common.Address{}, fmt.Errorf("failed to read token0: %w", err)
 	}
-	token0 := common.BytesToAddress(token0Data)
 
 	token1Data, err := c.ReadStorageSlot(ctx, pool, blockNum, UniswapV2Token1StorageSlot)
 	if err != nil {
 		return common.Address{}, common.Address{}, fmt.Errorf("failed to read token1: %w", err)
 	}
-	token1 := common.BytesToAddress(token1Data)
 
+	return tokensFromWords(pool, token0Data, token1Data)
+}
+
+// tokensFromWords parses the token0 and token1 storage words; a zero token means
+// there is no pair at pool
+func tokensFromWords(pool common.Address, token0Data, token1Data []byte) (common.Address, common.Address, error) {
+	token0 := common.BytesToAddress(token0Data)
+	token1 := common.BytesToAddress(token1Data)
 	if bytes.Equal(token0[:], ZeroAddress[:]) || bytes.Equal(token1[:], ZeroAddress[:]) {
 		return common.Address{}, common.Address{}, fmt.Errorf("%w for pool %s", ErrPoolNotFound, pool.Hex())
 	}
-
 	return token0, token1, nil
 }
 
 // LoadReserves reads reserves from Uniswap V2 pair storage
 func (c *UniswapV2ClientImpl) LoadReserves(ctx context.Context, pool common.Address, blockNum *big.Int) (*big.Int, *big.Int, error) {
+	reserve0, reserve1, _, err := c.LoadReservesWithTimestamp(ctx, pool, blockNum)
+	return reserve0, reserve1, err
+}
+
+// LoadReservesWithTimestamp reads reserves and blockTimestampLast from Uniswap V2 pair storage
+func (c *UniswapV2ClientImpl) LoadReservesWithTimestamp(ctx context.Context, pool common.Address, blockNum *big.Int) (*big.Int, *big.Int, uint32, error) {
 	reserveData, err := c.ReadStorageSlot(ctx, pool, blockNum, UniswapV2ReservesStorageSlot)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to read reserves: %w", err)
+		return nil, nil, 0, fmt.Errorf("failed to read reserves: %w", err)
 	}
+	return reservesFromWord(pool, reserveData)
+}
 
-	reserve0, reserve1 := utils.ParseReserves(reserveData)
+// LoadTokenDecimals reads an ERC-20 token's decimals() with an eth_call. decimals()
+// is optional in ERC-20: a revert or empty return is ErrNoDecimals. The standard
+// returns a uint8, so any wider value is ErrInvalidDecimals.
+func (c *UniswapV2ClientImpl) LoadTokenDecimals(ctx context.Context, token common.Address) (uint8, error) {
+	data, err := c.client.CallContract(ctx, token, erc20DecimalsSelector, nil)
+	if errors.Is(err, ethereum.ErrExecutionReverted) {
+		return 0, fmt.Errorf("%w: token %s: %v", ErrNoDecimals, token.Hex(), err)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read decimals: %w", err)
+	}
+	if len(data) == 0 {
+		return 0, fmt.Errorf("%w: token %s returned no data", ErrNoDecimals, token.Hex())
+	}
+	if len(data) != 32 {
+		return 0, fmt.Errorf("%w: token %s returned %d bytes", ErrInvalidDecimals, token.Hex(), len(data))
+	}
+	decimals := new(big.Int).SetBytes(data)
+	if !decimals.IsUint64() || decimals.Uint64() > 255 {
+		return 0, fmt.Errorf("%w: token %s returned %s", ErrInvalidDecimals, token.Hex(), decimals)
+	}
+	return uint8(decimals.Uint64()), nil
+}
 
-	if reserve0.Sign() == 0 || reserve1.Sign() == 0 {
-		return nil, nil, fmt.Errorf("%w for pool %s", ErrInsufficientLiquidity, pool.Hex())
+// LoadPoolFee calls a fee view such as swapFee() on the pool. A revert or a
+// result that is not a single word is ErrNoFeeMethod.
+func (c *UniswapV2ClientImpl) LoadPoolFee(ctx context.Context, pool common.Address, selector []byte) (*big.Int, error) {
+	data, err := c.client.CallContract(ctx, pool, selector, nil)
+	if errors.Is(err, ethereum.ErrExecutionReverted) {
+		return nil, fmt.Errorf("%w: pool %s: %v", ErrNoFeeMethod, pool.Hex(), err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pool fee: %w", err)
+	}
+	if len(data) != 32 {
+		return nil, fmt.Errorf("%w: pool %s returned %d bytes", ErrNoFeeMethod, pool.Hex(), len(data))
+	}
+	return new(big.Int).SetBytes(data), nil
+}
+
+// reservesFromWord parses a packed reserves word and its blockTimestampLast,
+// classifying empty pools
+func reservesFromWord(pool common.Address, reserveData []byte) (*big.Int, *big.Int, uint32, error) {
+	if err := utils.ValidateStorageWord(reserveData); err != nil {
+		return nil, nil, 0, fmt.Errorf("failed to parse reserves for pool %s: %w", pool.Hex(), err)
 	}
 
-	return reserve0, reserve1, nil
+	reserve0, reserve1, timestamp := utils.ParseReservesWithTimestamp(reserveData)
+
+	// Both errors also match ErrInsufficientLiquidity so existing checks keep working.
+	// An all-zero slot is a pair that never synced, or no pair at all; the tokens,
+	// read first by every quote, tell those apart as ErrPoolNotFound.
+	switch {
+	case reserve0.Sign() == 0 && reserve1.Sign() == 0 && timestamp == 0:
+		return nil, nil, 0, fmt.Errorf("%w: %w for pool %s: reserves slot is empty, the pair has never synced",
+			ErrInsufficientLiquidity, ErrPoolNotInitialized, pool.Hex())
+	case reserve0.Sign() == 0 && reserve1.Sign() == 0:
+		return nil, nil, 0, fmt.Errorf("%w: %w for pool %s", ErrInsufficientLiquidity, ErrPoolNotInitialized, pool.Hex())
+	case reserve0.Sign() == 0 || reserve1.Sign() == 0:
+		return nil, nil, 0, fmt.Errorf("%w: %w for pool %s (reserve0=%s reserve1=%s)",
+			ErrInsufficientLiquidity, ErrPoolDrained, pool.Hex(), reserve0, reserve1)
+	}
+
+	return reserve0, reserve1, timestamp, nil
 }
 
 // DetermineReserveOrder determines which reserve corresponds to src and dst tokens
 func (c *UniswapV2ClientImpl) DetermineReserveOrder(src, dst, token0, token1 common.Address, reserve0, reserve1 *big.Int) (*big.Int, *big.Int, error) {
+	zeroForOne, err := OrientPair(src, dst, token0, token1)
+	if err != nil {
+		return nil, nil, err
+	}
+	if zeroForOne {
+		return reserve0, reserve1, nil
+	}
+	return reserve1, reserve0, nil
+}
+
+// OrientPair reports whether src -> dst is token0 -> token1 for a pool holding
+// token0 and token1. On mismatch the error says which token belongs where.
+func OrientPair(src, dst, token0, token1 common.Address) (zeroForOne bool, err error) {
 	switch {
 	case bytes.Equal(src[:], token0[:]) && bytes.Equal(dst[:], token1[:]):
-		return reserve0, reserve1, nil
+		return true, nil
 	case bytes.Equal(src[:], token1[:]) && bytes.Equal(dst[:], token0[:]):
-		return reserve1, reserve0, nil
+		return false, nil
+	}
+
+	srcInPool := bytes.Equal(src[:], token0[:]) || bytes.Equal(src[:], token1[:])
+	dstInPool := bytes.Equal(dst[:], token0[:]) || bytes.Equal(dst[:], token1[:])
+
+	var reason string
+	switch {
+	case !srcInPool && !dstInPool:
+		reason = fmt.Sprintf("neither src %s nor dst %s is in the pool", src.Hex(), dst.Hex())
+	case !srcInPool:
+		reason = fmt.Sprintf("src %s is not in the pool", src.Hex())
+	case !dstInPool:
+		reason = fmt.Sprintf("dst %s is not in the pool", dst.Hex())
 	default:
-		return nil, nil, fmt.Errorf("%w: src=%s dst=%s token0=%s token1=%s",
-			ErrTokenPairMismatch, src.Hex(), dst.Hex(), token0.Hex(), token1.Hex())
+		reason = fmt.Sprintf("src and dst are both %s", src.Hex())
 	}
+	return false, fmt.Errorf("%w: %s; pool has token0=%s token1=%s",
+		ErrTokenPairMismatch, reason, token0.Hex(), token1.Hex())
 }
@@ -0,0 +1,87 @@
+package http
+
+import (
+	"encoding/json"
+
+	estimate "bigswapenergy/internal/usecases"
+
+	"github.com/valyala/fasthttp"
+)
+
+type ArbitrageResponse struct {
+	IntermediateAmount string `json:"intermediate_amount"`
+	FinalAmount        string `json:"final_amount"`
+	Profit             string `json:"profit"`
+	BlockNumber        uint64 `json:"block_number"`
+}
+
+// EstimateArbitrage handles the /estimate/arb endpoint
+func (h *EstimateHandler) EstimateArbitrage(ctx *fasthttp.RequestCtx) {
+	timings := newPhaseTimings()
+	reqCtx, log, cancel := h.requestContext(ctx, timings)
+	defer cancel()
+
+	req, err := h.parseArbitrageParams(ctx)
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	timings.mark("parse")
+
+	result, err := h.estimateService.EstimateArbitrage(reqCtx, req)
+	if err != nil {
+		h.handleError(ctx, requestError(reqCtx, err))
+		return
+	}
+	timings.mark("estimate")
+	ctx.SetUserValue(userValueBlockNumber, result.BlockNumber)
+
+	h.logCompletion(log, "Arbitrage estimate completed", timings)
+
+	ctx.SetContentType("application/json")
+	json.NewEncoder(ctx).Encode(ArbitrageResponse{
+		IntermediateAmount: result.IntermediateAmount.String(),
+		FinalAmount:        result.FinalAmount.String(),
+		Profit:             result.Profit.String(),
+		BlockNumber:        result.BlockNumber,
+	})
+}
+
+func (h *EstimateHandler) parseArbitrageParams(ctx *fasthttp.RequestCtx) (estimate.ArbitrageRequest, error) {
+	args := ctx.QueryArgs()
+	if err := rejectDuplicateParams(args); err != nil {
+		return estimate.ArbitrageRequest{}, err
+	}
+
+	poolA, err := requireQueryParam(args, "pool_a", "pool_a")
+	if err != nil {
+		return estimate.ArbitrageRequest{}, err
+	}
+	poolB, err := requireQueryParam(args, "pool_b", "pool_b")
+	if err != nil {
+		return estimate.ArbitrageRequest{}, err
+	}
+	src, err := requireQueryParam(args, "src", "source token")
+	if err != nil {
+		return estimate.ArbitrageRequest{}, err
+	}
+	dst, err := requireQueryParam(args, "dst", "destination token")
+	if err != nil {
+		return estimate.ArbitrageRequest{}, err
+	}
+	srcAmount, err := parseSrcAmount(args.Peek("src_amount"))
+	if err != nil {
+		return estimate.ArbitrageRequest{}, err
+	}
+	if err := h.validatePoolCount(2); err != nil {
+		return estimate.ArbitrageRequest{}, err
+	}
+
+	return estimate.ArbitrageRequest{
+		PoolA:     poolA,
+		PoolB:     poolB,
+		SrcToken:  src,
+		DstToken:  dst,
+		SrcAmount: srcAmount,
+	}, nil
+}
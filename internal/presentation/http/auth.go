@@ -0,0 +1,82 @@
+package http
+
+import (
+	"crypto/subtle"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+const headerAPIKey = "X-API-Key"
+
+// apiKeyUserValue is the request user value holding the authenticated API key
+const apiKeyUserValue = "api_key"
+
+// authExemptPaths are probes a load balancer calls without a key
+var authExemptPaths = map[string]bool{
+	"/health": true,
+	"/ready":  true,
+}
+
+type APIKeyConfigurable interface {
+	GetAPIKeys() []string
+}
+
+// APIKeyMiddleware rejects requests without one of the configured API keys
+type APIKeyMiddleware struct {
+	keys   [][]byte
+	logger *zap.Logger
+}
+
+func NewAPIKeyMiddleware(keys []string, logger *zap.Logger) *APIKeyMiddleware {
+	m := &APIKeyMiddleware{logger: logger}
+	for _, key := range keys {
+		m.keys = append(m.keys, []byte(key))
+	}
+	return m
+}
+
+func (m *APIKeyMiddleware) Apply(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		if authExemptPaths[string(ctx.Path())] {
+			next(ctx)
+			return
+		}
+
+		key := ctx.Request.Header.Peek(headerAPIKey)
+		if !m.valid(key) {
+			withRequestID(m.logger, ctx).Warn("Rejected request without a valid API key",
+				zap.Bool("key_present", len(key) > 0),
+				zap.String("path", string(ctx.Path())),
+			)
+
+			ctx.SetStatusCode(fasthttp.StatusUnauthorized)
+			ctx.SetContentType("application/json")
+			ctx.SetBodyString(`{"error":{"code":"UNAUTHORIZED","message":"Missing or invalid API key"}}`)
+			return
+		}
+
+		ctx.SetUserValue(apiKeyUserValue, string(key))
+		next(ctx)
+	}
+}
+
+// valid compares key against every configured key in constant time
+func (m *APIKeyMiddleware) valid(key []byte) bool {
+	if len(key) == 0 {
+		return false
+	}
+	match := 0
+	for _, allowed := range m.keys {
+		match |= subtle.ConstantTimeCompare(key, allowed)
+	}
+	return match == 1
+}
+
+// APIKeyFromContext returns the API key a request authenticated with, or "" when
+// authentication is disabled. It lets later middleware, such as per-key rate
+// limits, act on the caller's key.
+func APIKeyFromContext(ctx *fasthttp.RequestCtx) string {
+	key, _ := ctx.UserValue(apiKeyUserValue).(string)
+	return key
+}
@@ -0,0 +1,107 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	apperrors "bigswapenergy/internal/shared/errors"
+	estimate "bigswapenergy/internal/usecases"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// maxBatchSize caps how many estimates one /estimate/batch request may ask for
+const maxBatchSize = 50
+
+type BatchEstimateItem struct {
+	Pool      string `json:"pool"`
+	SrcToken  string `json:"src"`
+	DstToken  string `json:"dst"`
+	SrcAmount string `json:"src_amount"`
+}
+
+type BatchEstimateResult struct {
+	AmountOut string `json:"amount_out,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// EstimateBatch handles POST /estimate/batch, quoting each item of a JSON array
+// concurrently on up to server.batch_workers workers. Results keep the request
+// order, and an item that fails carries an inline error instead of failing the batch.
+func (h *EstimateHandler) EstimateBatch(ctx *fasthttp.RequestCtx) {
+	if !requirePost(ctx) {
+		return
+	}
+
+	timings := newPhaseTimings()
+	reqCtx, log, cancel := h.requestContext(ctx, timings)
+	defer cancel()
+
+	var items []BatchEstimateItem
+	if err := json.Unmarshal(ctx.PostBody(), &items); err != nil {
+		h.handleError(ctx, fmt.Errorf("%w: request body must be a JSON array of {pool, src, dst, src_amount}: %v", apperrors.ErrValidation, err))
+		return
+	}
+	if len(items) == 0 {
+		h.handleError(ctx, fmt.Errorf("%w: at least one estimate is required", apperrors.ErrValidation))
+		return
+	}
+	if len(items) > maxBatchSize {
+		h.handleError(ctx, fmt.Errorf("%w: batch has %d estimates, maximum is %d", apperrors.ErrValidation, len(items), maxBatchSize))
+		return
+	}
+	timings.mark("parse")
+
+	workers := max(h.config.Server.BatchWorkers, 1)
+	results := make([]BatchEstimateResult, len(items))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, workers)
+	for i, item := range items {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			amountOut, err := h.estimateBatchItem(reqCtx, item)
+			if err != nil {
+				results[i].Error = err.Error()
+				return
+			}
+			results[i].AmountOut = amountOut
+		}()
+	}
+	wg.Wait()
+	timings.mark("estimate")
+
+	log = log.With(zap.Int("batch_size", len(items)))
+	h.logCompletion(log, "Batch estimate completed", timings)
+
+	ctx.SetContentType("application/json")
+	json.NewEncoder(ctx).Encode(results)
+}
+
+// estimateBatchItem quotes a single batch item, returning amount out as a string
+func (h *EstimateHandler) estimateBatchItem(ctx context.Context, item BatchEstimateItem) (string, error) {
+	if err := h.checkBatchItemAddresses(item); err != nil {
+		return "", err
+	}
+	srcAmount, err := parseSrcAmount([]byte(item.SrcAmount))
+	if err != nil {
+		return "", err
+	}
+	result, err := h.estimateService.EstimateSwap(ctx, estimate.EstimateRequest{
+		PoolAddress: item.Pool,
+		SrcToken:    item.SrcToken,
+		DstToken:    item.DstToken,
+		SrcAmount:   srcAmount,
+	})
+	if err != nil {
+		return "", err
+	}
+	return result.AmountOut.String(), nil
+}
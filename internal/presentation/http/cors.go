@@ -0,0 +1,74 @@
+package http
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+)
+
+// corsWildcard as an allowed origin allows any origin
+const corsWildcard = "*"
+
+type HTTPCORSConfig struct {
+	// AllowedOrigins are the origins browsers may call from; empty disables CORS
+	AllowedOrigins []string
+	AllowedMethods []string
+	AllowedHeaders []string
+}
+
+type CORSConfigurable interface {
+	GetCORSConfig() HTTPCORSConfig
+}
+
+// CORSMiddleware sets the Access-Control-Allow-* headers for allowed origins and
+// answers preflight requests itself
+type CORSMiddleware struct {
+	config  HTTPCORSConfig
+	methods string
+	headers string
+}
+
+func NewCORSMiddleware(config HTTPCORSConfig) *CORSMiddleware {
+	return &CORSMiddleware{
+		config:  config,
+		methods: strings.Join(config.AllowedMethods, ", "),
+		headers: strings.Join(config.AllowedHeaders, ", "),
+	}
+}
+
+func (m *CORSMiddleware) Apply(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		origin := string(ctx.Request.Header.Peek(fasthttp.HeaderOrigin))
+		allowed := origin != "" && m.allows(origin)
+		if allowed {
+			if slices.Contains(m.config.AllowedOrigins, corsWildcard) {
+				ctx.Response.Header.Set(fasthttp.HeaderAccessControlAllowOrigin, corsWildcard)
+			} else {
+				ctx.Response.Header.Set(fasthttp.HeaderAccessControlAllowOrigin, origin)
+				ctx.Response.Header.Add(fasthttp.HeaderVary, fasthttp.HeaderOrigin)
+			}
+		}
+
+		// A preflight is answered here, so it never reaches the rate limiter
+		if ctx.IsOptions() && origin != "" && len(ctx.Request.Header.Peek(fasthttp.HeaderAccessControlRequestMethod)) > 0 {
+			if allowed {
+				ctx.Response.Header.Set(fasthttp.HeaderAccessControlAllowMethods, m.methods)
+				ctx.Response.Header.Set(fasthttp.HeaderAccessControlAllowHeaders, m.headers)
+			}
+			ctx.SetStatusCode(fasthttp.StatusNoContent)
+			return
+		}
+
+		next(ctx)
+	}
+}
+
+func (m *CORSMiddleware) allows(origin string) bool {
+	for _, allowed := range m.config.AllowedOrigins {
+		if allowed == corsWildcard || allowed == origin {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package http
+
+import (
+	"encoding/json"
+	"time"
+
+	"bigswapenergy/internal/infrastructure/ethereum"
+
+	"github.com/valyala/fasthttp"
+)
+
+// EndpointStatusSource reports the circuit of every RPC endpoint, in failover order
+type EndpointStatusSource interface {
+	EndpointStatuses() []ethereum.EndpointStatus
+}
+
+type EndpointStatusResponse struct {
+	Name  string `json:"name"`
+	Host  string `json:"host"`
+	State string `json:"state"`
+	// WindowRequests and WindowFailures count calls in the breaker's current window
+	WindowRequests int `json:"window_requests"`
+	WindowFailures int `json:"window_failures"`
+	// ConsecutiveFailures counts failures since the endpoint's last success
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	Opens               uint64     `json:"opens"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+}
+
+type EndpointPoolHandler struct {
+	source EndpointStatusSource
+}
+
+func NewEndpointPoolHandler(source EndpointStatusSource) *EndpointPoolHandler {
+	return &EndpointPoolHandler{source: source}
+}
+
+// GetPool handles the /debug/pool endpoint, returning each RPC endpoint's circuit
+func (h *EndpointPoolHandler) GetPool(ctx *fasthttp.RequestCtx) {
+	statuses := h.source.EndpointStatuses()
+	resp := make([]EndpointStatusResponse, len(statuses))
+	for i, status := range statuses {
+		resp[i] = EndpointStatusResponse{
+			Name:                status.Name,
+			Host:                status.Host,
+			State:               status.Circuit.State.String(),
+			WindowRequests:      status.Circuit.Requests,
+			WindowFailures:      status.Circuit.Failures,
+			ConsecutiveFailures: status.Circuit.ConsecutiveFailures,
+			Opens:               status.Circuit.Opens,
+		}
+		if !status.Circuit.OpenedAt.IsZero() {
+			openedAt := status.Circuit.OpenedAt
+			resp[i].OpenedAt = &openedAt
+		}
+	}
+
+	ctx.SetContentType("application/json")
+	json.NewEncoder(ctx).Encode(resp)
+}
@@ -2,8 +2,10 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
 
 	apperrors "bigswapenergy/internal/shared/errors"
+	"bigswapenergy/internal/shared/metrics"
 
 	"github.com/valyala/fasthttp"
 	"go.uber.org/zap"
@@ -22,58 +24,86 @@ type ErrorMapping struct {
 	ShouldLog  bool
 }
 
-var errorMappings = map[error]ErrorMapping{
-	apperrors.ErrValidation: {
+// errorMappings is matched in order with errors.Is, so errors wrapping a sentinel
+// map like the sentinel itself. The more specific sentinels come first, since an
+// error can wrap several (e.g. a drained pool is also a business rule violation).
+var errorMappings = []struct {
+	err     error
+	mapping ErrorMapping
+}{
+	{apperrors.ErrValidation, ErrorMapping{
 		HTTPStatus: fasthttp.StatusBadRequest,
 		Code:       "VALIDATION_ERROR",
 		Message:    "Request validation failed",
 		ShouldLog:  false,
-	},
-	apperrors.ErrInvalidInput: {
+	}},
+	{apperrors.ErrInvalidInput, ErrorMapping{
 		HTTPStatus: fasthttp.StatusBadRequest,
 		Code:       "INVALID_INPUT",
 		Message:    "Invalid input parameters",
 		ShouldLog:  false,
-	},
-
-	apperrors.ErrBusinessRule: {
+	}},
+	{apperrors.ErrPoolNotInitialized, ErrorMapping{
+		HTTPStatus: fasthttp.StatusBadRequest,
+		Code:       "POOL_NOT_INITIALIZED",
+		Message:    "Pool has no liquidity on either side",
+		ShouldLog:  false,
+	}},
+	{apperrors.ErrPoolDrained, ErrorMapping{
+		HTTPStatus: fasthttp.StatusBadRequest,
+		Code:       "POOL_DRAINED",
+		Message:    "Pool has liquidity on only one side",
+		ShouldLog:  false,
+	}},
+	{apperrors.ErrBusinessRule, ErrorMapping{
 		HTTPStatus: fasthttp.StatusBadRequest,
 		Code:       "BUSINESS_RULE_VIOLATION",
 		Message:    "Business rule violation",
 		ShouldLog:  false,
-	},
-	apperrors.ErrNotFound: {
+	}},
+	{apperrors.ErrNotFound, ErrorMapping{
 		HTTPStatus: fasthttp.StatusNotFound,
 		Code:       "NOT_FOUND",
 		Message:    "Requested resource not found",
 		ShouldLog:  false,
-	},
-
-	apperrors.ErrExternalService: {
-		HTTPStatus: fasthttp.StatusBadGateway,
-		Code:       "EXTERNAL_SERVICE_ERROR",
-		Message:    "External service unavailable",
-		ShouldLog:  true,
-	},
-	apperrors.ErrTimeout: {
+	}},
+	{apperrors.ErrTimeout, ErrorMapping{
 		HTTPStatus: fasthttp.StatusGatewayTimeout,
 		Code:       "TIMEOUT_ERROR",
 		Message:    "Request timeout",
 		ShouldLog:  true,
-	},
-
-	apperrors.ErrInternal: {
+	}},
+	{apperrors.ErrExternalService, ErrorMapping{
+		HTTPStatus: fasthttp.StatusBadGateway,
+		Code:       "EXTERNAL_SERVICE_ERROR",
+		Message:    "External service unavailable",
+		ShouldLog:  true,
+	}},
+	{apperrors.ErrInternal, ErrorMapping{
 		HTTPStatus: fasthttp.StatusInternalServerError,
 		Code:       "INTERNAL_ERROR",
 		Message:    "Internal server error",
 		ShouldLog:  true,
-	},
+	}},
+}
+
+// lookupErrorMapping returns the mapping of the first sentinel err wraps
+func lookupErrorMapping(err error) (ErrorMapping, bool) {
+	for _, entry := range errorMappings {
+		if errors.Is(err, entry.err) {
+			return entry.mapping, true
+		}
+	}
+	return ErrorMapping{}, false
 }
 
 func (h *EstimateHandler) handleError(ctx *fasthttp.RequestCtx, err error) {
-	mapping, found := errorMappings[err]
+	mapping, found := lookupErrorMapping(err)
 
 	if !found {
+		// Unmapped errors are unexpected by definition; they are counted separately
+		// from business errors so alerting can page on them alone
+		metrics.Global.Counter(MetricUnmappedErrors).Inc()
 		mapping = ErrorMapping{
 			HTTPStatus: fasthttp.StatusInternalServerError,
 			Code:       "UNKNOWN_ERROR",
@@ -82,8 +112,10 @@ func (h *EstimateHandler) handleError(ctx *fasthttp.RequestCtx, err error) {
 		}
 	}
 
+	metrics.Global.Counter(metricRequestErrors + mapping.Code).Inc()
+
 	if mapping.ShouldLog {
-		h.logger.Error("Request error",
+		withRequestID(h.logger, ctx).Error("Request error",
 			zap.Error(err),
 			zap.String("path", string(ctx.Path())),
 			zap.String("method", string(ctx.Method())),
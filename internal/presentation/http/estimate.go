@@ -1,19 +1,41 @@
 package http
 
 import (
+	"encoding/json"
 	"fmt"
 	"math/big"
+	"slices"
 	"strconv"
-	"time"
+	"strings"
 
 	"bigswapenergy/internal/shared/config"
 	apperrors "bigswapenergy/internal/shared/errors"
+	"bigswapenergy/internal/shared/utils"
 	estimate "bigswapenergy/internal/usecases"
 
 	"github.com/valyala/fasthttp"
 	"go.uber.org/zap"
 )
 
+// headerPoolWarning carries EstimateResult.Warnings, one header per warning
+const headerPoolWarning = "X-Pool-Warning"
+
+// headerQuoteID carries the estimate's deterministic quote ID
+const headerQuoteID = "X-Quote-Id"
+
+// headerEffectiveFee and headerFeeSource carry the fee the quote applied and
+// where it came from (request, config, onchain or default). Every fee the API
+// reads or reports (fee_bps, fee_bps_0to1, fee_bps_1to0, effective_fee_bps) is in
+// basis points, 30 = 0.3%, the same unit as slippage_bps and price_impact_bps.
+const (
+	headerEffectiveFee = "X-Effective-Fee-Bps"
+	headerFeeSource    = "X-Fee-Source"
+)
+
+// headerReserveAge carries how many blocks old the quoted reserves are, set when
+// the reserves cache is enabled
+const headerReserveAge = "X-Reserve-Age-Blocks"
+
 type EstimateHandler struct {
 	estimateService estimate.EstimateService
 	logger          *zap.Logger
@@ -22,11 +44,34 @@ type EstimateHandler struct {
 
 // GetRateLimitConfig implements RateLimitable interface
 func (h *EstimateHandler) GetRateLimitConfig() HTTPRateLimitConfig {
+	return NewHTTPRateLimitConfig(h.config.RateLimit)
+}
+
+// NewHTTPRateLimitConfig maps the rate_limit config section to the middleware's config
+func NewHTTPRateLimitConfig(cfg config.RateLimitConfig) HTTPRateLimitConfig {
 	return HTTPRateLimitConfig{
-		RequestsPerMinute: h.config.RateLimit.RequestsPerMinute,
+		RequestsPerMinute: cfg.RequestsPerMinute,
+		Burst:             cfg.Burst,
+		MaxClients:        cfg.MaxClients,
+		CleanupInterval:   cfg.CleanupInterval,
+		TrustedProxies:    cfg.TrustedProxies,
+	}
+}
+
+// GetCORSConfig implements CORSConfigurable interface
+func (h *EstimateHandler) GetCORSConfig() HTTPCORSConfig {
+	return HTTPCORSConfig{
+		AllowedOrigins: h.config.CORS.AllowedOrigins,
+		AllowedMethods: h.config.CORS.AllowedMethods,
+		AllowedHeaders: h.config.CORS.AllowedHeaders,
 	}
 }
 
+// GetAPIKeys implements APIKeyConfigurable interface
+func (h *EstimateHandler) GetAPIKeys() []string {
+	return h.config.Server.APIKeys
+}
+
 func NewEstimateHandler(estimateService estimate.EstimateService, logger *zap.Logger, config *config.Config) *EstimateHandler {
 	return &EstimateHandler{
 		estimateService: estimateService,
@@ -37,64 +82,556 @@ func NewEstimateHandler(estimateService estimate.EstimateService, logger *zap.Lo
 
 // EstimateSwapAmount handles the /estimate endpoint
 func (h *EstimateHandler) EstimateSwapAmount(ctx *fasthttp.RequestCtx) {
-	startTime := time.Now()
+	timings := newPhaseTimings()
+	reqCtx, log, cancel := h.requestContext(ctx, timings)
+	defer cancel()
 
-	poolAddress, srcToken, dstToken, srcAmountBig, err := h.parseEstimateParams(ctx)
+	req, err := h.parseEstimateParams(ctx)
 	if err != nil {
 		h.handleError(ctx, err)
 		return
 	}
-
-	dstAmount, err := h.estimateService.EstimateSwapAmount(ctx, poolAddress, srcToken, dstToken, srcAmountBig)
+	sign, err := h.parseSign(ctx, req)
 	if err != nil {
 		h.handleError(ctx, err)
 		return
 	}
+	asJSON := wantsJSON(ctx)
+	timings.mark("parse")
+
+	result, err := h.estimateService.EstimateSwap(reqCtx, req)
+	if err != nil {
+		h.handleError(ctx, requestError(reqCtx, err))
+		return
+	}
+	timings.mark("estimate")
+	ctx.SetUserValue(userValueBlockNumber, result.BlockNumber)
+	for _, warning := range result.Warnings {
+		ctx.Response.Header.Add(headerPoolWarning, warning)
+	}
+	if result.FeeSource != "" {
+		ctx.Response.Header.Set(headerEffectiveFee, strconv.Itoa(result.EffectiveFeeBasisPoints))
+		ctx.Response.Header.Set(headerFeeSource, string(result.FeeSource))
+	}
+	if h.config.Cache.ReservesMaxAgeBlocks > 0 {
+		ctx.Response.Header.Set(headerReserveAge, strconv.FormatUint(result.ReserveAgeBlocks, 10))
+	}
+	if result.QuoteID != "" {
+		ctx.Response.Header.Set(headerQuoteID, result.QuoteID)
+		log = log.With(zap.String("quote_id", result.QuoteID))
+	}
 
-	totalDuration := time.Since(startTime)
+	h.logCompletion(log, "Estimate completed", timings)
 
-	h.logger.Info("Estimate completed", zap.Duration("duration", totalDuration))
+	if sign {
+		h.writeSignedQuote(ctx, req, result)
+		return
+	}
+	if result.Math != nil {
+		writeSwapMath(ctx, result)
+		return
+	}
+	if req.ItemStatus {
+		writeQuoteItems(ctx, result)
+		return
+	}
+	// fee_tiers answers in JSON already, so it comes before the generic writer
+	if len(req.FeeTiers) > 0 {
+		writeFeeTiers(ctx, result)
+		return
+	}
+	if req.PriceImpact || asJSON {
+		writeEstimateJSON(ctx, req, result)
+		return
+	}
 
 	ctx.SetContentType("text/plain")
-	dstAmountStr := dstAmount.String()
-	ctx.SetBodyString(dstAmountStr)
+	if req.DstAmount != nil {
+		ctx.SetBodyString(result.AmountIn.String())
+		return
+	}
+	if len(req.SrcAmounts) > 0 {
+		writeAmountList(ctx, result.AmountsOut, result.DstDecimals)
+		return
+	}
+	ctx.SetBodyString(formatAmount(result.AmountOut, result.DstDecimals))
+}
+
+// effectiveFee returns the fee the quote applied in basis points, or nil when it
+// reports none
+func effectiveFee(result *estimate.EstimateResult) *int {
+	if result.FeeSource == "" {
+		return nil
+	}
+	fee := result.EffectiveFeeBasisPoints
+	return &fee
+}
+
+// formatAmount renders amount in base units, or in whole tokens when decimals is set
+func formatAmount(amount *big.Int, decimals *uint8) string {
+	if decimals == nil {
+		return amount.String()
+	}
+	return utils.FormatUnits(amount, *decimals)
+}
+
+// EstimateResponse is the JSON form of a plain quote: AmountOut for a single
+// exact-in amount, AmountsOut for a list and AmountIn for an exact-out quote
+type EstimateResponse struct {
+	AmountOut      string   `json:"amount_out,omitempty"`
+	AmountsOut     []string `json:"amounts_out,omitempty"`
+	AmountIn       string   `json:"amount_in,omitempty"`
+	AmountOutMin   string   `json:"amount_out_min,omitempty"`
+	PriceImpactBps *int     `json:"price_impact_bps,omitempty"`
+	QuoteID        string   `json:"quote_id,omitempty"`
+	// ReserveAgeBlocks is omitted for fresh reserves
+	ReserveAgeBlocks uint64 `json:"reserve_age_blocks,omitempty"`
+	EffectiveFee     *int   `json:"effective_fee_bps,omitempty"`
+	FeeSource        string `json:"fee_source,omitempty"`
+}
+
+// writeEstimateJSON writes a plain quote as an EstimateResponse, formatting amounts
+// as the text response would
+func writeEstimateJSON(ctx *fasthttp.RequestCtx, req estimate.EstimateRequest, result *estimate.EstimateResult) {
+	resp := EstimateResponse{
+		PriceImpactBps:   result.PriceImpactBps,
+		QuoteID:          result.QuoteID,
+		ReserveAgeBlocks: result.ReserveAgeBlocks,
+		EffectiveFee:     effectiveFee(result),
+		FeeSource:        string(result.FeeSource),
+	}
+	switch {
+	case req.DstAmount != nil:
+		resp.AmountIn = result.AmountIn.String()
+	case len(req.SrcAmounts) > 0:
+		resp.AmountsOut = make([]string, len(result.AmountsOut))
+		for i, amount := range result.AmountsOut {
+			resp.AmountsOut[i] = formatAmount(amount, result.DstDecimals)
+		}
+	default:
+		resp.AmountOut = formatAmount(result.AmountOut, result.DstDecimals)
+	}
+	if result.AmountOutMin != nil {
+		resp.AmountOutMin = formatAmount(result.AmountOutMin, result.DstDecimals)
+	}
+
+	ctx.SetContentType("application/json")
+	json.NewEncoder(ctx).Encode(resp)
+}
+
+// wantsJSON reports whether the client asked for an EstimateResponse instead of
+// text/plain, with format=json or an Accept header listing application/json.
+// The response varies on Accept either way, so it is marked for caches.
+func wantsJSON(ctx *fasthttp.RequestCtx) bool {
+	ctx.Response.Header.Add(fasthttp.HeaderVary, fasthttp.HeaderAccept)
+	if string(ctx.QueryArgs().Peek("format")) == "json" {
+		return true
+	}
+	for _, mediaType := range strings.Split(string(ctx.Request.Header.Peek(fasthttp.HeaderAccept)), ",") {
+		mediaType, _, _ = strings.Cut(mediaType, ";")
+		if strings.EqualFold(strings.TrimSpace(mediaType), "application/json") {
+			return true
+		}
+	}
+	return false
+}
+
+// SwapMathResponse exposes the output formula's intermediates under the variable
+// names used by UniswapV2Library.getAmountOut. For forks with a protocol cut,
+// amountOutBeforeCut is numerator / denominator and amountOut is that less protocolCut.
+type SwapMathResponse struct {
+	AmountOut string `json:"amountOut"`
+	QuoteID   string `json:"quote_id,omitempty"`
+	// ReserveAgeBlocks is omitted for fresh reserves
+	ReserveAgeBlocks uint64 `json:"reserve_age_blocks,omitempty"`
+	EffectiveFee     *int   `json:"effective_fee_bps,omitempty"`
+	FeeSource        string `json:"fee_source,omitempty"`
+	Math             struct {
+		AmountInWithFee string `json:"amountInWithFee"`
+		Numerator       string `json:"numerator"`
+		Denominator     string `json:"denominator"`
+
+		AmountOutBeforeCut string `json:"amountOutBeforeCut,omitempty"`
+		ProtocolCut        string `json:"protocolCut,omitempty"`
+	} `json:"math"`
+}
+
+// writeSwapMath writes a show_math response
+func writeSwapMath(ctx *fasthttp.RequestCtx, result *estimate.EstimateResult) {
+	resp := SwapMathResponse{
+		AmountOut:        result.AmountOut.String(),
+		QuoteID:          result.QuoteID,
+		ReserveAgeBlocks: result.ReserveAgeBlocks,
+		EffectiveFee:     effectiveFee(result),
+		FeeSource:        string(result.FeeSource),
+	}
+	resp.Math.AmountInWithFee = result.Math.AmountInWithFee.String()
+	resp.Math.Numerator = result.Math.Numerator.String()
+	resp.Math.Denominator = result.Math.Denominator.String()
+	if result.Math.ProtocolCut != nil {
+		resp.Math.AmountOutBeforeCut = result.Math.AmountOutBeforeCut.String()
+		resp.Math.ProtocolCut = result.Math.ProtocolCut.String()
+	}
+
+	ctx.SetContentType("application/json")
+	json.NewEncoder(ctx).Encode(resp)
+}
+
+type QuoteItemResponse struct {
+	AmountIn  string `json:"amount_in"`
+	AmountOut string `json:"amount_out,omitempty"`
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+}
+
+type QuoteItemsResponse struct {
+	BlockNumber uint64 `json:"block_number"`
+	QuoteID     string `json:"quote_id,omitempty"`
+	// ReserveAgeBlocks is omitted for fresh reserves
+	ReserveAgeBlocks uint64              `json:"reserve_age_blocks,omitempty"`
+	EffectiveFee     *int                `json:"effective_fee_bps,omitempty"`
+	FeeSource        string              `json:"fee_source,omitempty"`
+	Items            []QuoteItemResponse `json:"items"`
+}
+
+// writeQuoteItems writes an item_status response, one classified entry per src_amount
+func writeQuoteItems(ctx *fasthttp.RequestCtx, result *estimate.EstimateResult) {
+	resp := QuoteItemsResponse{
+		BlockNumber:      result.BlockNumber,
+		QuoteID:          result.QuoteID,
+		ReserveAgeBlocks: result.ReserveAgeBlocks,
+		EffectiveFee:     effectiveFee(result),
+		FeeSource:        string(result.FeeSource),
+		Items:            make([]QuoteItemResponse, len(result.Items)),
+	}
+	for i, item := range result.Items {
+		resp.Items[i] = QuoteItemResponse{AmountIn: item.AmountIn.String(), Status: string(item.Status)}
+		if item.AmountOut != nil {
+			resp.Items[i].AmountOut = item.AmountOut.String()
+		}
+		if item.Err != nil {
+			resp.Items[i].Error = item.Err.Error()
+		}
+	}
+
+	ctx.SetContentType("application/json")
+	json.NewEncoder(ctx).Encode(resp)
+}
+
+type FeeTierQuoteResponse struct {
+	// FeeBasisPoints is the tier's fee in basis points (30 = 0.3%)
+	FeeBasisPoints int    `json:"fee_bps"`
+	AmountOut      string `json:"amount_out"`
+}
+
+type FeeTiersResponse struct {
+	BlockNumber uint64 `json:"block_number"`
+	FeeAssumed  bool   `json:"fee_assumed"`
+	Note        string `json:"note,omitempty"`
+	// Quotes are ranked by output, best first
+	Quotes []FeeTierQuoteResponse `json:"quotes"`
+}
+
+// feeAssumedNote tells clients the tiers are guesses they must choose between
+const feeAssumedNote = "pool fee is unknown; each quote assumes a common fee tier"
+
+// writeFeeTiers writes a fee_tiers response
+func writeFeeTiers(ctx *fasthttp.RequestCtx, result *estimate.EstimateResult) {
+	resp := FeeTiersResponse{
+		BlockNumber: result.BlockNumber,
+		FeeAssumed:  result.FeeAssumed,
+		Quotes:      make([]FeeTierQuoteResponse, len(result.Tiers)),
+	}
+	if result.FeeAssumed {
+		resp.Note = feeAssumedNote
+	}
+	for i, tier := range result.Tiers {
+		resp.Quotes[i] = FeeTierQuoteResponse{FeeBasisPoints: tier.FeeBasisPoints, AmountOut: tier.AmountOut.String()}
+	}
+
+	ctx.SetContentType("application/json")
+	json.NewEncoder(ctx).Encode(resp)
 }
 
-func (h *EstimateHandler) parseEstimateParams(ctx *fasthttp.RequestCtx) (string, string, string, *big.Int, error) {
-	poolBytes := ctx.QueryArgs().Peek("pool")
-	if len(poolBytes) == 0 {
-		return "", "", "", nil, fmt.Errorf("%w: pool parameter is required", apperrors.ErrValidation)
+// writeAmountList writes one amount per line, in request order, formatted as by formatAmount
+func writeAmountList(ctx *fasthttp.RequestCtx, amounts []*big.Int, decimals *uint8) {
+	for i, amount := range amounts {
+		if i > 0 {
+			ctx.WriteString("\n")
+		}
+		ctx.WriteString(formatAmount(amount, decimals))
 	}
-	poolValue := string(poolBytes)
+}
 
-	srcBytes := ctx.QueryArgs().Peek("src")
-	if len(srcBytes) == 0 {
-		return "", "", "", nil, fmt.Errorf("%w: source token parameter is required", apperrors.ErrValidation)
+// parseEstimateParams parses the query into an EstimateRequest and validates it
+// with estimate.ValidateAndNormalize, the same check the service applies
+func (h *EstimateHandler) parseEstimateParams(ctx *fasthttp.RequestCtx) (estimate.EstimateRequest, error) {
+	if err := rejectDuplicateParams(ctx.QueryArgs(), "src_amount"); err != nil {
+		return estimate.EstimateRequest{}, err
+	}
+	srcAmountValues := ctx.QueryArgs().PeekMulti("src_amount")
+	// With decimals=true src_amount is in whole tokens, scaled by the service once
+	// it has read the source token's decimals
+	inTokens := ctx.QueryArgs().GetBool("decimals")
+	if inTokens {
+		if len(srcAmountValues) != 1 {
+			return estimate.EstimateRequest{}, fmt.Errorf("%w: decimals=true takes a single src_amount", apperrors.ErrValidation)
+		}
+		srcAmountValues = nil
+	}
+	srcAmounts := make([]*big.Int, len(srcAmountValues))
+	for i, srcAmountBytes := range srcAmountValues {
+		// The sign is checked by ValidateAndNormalize, so item_status can report it per item
+		srcAmountBig, err := parseSrcAmountValue(srcAmountBytes)
+		if err != nil {
+			return estimate.EstimateRequest{}, err
+		}
+		srcAmounts[i] = srcAmountBig
+	}
+
+	feeSide, err := parseFeeSide(ctx.QueryArgs().Peek("fee_side"))
+	if err != nil {
+		return estimate.EstimateRequest{}, err
+	}
+
+	req := estimate.EstimateRequest{
+		PoolAddress:   string(ctx.QueryArgs().Peek("pool")),
+		SrcToken:      string(ctx.QueryArgs().Peek("src")),
+		DstToken:      string(ctx.QueryArgs().Peek("dst")),
+		Factory:       string(ctx.QueryArgs().Peek("factory")),
+		FeeName:       string(ctx.QueryArgs().Peek("fee")),
+		FeeSide:       feeSide,
+		ItemStatus:    ctx.QueryArgs().GetBool("item_status"),
+		AllowIdentity: ctx.QueryArgs().GetBool("allow_identity"),
+		PriceImpact:   ctx.QueryArgs().GetBool("price_impact"),
+	}
+	if req.SlippageBps, err = parseIntParam(ctx.QueryArgs(), "slippage_bps"); err != nil {
+		return estimate.EstimateRequest{}, err
+	}
+	if req.FeeBasisPoints, err = parseIntParam(ctx.QueryArgs(), "fee_bps"); err != nil {
+		return estimate.EstimateRequest{}, err
+	}
+	if req.FeeBasisPoints0To1, err = parseIntParam(ctx.QueryArgs(), "fee_bps_0to1"); err != nil {
+		return estimate.EstimateRequest{}, err
+	}
+	if req.FeeBasisPoints1To0, err = parseIntParam(ctx.QueryArgs(), "fee_bps_1to0"); err != nil {
+		return estimate.EstimateRequest{}, err
+	}
+	sweep, err := parseGeometricSweep(ctx.QueryArgs())
+	if err != nil {
+		return estimate.EstimateRequest{}, err
+	}
+	if sweep != nil {
+		if len(srcAmounts) > 0 {
+			return estimate.EstimateRequest{}, fmt.Errorf("%w: src_amount and a start/factor/count sweep are mutually exclusive", apperrors.ErrValidation)
+		}
+		srcAmounts = sweep
+	}
+	switch {
+	case len(srcAmounts) == 1 && sweep == nil:
+		req.SrcAmount = srcAmounts[0]
+	case len(srcAmounts) > 0:
+		req.SrcAmounts = srcAmounts
+	}
+	if dstAmount := ctx.QueryArgs().Peek("dst_amount"); len(dstAmount) > 0 {
+		if req.DstAmount, err = parseAmountValue(dstAmount, "destination amount"); err != nil {
+			return estimate.EstimateRequest{}, err
+		}
+	}
+	if offset := ctx.QueryArgs().Peek("block_offset"); len(offset) > 0 {
+		if req.BlockOffset, err = strconv.ParseUint(string(offset), 10, 64); err != nil {
+			return estimate.EstimateRequest{}, fmt.Errorf("%w: block_offset must be a non-negative integer", apperrors.ErrValidation)
+		}
+	}
+	if req.Block, err = parseBlockParam(ctx.QueryArgs()); err != nil {
+		return estimate.EstimateRequest{}, err
+	}
+	if ctx.QueryArgs().GetBool("show_math") {
+		if !h.config.Debug.Enabled {
+			return estimate.EstimateRequest{}, fmt.Errorf("%w: show_math requires debug mode", apperrors.ErrValidation)
+		}
+		req.ShowMath = true
+	}
+	if ctx.QueryArgs().GetBool("fee_tiers") {
+		if len(h.config.AssumedFeeTiers) == 0 {
+			return estimate.EstimateRequest{}, fmt.Errorf("%w: no assumed fee tiers are configured", apperrors.ErrValidation)
+		}
+		req.FeeTiers = h.config.AssumedFeeTiers
 	}
-	srcValue := string(srcBytes)
 
-	dstBytes := ctx.QueryArgs().Peek("dst")
-	if len(dstBytes) == 0 {
-		return "", "", "", nil, fmt.Errorf("%w: destination token parameter is required", apperrors.ErrValidation)
+	if req.HumanAmounts, err = parseAmountFormat(ctx.QueryArgs().Peek("format")); err != nil {
+		return estimate.EstimateRequest{}, err
+	}
+	if inTokens {
+		req.SrcTokenAmount = string(ctx.QueryArgs().Peek("src_amount"))
+		req.HumanAmounts = true
+	}
+	if decimals := ctx.QueryArgs().Peek("dst_decimals"); len(decimals) > 0 {
+		value, err := strconv.ParseUint(string(decimals), 10, 8)
+		if err != nil {
+			return estimate.EstimateRequest{}, fmt.Errorf("%w: dst_decimals must be an integer between 0 and 255", apperrors.ErrValidation)
+		}
+		dstDecimals := uint8(value)
+		req.DstDecimals = &dstDecimals
 	}
-	dstValue := string(dstBytes)
 
-	srcAmountBytes := ctx.QueryArgs().Peek("src_amount")
-	if len(srcAmountBytes) == 0 {
-		return "", "", "", nil, fmt.Errorf("%w: source amount parameter is required", apperrors.ErrValidation)
+	req.MaxReserveRatio = h.config.ReserveGuard.MaxRatio
+	req.ReserveRatioWarnOnly = h.config.ReserveGuard.Action == config.ReserveGuardWarn
+	req.MaxReserveStaleness = h.config.ReserveGuard.MaxStaleness
+	req.EmptyReservesRetry = h.config.Blockchain.EmptyReservesRetry
+
+	if err := estimate.ValidateAndNormalize(&req); err != nil {
+		return estimate.EstimateRequest{}, err
 	}
-	srcAmountValue := string(srcAmountBytes)
+	return req, nil
+}
 
-	srcAmount, err := strconv.ParseInt(srcAmountValue, 10, 64)
+// parseBlockParam parses the optional block parameter, returning nil when absent
+func parseBlockParam(args *fasthttp.Args) (*uint64, error) {
+	block := args.Peek("block")
+	if len(block) == 0 {
+		return nil, nil
+	}
+	number, err := strconv.ParseUint(string(block), 10, 64)
 	if err != nil {
-		return "", "", "", nil, fmt.Errorf("%w: source amount must be a valid number", apperrors.ErrValidation)
+		return nil, fmt.Errorf("%w: block must be a non-negative decimal integer", apperrors.ErrValidation)
 	}
+	return &number, nil
+}
 
-	if srcAmount <= 0 {
-		return "", "", "", nil, fmt.Errorf("%w: source amount must be positive", apperrors.ErrValidation)
+// parseAmountFormat reports whether format selects human-readable amounts; the
+// default, "raw", is base units, and "json" is base units in a JSON body
+func parseAmountFormat(value []byte) (bool, error) {
+	switch string(value) {
+	case "", "raw", "json":
+		return false, nil
+	case "human":
+		return true, nil
 	}
+	return false, fmt.Errorf("%w: format must be raw, human or json", apperrors.ErrValidation)
+}
 
-	srcAmountBig := big.NewInt(srcAmount)
+// parseGeometricSweep expands start, factor and count into a geometric sequence of
+// input sizes, or returns nil when none of them is set. All three are required together.
+func parseGeometricSweep(args *fasthttp.Args) ([]*big.Int, error) {
+	startValue, factorValue, countValue := args.Peek("start"), args.Peek("factor"), args.Peek("count")
+	if len(startValue) == 0 && len(factorValue) == 0 && len(countValue) == 0 {
+		return nil, nil
+	}
+	if len(startValue) == 0 || len(factorValue) == 0 || len(countValue) == 0 {
+		return nil, fmt.Errorf("%w: a sweep requires start, factor and count", apperrors.ErrValidation)
+	}
 
-	return poolValue, srcValue, dstValue, srcAmountBig, nil
+	start, err := parseAmountValue(startValue, "sweep start")
+	if err != nil {
+		return nil, err
+	}
+	factor, err := strconv.ParseInt(string(factorValue), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: sweep factor must be an integer", apperrors.ErrValidation)
+	}
+	count, err := strconv.Atoi(string(countValue))
+	if err != nil {
+		return nil, fmt.Errorf("%w: sweep count must be an integer", apperrors.ErrValidation)
+	}
+	return estimate.GeometricAmounts(start, factor, count)
+}
+
+// rejectDuplicateParams fails when any query parameter other than multiValued
+// repeats. Peek returns only the first value, so ?pool=A&pool=B would otherwise
+// quietly quote A.
+func rejectDuplicateParams(args *fasthttp.Args, multiValued ...string) error {
+	var duplicate string
+	args.VisitAll(func(key, _ []byte) {
+		name := string(key)
+		if duplicate != "" || slices.Contains(multiValued, name) {
+			return
+		}
+		if len(args.PeekMulti(name)) > 1 {
+			duplicate = name
+		}
+	})
+	if duplicate != "" {
+		return fmt.Errorf("%w: %s parameter must not be repeated", apperrors.ErrValidation, duplicate)
+	}
+	return nil
+}
+
+// requireQueryParam returns the value of a mandatory query parameter
+func requireQueryParam(args *fasthttp.Args, name, label string) (string, error) {
+	value := args.Peek(name)
+	if len(value) == 0 {
+		return "", fmt.Errorf("%w: %s parameter is required", apperrors.ErrValidation, label)
+	}
+	return string(value), nil
+}
+
+// validatePoolCount rejects multi-pool requests that exceed the configured
+// max_pools_per_request, before any RPC work is done
+func (h *EstimateHandler) validatePoolCount(count int) error {
+	limit := h.config.Server.MaxPoolsPerRequest
+	if limit > 0 && count > limit {
+		return fmt.Errorf("%w: request uses %d pools, maximum is %d", apperrors.ErrValidation, count, limit)
+	}
+	return nil
+}
+
+// parseSrcAmount parses a single src_amount value, which must be positive
+func parseSrcAmount(srcAmountBytes []byte) (*big.Int, error) {
+	srcAmount, err := parseSrcAmountValue(srcAmountBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	if srcAmount.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: source amount must be positive", apperrors.ErrValidation)
+	}
+
+	return srcAmount, nil
+}
+
+// parseSrcAmountValue parses a single src_amount value without checking its sign
+func parseSrcAmountValue(srcAmountBytes []byte) (*big.Int, error) {
+	return parseAmountValue(srcAmountBytes, "source amount")
+}
+
+// parseAmountValue parses a single decimal or 0x-prefixed hex amount parameter
+// without checking its sign; label names it in errors
+func parseAmountValue(amountBytes []byte, label string) (*big.Int, error) {
+	if len(amountBytes) == 0 {
+		return nil, fmt.Errorf("%w: %s parameter is required", apperrors.ErrValidation, label)
+	}
+
+	amount, err := utils.ParseAmount(string(amountBytes))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s must be a decimal or 0x-prefixed hex integer", apperrors.ErrValidation, label)
+	}
+
+	return amount, nil
+}
+
+// parseIntParam parses an optional integer parameter, such as a fee override,
+// returning nil when it is absent
+func parseIntParam(args *fasthttp.Args, name string) (*int, error) {
+	value := args.Peek(name)
+	if len(value) == 0 {
+		return nil, nil
+	}
+	number, err := strconv.Atoi(string(value))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s must be an integer", apperrors.ErrValidation, name)
+	}
+	return &number, nil
+}
+
+// parseFeeSide parses the optional fee_side parameter, defaulting to the input side
+func parseFeeSide(value []byte) (utils.FeeSide, error) {
+	switch string(value) {
+	case "", "in":
+		return utils.FeeOnInput, nil
+	case "out":
+		return utils.FeeOnOutput, nil
+	default:
+		return utils.FeeOnInput, fmt.Errorf("%w: fee_side must be either \"in\" or \"out\"", apperrors.ErrValidation)
+	}
 }
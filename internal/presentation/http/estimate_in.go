@@ -0,0 +1,46 @@
+package http
+
+import (
+	"fmt"
+
+	apperrors "bigswapenergy/internal/shared/errors"
+
+	"github.com/valyala/fasthttp"
+)
+
+// EstimateSwapAmountIn handles the /estimate-in endpoint: the src amount required
+// to receive exactly dst_amount, as a plain-text integer. It is the dedicated
+// form of /estimate?dst_amount=..., validated and mapped the same way.
+func (h *EstimateHandler) EstimateSwapAmountIn(ctx *fasthttp.RequestCtx) {
+	timings := newPhaseTimings()
+	reqCtx, log, cancel := h.requestContext(ctx, timings)
+	defer cancel()
+
+	args := ctx.QueryArgs()
+	if err := rejectDuplicateParams(args); err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	if args.Has("src_amount") {
+		h.handleError(ctx, fmt.Errorf("%w: /estimate-in takes dst_amount, not src_amount", apperrors.ErrValidation))
+		return
+	}
+	dstAmount, err := parseAmountValue(args.Peek("dst_amount"), "destination amount")
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	timings.mark("parse")
+
+	amountIn, err := h.estimateService.EstimateSwapAmountIn(reqCtx, string(args.Peek("pool")), string(args.Peek("src")), string(args.Peek("dst")), dstAmount)
+	if err != nil {
+		h.handleError(ctx, requestError(reqCtx, err))
+		return
+	}
+	timings.mark("estimate")
+
+	h.logCompletion(log, "Exact-out estimate completed", timings)
+
+	ctx.SetContentType("text/plain")
+	ctx.SetBodyString(amountIn.String())
+}
@@ -0,0 +1,74 @@
+package http
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Optional endpoint features. Core endpoints (/estimate, /stats, /ready) have no flag.
+const (
+	FeatureArbitrage  = "arb"
+	FeatureQuote      = "quote"
+	FeatureRoute      = "route"
+	FeaturePools      = "pools"
+	FeaturePool       = "pool"
+	FeaturePoolRaw    = "pool_raw"
+	FeaturePoolTokens = "pool_tokens"
+	FeatureMaxImpact  = "max_impact"
+	FeatureCheck      = "check"
+	FeatureInvariant  = "pool_invariant"
+	FeaturePrice      = "price"
+	FeatureEstimateIn = "estimate_in"
+	FeatureBatch      = "batch"
+)
+
+var knownFeatures = map[string]bool{
+	FeatureArbitrage:  true,
+	FeatureQuote:      true,
+	FeatureRoute:      true,
+	FeaturePools:      true,
+	FeaturePool:       true,
+	FeaturePoolRaw:    true,
+	FeaturePoolTokens: true,
+	FeatureMaxImpact:  true,
+	FeatureCheck:      true,
+	FeatureInvariant:  true,
+	FeaturePrice:      true,
+	FeatureEstimateIn: true,
+	FeatureBatch:      true,
+}
+
+// FeatureFlags reports which optional endpoints are enabled for this deployment
+type FeatureFlags struct {
+	disabled map[string]bool
+}
+
+// NewFeatureFlags builds flags from the features config section. Features not
+// listed stay enabled; unknown names are rejected so typos don't go unnoticed.
+func NewFeatureFlags(features map[string]bool) (*FeatureFlags, error) {
+	flags := &FeatureFlags{disabled: make(map[string]bool)}
+	for name, enabled := range features {
+		if !knownFeatures[name] {
+			return nil, fmt.Errorf("unknown feature %q", name)
+		}
+		if !enabled {
+			flags.disabled[name] = true
+		}
+	}
+	return flags, nil
+}
+
+// Enabled reports whether feature is on
+func (f *FeatureFlags) Enabled(feature string) bool {
+	return !f.disabled[feature]
+}
+
+// Disabled returns the disabled features, sorted
+func (f *FeatureFlags) Disabled() []string {
+	names := make([]string, 0, len(f.disabled))
+	for name := range f.disabled {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,90 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"bigswapenergy/internal/shared/clock"
+
+	"github.com/valyala/fasthttp"
+)
+
+// healthCacheTTL is how long a /health result is reused, so frequent load
+// balancer polls don't each cost an RPC call per connection
+const healthCacheTTL = time.Second
+
+// ConnectionsHealthChecker reports the health of each RPC connection by index
+type ConnectionsHealthChecker interface {
+	CheckConnectionsHealth(ctx context.Context) map[int]bool
+}
+
+type HealthResponse struct {
+	Healthy int `json:"healthy"`
+	Total   int `json:"total"`
+}
+
+type HealthHandler struct {
+	checker ConnectionHealthChecker
+	timeout time.Duration
+	clock   clock.Clock
+
+	// mu is held through a check, so concurrent polls on expiry share one
+	mu        sync.Mutex
+	last      HealthResponse
+	checkedAt time.Time
+}
+
+// NewHealthHandler creates the /health handler. A checker without per-connection
+// health counts as a single connection.
+func NewHealthHandler(checker ConnectionHealthChecker, timeout time.Duration, clk clock.Clock) *HealthHandler {
+	return &HealthHandler{checker: checker, timeout: timeout, clock: clk}
+}
+
+// GetHealth handles the /health endpoint: 200 while any RPC connection is
+// healthy and 503 when none is, with the counts, checked at most once per second
+func (h *HealthHandler) GetHealth(ctx *fasthttp.RequestCtx) {
+	resp := h.health()
+
+	ctx.SetContentType("application/json")
+	if resp.Healthy == 0 {
+		ctx.SetStatusCode(fasthttp.StatusServiceUnavailable)
+	}
+	json.NewEncoder(ctx).Encode(resp)
+}
+
+// health returns the cached result, checking the connections again once it has
+// expired. The check is shared by every poll, so it isn't tied to any one request.
+func (h *HealthHandler) health() HealthResponse {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.checkedAt.IsZero() && h.clock.Since(h.checkedAt) < healthCacheTTL {
+		return h.last
+	}
+
+	ctx := context.Background()
+	if h.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.timeout)
+		defer cancel()
+	}
+
+	var resp HealthResponse
+	if checker, ok := h.checker.(ConnectionsHealthChecker); ok {
+		for _, healthy := range checker.CheckConnectionsHealth(ctx) {
+			resp.Total++
+			if healthy {
+				resp.Healthy++
+			}
+		}
+	} else {
+		resp.Total = 1
+		if h.checker.CheckConnectionHealth(ctx) {
+			resp.Healthy = 1
+		}
+	}
+
+	h.last, h.checkedAt = resp, h.clock.Now()
+	return resp
+}
@@ -0,0 +1,93 @@
+package http
+
+import (
+	"encoding/json"
+
+	estimate "bigswapenergy/internal/usecases"
+
+	"github.com/valyala/fasthttp"
+)
+
+// PoolInvariantResponse carries k and reserves as decimal strings, since k for a
+// pair of uint112 reserves can reach 224 bits
+type PoolInvariantResponse struct {
+	Pool        string                 `json:"pool"`
+	BlockNumber uint64                 `json:"block_number"`
+	Reserve0    string                 `json:"reserve0"`
+	Reserve1    string                 `json:"reserve1"`
+	K           string                 `json:"k"`
+	Swap        *InvariantSwapResponse `json:"swap,omitempty"`
+}
+
+type InvariantSwapResponse struct {
+	AmountIn      string `json:"amount_in"`
+	AmountOut     string `json:"amount_out"`
+	Reserve0After string `json:"reserve0_after"`
+	Reserve1After string `json:"reserve1_after"`
+	KAfter        string `json:"k_after"`
+	KIncrease     string `json:"k_increase"`
+}
+
+// GetPoolInvariant handles the /pool/invariant endpoint. With src and src_amount
+// it also reports k after that swap, which grows by the fee the pool keeps.
+func (h *EstimateHandler) GetPoolInvariant(ctx *fasthttp.RequestCtx) {
+	timings := newPhaseTimings()
+	reqCtx, log, cancel := h.requestContext(ctx, timings)
+	defer cancel()
+
+	req, err := parseInvariantParams(ctx.QueryArgs())
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	timings.mark("parse")
+
+	result, err := h.estimateService.ReadPoolInvariant(reqCtx, req)
+	if err != nil {
+		h.handleError(ctx, requestError(reqCtx, err))
+		return
+	}
+	timings.mark("estimate")
+	ctx.SetUserValue(userValueBlockNumber, result.BlockNumber)
+
+	h.logCompletion(log, "Pool invariant completed", timings)
+
+	resp := PoolInvariantResponse{
+		Pool:        result.Pool.Hex(),
+		BlockNumber: result.BlockNumber,
+		Reserve0:    result.Reserve0.String(),
+		Reserve1:    result.Reserve1.String(),
+		K:           result.K.String(),
+	}
+	if swap := result.Swap; swap != nil {
+		resp.Swap = &InvariantSwapResponse{
+			AmountIn:      swap.AmountIn.String(),
+			AmountOut:     swap.AmountOut.String(),
+			Reserve0After: swap.Reserve0After.String(),
+			Reserve1After: swap.Reserve1After.String(),
+			KAfter:        swap.KAfter.String(),
+			KIncrease:     swap.KIncrease.String(),
+		}
+	}
+
+	ctx.SetContentType("application/json")
+	json.NewEncoder(ctx).Encode(resp)
+}
+
+func parseInvariantParams(args *fasthttp.Args) (estimate.InvariantRequest, error) {
+	if err := rejectDuplicateParams(args); err != nil {
+		return estimate.InvariantRequest{}, err
+	}
+	pool, err := requireQueryParam(args, "pool", "pool")
+	if err != nil {
+		return estimate.InvariantRequest{}, err
+	}
+	req := estimate.InvariantRequest{PoolAddress: pool, SrcToken: string(args.Peek("src"))}
+
+	if amount := args.Peek("src_amount"); len(amount) > 0 {
+		if req.SrcAmount, err = parseSrcAmount(amount); err != nil {
+			return estimate.InvariantRequest{}, err
+		}
+	}
+	return req, nil
+}
@@ -0,0 +1,84 @@
+package http
+
+import (
+	"fmt"
+	"math/big"
+
+	apperrors "bigswapenergy/internal/shared/errors"
+	estimate "bigswapenergy/internal/usecases"
+
+	"github.com/valyala/fasthttp"
+)
+
+// Headers carrying caller-tracked reserves for /estimate/local, oriented for the swap
+const (
+	headerReserveIn  = "X-Reserve-In"
+	headerReserveOut = "X-Reserve-Out"
+)
+
+// QuoteFromReserves handles the /estimate/local endpoint. Reserves come from
+// X-Reserve-In and X-Reserve-Out and are trusted, so the quote needs no RPC; the
+// route is only registered when server.trusted_reserves is on.
+func (h *EstimateHandler) QuoteFromReserves(ctx *fasthttp.RequestCtx) {
+	req, err := parseLocalQuoteParams(ctx)
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+
+	amountOut, err := estimate.QuoteFromReserves(req)
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+
+	ctx.SetContentType("text/plain")
+	ctx.SetBodyString(amountOut.String())
+}
+
+func parseLocalQuoteParams(ctx *fasthttp.RequestCtx) (estimate.LocalQuoteRequest, error) {
+	if err := rejectDuplicateParams(ctx.QueryArgs()); err != nil {
+		return estimate.LocalQuoteRequest{}, err
+	}
+	reserveIn, err := parseReserveHeader(ctx, headerReserveIn)
+	if err != nil {
+		return estimate.LocalQuoteRequest{}, err
+	}
+	reserveOut, err := parseReserveHeader(ctx, headerReserveOut)
+	if err != nil {
+		return estimate.LocalQuoteRequest{}, err
+	}
+	srcAmount, err := parseSrcAmount(ctx.QueryArgs().Peek("src_amount"))
+	if err != nil {
+		return estimate.LocalQuoteRequest{}, err
+	}
+	feeSide, err := parseFeeSide(ctx.QueryArgs().Peek("fee_side"))
+	if err != nil {
+		return estimate.LocalQuoteRequest{}, err
+	}
+	fee, err := parseIntParam(ctx.QueryArgs(), "fee_bps")
+	if err != nil {
+		return estimate.LocalQuoteRequest{}, err
+	}
+
+	return estimate.LocalQuoteRequest{
+		ReserveIn:      reserveIn,
+		ReserveOut:     reserveOut,
+		SrcAmount:      srcAmount,
+		FeeBasisPoints: fee,
+		FeeSide:        feeSide,
+	}, nil
+}
+
+// parseReserveHeader parses a mandatory decimal reserve header
+func parseReserveHeader(ctx *fasthttp.RequestCtx, name string) (*big.Int, error) {
+	value := ctx.Request.Header.Peek(name)
+	if len(value) == 0 {
+		return nil, fmt.Errorf("%w: %s header is required", apperrors.ErrValidation, name)
+	}
+	reserve, ok := new(big.Int).SetString(string(value), 10)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s must be a decimal integer", apperrors.ErrValidation, name)
+	}
+	return reserve, nil
+}
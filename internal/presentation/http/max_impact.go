@@ -0,0 +1,82 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	apperrors "bigswapenergy/internal/shared/errors"
+	estimate "bigswapenergy/internal/usecases"
+
+	"github.com/valyala/fasthttp"
+)
+
+type MaxImpactResponse struct {
+	AmountIn    string `json:"amount_in"`
+	AmountOut   string `json:"amount_out"`
+	BlockNumber uint64 `json:"block_number"`
+}
+
+// EstimateMaxForImpact handles the /estimate/max-for-impact endpoint
+func (h *EstimateHandler) EstimateMaxForImpact(ctx *fasthttp.RequestCtx) {
+	timings := newPhaseTimings()
+	reqCtx, log, cancel := h.requestContext(ctx, timings)
+	defer cancel()
+
+	req, err := parseMaxImpactParams(ctx.QueryArgs())
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	timings.mark("parse")
+
+	result, err := h.estimateService.EstimateMaxForImpact(reqCtx, req)
+	if err != nil {
+		h.handleError(ctx, requestError(reqCtx, err))
+		return
+	}
+	timings.mark("estimate")
+	ctx.SetUserValue(userValueBlockNumber, result.BlockNumber)
+
+	h.logCompletion(log, "Max-for-impact estimate completed", timings)
+
+	ctx.SetContentType("application/json")
+	json.NewEncoder(ctx).Encode(MaxImpactResponse{
+		AmountIn:    result.AmountIn.String(),
+		AmountOut:   result.AmountOut.String(),
+		BlockNumber: result.BlockNumber,
+	})
+}
+
+func parseMaxImpactParams(args *fasthttp.Args) (estimate.MaxImpactRequest, error) {
+	if err := rejectDuplicateParams(args); err != nil {
+		return estimate.MaxImpactRequest{}, err
+	}
+	pool, err := requireQueryParam(args, "pool", "pool")
+	if err != nil {
+		return estimate.MaxImpactRequest{}, err
+	}
+	src, err := requireQueryParam(args, "src", "source token")
+	if err != nil {
+		return estimate.MaxImpactRequest{}, err
+	}
+	dst, err := requireQueryParam(args, "dst", "destination token")
+	if err != nil {
+		return estimate.MaxImpactRequest{}, err
+	}
+	bpsValue, err := requireQueryParam(args, "max_impact_bps", "max_impact_bps")
+	if err != nil {
+		return estimate.MaxImpactRequest{}, err
+	}
+	bps, err := strconv.Atoi(bpsValue)
+	if err != nil {
+		return estimate.MaxImpactRequest{}, fmt.Errorf("%w: max_impact_bps must be an integer", apperrors.ErrValidation)
+	}
+
+	return estimate.MaxImpactRequest{
+		PoolAddress:  pool,
+		SrcToken:     src,
+		DstToken:     dst,
+		MaxImpactBps: bps,
+	}, nil
+}
@@ -0,0 +1,58 @@
+package http
+
+import (
+	"strconv"
+	"time"
+
+	"bigswapenergy/internal/shared/metrics"
+	"bigswapenergy/internal/shared/utils"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
+)
+
+// RequestMetricsMiddleware counts every response and its latency by status code
+type RequestMetricsMiddleware struct {
+	exporter *metrics.Exporter
+}
+
+func NewRequestMetricsMiddleware(exporter *metrics.Exporter) *RequestMetricsMiddleware {
+	return &RequestMetricsMiddleware{exporter: exporter}
+}
+
+func (m *RequestMetricsMiddleware) Apply(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		start := time.Now()
+		next(ctx)
+
+		code := strconv.Itoa(ctx.Response.StatusCode())
+		m.exporter.HTTPRequests.WithLabelValues(code).Inc()
+		m.exporter.HTTPRequestDuration.WithLabelValues(code).Observe(time.Since(start).Seconds())
+	}
+}
+
+type MetricsHandler struct {
+	handler fasthttp.RequestHandler
+}
+
+func NewMetricsHandler(exporter *metrics.Exporter) *MetricsHandler {
+	return &MetricsHandler{
+		handler: fasthttpadaptor.NewFastHTTPHandler(promhttp.HandlerFor(exporter.Registry, promhttp.HandlerOpts{})),
+	}
+}
+
+// GetMetrics handles /metrics, returning every collector on the exporter's
+// registry in the Prometheus exposition format
+func (h *MetricsHandler) GetMetrics(ctx *fasthttp.RequestCtx) {
+	h.handler(ctx)
+}
+
+// ExportBigIntPoolMetrics exposes pool's Get/Put balance and avoided allocations
+// on exporter, alongside the /stats gauges of RegisterBigIntPoolMetrics
+func ExportBigIntPoolMetrics(exporter *metrics.Exporter, pool *utils.BigIntPool) {
+	exporter.CounterFunc(MetricBigIntPoolGets, "BigIntPool Get calls.", func() float64 { return float64(pool.Stats().Gets) })
+	exporter.CounterFunc(MetricBigIntPoolPuts, "BigIntPool Put calls.", func() float64 { return float64(pool.Stats().Puts) })
+	exporter.GaugeFunc(MetricBigIntPoolOutstanding, "BigIntPool values taken and not yet returned.", func() float64 { return float64(pool.Stats().Outstanding()) })
+	exporter.CounterFunc(MetricBigIntPoolAllocsAvoided, "BigIntPool Gets served without allocating.", func() float64 { return float64(pool.Stats().AllocsAvoided()) })
+}
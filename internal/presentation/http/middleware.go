@@ -1,17 +1,39 @@
 package http
 
 import (
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"bigswapenergy/internal/shared/clock"
+	"bigswapenergy/internal/shared/janitor"
+	"bigswapenergy/internal/shared/metrics"
+
 	"github.com/valyala/fasthttp"
 	"go.uber.org/zap"
 )
 
 type HTTPRateLimitConfig struct {
 	RequestsPerMinute int
+	// Burst is how many requests a client may make at once; 0 allows RequestsPerMinute
+	Burst int
+	// MaxClients caps the number of tracked clients; 0 leaves it unbounded
+	MaxClients int
+	// CleanupInterval is how often idle clients are swept; 0 sweeps once per window
+	CleanupInterval time.Duration
+	// TrustedProxies are the peers whose X-Forwarded-For is believed; from anyone
+	// else the header is ignored and the peer address is the client
+	TrustedProxies []netip.Prefix
 }
 
+// pruneTarget is the fraction of MaxClients kept after a prune, so pruning runs
+// once per batch of new clients rather than on every insert
+const pruneTarget = 0.9
+
 type RateLimitable interface {
 	GetRateLimitConfig() HTTPRateLimitConfig
 }
@@ -20,36 +42,59 @@ type Middleware interface {
 	Apply(next fasthttp.RequestHandler) fasthttp.RequestHandler
 }
 
+// ClientRateLimit is a client's token bucket: each request spends a token, and
+// tokens refill continuously at RequestsPerMinute up to the burst
 type ClientRateLimit struct {
-	requests    int
+	tokens      float64
 	lastRequest time.Time
 	mutex       sync.RWMutex
 }
 
 type RateLimitMiddleware struct {
-	config     HTTPRateLimitConfig
+	// config is swapped whole by UpdateConfig, so a check sees one version
+	config     atomic.Pointer[HTTPRateLimitConfig]
 	logger     *zap.Logger
 	clients    map[string]*ClientRateLimit
 	clientsMux sync.RWMutex
+	clock      clock.Clock
+	janitor    *janitor.Janitor
 }
 
-func NewRateLimitMiddleware(config HTTPRateLimitConfig, logger *zap.Logger) *RateLimitMiddleware {
-	return &RateLimitMiddleware{
-		config:  config,
+// rateLimitWindow is the period RequestsPerMinute refills over
+const rateLimitWindow = time.Minute
+
+func NewRateLimitMiddleware(config HTTPRateLimitConfig, logger *zap.Logger, clk clock.Clock) *RateLimitMiddleware {
+	m := &RateLimitMiddleware{
 		logger:  logger,
 		clients: make(map[string]*ClientRateLimit),
+		clock:   clk,
 	}
+	m.config.Store(&config)
+	metrics.Global.Gauge(MetricRateLimitClients, func() int64 { return int64(m.Size()) })
+	return m
+}
+
+// UpdateConfig replaces the limits applied from the next request on. Clients keep
+// their buckets, capped at the new burst as they refill. The cleanup interval
+// only takes effect when the janitor is started.
+func (m *RateLimitMiddleware) UpdateConfig(config HTTPRateLimitConfig) {
+	m.config.Store(&config)
+}
+
+// Size returns the number of clients currently tracked
+func (m *RateLimitMiddleware) Size() int {
+	m.clientsMux.RLock()
+	defer m.clientsMux.RUnlock()
+	return len(m.clients)
 }
 
 func (m *RateLimitMiddleware) Apply(next fasthttp.RequestHandler) fasthttp.RequestHandler {
 	return func(ctx *fasthttp.RequestCtx) {
-		clientIP := string(ctx.Request.Header.Peek("X-Forwarded-For"))
-		if clientIP == "" {
-			clientIP = ctx.RemoteIP().String()
-		}
+		clientIP := m.clientIP(ctx)
 
 		if !m.checkRateLimit(clientIP) {
-			m.logger.Warn("Rate limit exceeded",
+			metrics.GlobalExporter.RateLimitRejections.Inc()
+			withRequestID(m.logger, ctx).Warn("Rate limit exceeded",
 				zap.String("client_ip", clientIP),
 				zap.String("path", string(ctx.Path())),
 			)
@@ -65,15 +110,19 @@ func (m *RateLimitMiddleware) Apply(next fasthttp.RequestHandler) fasthttp.Reque
 }
 
 func (m *RateLimitMiddleware) checkRateLimit(clientIP string) bool {
-	now := time.Now()
+	now := m.clock.Now()
+	config := m.config.Load()
 
 	m.clientsMux.Lock()
 	defer m.clientsMux.Unlock()
 
 	client, exists := m.clients[clientIP]
 	if !exists {
+		if config.MaxClients > 0 && len(m.clients) >= config.MaxClients {
+			m.pruneClients(config.MaxClients)
+		}
 		client = &ClientRateLimit{
-			requests:    1,
+			tokens:      float64(config.burst() - 1),
 			lastRequest: now,
 		}
 		m.clients[clientIP] = client
@@ -83,27 +132,197 @@ func (m *RateLimitMiddleware) checkRateLimit(clientIP string) bool {
 	client.mutex.Lock()
 	defer client.mutex.Unlock()
 
-	if now.Sub(client.lastRequest) > time.Minute {
-		client.requests = 1
-		client.lastRequest = now
-		return true
-	}
+	refill := now.Sub(client.lastRequest).Minutes() * float64(config.RequestsPerMinute)
+	client.tokens = min(client.tokens+refill, float64(config.burst()))
+	client.lastRequest = now
 
-	if client.requests >= m.config.RequestsPerMinute {
+	if client.tokens < 1 {
 		return false
 	}
-
-	client.requests++
-	client.lastRequest = now
+	client.tokens--
 	return true
 }
 
-func ApplyMiddleware(handler fasthttp.RequestHandler, logger *zap.Logger, configurable interface{}) fasthttp.RequestHandler {
+// clientIP returns the address requests are limited by. X-Forwarded-For is only
+// read when the peer is a trusted proxy, walking it right to left past further
+// trusted proxies: hops left of the first untrusted one may be forged by the client.
+func (m *RateLimitMiddleware) clientIP(ctx *fasthttp.RequestCtx) string {
+	client, _ := netip.AddrFromSlice(ctx.RemoteIP())
+	client = client.Unmap()
+	if !m.trusted(client) {
+		return client.String()
+	}
+
+	hops := strings.Split(string(ctx.Request.Header.Peek("X-Forwarded-For")), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		client = hop.Unmap()
+		if !m.trusted(client) {
+			break
+		}
+	}
+	return client.String()
+}
+
+// trusted reports whether addr is in one of the trusted proxy ranges
+func (m *RateLimitMiddleware) trusted(addr netip.Addr) bool {
+	for _, prefix := range m.config.Load().TrustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// burst returns the bucket size, at least one request
+func (c *HTTPRateLimitConfig) burst() int {
+	burst := c.Burst
+	if burst <= 0 {
+		burst = c.RequestsPerMinute
+	}
+	return max(burst, 1)
+}
+
+// refillTime returns how long an empty bucket takes to fill; a client idle that
+// long is indistinguishable from a new one
+func (c *HTTPRateLimitConfig) refillTime() time.Duration {
+	if c.RequestsPerMinute <= 0 {
+		return rateLimitWindow
+	}
+	return time.Duration(c.burst()) * rateLimitWindow / time.Duration(c.RequestsPerMinute)
+}
+
+// StartJanitor removes clients idle long enough for their bucket to refill every
+// interval, so the map only holds clients seen recently
+func (m *RateLimitMiddleware) StartJanitor(interval time.Duration) {
+	m.janitor = janitor.Start(interval, m.sweepIdleClients)
+}
+
+// Stop stops the janitor, if started, and waits for it to exit
+func (m *RateLimitMiddleware) Stop() {
+	if m.janitor != nil {
+		m.janitor.Stop()
+	}
+}
+
+// sweepIdleClients drops clients whose bucket has refilled; they would start
+// full on their next request anyway
+func (m *RateLimitMiddleware) sweepIdleClients() {
+	now := m.clock.Now()
+	refillTime := m.config.Load().refillTime()
+
+	m.clientsMux.Lock()
+	defer m.clientsMux.Unlock()
+	for ip, client := range m.clients {
+		client.mutex.RLock()
+		idle := now.Sub(client.lastRequest) > refillTime
+		client.mutex.RUnlock()
+		if idle {
+			delete(m.clients, ip)
+		}
+	}
+}
+
+// pruneClients evicts the least recently seen clients until the map is back to
+// pruneTarget of maxClients. Must be called with clientsMux held.
+func (m *RateLimitMiddleware) pruneClients(maxClients int) {
+	type entry struct {
+		ip          string
+		lastRequest time.Time
+	}
+	entries := make([]entry, 0, len(m.clients))
+	for ip, client := range m.clients {
+		client.mutex.RLock()
+		entries = append(entries, entry{ip: ip, lastRequest: client.lastRequest})
+		client.mutex.RUnlock()
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].lastRequest.Before(entries[j].lastRequest)
+	})
+
+	// Leave room for the client about to be inserted
+	keep := int(float64(maxClients) * pruneTarget)
+	if keep >= maxClients {
+		keep = maxClients - 1
+	}
+	evict := len(entries) - keep
+	for _, e := range entries[:evict] {
+		delete(m.clients, e.ip)
+	}
+
+	metrics.Global.Counter(MetricRateLimitEvictions).Add(uint64(evict))
+	m.logger.Debug("Pruned rate limit clients", zap.Int("evicted", evict), zap.Int("remaining", len(m.clients)))
+}
+
+type RecoveryMiddleware struct {
+	logger *zap.Logger
+}
+
+func NewRecoveryMiddleware(logger *zap.Logger) *RecoveryMiddleware {
+	return &RecoveryMiddleware{
+		logger: logger,
+	}
+}
+
+func (m *RecoveryMiddleware) Apply(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		defer func() {
+			if r := recover(); r != nil {
+				metrics.Global.Counter(MetricPanicsRecovered).Inc()
+				withRequestID(m.logger, ctx).Error("Recovered from panic",
+					zap.String("panic", fmt.Sprint(r)),
+					zap.String("path", string(ctx.Path())),
+					zap.String("method", string(ctx.Method())),
+					zap.Stack("stack"),
+				)
+
+				ctx.ResetBody()
+				ctx.SetStatusCode(fasthttp.StatusInternalServerError)
+				ctx.SetContentType("application/json")
+				ctx.SetBodyString(`{"error":{"code":"INTERNAL_ERROR","message":"Internal server error"}}`)
+			}
+		}()
+
+		next(ctx)
+	}
+}
+
+// ApplyMiddleware wraps handler with rate limiting, API key authentication and
+// CORS, when configurable supports them, panic recovery, request IDs and request
+// metrics.
+// Unauthenticated requests are rejected before spending rate limit budget, and
+// CORS sits outside both so preflights are free and rejections still carry CORS
+// headers. The returned rate limiter is nil without
+// rate limiting; the caller owns it and must Stop it on shutdown.
+func ApplyMiddleware(handler fasthttp.RequestHandler, logger *zap.Logger, configurable interface{}) (fasthttp.RequestHandler, *RateLimitMiddleware) {
+	var rateLimitMiddleware *RateLimitMiddleware
 	if rateLimitable, ok := configurable.(RateLimitable); ok {
 		rateLimitConfig := rateLimitable.GetRateLimitConfig()
-		rateLimitMiddleware := NewRateLimitMiddleware(rateLimitConfig, logger)
-		return rateLimitMiddleware.Apply(handler)
+		rateLimitMiddleware = NewRateLimitMiddleware(rateLimitConfig, logger, clock.New())
+		interval := rateLimitConfig.CleanupInterval
+		if interval <= 0 {
+			interval = rateLimitWindow
+		}
+		rateLimitMiddleware.StartJanitor(interval)
+		handler = rateLimitMiddleware.Apply(handler)
+	}
+
+	if apiKeyConfigurable, ok := configurable.(APIKeyConfigurable); ok {
+		if keys := apiKeyConfigurable.GetAPIKeys(); len(keys) > 0 {
+			handler = NewAPIKeyMiddleware(keys, logger).Apply(handler)
+		}
+	}
+
+	if corsConfigurable, ok := configurable.(CORSConfigurable); ok {
+		if corsConfig := corsConfigurable.GetCORSConfig(); len(corsConfig.AllowedOrigins) > 0 {
+			handler = NewCORSMiddleware(corsConfig).Apply(handler)
+		}
 	}
 
-	return handler
+	handler = NewRecoveryMiddleware(logger).Apply(handler)
+	handler = NewRequestIDMiddleware().Apply(handler)
+	return NewRequestMetricsMiddleware(metrics.GlobalExporter).Apply(handler), rateLimitMiddleware
 }
@@ -0,0 +1,225 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+
+	apperrors "bigswapenergy/internal/shared/errors"
+	estimate "bigswapenergy/internal/usecases"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/valyala/fasthttp"
+)
+
+type RawPoolStorageResponse struct {
+	Pool        string `json:"pool"`
+	BlockNumber uint64 `json:"block_number"`
+	Token0      string `json:"token0"`
+	Token1      string `json:"token1"`
+	Reserves    string `json:"reserves"`
+}
+
+type PoolTokensResponse struct {
+	Pool        string `json:"pool"`
+	BlockNumber uint64 `json:"block_number"`
+	Token0      string `json:"token0"`
+	Token1      string `json:"token1"`
+}
+
+type PoolResponse struct {
+	Pool        string `json:"pool"`
+	BlockNumber uint64 `json:"block_number"`
+	Token0      string `json:"token0"`
+	Token1      string `json:"token1"`
+	Reserve0    string `json:"reserve0"`
+	Reserve1    string `json:"reserve1"`
+}
+
+type PoolsRequestBody struct {
+	Pools []string `json:"pools"`
+}
+
+type PoolStateResponse struct {
+	Pool     string `json:"pool"`
+	Token0   string `json:"token0,omitempty"`
+	Token1   string `json:"token1,omitempty"`
+	Reserve0 string `json:"reserve0,omitempty"`
+	Reserve1 string `json:"reserve1,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+type PoolsResponse struct {
+	BlockNumber uint64              `json:"block_number"`
+	Pools       []PoolStateResponse `json:"pools"`
+}
+
+// ReadPools handles POST /pools, returning tokens and reserves for every listed pool
+// read at one block. Pools that fail to read carry an inline error.
+func (h *EstimateHandler) ReadPools(ctx *fasthttp.RequestCtx) {
+	if !requirePost(ctx) {
+		return
+	}
+
+	timings := newPhaseTimings()
+	reqCtx, log, cancel := h.requestContext(ctx, timings)
+	defer cancel()
+
+	var body PoolsRequestBody
+	if err := json.Unmarshal(ctx.PostBody(), &body); err != nil {
+		h.handleError(ctx, fmt.Errorf("%w: request body must be {\"pools\": [...]}: %v", apperrors.ErrValidation, err))
+		return
+	}
+	if err := h.validatePoolCount(len(body.Pools)); err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	timings.mark("parse")
+
+	result, err := h.estimateService.ReadPools(reqCtx, body.Pools)
+	if err != nil {
+		h.handleError(ctx, requestError(reqCtx, err))
+		return
+	}
+	timings.mark("estimate")
+	ctx.SetUserValue(userValueBlockNumber, result.BlockNumber)
+
+	h.logCompletion(log, "Batch pool read completed", timings)
+
+	resp := PoolsResponse{
+		BlockNumber: result.BlockNumber,
+		Pools:       make([]PoolStateResponse, len(result.Pools)),
+	}
+	for i, pool := range result.Pools {
+		resp.Pools[i] = PoolStateResponse{Pool: pool.Pool.Hex()}
+		if pool.Err != nil {
+			resp.Pools[i].Error = pool.Err.Error()
+			continue
+		}
+		resp.Pools[i].Token0 = pool.Token0.Hex()
+		resp.Pools[i].Token1 = pool.Token1.Hex()
+		resp.Pools[i].Reserve0 = pool.Reserve0.String()
+		resp.Pools[i].Reserve1 = pool.Reserve1.String()
+	}
+
+	ctx.SetContentType("application/json")
+	json.NewEncoder(ctx).Encode(resp)
+}
+
+// requirePost answers 405 and reports false unless the request is a POST
+func requirePost(ctx *fasthttp.RequestCtx) bool {
+	if ctx.IsPost() {
+		return true
+	}
+	ctx.Response.Header.Set(fasthttp.HeaderAllow, fasthttp.MethodPost)
+	ctx.SetStatusCode(fasthttp.StatusMethodNotAllowed)
+	ctx.SetContentType("application/json")
+	ctx.SetBodyString(`{"error":{"code":"METHOD_NOT_ALLOWED","message":"Use POST"}}`)
+	return false
+}
+
+// GetPool handles the /pool endpoint, returning the pool's tokens and reserves
+// at the latest block, or at block when given, without quoting a swap
+func (h *EstimateHandler) GetPool(ctx *fasthttp.RequestCtx) {
+	timings := newPhaseTimings()
+	reqCtx, log, cancel := h.requestContext(ctx, timings)
+	defer cancel()
+
+	if err := rejectDuplicateParams(ctx.QueryArgs()); err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	pool, err := requireQueryParam(ctx.QueryArgs(), "pool", "pool")
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	block, err := parseBlockParam(ctx.QueryArgs())
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	timings.mark("parse")
+
+	result, err := h.estimateService.ReadPool(reqCtx, estimate.PoolRequest{PoolAddress: pool, Block: block})
+	if err != nil {
+		h.handleError(ctx, requestError(reqCtx, err))
+		return
+	}
+	timings.mark("estimate")
+	ctx.SetUserValue(userValueBlockNumber, result.BlockNumber)
+
+	h.logCompletion(log, "Pool read completed", timings)
+
+	ctx.SetContentType("application/json")
+	json.NewEncoder(ctx).Encode(PoolResponse{
+		Pool:        result.Pool.Hex(),
+		BlockNumber: result.BlockNumber,
+		Token0:      result.Token0.Hex(),
+		Token1:      result.Token1.Hex(),
+		Reserve0:    result.Reserve0.String(),
+		Reserve1:    result.Reserve1.String(),
+	})
+}
+
+// GetPoolTokens handles the /pool/tokens endpoint, returning the pool's token0 and
+// token1 so clients can orient src and dst
+func (h *EstimateHandler) GetPoolTokens(ctx *fasthttp.RequestCtx) {
+	reqCtx, _, cancel := h.requestContext(ctx, nil)
+	defer cancel()
+
+	if err := rejectDuplicateParams(ctx.QueryArgs()); err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	pool, err := requireQueryParam(ctx.QueryArgs(), "pool", "pool")
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+
+	tokens, err := h.estimateService.ReadPoolTokens(reqCtx, pool)
+	if err != nil {
+		h.handleError(ctx, requestError(reqCtx, err))
+		return
+	}
+
+	ctx.SetContentType("application/json")
+	json.NewEncoder(ctx).Encode(PoolTokensResponse{
+		Pool:        tokens.Pool.Hex(),
+		BlockNumber: tokens.BlockNumber,
+		Token0:      tokens.Token0.Hex(),
+		Token1:      tokens.Token1.Hex(),
+	})
+}
+
+// GetRawPoolStorage handles the /pool/raw endpoint, returning the pool's token and
+// reserves storage words as hex, exactly as read from storage
+func (h *EstimateHandler) GetRawPoolStorage(ctx *fasthttp.RequestCtx) {
+	reqCtx, _, cancel := h.requestContext(ctx, nil)
+	defer cancel()
+
+	if err := rejectDuplicateParams(ctx.QueryArgs()); err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	pool, err := requireQueryParam(ctx.QueryArgs(), "pool", "pool")
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+
+	raw, err := h.estimateService.ReadRawPoolStorage(reqCtx, pool)
+	if err != nil {
+		h.handleError(ctx, requestError(reqCtx, err))
+		return
+	}
+
+	ctx.SetContentType("application/json")
+	json.NewEncoder(ctx).Encode(RawPoolStorageResponse{
+		Pool:        raw.Pool.Hex(),
+		BlockNumber: raw.BlockNumber,
+		Token0:      hexutil.Encode(raw.Token0),
+		Token1:      hexutil.Encode(raw.Token1),
+		Reserves:    hexutil.Encode(raw.Reserves),
+	})
+}
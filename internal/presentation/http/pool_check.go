@@ -0,0 +1,77 @@
+package http
+
+import (
+	"encoding/json"
+
+	estimate "bigswapenergy/internal/usecases"
+
+	"github.com/valyala/fasthttp"
+)
+
+type PoolCheckResponse struct {
+	Supported   bool   `json:"supported"`
+	ReserveIn   string `json:"reserve_in,omitempty"`
+	ReserveOut  string `json:"reserve_out,omitempty"`
+	Reason      string `json:"reason,omitempty"`
+	Detail      string `json:"detail,omitempty"`
+	BlockNumber uint64 `json:"block_number"`
+}
+
+// CheckPool handles the /estimate/check endpoint. An unsupported pool is a 200
+// with supported=false and a reason; only bad requests and RPC failures are errors.
+func (h *EstimateHandler) CheckPool(ctx *fasthttp.RequestCtx) {
+	timings := newPhaseTimings()
+	reqCtx, log, cancel := h.requestContext(ctx, timings)
+	defer cancel()
+
+	req, err := parsePoolCheckParams(ctx.QueryArgs())
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	timings.mark("parse")
+
+	check, err := h.estimateService.CheckPool(reqCtx, req)
+	if err != nil {
+		h.handleError(ctx, requestError(reqCtx, err))
+		return
+	}
+	timings.mark("estimate")
+	ctx.SetUserValue(userValueBlockNumber, check.BlockNumber)
+
+	h.logCompletion(log, "Pool check completed", timings)
+
+	resp := PoolCheckResponse{
+		Supported:   check.Supported,
+		Reason:      string(check.Reason),
+		Detail:      check.Detail,
+		BlockNumber: check.BlockNumber,
+	}
+	if check.Supported {
+		resp.ReserveIn = check.ReserveIn.String()
+		resp.ReserveOut = check.ReserveOut.String()
+	}
+
+	ctx.SetContentType("application/json")
+	json.NewEncoder(ctx).Encode(resp)
+}
+
+func parsePoolCheckParams(args *fasthttp.Args) (estimate.PoolCheckRequest, error) {
+	if err := rejectDuplicateParams(args); err != nil {
+		return estimate.PoolCheckRequest{}, err
+	}
+	pool, err := requireQueryParam(args, "pool", "pool")
+	if err != nil {
+		return estimate.PoolCheckRequest{}, err
+	}
+	src, err := requireQueryParam(args, "src", "source token")
+	if err != nil {
+		return estimate.PoolCheckRequest{}, err
+	}
+	dst, err := requireQueryParam(args, "dst", "destination token")
+	if err != nil {
+		return estimate.PoolCheckRequest{}, err
+	}
+
+	return estimate.PoolCheckRequest{PoolAddress: pool, SrcToken: src, DstToken: dst}, nil
+}
@@ -0,0 +1,89 @@
+package http
+
+import (
+	"encoding/json"
+
+	estimate "bigswapenergy/internal/usecases"
+
+	"github.com/valyala/fasthttp"
+)
+
+// priceDecimals is the number of decimal places prices are rendered with. The
+// exact ratio is rounded to the nearest digit, halves away from zero, so with
+// both=true the product of the two prices can differ from 1 in the last few
+// places, and a price below 1e-18 renders as zero.
+const priceDecimals = 18
+
+// PoolPriceResponse carries spot prices in base units as decimal strings
+type PoolPriceResponse struct {
+	Pool        string `json:"pool"`
+	BlockNumber uint64 `json:"block_number"`
+	Token0      string `json:"token0"`
+	Token1      string `json:"token1"`
+	Price1Per0  string `json:"price_1_per_0"`
+	Price0Per1  string `json:"price_0_per_1,omitempty"`
+	Src         string `json:"src,omitempty"`
+	Dst         string `json:"dst,omitempty"`
+	Price       string `json:"price,omitempty"`
+}
+
+// GetPoolPrice handles the /price endpoint. With both=true it also returns the
+// reciprocal price from the same reserves read, and with src and dst the marginal
+// price of src in dst, independent of trade size.
+func (h *EstimateHandler) GetPoolPrice(ctx *fasthttp.RequestCtx) {
+	timings := newPhaseTimings()
+	reqCtx, log, cancel := h.requestContext(ctx, timings)
+	defer cancel()
+
+	req, err := parsePriceParams(ctx.QueryArgs())
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	timings.mark("parse")
+
+	result, err := h.estimateService.ReadPoolPrice(reqCtx, req)
+	if err != nil {
+		h.handleError(ctx, requestError(reqCtx, err))
+		return
+	}
+	timings.mark("estimate")
+	ctx.SetUserValue(userValueBlockNumber, result.BlockNumber)
+
+	h.logCompletion(log, "Pool price completed", timings)
+
+	resp := PoolPriceResponse{
+		Pool:        result.Pool.Hex(),
+		BlockNumber: result.BlockNumber,
+		Token0:      result.Token0.Hex(),
+		Token1:      result.Token1.Hex(),
+		Price1Per0:  result.Price1Per0.FloatString(priceDecimals),
+	}
+	if result.Price0Per1 != nil {
+		resp.Price0Per1 = result.Price0Per1.FloatString(priceDecimals)
+	}
+	if result.Price != nil {
+		resp.Src = result.Src.Hex()
+		resp.Dst = result.Dst.Hex()
+		resp.Price = result.Price.FloatString(priceDecimals)
+	}
+
+	ctx.SetContentType("application/json")
+	json.NewEncoder(ctx).Encode(resp)
+}
+
+func parsePriceParams(args *fasthttp.Args) (estimate.PriceRequest, error) {
+	if err := rejectDuplicateParams(args); err != nil {
+		return estimate.PriceRequest{}, err
+	}
+	pool, err := requireQueryParam(args, "pool", "pool")
+	if err != nil {
+		return estimate.PriceRequest{}, err
+	}
+	return estimate.PriceRequest{
+		PoolAddress: pool,
+		SrcToken:    string(args.Peek("src")),
+		DstToken:    string(args.Peek("dst")),
+		Both:        args.GetBool("both"),
+	}, nil
+}
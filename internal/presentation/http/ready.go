@@ -0,0 +1,94 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"time"
+
+	"bigswapenergy/internal/shared/config"
+	estimate "bigswapenergy/internal/usecases"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// ConnectionHealthChecker reports whether the blockchain connection is usable
+type ConnectionHealthChecker interface {
+	CheckConnectionHealth(ctx context.Context) bool
+}
+
+type ReadinessResponse struct {
+	Status string `json:"status"`
+	Check  string `json:"check"`
+	Error  string `json:"error,omitempty"`
+	// Circuits maps each RPC endpoint to its circuit state, when the connection reports them
+	Circuits map[string]string `json:"circuits,omitempty"`
+}
+
+type ReadinessHandler struct {
+	checker         ConnectionHealthChecker
+	estimateService estimate.EstimateService
+	canary          config.CanaryQuoteConfig
+	canaryAmount    *big.Int
+	timeout         time.Duration
+	logger          *zap.Logger
+}
+
+// NewReadinessHandler creates the /ready handler. The canary quote is only
+// performed when cfg.Canary is enabled; LoadConfig has already validated it.
+func NewReadinessHandler(checker ConnectionHealthChecker, estimateService estimate.EstimateService, cfg config.ReadinessConfig, logger *zap.Logger) *ReadinessHandler {
+	h := &ReadinessHandler{
+		checker:         checker,
+		estimateService: estimateService,
+		canary:          cfg.Canary,
+		timeout:         cfg.Timeout,
+		logger:          logger,
+	}
+	if cfg.Canary.Enabled() {
+		h.canaryAmount, _ = cfg.Canary.ParsedAmount()
+	}
+	return h
+}
+
+// GetReady handles the /ready endpoint. It checks connection health and, when a
+// canary is configured, performs a real quote so storage or chain misconfiguration
+// is caught too.
+func (h *ReadinessHandler) GetReady(ctx *fasthttp.RequestCtx) {
+	checkCtx := context.Context(ctx)
+	if h.timeout > 0 {
+		var cancel context.CancelFunc
+		checkCtx, cancel = context.WithTimeout(ctx, h.timeout)
+		defer cancel()
+	}
+
+	if !h.checker.CheckConnectionHealth(checkCtx) {
+		h.writeReadiness(ctx, fasthttp.StatusServiceUnavailable, ReadinessResponse{Status: "unavailable", Check: "connection"})
+		return
+	}
+
+	if h.canaryAmount == nil {
+		h.writeReadiness(ctx, fasthttp.StatusOK, ReadinessResponse{Status: "ready", Check: "connection"})
+		return
+	}
+
+	if _, err := h.estimateService.EstimateSwapAmount(checkCtx, h.canary.Pool, h.canary.Src, h.canary.Dst, h.canaryAmount); err != nil {
+		h.logger.Warn("Readiness canary quote failed", zap.Error(err))
+		h.writeReadiness(ctx, fasthttp.StatusServiceUnavailable, ReadinessResponse{Status: "unavailable", Check: "canary", Error: err.Error()})
+		return
+	}
+
+	h.writeReadiness(ctx, fasthttp.StatusOK, ReadinessResponse{Status: "ready", Check: "canary"})
+}
+
+func (h *ReadinessHandler) writeReadiness(ctx *fasthttp.RequestCtx, status int, resp ReadinessResponse) {
+	if source, ok := h.checker.(EndpointStatusSource); ok {
+		resp.Circuits = make(map[string]string)
+		for _, endpoint := range source.EndpointStatuses() {
+			resp.Circuits[endpoint.Name] = endpoint.Circuit.State.String()
+		}
+	}
+	ctx.SetContentType("application/json")
+	ctx.SetStatusCode(status)
+	json.NewEncoder(ctx).Encode(resp)
+}
@@ -0,0 +1,79 @@
+package http
+
+import (
+	"encoding/json"
+	"time"
+
+	"bigswapenergy/internal/shared/ringbuffer"
+
+	"github.com/valyala/fasthttp"
+)
+
+// userValueBlockNumber is the RequestCtx user value handlers set to the block a
+// response was computed at, so middleware can record it
+const userValueBlockNumber = "block_number"
+
+// maxRecordedBody bounds how much of each response is kept in the recent buffer
+const maxRecordedBody = 512
+
+type RecentRequest struct {
+	Time        time.Time     `json:"time"`
+	Method      string        `json:"method"`
+	Path        string        `json:"path"`
+	Query       string        `json:"query"`
+	Status      int           `json:"status"`
+	Duration    time.Duration `json:"duration_ns"`
+	BlockNumber uint64        `json:"block_number,omitempty"`
+	Response    string        `json:"response"`
+}
+
+// RecentRequestsMiddleware records every request it wraps into a ring buffer
+type RecentRequestsMiddleware struct {
+	buffer *ringbuffer.Ring[RecentRequest]
+}
+
+func NewRecentRequestsMiddleware(buffer *ringbuffer.Ring[RecentRequest]) *RecentRequestsMiddleware {
+	return &RecentRequestsMiddleware{
+		buffer: buffer,
+	}
+}
+
+func (m *RecentRequestsMiddleware) Apply(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		start := time.Now()
+		next(ctx)
+
+		body := ctx.Response.Body()
+		if len(body) > maxRecordedBody {
+			body = body[:maxRecordedBody]
+		}
+		blockNumber, _ := ctx.UserValue(userValueBlockNumber).(uint64)
+
+		m.buffer.Add(RecentRequest{
+			Time:        start,
+			Method:      string(ctx.Method()),
+			Path:        string(ctx.Path()),
+			Query:       string(ctx.QueryArgs().QueryString()),
+			Status:      ctx.Response.StatusCode(),
+			Duration:    time.Since(start),
+			BlockNumber: blockNumber,
+			Response:    string(body),
+		})
+	}
+}
+
+type RecentRequestsHandler struct {
+	buffer *ringbuffer.Ring[RecentRequest]
+}
+
+func NewRecentRequestsHandler(buffer *ringbuffer.Ring[RecentRequest]) *RecentRequestsHandler {
+	return &RecentRequestsHandler{
+		buffer: buffer,
+	}
+}
+
+// GetRecent handles the /debug/recent endpoint, returning recorded requests newest first
+func (h *RecentRequestsHandler) GetRecent(ctx *fasthttp.RequestCtx) {
+	ctx.SetContentType("application/json")
+	json.NewEncoder(ctx).Encode(h.buffer.Snapshot())
+}
@@ -0,0 +1,66 @@
+package http
+
+import (
+	"bigswapenergy/internal/shared/requestid"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+const headerRequestID = "X-Request-ID"
+
+// requestIDUserValue is the request user value holding the request ID
+const requestIDUserValue = "request_id"
+
+// maxRequestIDLength bounds an incoming X-Request-ID kept as is
+const maxRequestIDLength = 128
+
+// RequestIDMiddleware gives every request an ID, taken from X-Request-ID when the
+// client or a gateway sent a usable one, and echoes it in the response
+type RequestIDMiddleware struct{}
+
+func NewRequestIDMiddleware() *RequestIDMiddleware {
+	return &RequestIDMiddleware{}
+}
+
+func (m *RequestIDMiddleware) Apply(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		id := string(ctx.Request.Header.Peek(headerRequestID))
+		if !validRequestID(id) {
+			id = requestid.New()
+		}
+		ctx.SetUserValue(requestIDUserValue, id)
+		ctx.Response.Header.Set(headerRequestID, id)
+		next(ctx)
+	}
+}
+
+// validRequestID accepts short IDs of URL-safe characters, so a client can't
+// inject arbitrary text into the logs
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// RequestIDFromContext returns the ID RequestIDMiddleware assigned the request, or ""
+func RequestIDFromContext(ctx *fasthttp.RequestCtx) string {
+	id, _ := ctx.UserValue(requestIDUserValue).(string)
+	return id
+}
+
+// withRequestID returns log tagged with the request's ID, if it has one
+func withRequestID(log *zap.Logger, ctx *fasthttp.RequestCtx) *zap.Logger {
+	if id := RequestIDFromContext(ctx); id != "" {
+		return log.With(zap.String("request_id", id))
+	}
+	return log
+}
@@ -0,0 +1,100 @@
+package http
+
+import (
+	"encoding/json"
+
+	estimate "bigswapenergy/internal/usecases"
+
+	"github.com/valyala/fasthttp"
+)
+
+type RouteHopResponse struct {
+	Pool      string `json:"pool"`
+	SrcToken  string `json:"src"`
+	DstToken  string `json:"dst"`
+	AmountIn  string `json:"amount_in"`
+	AmountOut string `json:"amount_out,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+type RouteResponse struct {
+	AmountOut   string             `json:"amount_out,omitempty"`
+	Complete    bool               `json:"complete"`
+	BlockNumber uint64             `json:"block_number"`
+	Hops        []RouteHopResponse `json:"hops"`
+}
+
+// EstimateRoute handles the /estimate/route endpoint, also served as /estimate-path
+func (h *EstimateHandler) EstimateRoute(ctx *fasthttp.RequestCtx) {
+	timings := newPhaseTimings()
+	reqCtx, log, cancel := h.requestContext(ctx, timings)
+	defer cancel()
+
+	req, err := h.parseRouteParams(ctx.QueryArgs())
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	timings.mark("parse")
+
+	result, err := h.estimateService.EstimateRoute(reqCtx, req)
+	if err != nil {
+		h.handleError(ctx, requestError(reqCtx, err))
+		return
+	}
+	timings.mark("estimate")
+	ctx.SetUserValue(userValueBlockNumber, result.BlockNumber)
+
+	h.logCompletion(log, "Route estimate completed", timings)
+
+	resp := RouteResponse{
+		Complete:    result.Complete,
+		BlockNumber: result.BlockNumber,
+		Hops:        make([]RouteHopResponse, len(result.Hops)),
+	}
+	if result.AmountOut != nil {
+		resp.AmountOut = result.AmountOut.String()
+	}
+	for i, hop := range result.Hops {
+		resp.Hops[i] = RouteHopResponse{
+			Pool:     hop.Pool.Hex(),
+			SrcToken: hop.SrcToken.Hex(),
+			DstToken: hop.DstToken.Hex(),
+			AmountIn: hop.AmountIn.String(),
+		}
+		if hop.AmountOut != nil {
+			resp.Hops[i].AmountOut = hop.AmountOut.String()
+		}
+		if hop.Err != nil {
+			resp.Hops[i].Error = hop.Err.Error()
+		}
+	}
+
+	ctx.SetContentType("application/json")
+	json.NewEncoder(ctx).Encode(resp)
+}
+
+func (h *EstimateHandler) parseRouteParams(args *fasthttp.Args) (estimate.RouteRequest, error) {
+	if err := rejectDuplicateParams(args, "pool", "token"); err != nil {
+		return estimate.RouteRequest{}, err
+	}
+	var req estimate.RouteRequest
+	for _, pool := range args.PeekMulti("pool") {
+		req.Pools = append(req.Pools, string(pool))
+	}
+	for _, token := range args.PeekMulti("token") {
+		req.Tokens = append(req.Tokens, string(token))
+	}
+	if err := h.validatePoolCount(len(req.Pools)); err != nil {
+		return estimate.RouteRequest{}, err
+	}
+
+	srcAmount, err := parseSrcAmount(args.Peek("src_amount"))
+	if err != nil {
+		return estimate.RouteRequest{}, err
+	}
+	req.SrcAmount = srcAmount
+	req.Partial = args.GetBool("partial")
+
+	return req, nil
+}
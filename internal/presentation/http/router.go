@@ -0,0 +1,42 @@
+package http
+
+import (
+	"github.com/valyala/fasthttp"
+)
+
+// Router dispatches requests to the handler registered for their exact path
+type Router struct {
+	routes map[string]fasthttp.RequestHandler
+}
+
+// NewRouter creates an empty router
+func NewRouter() *Router {
+	return &Router{
+		routes: make(map[string]fasthttp.RequestHandler),
+	}
+}
+
+// Handle registers handler for path
+func (r *Router) Handle(path string, handler fasthttp.RequestHandler) {
+	r.routes[path] = handler
+}
+
+// HandleFeature registers handler for path only when feature is enabled, so a
+// disabled endpoint answers 404 like any unknown route
+func (r *Router) HandleFeature(flags *FeatureFlags, feature, path string, handler fasthttp.RequestHandler) {
+	if flags.Enabled(feature) {
+		r.Handle(path, handler)
+	}
+}
+
+// Handler is the fasthttp entrypoint that routes the request
+func (r *Router) Handler(ctx *fasthttp.RequestCtx) {
+	handler, ok := r.routes[string(ctx.Path())]
+	if !ok {
+		ctx.SetStatusCode(fasthttp.StatusNotFound)
+		ctx.SetContentType("application/json")
+		ctx.SetBodyString(`{"error":{"code":"NOT_FOUND","message":"Route not found"}}`)
+		return
+	}
+	handler(ctx)
+}
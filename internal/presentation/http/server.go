@@ -0,0 +1,105 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	nethttp "net/http"
+
+	"bigswapenergy/internal/shared/config"
+
+	"github.com/valyala/fasthttp"
+)
+
+// Server is the transport the application handler is served over
+type Server interface {
+	ListenAndServe(addr string) error
+	Serve(ln net.Listener) error
+	ShutdownWithContext(ctx context.Context) error
+}
+
+// NewServer returns a fasthttp server, or a net/http server speaking HTTP/1.1
+// and prior-knowledge h2c when cfg.H2C is set. fasthttp has no HTTP/2 support,
+// so the h2c path adapts the same handler onto net/http.
+func NewServer(handler fasthttp.RequestHandler, cfg config.ServerConfig) Server {
+	if !cfg.H2C {
+		return &fasthttp.Server{Handler: handler}
+	}
+
+	protocols := new(nethttp.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetUnencryptedHTTP2(true)
+	return &h2cServer{
+		server: &nethttp.Server{
+			Handler:   NewNetHTTPHandler(handler),
+			Protocols: protocols,
+		},
+	}
+}
+
+type h2cServer struct {
+	server *nethttp.Server
+}
+
+func (s *h2cServer) ListenAndServe(addr string) error {
+	s.server.Addr = addr
+	if err := s.server.ListenAndServe(); !errors.Is(err, nethttp.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (s *h2cServer) Serve(ln net.Listener) error {
+	if err := s.server.Serve(ln); !errors.Is(err, nethttp.ErrServerClosed) && !errors.Is(err, net.ErrClosed) {
+		return err
+	}
+	return nil
+}
+
+func (s *h2cServer) ShutdownWithContext(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
+
+// hopByHopHeaders must not be copied onto a net/http response; HTTP/2 forbids them
+var hopByHopHeaders = map[string]bool{
+	fasthttp.HeaderConnection:       true,
+	fasthttp.HeaderContentLength:    true,
+	fasthttp.HeaderTransferEncoding: true,
+}
+
+// NewNetHTTPHandler adapts a fasthttp handler to net/http by copying the request
+// into a RequestCtx and the resulting response back out
+func NewNetHTTPHandler(handler fasthttp.RequestHandler) nethttp.Handler {
+	return nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		var req fasthttp.Request
+		req.Header.SetMethod(r.Method)
+		req.SetRequestURI(r.URL.RequestURI())
+		req.Header.SetHost(r.Host)
+		for name, values := range r.Header {
+			for _, value := range values {
+				req.Header.Add(name, value)
+			}
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			nethttp.Error(w, "failed to read request body", nethttp.StatusBadRequest)
+			return
+		}
+		req.SetBody(body)
+
+		remoteAddr, _ := net.ResolveTCPAddr("tcp", r.RemoteAddr)
+
+		var ctx fasthttp.RequestCtx
+		ctx.Init(&req, remoteAddr, nil)
+		handler(&ctx)
+
+		ctx.Response.Header.VisitAll(func(name, value []byte) {
+			if !hopByHopHeaders[string(name)] {
+				w.Header().Add(string(name), string(value))
+			}
+		})
+		w.WriteHeader(ctx.Response.StatusCode())
+		w.Write(ctx.Response.Body())
+	})
+}
@@ -0,0 +1,82 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+
+	apperrors "bigswapenergy/internal/shared/errors"
+	"bigswapenergy/internal/shared/quotesig"
+	estimate "bigswapenergy/internal/usecases"
+
+	"github.com/valyala/fasthttp"
+)
+
+// SignedQuoteResponse carries every field covered by Signature, so a verifier can
+// rebuild the canonical message described in package quotesig
+type SignedQuoteResponse struct {
+	Pool        string `json:"pool"`
+	Src         string `json:"src"`
+	Dst         string `json:"dst"`
+	AmountIn    string `json:"amount_in"`
+	AmountOut   string `json:"amount_out"`
+	BlockNumber uint64 `json:"block_number"`
+	Signature   string `json:"signature"`
+	// The remaining fields are informational and not covered by Signature
+	QuoteID          string `json:"quote_id,omitempty"`
+	ReserveAgeBlocks uint64 `json:"reserve_age_blocks,omitempty"`
+	EffectiveFee     *int   `json:"effective_fee_bps,omitempty"`
+	FeeSource        string `json:"fee_source,omitempty"`
+}
+
+// parseSign reports whether sign=true was requested, rejecting it when no secret
+// is configured or the request doesn't produce a single quote
+func (h *EstimateHandler) parseSign(ctx *fasthttp.RequestCtx, req estimate.EstimateRequest) (bool, error) {
+	if !ctx.QueryArgs().GetBool("sign") {
+		return false, nil
+	}
+	if h.config.Server.QuoteSigningSecret == "" {
+		return false, fmt.Errorf("%w: quote signing is not configured", apperrors.ErrValidation)
+	}
+	if len(req.SrcAmounts) > 1 || req.ItemStatus || req.ShowMath || len(req.FeeTiers) > 0 {
+		return false, fmt.Errorf("%w: sign is only supported for a single quote", apperrors.ErrValidation)
+	}
+	if req.HumanAmounts {
+		return false, fmt.Errorf("%w: a signed quote is always in base units", apperrors.ErrValidation)
+	}
+	if req.PriceImpact {
+		return false, fmt.Errorf("%w: sign does not support price_impact", apperrors.ErrValidation)
+	}
+	return true, nil
+}
+
+// writeSignedQuote writes a sign=true response for an exact-in or exact-out quote
+func (h *EstimateHandler) writeSignedQuote(ctx *fasthttp.RequestCtx, req estimate.EstimateRequest, result *estimate.EstimateResult) {
+	quote := quotesig.Quote{
+		Pool:        result.PoolAddress,
+		Src:         req.SrcToken,
+		Dst:         req.DstToken,
+		AmountIn:    req.SrcAmount,
+		AmountOut:   result.AmountOut,
+		BlockNumber: result.BlockNumber,
+	}
+	if req.DstAmount != nil {
+		quote.AmountIn, quote.AmountOut = result.AmountIn, req.DstAmount
+	}
+
+	resp := SignedQuoteResponse{
+		Pool:             quote.Pool,
+		Src:              quote.Src,
+		Dst:              quote.Dst,
+		AmountIn:         quote.AmountIn.String(),
+		AmountOut:        quote.AmountOut.String(),
+		BlockNumber:      quote.BlockNumber,
+		Signature:        quotesig.Sign([]byte(h.config.Server.QuoteSigningSecret), quote),
+		QuoteID:          result.QuoteID,
+		ReserveAgeBlocks: result.ReserveAgeBlocks,
+		EffectiveFee:     effectiveFee(result),
+		FeeSource:        string(result.FeeSource),
+	}
+
+	ctx.SetContentType("application/json")
+	json.NewEncoder(ctx).Encode(resp)
+}
@@ -0,0 +1,82 @@
+package http
+
+import (
+	"encoding/json"
+
+	"bigswapenergy/internal/infrastructure/uniswap_v2"
+	"bigswapenergy/internal/shared/metrics"
+	"bigswapenergy/internal/shared/utils"
+
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	MetricUnmappedErrors  = "errors_unmapped_total"
+	MetricPanicsRecovered = "panics_recovered_total"
+	metricRequestErrors   = "request_errors_total."
+
+	MetricLatencySLOBreaches        = "latency_slo_breaches_total"
+	metricLatencySLOBreachesByPhase = "latency_slo_breaches_total."
+
+	MetricRateLimitClients   = "rate_limit_clients"
+	MetricRateLimitEvictions = "rate_limit_clients_evicted_total"
+
+	MetricBigIntPoolGets          = "bigint_pool_gets_total"
+	MetricBigIntPoolPuts          = "bigint_pool_puts_total"
+	MetricBigIntPoolOutstanding   = "bigint_pool_outstanding"
+	MetricBigIntPoolAllocsAvoided = "bigint_pool_allocs_avoided_total"
+
+	MetricReservesCacheHits    = "reserves_cache_hits_total"
+	MetricReservesCacheMisses  = "reserves_cache_misses_total"
+	MetricReservesCacheEntries = "reserves_cache_entries"
+
+	// Suffixed with the endpoint name; the state is 0 closed, 1 open, 2 half-open
+	metricRPCCircuitState = "rpc_circuit_state."
+	metricRPCCircuitOpens = "rpc_circuit_opens_total."
+)
+
+type StatsHandler struct {
+	registry *metrics.Registry
+}
+
+func NewStatsHandler(registry *metrics.Registry) *StatsHandler {
+	return &StatsHandler{
+		registry: registry,
+	}
+}
+
+// GetStats handles the /stats endpoint, returning every registered metric as JSON
+func (h *StatsHandler) GetStats(ctx *fasthttp.RequestCtx) {
+	ctx.SetContentType("application/json")
+	json.NewEncoder(ctx).Encode(h.registry.Snapshot())
+}
+
+// RegisterBigIntPoolMetrics exposes pool's Get/Put balance and avoided
+// allocations as gauges on registry
+func RegisterBigIntPoolMetrics(registry *metrics.Registry, pool *utils.BigIntPool) {
+	registry.Gauge(MetricBigIntPoolGets, func() int64 { return int64(pool.Stats().Gets) })
+	registry.Gauge(MetricBigIntPoolPuts, func() int64 { return int64(pool.Stats().Puts) })
+	registry.Gauge(MetricBigIntPoolOutstanding, func() int64 { return pool.Stats().Outstanding() })
+	registry.Gauge(MetricBigIntPoolAllocsAvoided, func() int64 { return int64(pool.Stats().AllocsAvoided()) })
+}
+
+// RegisterReservesCacheMetrics exposes the block-keyed reserves cache's hits,
+// misses and size as gauges on registry
+func RegisterReservesCacheMetrics(registry *metrics.Registry, cache *uniswap_v2.ReservesCachingUniswapV2Client) {
+	registry.Gauge(MetricReservesCacheHits, func() int64 { return int64(cache.Stats().Hits) })
+	registry.Gauge(MetricReservesCacheMisses, func() int64 { return int64(cache.Stats().Misses) })
+	registry.Gauge(MetricReservesCacheEntries, func() int64 { return int64(cache.Stats().Entries) })
+}
+
+// RegisterCircuitMetrics exposes each RPC endpoint's circuit state and open count
+// as gauges on registry
+func RegisterCircuitMetrics(registry *metrics.Registry, source EndpointStatusSource) {
+	for i, status := range source.EndpointStatuses() {
+		registry.Gauge(metricRPCCircuitState+status.Name, func() int64 {
+			return int64(source.EndpointStatuses()[i].Circuit.State)
+		})
+		registry.Gauge(metricRPCCircuitOpens+status.Name, func() int64 {
+			return int64(source.EndpointStatuses()[i].Circuit.Opens)
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package http
+
+import (
+	estimate "bigswapenergy/internal/usecases"
+
+	"github.com/valyala/fasthttp"
+)
+
+// addressParams are the query parameters that carry addresses on any endpoint
+var addressParams = []string{"pool", "src", "dst", "token"}
+
+// StrictAddresses wraps next so every address query parameter must be EIP-55
+// checksummed. It is applied when strict_addresses is enabled, which also has
+// batch items checked as they are quoted.
+func (h *EstimateHandler) StrictAddresses(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		for _, param := range addressParams {
+			for _, value := range ctx.QueryArgs().PeekMulti(param) {
+				if err := estimate.ValidateChecksumAddress(param, string(value)); err != nil {
+					h.handleError(ctx, err)
+					return
+				}
+			}
+		}
+		next(ctx)
+	}
+}
+
+// checkBatchItemAddresses applies strict_addresses to a batch item's addresses
+func (h *EstimateHandler) checkBatchItemAddresses(item BatchEstimateItem) error {
+	if !h.config.Server.StrictAddresses {
+		return nil
+	}
+	for _, address := range []struct{ param, value string }{
+		{"pool", item.Pool},
+		{"src", item.SrcToken},
+		{"dst", item.DstToken},
+	} {
+		if err := estimate.ValidateChecksumAddress(address.param, address.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	apperrors "bigswapenergy/internal/shared/errors"
+	"bigswapenergy/internal/shared/logger"
+	"bigswapenergy/internal/shared/metrics"
+	"bigswapenergy/internal/shared/requestid"
+	"bigswapenergy/internal/shared/rpcbudget"
+	"bigswapenergy/internal/shared/timing"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// headerTimeoutMs reports the server-side request timeout to clients
+const headerTimeoutMs = "X-Timeout-Ms"
+
+// phaseCompute is the part of the estimate phase not spent in service-recorded RPC spans
+const phaseCompute = "compute"
+
+// breakdownOrder lists the phases of a breakdown in request order
+var breakdownOrder = []string{"parse", timing.PhaseBlock, timing.PhaseTokens, timing.PhaseReserves, phaseCompute}
+
+// phaseTimings records how long each named phase of a request took, in order,
+// plus the RPC spans recorded by the service layer during the estimate phase
+type phaseTimings struct {
+	start     time.Time
+	last      time.Time
+	phases    []zap.Field
+	durations map[string]time.Duration
+	spans     *timing.Spans
+}
+
+func newPhaseTimings() *phaseTimings {
+	now := time.Now()
+	return &phaseTimings{
+		start:     now,
+		last:      now,
+		durations: make(map[string]time.Duration),
+		spans:     timing.NewSpans(),
+	}
+}
+
+// mark closes the current phase under name and starts the next one
+func (p *phaseTimings) mark(name string) {
+	now := time.Now()
+	p.phases = append(p.phases, zap.Duration(name, now.Sub(p.last)))
+	p.durations[name] = now.Sub(p.last)
+	p.last = now
+}
+
+// breakdown splits the request into parse, the RPC spans, and the compute left over
+// from the estimate phase
+func (p *phaseTimings) breakdown() map[string]time.Duration {
+	phases := map[string]time.Duration{"parse": p.durations["parse"]}
+	compute := p.durations["estimate"]
+	for _, phase := range []string{timing.PhaseBlock, timing.PhaseTokens, timing.PhaseReserves} {
+		phases[phase] = p.spans.Total(phase)
+		compute -= phases[phase]
+	}
+	phases[phaseCompute] = max(compute, 0)
+	return phases
+}
+
+// dominantPhase returns the phase that consumed the most time
+func (p *phaseTimings) dominantPhase() (string, time.Duration) {
+	var (
+		name    string
+		longest time.Duration = -1
+	)
+	breakdown := p.breakdown()
+	for _, phase := range breakdownOrder {
+		if d := breakdown[phase]; d > longest {
+			name, longest = phase, d
+		}
+	}
+	return name, longest
+}
+
+// total returns the time elapsed since the request started
+func (p *phaseTimings) total() time.Duration {
+	return time.Since(p.start)
+}
+
+// logCompletion logs msg at Info, or at Warn with per-phase timings when the
+// request exceeded the configured slow request threshold
+func (h *EstimateHandler) logCompletion(log *zap.Logger, msg string, timings *phaseTimings) {
+	duration := timings.total()
+	metrics.GlobalExporter.EstimateDuration.Observe(duration.Seconds())
+	h.checkLatencySLO(log, duration, timings)
+
+	threshold := h.config.Server.SlowRequestThreshold
+	if threshold > 0 && duration > threshold {
+		log.Warn(msg+" slowly",
+			zap.Duration("duration", duration),
+			zap.Duration("threshold", threshold),
+			zap.Dict("phases", timings.phases...),
+		)
+		return
+	}
+
+	log.Info(msg, zap.Duration("duration", duration))
+	log.Debug("Request phase timings", zap.Dict("phases", timings.phases...))
+}
+
+// checkLatencySLO counts a breach of the configured latency SLO, tagged by the phase
+// that consumed the most time, and optionally logs the full breakdown at Warn
+func (h *EstimateHandler) checkLatencySLO(log *zap.Logger, duration time.Duration, timings *phaseTimings) {
+	slo := h.config.Server.LatencySLO
+	if slo <= 0 || duration <= slo {
+		return
+	}
+
+	phase, phaseDuration := timings.dominantPhase()
+	metrics.Global.Counter(MetricLatencySLOBreaches).Inc()
+	metrics.Global.Counter(metricLatencySLOBreachesByPhase + phase).Inc()
+
+	if !h.config.Server.LatencySLOLog {
+		return
+	}
+	breakdown := timings.breakdown()
+	fields := make([]zap.Field, 0, len(breakdown))
+	for _, name := range breakdownOrder {
+		fields = append(fields, zap.Duration(name, breakdown[name]))
+	}
+	log.Warn("Latency SLO exceeded",
+		zap.Duration("duration", duration),
+		zap.Duration("slo", slo),
+		zap.String("phase", phase),
+		zap.Duration("phase_duration", phaseDuration),
+		zap.Dict("phases", fields...),
+	)
+}
+
+// requestContext builds the context for the service call. It carries the configured
+// request timeout as a deadline, which is also reported via X-Timeout-Ms, the RPC
+// call budget, the request ID with a logger tagged by it, and a
+// debug-enabled logger when the request is sampled for verbose tracing. When timings
+// is set, the service records its RPC spans into it. The returned cancel func must
+// be called once the request is served.
+func (h *EstimateHandler) requestContext(ctx *fasthttp.RequestCtx, timings *phaseTimings) (context.Context, *zap.Logger, context.CancelFunc) {
+	var reqCtx context.Context = ctx
+	if timings != nil {
+		reqCtx = timing.WithSpans(reqCtx, timings.spans)
+	}
+	cancel := context.CancelFunc(func() {})
+
+	if timeout := h.config.Server.RequestTimeout; timeout > 0 {
+		reqCtx, cancel = context.WithTimeout(reqCtx, timeout)
+		ctx.Response.Header.Set(headerTimeoutMs, strconv.FormatInt(timeout.Milliseconds(), 10))
+	}
+	if limit := h.config.Server.MaxRPCCallsPerRequest; limit > 0 {
+		reqCtx = rpcbudget.WithBudget(reqCtx, rpcbudget.New(limit))
+	}
+
+	log := h.logger
+	if id := RequestIDFromContext(ctx); id != "" {
+		log = withRequestID(log, ctx)
+		reqCtx = logger.WithContext(requestid.WithID(reqCtx, id), log)
+	}
+
+	if !logger.Sampled(ctx.ID(), h.config.Logging.TraceSampleRate) {
+		return reqCtx, log, cancel
+	}
+
+	traceLogger := logger.WithDebug(log).With(zap.Uint64("trace_id", ctx.ID()))
+	traceLogger.Debug("Tracing request",
+		zap.ByteString("path", ctx.Path()),
+		zap.ByteString("query", ctx.QueryArgs().QueryString()),
+	)
+	return logger.WithContext(reqCtx, traceLogger), traceLogger, cancel
+}
+
+// requestError reports a service failure caused by the request timeout as ErrTimeout
+// and one caused by an exhausted RPC budget as ErrBusinessRule, since the service
+// wraps the underlying RPC error as an external service failure
+func requestError(reqCtx context.Context, err error) error {
+	if errors.Is(reqCtx.Err(), context.DeadlineExceeded) {
+		return apperrors.ErrTimeout
+	}
+	if budget := rpcbudget.FromContext(reqCtx); budget != nil && budget.Exceeded() {
+		return apperrors.ErrBusinessRule
+	}
+	return err
+}
@@ -0,0 +1,77 @@
+package http
+
+import (
+	"encoding/json"
+
+	estimate "bigswapenergy/internal/usecases"
+
+	"github.com/valyala/fasthttp"
+)
+
+type TwoWayQuoteResponse struct {
+	// Sell is the quote token received for amount of base
+	Sell string `json:"sell"`
+	// Buy is the base token received for amount of quote
+	Buy         string `json:"buy"`
+	BlockNumber uint64 `json:"block_number"`
+}
+
+// EstimateTwoWayQuote handles the /estimate/quote endpoint
+func (h *EstimateHandler) EstimateTwoWayQuote(ctx *fasthttp.RequestCtx) {
+	timings := newPhaseTimings()
+	reqCtx, log, cancel := h.requestContext(ctx, timings)
+	defer cancel()
+
+	req, err := parseTwoWayQuoteParams(ctx.QueryArgs())
+	if err != nil {
+		h.handleError(ctx, err)
+		return
+	}
+	timings.mark("parse")
+
+	result, err := h.estimateService.EstimateTwoWayQuote(reqCtx, req)
+	if err != nil {
+		h.handleError(ctx, requestError(reqCtx, err))
+		return
+	}
+	timings.mark("estimate")
+	ctx.SetUserValue(userValueBlockNumber, result.BlockNumber)
+
+	h.logCompletion(log, "Two-way quote completed", timings)
+
+	ctx.SetContentType("application/json")
+	json.NewEncoder(ctx).Encode(TwoWayQuoteResponse{
+		Sell:        result.Sell.String(),
+		Buy:         result.Buy.String(),
+		BlockNumber: result.BlockNumber,
+	})
+}
+
+func parseTwoWayQuoteParams(args *fasthttp.Args) (estimate.TwoWayQuoteRequest, error) {
+	if err := rejectDuplicateParams(args); err != nil {
+		return estimate.TwoWayQuoteRequest{}, err
+	}
+	pool, err := requireQueryParam(args, "pool", "pool")
+	if err != nil {
+		return estimate.TwoWayQuoteRequest{}, err
+	}
+	base, err := requireQueryParam(args, "base", "base token")
+	if err != nil {
+		return estimate.TwoWayQuoteRequest{}, err
+	}
+	quote, err := requireQueryParam(args, "quote", "quote token")
+	if err != nil {
+		return estimate.TwoWayQuoteRequest{}, err
+	}
+	amount, err := parseSrcAmount(args.Peek("amount"))
+	if err != nil {
+		return estimate.TwoWayQuoteRequest{}, err
+	}
+
+	return estimate.TwoWayQuoteRequest{
+		PoolAddress: pool,
+		BaseToken:   base,
+		QuoteToken:  quote,
+		Amount:      amount,
+	}, nil
+}
@@ -0,0 +1,183 @@
+// Package circuit implements an error-rate and consecutive-failure circuit
+// breaker for an upstream endpoint.
+package circuit
+
+import (
+	"sync"
+	"time"
+
+	"bigswapenergy/internal/shared/clock"
+	"bigswapenergy/internal/shared/config"
+)
+
+// State is a breaker's position
+type State int
+
+const (
+	// Closed lets every call through
+	Closed State = iota
+	// Open refuses calls until the cooldown has passed
+	Open
+	// HalfOpen lets a single probe call through to test recovery
+	HalfOpen
+)
+
+// String returns the state's name as shown by /debug/pool
+func (s State) String() string {
+	switch s {
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half_open"
+	default:
+		return "closed"
+	}
+}
+
+// Snapshot is a point-in-time view of a breaker
+type Snapshot struct {
+	State State
+	// Requests and Failures count calls in the current window
+	Requests int
+	Failures int
+	// ConsecutiveFailures counts failures since the last success
+	ConsecutiveFailures int
+	// Opens counts how many times the circuit has opened
+	Opens uint64
+	// OpenedAt is when the circuit last opened; zero if it never has
+	OpenedAt time.Time
+}
+
+// Breaker tracks one endpoint's outcomes over fixed windows, and its run of
+// consecutive failures. Safe for concurrent use.
+type Breaker struct {
+	cfg   config.CircuitConfig
+	clock clock.Clock
+
+	mu          sync.Mutex
+	state       State
+	windowStart time.Time
+	requests    int
+	failures    int
+	consecutive int
+	opens       uint64
+	openedAt    time.Time
+	probing     bool
+}
+
+// NewBreaker creates a closed breaker
+func NewBreaker(cfg config.CircuitConfig, clk clock.Clock) *Breaker {
+	return &Breaker{cfg: cfg, clock: clk, windowStart: clk.Now()}
+}
+
+// Allow reports whether a call may go to the endpoint. Once the cooldown has
+// passed, the first caller becomes the half-open probe and must report back
+// through Record or Release.
+func (b *Breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case Open:
+		if b.clock.Since(b.openedAt) < b.cfg.Cooldown {
+			return false
+		}
+		b.state = HalfOpen
+		b.probing = true
+		return true
+	case HalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// Record reports a call's outcome and returns true when it opened the circuit
+func (b *Breaker) Record(success bool) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case HalfOpen:
+		b.probing = false
+		if success {
+			b.state = Closed
+			b.resetWindow()
+			return false
+		}
+		b.open()
+		return true
+	case Open:
+		// A call allowed before the circuit opened; its outcome no longer matters
+		return false
+	}
+
+	if b.clock.Since(b.windowStart) >= b.cfg.Window {
+		b.resetWindow()
+	}
+	b.requests++
+	if success {
+		b.consecutive = 0
+	} else {
+		b.failures++
+		b.consecutive++
+	}
+	if b.cfg.ConsecutiveFailures > 0 && b.consecutive >= b.cfg.ConsecutiveFailures {
+		b.open()
+		return true
+	}
+	if b.requests >= b.cfg.MinRequests && float64(b.failures) >= b.cfg.ErrorRate*float64(b.requests) {
+		b.open()
+		return true
+	}
+	return false
+}
+
+// Release gives up a call without an outcome, e.g. one cancelled by its caller,
+// so a half-open breaker can let another probe through
+func (b *Breaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == HalfOpen {
+		b.probing = false
+	}
+}
+
+// State returns the current state without moving an expired open circuit to half-open
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Snapshot returns the breaker's current state and counts
+func (b *Breaker) Snapshot() Snapshot {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return Snapshot{
+		State:               b.state,
+		Requests:            b.requests,
+		Failures:            b.failures,
+		ConsecutiveFailures: b.consecutive,
+		Opens:               b.opens,
+		OpenedAt:            b.openedAt,
+	}
+}
+
+func (b *Breaker) open() {
+	b.state = Open
+	b.openedAt = b.clock.Now()
+	b.opens++
+	b.consecutive = 0
+	b.resetWindow()
+}
+
+func (b *Breaker) resetWindow() {
+	b.windowStart = b.clock.Now()
+	b.requests = 0
+	b.failures = 0
+}
@@ -1,30 +1,264 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"math/big"
+	"net/netip"
 	"os"
+	"strings"
 	"time"
 
+	apperrors "bigswapenergy/internal/shared/errors"
+
 	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
 	Server     ServerConfig
 	Blockchain BlockchainConfig
-	RateLimit  RateLimitConfig
+	RateLimit  RateLimitConfig `yaml:"rate_limit"`
+	CORS       CORSConfig      `yaml:"cors"`
+	Factories  map[string]FactoryConfig
+	Cache      CacheConfig
+	Logging    LoggingConfig
+	Readiness  ReadinessConfig
+	Debug      DebugConfig
+
+	// Features toggles optional endpoints by name; unset features are enabled
+	Features map[string]bool `yaml:"features"`
+
+	// AssumedFeeTiers are the fees in basis points (30 = 0.3%) quoted by fee_tiers=true
+	// when a pool's fee is not known
+	AssumedFeeTiers []int `yaml:"assumed_fee_tiers"`
+
+	ReserveGuard ReserveGuardConfig `yaml:"reserve_guard"`
 }
 
 type ServerConfig struct {
 	Address         string        `yaml:"address"`
 	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+
+	// SlowRequestThreshold logs requests taking longer than this at Warn; 0 disables it
+	SlowRequestThreshold time.Duration `yaml:"slow_request_threshold"`
+
+	// LatencySLO counts requests slower than this under latency_slo_breaches_total,
+	// tagged by the phase that took longest; 0 disables it
+	LatencySLO time.Duration `yaml:"latency_slo"`
+	// LatencySLOLog also logs each SLO breach at Warn with its phase breakdown
+	LatencySLOLog bool `yaml:"latency_slo_log"`
+
+	// MaxPoolsPerRequest caps how many pools a multi-pool endpoint may read; 0 disables the cap
+	MaxPoolsPerRequest int `yaml:"max_pools_per_request"`
+
+	// BatchWorkers bounds how many items of an /estimate/batch request are quoted at once
+	BatchWorkers int `yaml:"batch_workers"`
+
+	// RequestTimeout bounds every RPC call made while serving a request; 0 disables it
+	RequestTimeout time.Duration `yaml:"request_timeout"`
+
+	// MaxRPCCallsPerRequest caps the RPC calls a single request may issue; 0 disables it
+	MaxRPCCallsPerRequest int `yaml:"max_rpc_calls_per_request"`
+
+	// TrustedReserves exposes /estimate/local, which quotes against caller-supplied
+	// reserves without RPC. Only enable it for internal deployments.
+	TrustedReserves bool `yaml:"trusted_reserves"`
+
+	// QuoteSigningSecret keys the HMAC returned by /estimate?sign=true. It is read
+	// from QUOTE_SIGNING_SECRET only; signing is unavailable when it is empty.
+	QuoteSigningSecret string `yaml:"-"`
+
+	// APIKeys are the keys accepted in X-API-Key. They are read from API_KEYS,
+	// comma-separated, only; requests are unauthenticated when it is empty.
+	APIKeys []string `yaml:"-"`
+
+	// StrictAddresses rejects addresses that aren't in their EIP-55 checksummed
+	// form, instead of accepting any 40 hex digits
+	StrictAddresses bool `yaml:"strict_addresses"`
+
+	// HealthCheckPeriod is how often the RPC connections are checked in the
+	// background, logging each unhealthy one
+	HealthCheckPeriod time.Duration `yaml:"health_check_period"`
+
+	// H2C serves HTTP/1.1 and prior-knowledge cleartext HTTP/2 via net/http instead of fasthttp
+	H2C bool `yaml:"h2c"`
+
+	// MetricsAddress serves Prometheus metrics on /metrics, apart from the API
+	// so it needn't be exposed publicly; empty disables it
+	MetricsAddress string `yaml:"metrics_address"`
 }
 
 type BlockchainConfig struct {
 	EthereumRPCURL string `yaml:"ethereum_rpc_url"`
+
+	// DetectReservesSlot probes each pool once for its reserves slot, for forks
+	// that don't keep reserves at slot 8
+	DetectReservesSlot bool `yaml:"detect_reserves_slot"`
+	// MaxProbeSlot is the highest slot tried when detecting the reserves slot
+	MaxProbeSlot uint64 `yaml:"max_probe_slot"`
+
+	// EmptyReservesRetry re-reads a pool whose reserves are empty at head once,
+	// after this delay and at the newest head, since a pool created in the latest
+	// block can briefly read as empty on a lagging node. 0 disables it so
+	// genuinely empty pools fail fast.
+	EmptyReservesRetry time.Duration `yaml:"empty_reserves_retry"`
+
+	// MonotonicHeadWindow keeps the reported head from moving backwards for this
+	// long after a higher head was seen, smoothing over load-balanced RPC backends
+	// that lag each other. 0 disables it.
+	MonotonicHeadWindow time.Duration `yaml:"monotonic_head_window"`
+
+	// PoolSize is the number of connections opened to each RPC endpoint, with
+	// calls spread round-robin across them
+	PoolSize int `yaml:"pool_size"`
+
+	// FallbackRPCURLs are tried in order when the primary endpoint fails or its
+	// circuit is open. Read from ETHEREUM_FALLBACK_RPC_URLS (comma-separated),
+	// since RPC URLs usually embed API keys.
+	FallbackRPCURLs []string `yaml:"-"`
+	// Circuit stops routing to an endpoint after sustained errors, failing fast
+	// while every endpoint's circuit is open
+	Circuit CircuitConfig `yaml:"circuit"`
+	// Retry re-sends RPC calls that failed to reach the node or timed out
+	Retry RetryConfig `yaml:"retry"`
+}
+
+// RetryConfig retries a failed RPC call up to MaxAttempts times in all, waiting
+// BaseDelay before the first retry and doubling it for each one after. Jitter
+// varies each wait by up to that fraction. MaxAttempts of 1 disables retries.
+type RetryConfig struct {
+	MaxAttempts int           `yaml:"max_attempts"`
+	BaseDelay   time.Duration `yaml:"base_delay"`
+	Jitter      float64       `yaml:"jitter"`
+}
+
+// CircuitConfig opens an endpoint's circuit once at least MinRequests calls in a
+// Window have failed at ErrorRate or more, or ConsecutiveFailures calls in a row
+// have failed. After Cooldown a single probe call is let through; its success
+// closes the circuit and its failure reopens it.
+type CircuitConfig struct {
+	ErrorRate   float64       `yaml:"error_rate"`
+	Window      time.Duration `yaml:"window"`
+	MinRequests int           `yaml:"min_requests"`
+	Cooldown    time.Duration `yaml:"cooldown"`
+	// ConsecutiveFailures of 0 only opens the circuit on the error rate
+	ConsecutiveFailures int `yaml:"consecutive_failures"`
 }
 
 type RateLimitConfig struct {
 	RequestsPerMinute int `yaml:"requests_per_minute"`
+	// Burst is how many requests a client may make at once before being held to
+	// the steady rate. 0 allows a full minute's worth.
+	Burst int `yaml:"burst"`
+	// MaxClients caps the per-IP state kept in memory; the least recently seen
+	// clients are evicted past it. 0 disables the cap.
+	MaxClients int `yaml:"max_clients"`
+	// CleanupInterval is how often clients idle long enough to refill are dropped
+	CleanupInterval time.Duration `yaml:"cleanup_interval"`
+	// TrustedProxies are CIDRs of reverse proxies whose X-Forwarded-For header is
+	// honored. Empty ignores the header and limits by peer address.
+	TrustedProxies []netip.Prefix `yaml:"trusted_proxies"`
+}
+
+type CORSConfig struct {
+	// AllowedOrigins are the browser origins allowed to call the API, or "*" for
+	// any (development only). Empty disables CORS.
+	AllowedOrigins []string `yaml:"allowed_origins"`
+	AllowedMethods []string `yaml:"allowed_methods"`
+	AllowedHeaders []string `yaml:"allowed_headers"`
+}
+
+type CacheConfig struct {
+	TokenTTL time.Duration `yaml:"token_ttl"`
+	// TTLJitter randomises each entry's TTL within ±TTLJitter (a fraction of
+	// TokenTTL) so entries loaded together expire at different times
+	TTLJitter float64 `yaml:"ttl_jitter"`
+	// SingleFlight shares one RPC read between concurrent token cache misses for the
+	// same pool, and one reserves read between concurrent quotes of a pool at a block
+	SingleFlight bool `yaml:"single_flight"`
+	// ReservesMaxAgeBlocks serves a pool's reserves for up to this many blocks after
+	// they were read, trading freshness for RPC calls. 0 always reads fresh reserves.
+	ReservesMaxAgeBlocks uint64 `yaml:"reserves_max_age_blocks"`
+	// ReservesTTL caches reserves read at a given block for this long, keyed by pool
+	// and block, so concurrent quotes share one read; 0 disables it
+	ReservesTTL time.Duration `yaml:"reserves_ttl"`
+	// ReservesMaxEntries bounds the ReservesTTL cache, evicting least recently used pools
+	ReservesMaxEntries int `yaml:"reserves_max_entries"`
+}
+
+type LoggingConfig struct {
+	// TraceSampleRate is the fraction of requests (0.0-1.0) logged verbosely at Debug
+	TraceSampleRate float64 `yaml:"trace_sample_rate"`
+	// RPCCalls logs every RPC call with its method, target, block and latency at Debug
+	RPCCalls bool `yaml:"rpc_calls"`
+}
+
+type ReadinessConfig struct {
+	Timeout time.Duration     `yaml:"timeout"`
+	Canary  CanaryQuoteConfig `yaml:"canary"`
+}
+
+// CanaryQuoteConfig names a known-good quote that /ready performs end to end.
+// Amount is a decimal string so it can exceed int64.
+type CanaryQuoteConfig struct {
+	Pool   string `yaml:"pool"`
+	Src    string `yaml:"src"`
+	Dst    string `yaml:"dst"`
+	Amount string `yaml:"amount"`
+}
+
+// Enabled reports whether a canary quote is configured
+func (c CanaryQuoteConfig) Enabled() bool {
+	return c.Pool != ""
+}
+
+// ParsedAmount returns Amount as a big.Int, or false if it is not a positive integer
+func (c CanaryQuoteConfig) ParsedAmount() (*big.Int, bool) {
+	amount, ok := new(big.Int).SetString(c.Amount, 10)
+	if !ok || amount.Sign() <= 0 {
+		return nil, false
+	}
+	return amount, true
+}
+
+// Reserve guard actions
+const (
+	ReserveGuardReject = "reject"
+	ReserveGuardWarn   = "warn"
+)
+
+type ReserveGuardConfig struct {
+	// MaxRatio flags pools whose larger reserve exceeds the smaller one by more
+	// than this factor, a sign of a scam or near-drained pool; 0 disables the guard
+	MaxRatio uint64 `yaml:"max_ratio"`
+	// Action is "reject" to fail the quote or "warn" to return it with a warning
+	Action string `yaml:"action"`
+	// MaxStaleness warns when a pool's reserves were last updated longer than
+	// this before the quote, judged by the pair's blockTimestampLast; 0 disables it
+	MaxStaleness time.Duration `yaml:"max_staleness"`
+}
+
+type DebugConfig struct {
+	// Enabled exposes debug-only endpoints such as /debug/recent
+	Enabled bool `yaml:"enabled"`
+	// RecentRequests is how many recent requests /debug/recent keeps
+	RecentRequests int `yaml:"recent_requests"`
+}
+
+type FactoryConfig struct {
+	Address        string `yaml:"address"`
+	InitCodeHash   string `yaml:"init_code_hash"`
+	FeeBasisPoints int    `yaml:"fee_basis_points"`
+
+	// ProtocolCut is the fraction of the fee (e.g. "1/6") that the fork pays to its
+	// protocol out of the swap output. Empty for standard V2, which never does.
+	ProtocolCut string `yaml:"protocol_cut"`
+
+	// FeeMethod is a no-argument view on the pair, e.g. "swapFee()", returning its
+	// fee for forks that store it on-chain. The result divided by FeeMethodDenominator
+	// is the fee fraction. Pools without the method use FeeBasisPoints.
+	FeeMethod            string `yaml:"fee_method"`
+	FeeMethodDenominator uint64 `yaml:"fee_method_denominator"`
 }
 
 func LoadConfig(configPath string) (*Config, error) {
@@ -36,15 +270,153 @@ func LoadConfig(configPath string) (*Config, error) {
 		}
 	}
 
-	rpcURL := os.Getenv("ETHEREUM_RPC_URL")
-	if rpcURL == "" {
-		return nil, fmt.Errorf("ETHEREUM_RPC_URL environment variable is required")
+	config.Blockchain.EthereumRPCURL = os.Getenv("ETHEREUM_RPC_URL")
+	config.Server.QuoteSigningSecret = os.Getenv("QUOTE_SIGNING_SECRET")
+	for _, key := range strings.Split(os.Getenv("API_KEYS"), ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			config.Server.APIKeys = append(config.Server.APIKeys, key)
+		}
+	}
+	for _, url := range strings.Split(os.Getenv("ETHEREUM_FALLBACK_RPC_URLS"), ",") {
+		if url = strings.TrimSpace(url); url != "" {
+			config.Blockchain.FallbackRPCURLs = append(config.Blockchain.FallbackRPCURLs, url)
+		}
 	}
-	config.Blockchain.EthereumRPCURL = rpcURL
 
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
 	return config, nil
 }
 
+// Validate checks every setting, returning an ErrValidation listing each invalid
+// one so they can all be fixed at once
+func (c *Config) Validate() error {
+	var problems []error
+	invalid := func(format string, args ...any) {
+		problems = append(problems, fmt.Errorf(format, args...))
+	}
+
+	if c.Blockchain.EthereumRPCURL == "" {
+		invalid("ETHEREUM_RPC_URL environment variable is required")
+	}
+
+	if c.Server.Address == "" {
+		invalid("server.address must not be empty")
+	}
+
+	if c.Server.ShutdownTimeout <= 0 {
+		invalid("server.shutdown_timeout must be positive, got %v", c.Server.ShutdownTimeout)
+	}
+
+	if c.Server.RequestTimeout < 0 {
+		invalid("server.request_timeout must not be negative, got %v", c.Server.RequestTimeout)
+	}
+
+	if c.Readiness.Timeout <= 0 {
+		invalid("readiness.timeout must be positive, got %v", c.Readiness.Timeout)
+	}
+
+	if c.RateLimit.RequestsPerMinute <= 0 {
+		invalid("rate_limit.requests_per_minute must be positive, got %d", c.RateLimit.RequestsPerMinute)
+	}
+
+	if rate := c.Logging.TraceSampleRate; rate < 0 || rate > 1 {
+		invalid("logging.trace_sample_rate must be between 0 and 1, got %v", rate)
+	}
+
+	if c.Blockchain.MaxProbeSlot > 255 {
+		invalid("blockchain.max_probe_slot must be at most 255, got %d", c.Blockchain.MaxProbeSlot)
+	}
+
+	if circuit := c.Blockchain.Circuit; circuit.ErrorRate <= 0 || circuit.ErrorRate > 1 ||
+		circuit.Window <= 0 || circuit.Cooldown <= 0 || circuit.MinRequests < 1 || circuit.ConsecutiveFailures < 0 {
+		invalid("blockchain.circuit needs error_rate in (0, 1], positive window and cooldown, min_requests of at least 1, and non-negative consecutive_failures")
+	}
+
+	if c.Server.HealthCheckPeriod <= 0 {
+		invalid("server.health_check_period must be positive, got %v", c.Server.HealthCheckPeriod)
+	}
+
+	if c.RateLimit.Burst < 0 {
+		invalid("rate_limit.burst must not be negative, got %d", c.RateLimit.Burst)
+	}
+
+	if c.RateLimit.CleanupInterval <= 0 {
+		invalid("rate_limit.cleanup_interval must be positive, got %v", c.RateLimit.CleanupInterval)
+	}
+
+	if len(c.CORS.AllowedOrigins) > 0 && len(c.CORS.AllowedMethods) == 0 {
+		invalid("cors.allowed_methods must not be empty when cors.allowed_origins is set")
+	}
+
+	if c.Server.MetricsAddress != "" && c.Server.MetricsAddress == c.Server.Address {
+		invalid("server.metrics_address must differ from server.address, both are %q", c.Server.Address)
+	}
+
+	if c.Server.BatchWorkers < 1 {
+		invalid("server.batch_workers must be at least 1, got %d", c.Server.BatchWorkers)
+	}
+
+	if c.Blockchain.EmptyReservesRetry < 0 {
+		invalid("blockchain.empty_reserves_retry must not be negative, got %v", c.Blockchain.EmptyReservesRetry)
+	}
+
+	if c.Blockchain.MonotonicHeadWindow < 0 {
+		invalid("blockchain.monotonic_head_window must not be negative, got %v", c.Blockchain.MonotonicHeadWindow)
+	}
+
+	if retry := c.Blockchain.Retry; retry.MaxAttempts < 1 || retry.BaseDelay < 0 || retry.Jitter < 0 || retry.Jitter > 1 {
+		invalid("blockchain.retry needs max_attempts of at least 1, a non-negative base_delay, and jitter in [0, 1]")
+	}
+
+	if c.Blockchain.PoolSize < 1 {
+		invalid("blockchain.pool_size must be at least 1, got %d", c.Blockchain.PoolSize)
+	}
+
+	if c.Debug.Enabled && c.Debug.RecentRequests <= 0 {
+		invalid("debug.recent_requests must be positive when debug is enabled")
+	}
+
+	if c.Cache.ReservesTTL < 0 {
+		invalid("cache.reserves_ttl must not be negative, got %v", c.Cache.ReservesTTL)
+	}
+	if c.Cache.ReservesTTL > 0 && c.Cache.ReservesMaxEntries < 1 {
+		invalid("cache.reserves_max_entries must be at least 1 when reserves_ttl is set, got %d", c.Cache.ReservesMaxEntries)
+	}
+
+	if jitter := c.Cache.TTLJitter; jitter < 0 || jitter >= 1 {
+		invalid("cache.ttl_jitter must be in [0, 1), got %v", jitter)
+	}
+
+	if action := c.ReserveGuard.Action; action != ReserveGuardReject && action != ReserveGuardWarn {
+		invalid("reserve_guard.action must be %q or %q, got %q", ReserveGuardReject, ReserveGuardWarn, action)
+	}
+	if c.ReserveGuard.MaxStaleness < 0 {
+		invalid("reserve_guard.max_staleness must not be negative, got %v", c.ReserveGuard.MaxStaleness)
+	}
+
+	for _, fee := range c.AssumedFeeTiers {
+		if fee < 0 || fee >= 10000 {
+			invalid("assumed_fee_tiers must be between 0 and 9999, got %d", fee)
+		}
+	}
+
+	if canary := c.Readiness.Canary; canary.Enabled() {
+		if canary.Src == "" || canary.Dst == "" {
+			invalid("readiness.canary requires src and dst when pool is set")
+		}
+		if _, ok := canary.ParsedAmount(); !ok {
+			invalid("readiness.canary.amount must be a positive integer, got %q", canary.Amount)
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: invalid configuration: %w", apperrors.ErrValidation, errors.Join(problems...))
+}
+
 func loadFromYAML(configPath string, config *Config) error {
 	data, err := os.ReadFile(configPath)
 	if err != nil {
@@ -56,12 +428,66 @@ func loadFromYAML(configPath string, config *Config) error {
 func getDefaultConfig() *Config {
 	return &Config{
 		Server: ServerConfig{
-			Address:         ":1337",
-			ShutdownTimeout: 30 * time.Second,
+			Address:               ":1337",
+			ShutdownTimeout:       30 * time.Second,
+			SlowRequestThreshold:  500 * time.Millisecond,
+			LatencySLO:            time.Second,
+			MaxPoolsPerRequest:    10,
+			BatchWorkers:          8,
+			MetricsAddress:        ":9090",
+			RequestTimeout:        5 * time.Second,
+			MaxRPCCallsPerRequest: 100,
+			HealthCheckPeriod:     30 * time.Second,
+		},
+		Blockchain: BlockchainConfig{
+			MaxProbeSlot: 15,
+			PoolSize:     1,
+			Retry: RetryConfig{
+				MaxAttempts: 1,
+				BaseDelay:   100 * time.Millisecond,
+				Jitter:      0.2,
+			},
+			Circuit: CircuitConfig{
+				ErrorRate:           0.5,
+				Window:              30 * time.Second,
+				MinRequests:         20,
+				Cooldown:            30 * time.Second,
+				ConsecutiveFailures: 5,
+			},
 		},
-		Blockchain: BlockchainConfig{},
 		RateLimit: RateLimitConfig{
 			RequestsPerMinute: 600, // 10 requests per second (Infura-friendly)
+			Burst:             20,
+			MaxClients:        100_000,
+			CleanupInterval:   time.Minute,
+		},
+		CORS: CORSConfig{
+			AllowedMethods: []string{"GET", "OPTIONS"},
+			AllowedHeaders: []string{"Content-Type"},
+		},
+		Cache: CacheConfig{
+			TokenTTL:           24 * time.Hour,
+			TTLJitter:          0.1,
+			SingleFlight:       true,
+			ReservesTTL:        300 * time.Millisecond,
+			ReservesMaxEntries: 10_000,
+		},
+		Readiness: ReadinessConfig{
+			Timeout: 5 * time.Second,
+		},
+		Debug: DebugConfig{
+			RecentRequests: 100,
+		},
+		AssumedFeeTiers: []int{30, 25, 100},
+		ReserveGuard: ReserveGuardConfig{
+			Action: ReserveGuardReject,
+		},
+		Factories: map[string]FactoryConfig{
+			"uniswap": {
+				Address:        "0x5C69bEe701ef814a2B6a3EDD4B1652CB9cc5aA6f",
+				InitCodeHash:   "0x96e8ac4277198ff8b6f785478aa9a39f403cb768dd02cbee326c3e7da348845f",
+				FeeBasisPoints: 30,
+			},
 		},
 	}
 }
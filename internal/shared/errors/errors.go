@@ -9,6 +9,9 @@ var (
 	ErrNotFound     = errors.New("not found")
 	ErrBusinessRule = errors.New("business rule violation")
 
+	ErrPoolNotInitialized = errors.New("pool not initialized")
+	ErrPoolDrained        = errors.New("pool drained")
+
 	ErrExternalService = errors.New("external service error")
 	ErrTimeout         = errors.New("timeout error")
 
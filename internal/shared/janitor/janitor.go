@@ -0,0 +1,43 @@
+// Package janitor runs periodic background sweeps that can be stopped cleanly.
+package janitor
+
+import (
+	"sync"
+	"time"
+)
+
+// Janitor calls a sweep function on a fixed interval until stopped
+type Janitor struct {
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// Start runs sweep every interval in a new goroutine
+func Start(interval time.Duration, sweep func()) *Janitor {
+	j := &Janitor{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go func() {
+		defer close(j.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				sweep()
+			case <-j.stop:
+				return
+			}
+		}
+	}()
+	return j
+}
+
+// Stop ends the sweep loop and waits for its goroutine to exit. Safe to call
+// more than once.
+func (j *Janitor) Stop() {
+	j.stopOnce.Do(func() { close(j.stop) })
+	<-j.done
+}
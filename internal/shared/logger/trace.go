@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type contextKey struct{}
+
+// debugCore admits every entry regardless of the wrapped core's level, so a single
+// request can be traced verbosely without lowering the global level
+type debugCore struct {
+	zapcore.Core
+}
+
+func (c debugCore) Enabled(zapcore.Level) bool {
+	return true
+}
+
+func (c debugCore) With(fields []zapcore.Field) zapcore.Core {
+	return debugCore{c.Core.With(fields)}
+}
+
+func (c debugCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return ce.AddCore(ent, c)
+}
+
+// WithDebug returns a copy of l that emits Debug entries even when l's level is higher
+func WithDebug(l *zap.Logger) *zap.Logger {
+	return l.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return debugCore{core}
+	}))
+}
+
+// Sampled reports whether the request identified by id falls within rate (0.0-1.0).
+// The decision is a pure function of id, so a request is either traced throughout or not at all.
+func Sampled(id uint64, rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	if rate >= 1 {
+		return true
+	}
+
+	// splitmix64 finalizer: spreads sequential ids uniformly over [0, 2^64)
+	id += 0x9e3779b97f4a7c15
+	id = (id ^ (id >> 30)) * 0xbf58476d1ce4e5b9
+	id = (id ^ (id >> 27)) * 0x94d049bb133111eb
+	id ^= id >> 31
+
+	return float64(id>>11)/(1<<53) < rate
+}
+
+// WithContext attaches a request-scoped logger to ctx
+func WithContext(ctx context.Context, l *zap.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the request-scoped logger attached to ctx, or fallback if there is none
+func FromContext(ctx context.Context, fallback *zap.Logger) *zap.Logger {
+	if l, ok := ctx.Value(contextKey{}).(*zap.Logger); ok {
+		return l
+	}
+	return fallback
+}
@@ -0,0 +1,104 @@
+package metrics
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// Global is the process-wide registry exposed through the stats endpoint
+	Global = NewRegistry()
+)
+
+// Counter is a monotonically increasing value safe for concurrent use
+type Counter struct {
+	value atomic.Uint64
+}
+
+// Inc increments the counter by one
+func (c *Counter) Inc() {
+	c.value.Add(1)
+}
+
+// Add increments the counter by n
+func (c *Counter) Add(n uint64) {
+	c.value.Add(n)
+}
+
+// Value returns the current counter value
+func (c *Counter) Value() uint64 {
+	return c.value.Load()
+}
+
+// Registry holds named counters and gauges
+type Registry struct {
+	mu       sync.RWMutex
+	counters map[string]*Counter
+	gauges   map[string]func() int64
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		counters: make(map[string]*Counter),
+		gauges:   make(map[string]func() int64),
+	}
+}
+
+// Counter returns the counter registered under name, creating it on first use
+func (r *Registry) Counter(name string) *Counter {
+	r.mu.RLock()
+	counter, ok := r.counters[name]
+	r.mu.RUnlock()
+	if ok {
+		return counter
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if counter, ok = r.counters[name]; !ok {
+		counter = &Counter{}
+		r.counters[name] = counter
+	}
+	return counter
+}
+
+// Gauge registers a function sampled whenever a snapshot is taken,
+// replacing any gauge previously registered under the same name
+func (r *Registry) Gauge(name string, fn func() int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.gauges[name] = fn
+}
+
+// Snapshot returns the current value of every counter and gauge
+func (r *Registry) Snapshot() map[string]int64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	snapshot := make(map[string]int64, len(r.counters)+len(r.gauges))
+	for name, counter := range r.counters {
+		snapshot[name] = int64(counter.Value())
+	}
+	for name, gauge := range r.gauges {
+		snapshot[name] = gauge()
+	}
+	return snapshot
+}
+
+// Names returns all registered metric names in sorted order
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.counters)+len(r.gauges))
+	for name := range r.counters {
+		names = append(names, name)
+	}
+	for name := range r.gauges {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// GlobalExporter holds the process-wide collectors served on /metrics
+	GlobalExporter = NewExporter()
+)
+
+// Exporter holds the Prometheus collectors served on /metrics, registered on a
+// registry of its own rather than the client library's default one
+type Exporter struct {
+	Registry *prometheus.Registry
+
+	// HTTPRequests and HTTPRequestDuration are labelled by status code
+	HTTPRequests        *prometheus.CounterVec
+	HTTPRequestDuration *prometheus.HistogramVec
+
+	// EstimateDuration is how long each /estimate request took, parse to response
+	EstimateDuration prometheus.Histogram
+
+	// RPCCalls and RPCErrors are labelled by JSON-RPC method; a storage batch
+	// counts once under eth_getStorageAt_batch
+	RPCCalls  *prometheus.CounterVec
+	RPCErrors *prometheus.CounterVec
+
+	RateLimitRejections prometheus.Counter
+}
+
+// NewExporter creates an Exporter with every collector registered
+func NewExporter() *Exporter {
+	e := &Exporter{
+		Registry: prometheus.NewRegistry(),
+		HTTPRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "HTTP responses by status code.",
+		}, []string{"code"}),
+		HTTPRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request latency by status code.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"code"}),
+		EstimateDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "estimate_duration_seconds",
+			Help:    "Time to handle an /estimate request.",
+			Buckets: prometheus.DefBuckets,
+		}),
+		RPCCalls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "rpc_calls_total",
+			Help: "Ethereum JSON-RPC calls by method.",
+		}, []string{"method"}),
+		RPCErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "rpc_errors_total",
+			Help: "Failed Ethereum JSON-RPC calls by method.",
+		}, []string{"method"}),
+		RateLimitRejections: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "rate_limit_rejections_total",
+			Help: "Requests rejected by the rate limiter.",
+		}),
+	}
+	e.Registry.MustRegister(e.HTTPRequests, e.HTTPRequestDuration, e.EstimateDuration, e.RPCCalls, e.RPCErrors, e.RateLimitRejections)
+	return e
+}
+
+// GaugeFunc registers a gauge sampled from fn on every scrape. Only the first
+// function registered under a name is kept.
+func (e *Exporter) GaugeFunc(name, help string, fn func() float64) {
+	e.register(prometheus.NewGaugeFunc(prometheus.GaugeOpts{Name: name, Help: help}, fn))
+}
+
+// CounterFunc registers a counter sampled from fn on every scrape. Only the first
+// function registered under a name is kept.
+func (e *Exporter) CounterFunc(name, help string, fn func() float64) {
+	e.register(prometheus.NewCounterFunc(prometheus.CounterOpts{Name: name, Help: help}, fn))
+}
+
+func (e *Exporter) register(collector prometheus.Collector) {
+	if err := e.Registry.Register(collector); err != nil {
+		var already prometheus.AlreadyRegisteredError
+		if !errors.As(err, &already) {
+			panic(err)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+// Package requestid carries the ID correlating a request's log lines across layers.
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+)
+
+type contextKey struct{}
+
+// New returns a random version 4 UUID
+func New() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// WithID attaches the request ID to ctx
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request ID attached to ctx, or "" if there is none
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
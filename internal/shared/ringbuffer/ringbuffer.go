@@ -0,0 +1,48 @@
+package ringbuffer
+
+import (
+	"sync/atomic"
+)
+
+// Ring is a fixed-size, lock-free buffer keeping the most recent values. Writers
+// never block each other or readers; when the buffer is full the oldest value is
+// overwritten.
+type Ring[T any] struct {
+	slots []atomic.Pointer[T]
+	next  atomic.Uint64
+}
+
+// New creates a ring holding up to size values. size must be positive.
+func New[T any](size int) *Ring[T] {
+	if size <= 0 {
+		panic("ringbuffer: size must be positive")
+	}
+	return &Ring[T]{
+		slots: make([]atomic.Pointer[T], size),
+	}
+}
+
+// Add stores v, overwriting the oldest value once the ring is full
+func (r *Ring[T]) Add(v T) {
+	i := r.next.Add(1) - 1
+	r.slots[i%uint64(len(r.slots))].Store(&v)
+}
+
+// Snapshot returns the stored values, newest first
+func (r *Ring[T]) Snapshot() []T {
+	n := r.next.Load()
+	count := min(n, uint64(len(r.slots)))
+
+	values := make([]T, 0, count)
+	for i := uint64(0); i < count; i++ {
+		if v := r.slots[(n-1-i)%uint64(len(r.slots))].Load(); v != nil {
+			values = append(values, *v)
+		}
+	}
+	return values
+}
+
+// Len returns the number of values currently held
+func (r *Ring[T]) Len() int {
+	return int(min(r.next.Load(), uint64(len(r.slots))))
+}
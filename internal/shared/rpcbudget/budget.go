@@ -0,0 +1,64 @@
+// Package rpcbudget caps the number of RPC calls a single request may issue.
+package rpcbudget
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+)
+
+// ErrExceeded is returned by Spend once the request has used up its budget
+var ErrExceeded = errors.New("RPC call budget exceeded")
+
+type contextKey struct{}
+
+// Budget counts RPC calls against a limit. Safe for concurrent use, so parallel
+// reads (e.g. both legs of an arbitrage) share one budget.
+type Budget struct {
+	limit int64
+	used  atomic.Int64
+}
+
+// New creates a budget allowing limit calls
+func New(limit int) *Budget {
+	return &Budget{limit: int64(limit)}
+}
+
+// Limit returns the number of calls allowed
+func (b *Budget) Limit() int {
+	return int(b.limit)
+}
+
+// Used returns the number of calls attempted, including any rejected ones
+func (b *Budget) Used() int {
+	return int(b.used.Load())
+}
+
+// Exceeded reports whether a call has been rejected for going over the limit
+func (b *Budget) Exceeded() bool {
+	return b.used.Load() > b.limit
+}
+
+// WithBudget attaches b to ctx
+func WithBudget(ctx context.Context, b *Budget) context.Context {
+	return context.WithValue(ctx, contextKey{}, b)
+}
+
+// FromContext returns the budget attached to ctx, or nil
+func FromContext(ctx context.Context) *Budget {
+	b, _ := ctx.Value(contextKey{}).(*Budget)
+	return b
+}
+
+// Spend records one call against the budget attached to ctx and fails with
+// ErrExceeded once the limit is passed. Without a budget it always succeeds.
+func Spend(ctx context.Context) error {
+	b := FromContext(ctx)
+	if b == nil {
+		return nil
+	}
+	if b.used.Add(1) > b.limit {
+		return ErrExceeded
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+// Package timing records how long each phase of a request spends, across layers.
+package timing
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Phase names recorded by the service layer
+const (
+	PhaseBlock    = "block"
+	PhaseTokens   = "tokens"
+	PhaseReserves = "reserves"
+)
+
+type contextKey struct{}
+
+// Spans accumulates time spent per named phase. Concurrent spans of the same
+// phase (e.g. both legs of an arbitrage) add up. Safe for concurrent use.
+type Spans struct {
+	mu     sync.Mutex
+	totals map[string]time.Duration
+}
+
+// NewSpans creates an empty span recorder
+func NewSpans() *Spans {
+	return &Spans{totals: make(map[string]time.Duration)}
+}
+
+// Add records d against phase
+func (s *Spans) Add(phase string, d time.Duration) {
+	s.mu.Lock()
+	s.totals[phase] += d
+	s.mu.Unlock()
+}
+
+// Total returns the time recorded against phase
+func (s *Spans) Total(phase string) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.totals[phase]
+}
+
+// WithSpans attaches a span recorder to ctx
+func WithSpans(ctx context.Context, s *Spans) context.Context {
+	return context.WithValue(ctx, contextKey{}, s)
+}
+
+// Start begins a span of phase on the recorder attached to ctx and returns the
+// func that ends it. Without a recorder it does nothing.
+func Start(ctx context.Context, phase string) func() {
+	s, ok := ctx.Value(contextKey{}).(*Spans)
+	if !ok {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		s.Add(phase, time.Since(start))
+	}
+}
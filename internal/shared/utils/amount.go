@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+)
+
+// ErrInvalidAmount is returned by ParseAmount for input that is not an integer
+var ErrInvalidAmount = errors.New("invalid amount")
+
+// ParseAmount parses a decimal integer, or a hexadecimal one when prefixed with
+// 0x or 0X. A single leading sign is accepted so callers can report the sign
+// separately. Unlike big.Int.SetString with base 0, a leading zero does not
+// switch to octal, so "010" is ten, and underscores are rejected.
+func ParseAmount(s string) (*big.Int, error) {
+	negative := strings.HasPrefix(s, "-")
+	digits := s
+	if negative || strings.HasPrefix(s, "+") {
+		digits = s[1:]
+	}
+
+	base := 10
+	if len(digits) >= 2 && digits[0] == '0' && (digits[1] == 'x' || digits[1] == 'X') {
+		base = 16
+		digits = digits[2:]
+	}
+	// SetString would otherwise accept a second sign, e.g. "0x-5" or "--5"
+	if digits == "" || digits[0] == '+' || digits[0] == '-' {
+		return nil, ErrInvalidAmount
+	}
+
+	amount, ok := new(big.Int).SetString(digits, base)
+	if !ok {
+		return nil, ErrInvalidAmount
+	}
+	if negative {
+		amount.Neg(amount)
+	}
+	return amount, nil
+}
+
+// FormatUnits renders a base-unit amount as a decimal with exactly decimals
+// fractional digits, e.g. 1500000 with 6 decimals is "1.500000". The amount is
+// an integer in base units, so no precision beyond the token's own is invented.
+func FormatUnits(amount *big.Int, decimals uint8) string {
+	digits := new(big.Int).Abs(amount).String()
+	sign := ""
+	if amount.Sign() < 0 {
+		sign = "-"
+	}
+	if decimals == 0 {
+		return sign + digits
+	}
+
+	width := int(decimals)
+	if len(digits) <= width {
+		digits = strings.Repeat("0", width-len(digits)+1) + digits
+	}
+	split := len(digits) - width
+	return sign + digits[:split] + "." + digits[split:]
+}
+
+// ErrTooManyDecimals is returned by ParseUnits when the input is more precise
+// than the token's decimals allow
+var ErrTooManyDecimals = errors.New("more fractional digits than the token's decimals")
+
+// ParseUnits parses a decimal token quantity such as "1.5" into base units, the
+// inverse of FormatUnits. A single leading sign is accepted as by ParseAmount.
+// Trailing fractional zeros are ignored, but any other digit past decimals is
+// an error rather than being rounded away.
+func ParseUnits(s string, decimals uint8) (*big.Int, error) {
+	negative := strings.HasPrefix(s, "-")
+	digits := s
+	if negative || strings.HasPrefix(s, "+") {
+		digits = s[1:]
+	}
+
+	whole, fraction, hasPoint := strings.Cut(digits, ".")
+	if !isDecimalDigits(whole) || (hasPoint && !isDecimalDigits(fraction)) {
+		return nil, ErrInvalidAmount
+	}
+	fraction = strings.TrimRight(fraction, "0")
+	if len(fraction) > int(decimals) {
+		return nil, ErrTooManyDecimals
+	}
+
+	amount, _ := new(big.Int).SetString(whole+fraction+strings.Repeat("0", int(decimals)-len(fraction)), 10)
+	if negative {
+		amount.Neg(amount)
+	}
+	return amount, nil
+}
+
+// isDecimalDigits reports whether s is one or more ASCII digits
+func isDecimalDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -1,68 +1,153 @@
 package utils
 
 import (
+	"encoding/binary"
+	"errors"
+	"fmt"
 	"math/big"
 	"sync"
+	"sync/atomic"
 )
 
+// StorageWordSize is the size in bytes of an EVM storage slot
+const StorageWordSize = 32
+
+var (
+	ErrInvalidStorageWord    = errors.New("invalid storage word")
+	ErrInsufficientLiquidity = errors.New("insufficient liquidity")
+	// ErrZeroDenominator marks a swap whose formula would divide by zero, as
+	// with an empty input reserve and a zero input
+	ErrZeroDenominator = errors.New("swap denominator is zero")
+	// ErrOutputRoundsToZero marks a dust input whose output truncates to zero
+	ErrOutputRoundsToZero = errors.New("output rounds to zero")
+)
+
+// Fees are in basis points, 1/10000ths (30 = 0.3%)
 var (
-	FeeBasisPoints1000 = big.NewInt(1000)
-	FeeBasisPoints997  = big.NewInt(997) // 1000 - 3 (0.3% fee)
-	FeeBasisPoints995  = big.NewInt(995) // 1000 - 5 (0.5% fee)
-	FeeBasisPoints990  = big.NewInt(990) // 1000 - 10 (1.0% fee)
+	FeeBasisPoints10000 = big.NewInt(10000)
+	FeeBasisPoints9975  = big.NewInt(9975) // 10000 - 25 (0.25% fee)
+	FeeBasisPoints9970  = big.NewInt(9970) // 10000 - 30 (0.3% fee)
+	FeeBasisPoints9950  = big.NewInt(9950) // 10000 - 50 (0.5% fee)
+	FeeBasisPoints9900  = big.NewInt(9900) // 10000 - 100 (1.0% fee)
+
+	big1 = big.NewInt(1)
 
 	Mask112 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 112), big.NewInt(1))
 
 	GlobalBigIntPool = NewBigIntPool()
 )
 
-// BigIntPool provides a pool of reusable big.Int objects for memory optimization
+// BigIntPool provides a pool of reusable big.Int objects for memory optimization.
+// It counts Gets, Puts and fresh allocations so production traffic can show
+// whether the pool is paying off and whether any path forgets to Put.
 type BigIntPool struct {
 	pool sync.Pool
+
+	gets   atomic.Uint64
+	puts   atomic.Uint64
+	allocs atomic.Uint64
+}
+
+// PoolStats is a snapshot of a BigIntPool or BigRatPool's counters
+type PoolStats struct {
+	Gets   uint64
+	Puts   uint64
+	Allocs uint64
+}
+
+// Outstanding is the number of values taken and not yet returned. It should
+// hover near zero; steady growth means some path skips a Put.
+func (s PoolStats) Outstanding() int64 {
+	return int64(s.Gets) - int64(s.Puts)
+}
+
+// AllocsAvoided estimates the allocations the pool saved: Gets served by reuse
+func (s PoolStats) AllocsAvoided() uint64 {
+	if s.Allocs >= s.Gets {
+		return 0
+	}
+	return s.Gets - s.Allocs
 }
 
 // NewBigIntPool creates a new BigInt pool
 func NewBigIntPool() *BigIntPool {
-	return &BigIntPool{
-		pool: sync.Pool{
-			New: func() interface{} {
-				return new(big.Int)
-			},
-		},
+	p := &BigIntPool{}
+	p.pool.New = func() interface{} {
+		p.allocs.Add(1)
+		return new(big.Int)
 	}
+	return p
 }
 
 // Get retrieves a big.Int from the pool
 func (p *BigIntPool) Get() *big.Int {
+	p.gets.Add(1)
 	return p.pool.Get().(*big.Int)
 }
 
 // Put returns a big.Int to the pool
 func (p *BigIntPool) Put(x *big.Int) {
 	if x != nil {
+		p.puts.Add(1)
 		x.SetInt64(0)
 		p.pool.Put(x)
 	}
 }
 
+// Stats returns a snapshot of the pool's counters
+func (p *BigIntPool) Stats() PoolStats {
+	// Puts is read first so a concurrent Get/Put pair can't make Outstanding negative
+	puts := p.puts.Load()
+	return PoolStats{
+		Gets:   p.gets.Load(),
+		Puts:   puts,
+		Allocs: p.allocs.Load(),
+	}
+}
+
 // GetPoolStats returns statistics about the pool (for debugging/monitoring)
 func (p *BigIntPool) GetPoolStats() map[string]interface{} {
-	// Note: sync.Pool doesn't expose internal stats, so we return basic info
+	stats := p.Stats()
 	return map[string]interface{}{
-		"pool_type":   "sync.Pool",
-		"object_type": "big.Int",
-		"pre_warmed":  true,
+		"pool_type":      "sync.Pool",
+		"object_type":    "big.Int",
+		"gets":           stats.Gets,
+		"puts":           stats.Puts,
+		"allocs":         stats.Allocs,
+		"outstanding":    stats.Outstanding(),
+		"allocs_avoided": stats.AllocsAvoided(),
 	}
 }
 
+// NormalizeStorageWord left-pads b to a full 32-byte storage word. Some RPC
+// providers strip leading zero bytes from eth_getStorageAt results; since the
+// word is big-endian, the missing bytes are always the most significant ones.
+func NormalizeStorageWord(b []byte) []byte {
+	if len(b) >= StorageWordSize {
+		return b
+	}
+	word := make([]byte, StorageWordSize)
+	copy(word[StorageWordSize-len(b):], b)
+	return word
+}
+
+// ValidateStorageWord rejects values that cannot be a single storage slot
+func ValidateStorageWord(b []byte) error {
+	if len(b) > StorageWordSize {
+		return fmt.Errorf("%w: got %d bytes, expected at most %d", ErrInvalidStorageWord, len(b), StorageWordSize)
+	}
+	return nil
+}
+
 // ParseReserves unpacks two uint112 reserves from the 32-byte storage word
 // used by Uniswap V2 pairs. The layout is:
 //
 //	[ 112 bits reserve0 | 112 bits reserve1 | 32 bits timestamp ]
 //
-// Values are treated as big-endian within the 256-bit word.
+// Values are treated as big-endian within the 256-bit word. Inputs shorter than
+// 32 bytes are left-padded first (see NormalizeStorageWord).
 func ParseReserves(b []byte) (reserve0, reserve1 *big.Int) {
-	v := new(big.Int).SetBytes(b)
+	v := new(big.Int).SetBytes(NormalizeStorageWord(b))
 
 	reserve0 = new(big.Int).And(v, Mask112)
 	tmp := new(big.Int).Rsh(v, 112)
@@ -70,10 +155,19 @@ func ParseReserves(b []byte) (reserve0, reserve1 *big.Int) {
 	return
 }
 
+// ParseReservesWithTimestamp is ParseReserves that also returns the pair's
+// blockTimestampLast, the block time of its last reserves update modulo 2^32
+func ParseReservesWithTimestamp(b []byte) (reserve0, reserve1 *big.Int, blockTimestampLast uint32) {
+	word := NormalizeStorageWord(b)
+	reserve0, reserve1 = ParseReserves(word)
+	// Like the reserves, the timestamp is taken from the low 256 bits
+	return reserve0, reserve1, binary.BigEndian.Uint32(word[len(word)-StorageWordSize:])
+}
+
 // ParseReservesWithPool unpacks two uint112 reserves using a BigInt pool for memory optimization
 func ParseReservesWithPool(b []byte, pool *BigIntPool) (reserve0, reserve1 *big.Int) {
 	v := pool.Get()
-	v.SetBytes(b)
+	v.SetBytes(NormalizeStorageWord(b))
 
 	tmp := pool.Get()
 	reserve0 = pool.Get()
@@ -95,46 +189,338 @@ func ParseReservesWithPool(b []byte, pool *BigIntPool) (reserve0, reserve1 *big.
 	return result0, result1
 }
 
+// FeeSide selects which leg of the swap the pool fee is charged on
+type FeeSide int
+
+const (
+	// FeeOnInput is the standard Uniswap V2 behaviour: the fee is deducted from amountIn
+	FeeOnInput FeeSide = iota
+	// FeeOnOutput is used by forks that charge the fee on the tokens leaving the pool
+	FeeOnOutput
+)
+
+// String returns the query parameter representation of the fee side
+func (s FeeSide) String() string {
+	if s == FeeOnOutput {
+		return "out"
+	}
+	return "in"
+}
+
+// feeMultiplierFor returns (10000 - feeBasisPoints) as a big.Int, reusing the
+// precomputed constants where possible. The second return value reports whether
+// the multiplier was taken from the pool and must be returned to it.
+func feeMultiplierFor(feeBasisPoints int, pool *BigIntPool) (*big.Int, bool) {
+	switch feeBasisPoints {
+	case 25:
+		return FeeBasisPoints9975, false
+	case 30:
+		return FeeBasisPoints9970, false
+	case 50:
+		return FeeBasisPoints9950, false
+	case 100:
+		return FeeBasisPoints9900, false
+	default:
+		feeMultiplier := pool.Get()
+		feeMultiplier.SetInt64(int64(10000 - feeBasisPoints))
+		return feeMultiplier, true
+	}
+}
+
 // CalculateSwapAmount calculates the swap amount using constant product AMM formula with fee
-// Formula: amountOut = (amountIn * (1000-fee) * reserveOut) / (reserveIn * 1000 + amountIn * (1000-fee))
+// Formula: amountOut = (amountIn * (10000-fee) * reserveOut) / (reserveIn * 10000 + amountIn * (10000-fee))
+// with fee in basis points; for whole per-mille fees it equals the on-chain 1000-based formula
 // This formula is used by Uniswap V2, SushiSwap, PancakeSwap, and other constant product AMMs
 // Uses zero-allocation approach with scratch variables (t1, t2) for optimal performance
-func CalculateSwapAmount(amountIn, reserveIn, reserveOut, amountOut *big.Int, feeBasisPoints int, pool *BigIntPool) {
+// Returns ErrZeroDenominator, leaving amountOut zero, instead of dividing by zero, and
+// ErrOutputRoundsToZero when the output truncates to zero
+func CalculateSwapAmount(amountIn, reserveIn, reserveOut, amountOut *big.Int, feeBasisPoints int, pool *BigIntPool) error {
 	t1 := pool.Get()
 	t2 := pool.Get()
 
-	var feeMultiplier *big.Int
-	switch feeBasisPoints {
-	case 3:
-		feeMultiplier = FeeBasisPoints997
-	case 5:
-		feeMultiplier = FeeBasisPoints995
-	case 10:
-		feeMultiplier = FeeBasisPoints990
-	default:
-		feeMultiplier = pool.Get()
-		feeMultiplier.SetInt64(int64(1000 - feeBasisPoints))
-	}
+	feeMultiplier, pooled := feeMultiplierFor(feeBasisPoints, pool)
+	defer func() {
+		pool.Put(t1)
+		pool.Put(t2)
+		if pooled {
+			pool.Put(feeMultiplier)
+		}
+	}()
 
 	t1.Mul(amountIn, feeMultiplier)
 
-	amountOut.Mul(reserveIn, FeeBasisPoints1000)
+	amountOut.Mul(reserveIn, FeeBasisPoints10000)
 
 	t2.Add(amountOut, t1)
+	if t2.Sign() == 0 {
+		amountOut.SetInt64(0)
+		return ErrZeroDenominator
+	}
 
 	amountOut.Mul(t1, reserveOut)
 
 	amountOut.QuoRem(amountOut, t2, t1)
+	if amountOut.Sign() == 0 {
+		return ErrOutputRoundsToZero
+	}
+	return nil
+}
+
+// SwapMath holds the intermediates of CalculateSwapAmount, named after the
+// variables in UniswapV2Library.getAmountOut
+type SwapMath struct {
+	AmountInWithFee *big.Int
+	Numerator       *big.Int
+	Denominator     *big.Int
+
+	// AmountOutBeforeCut and ProtocolCut are set for forks that pay a protocol
+	// cut out of the swap: numerator / denominator, and the part of it withheld
+	// by ApplyProtocolCut
+	AmountOutBeforeCut *big.Int
+	ProtocolCut        *big.Int
+}
+
+// CalculateSwapAmountWithMath computes the same output as CalculateSwapAmount and also
+// returns its intermediates so integrators can audit each step. It allocates, so it
+// is meant for debug requests rather than the hot path.
+func CalculateSwapAmountWithMath(amountIn, reserveIn, reserveOut, amountOut *big.Int, feeBasisPoints int) SwapMath {
+	amountInWithFee := new(big.Int).Mul(amountIn, big.NewInt(int64(10000-feeBasisPoints)))
+	numerator := new(big.Int).Mul(amountInWithFee, reserveOut)
+	denominator := new(big.Int).Mul(reserveIn, FeeBasisPoints10000)
+	denominator.Add(denominator, amountInWithFee)
+
+	amountOut.Quo(numerator, denominator)
+	return SwapMath{AmountInWithFee: amountInWithFee, Numerator: numerator, Denominator: denominator}
+}
+
+// CalculateSwapAmountFeeOnOutput calculates the swap amount for forks that charge the fee
+// on the output leg instead of the input
+// Formula: amountOut = (amountIn * reserveOut / (reserveIn + amountIn)) * (10000-fee) / 10000
+// Both divisions truncate, matching the integer arithmetic performed on-chain
+// Errors as CalculateSwapAmount does
+func CalculateSwapAmountFeeOnOutput(amountIn, reserveIn, reserveOut, amountOut *big.Int, feeBasisPoints int, pool *BigIntPool) error {
+	t1 := pool.Get()
+	t2 := pool.Get()
+
+	feeMultiplier, pooled := feeMultiplierFor(feeBasisPoints, pool)
+	defer func() {
+		pool.Put(t1)
+		pool.Put(t2)
+		if pooled {
+			pool.Put(feeMultiplier)
+		}
+	}()
+
+	t2.Add(reserveIn, amountIn)
+	if t2.Sign() == 0 {
+		amountOut.SetInt64(0)
+		return ErrZeroDenominator
+	}
+
+	amountOut.Mul(amountIn, reserveOut)
+	amountOut.QuoRem(amountOut, t2, t1)
+
+	amountOut.Mul(amountOut, feeMultiplier)
+	amountOut.QuoRem(amountOut, FeeBasisPoints10000, t1)
+	if amountOut.Sign() == 0 {
+		return ErrOutputRoundsToZero
+	}
+	return nil
+}
+
+// CalculateSwapAmountForSide dispatches to the input- or output-side fee formula
+func CalculateSwapAmountForSide(amountIn, reserveIn, reserveOut, amountOut *big.Int, feeBasisPoints int, side FeeSide, pool *BigIntPool) error {
+	if side == FeeOnOutput {
+		return CalculateSwapAmountFeeOnOutput(amountIn, reserveIn, reserveOut, amountOut, feeBasisPoints, pool)
+	}
+	return CalculateSwapAmount(amountIn, reserveIn, reserveOut, amountOut, feeBasisPoints, pool)
+}
+
+// CalculateAmountIn calculates the minimum input required to receive exactly amountOut
+// Formula: amountIn = (reserveIn * amountOut * 10000) / ((reserveOut - amountOut) * (10000-fee)) + 1
+// Returns ErrInsufficientLiquidity when amountOut >= reserveOut, since no finite input can drain the pool
+func CalculateAmountIn(amountOut, reserveIn, reserveOut, amountIn *big.Int, feeBasisPoints int, pool *BigIntPool) error {
+	if amountOut.Cmp(reserveOut) >= 0 {
+		return fmt.Errorf("%w: requested output %s, reserve %s", ErrInsufficientLiquidity, amountOut, reserveOut)
+	}
+
+	t1 := pool.Get()
+	t2 := pool.Get()
+
+	feeMultiplier, pooled := feeMultiplierFor(feeBasisPoints, pool)
+
+	t1.Sub(reserveOut, amountOut)
+	t1.Mul(t1, feeMultiplier)
+
+	amountIn.Mul(reserveIn, amountOut)
+	amountIn.Mul(amountIn, FeeBasisPoints10000)
+
+	amountIn.QuoRem(amountIn, t1, t2)
+	amountIn.Add(amountIn, big1)
 
 	pool.Put(t1)
 	pool.Put(t2)
-	if feeBasisPoints != 3 && feeBasisPoints != 5 && feeBasisPoints != 10 {
+	if pooled {
 		pool.Put(feeMultiplier)
 	}
+	return nil
+}
+
+// CalculateAmountInFeeOnOutput is the exact-out counterpart of CalculateSwapAmountFeeOnOutput:
+// it first grosses amountOut up by the fee, then solves the constant product for the input.
+// Returns ErrInsufficientLiquidity when the grossed-up output is >= reserveOut
+func CalculateAmountInFeeOnOutput(amountOut, reserveIn, reserveOut, amountIn *big.Int, feeBasisPoints int, pool *BigIntPool) error {
+	gross := pool.Get()
+	t1 := pool.Get()
+	defer pool.Put(gross)
+	defer pool.Put(t1)
+
+	feeMultiplier, pooled := feeMultiplierFor(feeBasisPoints, pool)
+	if pooled {
+		defer pool.Put(feeMultiplier)
+	}
+
+	// gross = ceil(amountOut * 10000 / (10000-fee))
+	gross.Mul(amountOut, FeeBasisPoints10000)
+	ceilDiv(gross, feeMultiplier, t1)
+
+	if gross.Cmp(reserveOut) >= 0 {
+		return fmt.Errorf("%w: requested output %s, reserve %s", ErrInsufficientLiquidity, amountOut, reserveOut)
+	}
+
+	// amountIn = ceil(gross * reserveIn / (reserveOut - gross))
+	t1.Sub(reserveOut, gross)
+	amountIn.Mul(gross, reserveIn)
+	ceilDiv(amountIn, t1, gross)
+	return nil
+}
+
+// CalculateAmountInForSide dispatches to the input- or output-side exact-out formula
+func CalculateAmountInForSide(amountOut, reserveIn, reserveOut, amountIn *big.Int, feeBasisPoints int, side FeeSide, pool *BigIntPool) error {
+	if side == FeeOnOutput {
+		return CalculateAmountInFeeOnOutput(amountOut, reserveIn, reserveOut, amountIn, feeBasisPoints, pool)
+	}
+	return CalculateAmountIn(amountOut, reserveIn, reserveOut, amountIn, feeBasisPoints, pool)
+}
+
+// CalculateUniswapV2AmountIn calculates the input required for amountOut with the standard 0.3% fee
+func CalculateUniswapV2AmountIn(amountOut, reserveIn, reserveOut, amountIn *big.Int, pool *BigIntPool) error {
+	return CalculateAmountIn(amountOut, reserveIn, reserveOut, amountIn, 30, pool)
+}
+
+// ComputeInvariant returns the constant-product invariant k = reserve0 * reserve1.
+// A swap with a nonzero fee leaves k strictly larger, since the fee stays in the pool.
+func ComputeInvariant(reserve0, reserve1 *big.Int) *big.Int {
+	return new(big.Int).Mul(reserve0, reserve1)
+}
+
+// MaxAmountInForImpact returns the largest input whose price impact stays within
+// impactBps (1/10000ths). Impact is measured against the mid price, excluding the fee:
+//
+//	impact = 1 - (amountOut / amountIn') / (reserveOut / reserveIn) = amountIn' / (reserveIn + amountIn')
+//
+// where amountIn' = amountIn * (10000-fee) / 10000 is the input after fee. Solving for
+// amountIn gives the closed form
+//
+//	amountIn = reserveIn * impactBps * 10000 / ((10000 - impactBps) * (10000 - fee))
+//
+// rounded down. Truncation of the on-chain output can add at most one unit of
+// output on top of the target impact.
+func MaxAmountInForImpact(reserveIn *big.Int, impactBps, feeBasisPoints int) *big.Int {
+	numerator := new(big.Int).Mul(reserveIn, big.NewInt(int64(impactBps)))
+	numerator.Mul(numerator, FeeBasisPoints10000)
+
+	denominator := big.NewInt(int64((10000 - impactBps) * (10000 - feeBasisPoints)))
+	return numerator.Quo(numerator, denominator)
+}
+
+// PriceImpactBps returns how far a swap's execution price falls short of the
+// pool's mid price, in 1/10000ths, rounded down:
+//
+//	impact = 1 - (amountOut / amountIn) / (reserveOut / reserveIn)
+//
+// Unlike MaxAmountInForImpact it is measured on the actual output, so the fee
+// counts towards the impact.
+func PriceImpactBps(amountIn, amountOut, reserveIn, reserveOut *big.Int) int {
+	mid := new(big.Int).Mul(amountIn, reserveOut)
+	if mid.Sign() <= 0 {
+		return 0
+	}
+	shortfall := new(big.Int).Mul(amountOut, reserveIn)
+	shortfall.Sub(mid, shortfall)
+	if shortfall.Sign() <= 0 {
+		return 0
+	}
+	shortfall.Mul(shortfall, big.NewInt(10000))
+	return int(shortfall.Quo(shortfall, mid).Int64())
+}
+
+// MinAmountOut returns the least output to accept for a swap quoted at amountOut
+// under a slippage tolerance in 1/10000ths, rounded down:
+//
+//	amountOutMin = amountOut * (10000 - slippageBps) / 10000
+func MinAmountOut(amountOut *big.Int, slippageBps int) *big.Int {
+	minOut := new(big.Int).Mul(amountOut, big.NewInt(int64(10000-slippageBps)))
+	return minOut.Quo(minOut, big.NewInt(10000))
+}
+
+// ApplyProtocolCut reduces amountOut by the protocol's share of the fee, for forks that
+// pay the protocol out of the swap output:
+//
+//	amountOut = amountOut - amountOut * fee * cut / 10000
+//
+// where cut is the protocol's fraction of the fee (e.g. 1/6 of 0.3% = 0.05% of output).
+// Standard Uniswap V2 collects its fee switch by minting LP shares on mint/burn, so its
+// swap output is unchanged and this must not be applied.
+func ApplyProtocolCut(amountOut *big.Int, feeBasisPoints int, cut *big.Rat, pool *BigIntPool) {
+	t1 := pool.Get()
+	t2 := pool.Get()
+
+	t1.Mul(amountOut, cut.Num())
+	t1.Mul(t1, t2.SetInt64(int64(feeBasisPoints)))
+
+	t2.Mul(cut.Denom(), FeeBasisPoints10000)
+	t1.Quo(t1, t2)
+
+	amountOut.Sub(amountOut, t1)
+
+	pool.Put(t1)
+	pool.Put(t2)
+}
+
+// GrossUpForProtocolCut returns the smallest pre-cut output that still leaves at
+// least target after ApplyProtocolCut, for exact-out quotes on such forks
+func GrossUpForProtocolCut(target *big.Int, feeBasisPoints int, cut *big.Rat) *big.Int {
+	// k/d is the fraction of output taken: fee * cut / 10000
+	k := new(big.Int).Mul(big.NewInt(int64(feeBasisPoints)), cut.Num())
+	d := new(big.Int).Mul(cut.Denom(), FeeBasisPoints10000)
+
+	// g = ceil(target * d / (d - k)) always suffices; step down while a smaller g still does
+	gross := new(big.Int).Mul(target, d)
+	ceilDiv(gross, new(big.Int).Sub(d, k), new(big.Int))
+
+	candidate := new(big.Int)
+	for {
+		candidate.Sub(gross, big1)
+		net := new(big.Int).Set(candidate)
+		ApplyProtocolCut(net, feeBasisPoints, cut, GlobalBigIntPool)
+		if candidate.Sign() <= 0 || net.Cmp(target) < 0 {
+			return gross
+		}
+		gross.Set(candidate)
+	}
+}
+
+// ceilDiv sets x = ceil(x / y) for positive operands, using rem as scratch
+func ceilDiv(x, y, rem *big.Int) {
+	x.QuoRem(x, y, rem)
+	if rem.Sign() != 0 {
+		x.Add(x, big1)
+	}
 }
 
 // CalculateUniswapV2SwapAmountInto calculates swap amount and stores result in the provided big.Int
 // This version avoids allocation by reusing the provided result parameter
-func CalculateUniswapV2SwapAmount(amountIn, reserveIn, reserveOut, result *big.Int, pool *BigIntPool) {
-	CalculateSwapAmount(amountIn, reserveIn, reserveOut, result, 3, pool)
+func CalculateUniswapV2SwapAmount(amountIn, reserveIn, reserveOut, result *big.Int, pool *BigIntPool) error {
+	return CalculateSwapAmount(amountIn, reserveIn, reserveOut, result, 30, pool)
 }
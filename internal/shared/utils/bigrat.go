@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"math/big"
+	"sync"
+	"sync/atomic"
+)
+
+var GlobalBigRatPool = NewBigRatPool()
+
+// BigRatPool provides a pool of reusable big.Rat objects for decimal intermediates,
+// mirroring BigIntPool. Only scratch values belong here: a Rat handed back to a
+// caller must be allocated normally, or it is never Put.
+type BigRatPool struct {
+	pool sync.Pool
+
+	gets   atomic.Uint64
+	puts   atomic.Uint64
+	allocs atomic.Uint64
+}
+
+// NewBigRatPool creates a new BigRat pool
+func NewBigRatPool() *BigRatPool {
+	p := &BigRatPool{}
+	p.pool.New = func() interface{} {
+		p.allocs.Add(1)
+		return new(big.Rat)
+	}
+	return p
+}
+
+// Get retrieves a big.Rat from the pool
+func (p *BigRatPool) Get() *big.Rat {
+	p.gets.Add(1)
+	return p.pool.Get().(*big.Rat)
+}
+
+// Put resets x to zero and returns it to the pool
+func (p *BigRatPool) Put(x *big.Rat) {
+	if x != nil {
+		p.puts.Add(1)
+		x.SetInt64(0)
+		p.pool.Put(x)
+	}
+}
+
+// Stats returns a snapshot of the pool's counters
+func (p *BigRatPool) Stats() PoolStats {
+	puts := p.puts.Load()
+	return PoolStats{
+		Gets:   p.gets.Load(),
+		Puts:   puts,
+		Allocs: p.allocs.Load(),
+	}
+}
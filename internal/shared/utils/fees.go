@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// FeeNameCustom selects an explicit fee instead of a protocol's well-known one
+const FeeNameCustom = "custom"
+
+// MaxFeePerMille bounds a numeric fee name, at 10%
+const MaxFeePerMille = 100
+
+var (
+	// ErrUnknownFeeName is returned for a protocol name missing from the fee table
+	ErrUnknownFeeName = errors.New("unknown fee name")
+	// ErrFeeOutOfRange is returned for a numeric fee name outside [0, MaxFeePerMille]
+	ErrFeeOutOfRange = errors.New("fee out of range")
+)
+
+// protocolFees holds each protocol's swap fee in basis points (30 = 0.3%)
+var protocolFees = map[string]int{
+	"uniswap":   30,
+	"sushiswap": 30,
+	"pancake":   25,
+}
+
+// ResolveFeeName returns the fee in basis points (30 = 0.3%) for a well-known
+// protocol name, or for a numeric name in tenths of a percent, the units of the
+// FeeBasisPoints* tiers: 3 is 0.3%, 5 is 0.5% and 10 is 1%
+func ResolveFeeName(name string) (int, error) {
+	if perMille, err := strconv.Atoi(name); err == nil {
+		if perMille < 0 || perMille > MaxFeePerMille {
+			return 0, fmt.Errorf("%w: fee must be between 0 and %d, got %d", ErrFeeOutOfRange, MaxFeePerMille, perMille)
+		}
+		return perMille * 10, nil
+	}
+	fee, ok := protocolFees[name]
+	if !ok {
+		return 0, fmt.Errorf("%w %q, expected one of %v, %q or a number from 0 to %d", ErrUnknownFeeName, name, FeeNames(), FeeNameCustom, MaxFeePerMille)
+	}
+	return fee, nil
+}
+
+// FeeNames returns the known protocol names, sorted
+func FeeNames() []string {
+	names := make([]string, 0, len(protocolFees))
+	for name := range protocolFees {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"errors"
 	"math/big"
 	"testing"
 )
@@ -58,7 +59,346 @@ func TestGlobalPoolIntegration(t *testing.T) {
 	}
 }
 
+func TestCalculateSwapAmountFeeOnOutput(t *testing.T) {
+	cases := []struct {
+		name       string
+		amountIn   *big.Int
+		reserveIn  *big.Int
+		reserveOut *big.Int
+		fee        int
+	}{
+		{"balanced", big.NewInt(1_000), big.NewInt(1_000_000), big.NewInt(1_000_000), 30},
+		{"skewed", big.NewInt(50_000_000), big.NewInt(5_000_000_000), big.NewInt(100_000_000_000), 30},
+		{"custom_fee", big.NewInt(123_456), big.NewInt(9_999_999), big.NewInt(7_777_777), 25},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			amountOut := new(big.Int)
+			CalculateSwapAmountFeeOnOutput(tc.amountIn, tc.reserveIn, tc.reserveOut, amountOut, tc.fee, GlobalBigIntPool)
+
+			gross := new(big.Int).Mul(tc.amountIn, tc.reserveOut)
+			gross.Div(gross, new(big.Int).Add(tc.reserveIn, tc.amountIn))
+			expected := new(big.Int).Mul(gross, big.NewInt(int64(10000-tc.fee)))
+			expected.Div(expected, big.NewInt(10000))
+
+			if amountOut.Cmp(expected) != 0 {
+				t.Fatalf("unexpected result: got %s want %s", amountOut, expected)
+			}
+
+			inputSide := new(big.Int)
+			CalculateSwapAmount(tc.amountIn, tc.reserveIn, tc.reserveOut, inputSide, tc.fee, GlobalBigIntPool)
+			if amountOut.Cmp(inputSide) > 0 {
+				t.Fatalf("output-side fee should not exceed input-side quote: %s > %s", amountOut, inputSide)
+			}
+		})
+	}
+}
+
+func TestCalculateSwapAmount_DustAndZeroDenominator(t *testing.T) {
+	huge := new(big.Int).Exp(big.NewInt(10), big.NewInt(30), nil)
+	cases := []struct {
+		name                            string
+		amountIn, reserveIn, reserveOut *big.Int
+		want                            error
+	}{
+		{"dust_against_huge_reserves", big.NewInt(1), huge, big.NewInt(1_000), ErrOutputRoundsToZero},
+		{"empty_reserves_zero_input", big.NewInt(0), big.NewInt(0), big.NewInt(1_000), ErrZeroDenominator},
+		{"zero_input", big.NewInt(0), big.NewInt(1_000), big.NewInt(1_000), ErrOutputRoundsToZero},
+		{"ok", big.NewInt(1_000), big.NewInt(1_000_000), big.NewInt(1_000_000), nil},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, side := range []FeeSide{FeeOnInput, FeeOnOutput} {
+				amountOut := big.NewInt(-1)
+				err := CalculateSwapAmountForSide(tc.amountIn, tc.reserveIn, tc.reserveOut, amountOut, 30, side, GlobalBigIntPool)
+				if !errors.Is(err, tc.want) {
+					t.Fatalf("fee %s: expected %v, got %v", side, tc.want, err)
+				}
+				if tc.want != nil && amountOut.Sign() != 0 {
+					t.Errorf("fee %s: expected a zero output alongside the error, got %s", side, amountOut)
+				}
+			}
+		})
+	}
+}
+
+func TestCalculateSwapAmountForSide(t *testing.T) {
+	reserveIn := big.NewInt(1_000_000)
+	reserveOut := big.NewInt(2_000_000)
+	amountIn := big.NewInt(10_000)
+
+	standard := new(big.Int)
+	CalculateUniswapV2SwapAmount(amountIn, reserveIn, reserveOut, standard, GlobalBigIntPool)
+
+	viaInput := new(big.Int)
+	CalculateSwapAmountForSide(amountIn, reserveIn, reserveOut, viaInput, 30, FeeOnInput, GlobalBigIntPool)
+	if viaInput.Cmp(standard) != 0 {
+		t.Fatalf("input side should match the standard formula: got %s want %s", viaInput, standard)
+	}
+
+	viaOutput := new(big.Int)
+	CalculateSwapAmountForSide(amountIn, reserveIn, reserveOut, viaOutput, 30, FeeOnOutput, GlobalBigIntPool)
+	expected := new(big.Int)
+	CalculateSwapAmountFeeOnOutput(amountIn, reserveIn, reserveOut, expected, 30, GlobalBigIntPool)
+	if viaOutput.Cmp(expected) != 0 {
+		t.Fatalf("output side mismatch: got %s want %s", viaOutput, expected)
+	}
+}
+
+func TestParseReservesShortInput(t *testing.T) {
+	reserve0 := big.NewInt(1_234_567_890)
+	reserve1 := big.NewInt(987_654_321)
+
+	word := new(big.Int).Lsh(reserve1, 112)
+	word.Or(word, reserve0)
+	full := word.FillBytes(make([]byte, StorageWordSize))
+
+	// A trimming provider drops the two leading zero bytes
+	trimmed := full[2:]
+	if len(trimmed) != 30 {
+		t.Fatalf("expected a 30-byte input, got %d", len(trimmed))
+	}
+
+	for name, input := range map[string][]byte{"full": full, "trimmed": trimmed} {
+		got0, got1 := ParseReserves(input)
+		if got0.Cmp(reserve0) != 0 || got1.Cmp(reserve1) != 0 {
+			t.Fatalf("%s: unexpected reserves: got (%s, %s) want (%s, %s)", name, got0, got1, reserve0, reserve1)
+		}
+
+		pooled0, pooled1 := ParseReservesWithPool(input, GlobalBigIntPool)
+		if pooled0.Cmp(reserve0) != 0 || pooled1.Cmp(reserve1) != 0 {
+			t.Fatalf("%s: unexpected pooled reserves: got (%s, %s) want (%s, %s)", name, pooled0, pooled1, reserve0, reserve1)
+		}
+	}
+
+	if normalized := NormalizeStorageWord(trimmed); len(normalized) != StorageWordSize || normalized[0] != 0 || normalized[1] != 0 {
+		t.Fatalf("expected trimmed input to be left-padded with zero bytes, got %x", normalized)
+	}
+}
+
+func TestParseReservesInputLengths(t *testing.T) {
+	// reserve0 = 0x0102, reserve1 = 0x0304, blockTimestampLast = 0x05060708
+	full := make([]byte, StorageWordSize)
+	full[3], full[2], full[1], full[0] = 0x08, 0x07, 0x06, 0x05
+	full[17], full[16] = 0x04, 0x03
+	full[31], full[30] = 0x02, 0x01
+
+	tests := []struct {
+		name      string
+		input     []byte
+		reserve0  int64
+		reserve1  int64
+		timestamp uint32
+	}{
+		{"0 bytes", []byte{}, 0, 0, 0},
+		{"16 bytes", full[16:], 0x0102, 0x0304, 0},
+		{"31 bytes", full[1:], 0x0102, 0x0304, 0x060708},
+		{"32 bytes", full, 0x0102, 0x0304, 0x05060708},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reserve0, reserve1 := ParseReserves(tt.input)
+			if reserve0.Int64() != tt.reserve0 || reserve1.Int64() != tt.reserve1 {
+				t.Errorf("ParseReserves: got (%s, %s), want (%d, %d)", reserve0, reserve1, tt.reserve0, tt.reserve1)
+			}
+			_, _, timestamp := ParseReservesWithTimestamp(tt.input)
+			if timestamp != tt.timestamp {
+				t.Errorf("ParseReservesWithTimestamp: got timestamp %#x, want %#x", timestamp, tt.timestamp)
+			}
+		})
+	}
+}
+
+func TestParseReservesWithTimestamp(t *testing.T) {
+	reserve0 := big.NewInt(1_234_567_890)
+	reserve1 := big.NewInt(987_654_321)
+	const timestamp = uint32(1_700_000_000)
+
+	word := new(big.Int).Lsh(big.NewInt(int64(timestamp)), 224)
+	word.Or(word, new(big.Int).Lsh(reserve1, 112))
+	word.Or(word, reserve0)
+	full := word.FillBytes(make([]byte, StorageWordSize))
+
+	got0, got1, gotTimestamp := ParseReservesWithTimestamp(full)
+	if got0.Cmp(reserve0) != 0 || got1.Cmp(reserve1) != 0 || gotTimestamp != timestamp {
+		t.Fatalf("got (%s, %s, %d) want (%s, %s, %d)", got0, got1, gotTimestamp, reserve0, reserve1, timestamp)
+	}
+	if plain0, plain1 := ParseReserves(full); plain0.Cmp(reserve0) != 0 || plain1.Cmp(reserve1) != 0 {
+		t.Fatalf("ParseReserves changed: got (%s, %s)", plain0, plain1)
+	}
+
+	// A trimmed word without a timestamp reads as zero
+	if _, _, gotTimestamp := ParseReservesWithTimestamp(full[4:]); gotTimestamp != 0 {
+		t.Fatalf("expected a zero timestamp for a trimmed word, got %d", gotTimestamp)
+	}
+}
+
+func TestValidateStorageWord(t *testing.T) {
+	if err := ValidateStorageWord(make([]byte, 30)); err != nil {
+		t.Fatalf("short words should be accepted, got %v", err)
+	}
+	if err := ValidateStorageWord(make([]byte, StorageWordSize)); err != nil {
+		t.Fatalf("full words should be accepted, got %v", err)
+	}
+	if err := ValidateStorageWord(make([]byte, 33)); err == nil {
+		t.Fatalf("expected oversized words to be rejected")
+	}
+}
+
 // BenchmarkCalculateUniswapV2SwapAmountAllocations tests memory allocations in CalculateUniswapV2SwapAmount
+func TestCalculateUniswapV2AmountIn(t *testing.T) {
+	reserveIn := big.NewInt(1_000_000)
+	reserveOut := big.NewInt(1_000_000)
+	amountOut := big.NewInt(996)
+
+	amountIn := new(big.Int)
+	if err := CalculateUniswapV2AmountIn(amountOut, reserveIn, reserveOut, amountIn, GlobalBigIntPool); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 1_000_000 * 996 * 1000 / (999_004 * 997) + 1
+	if amountIn.Cmp(big.NewInt(1000)) != 0 {
+		t.Fatalf("unexpected amountIn: got %s want 1000", amountIn)
+	}
+
+	// The returned input must actually buy at least the requested output
+	check := new(big.Int)
+	CalculateUniswapV2SwapAmount(amountIn, reserveIn, reserveOut, check, GlobalBigIntPool)
+	if check.Cmp(amountOut) < 0 {
+		t.Fatalf("amountIn %s only yields %s, want >= %s", amountIn, check, amountOut)
+	}
+}
+
+func TestCalculateAmountInInsufficientLiquidity(t *testing.T) {
+	reserveIn := big.NewInt(1_000_000)
+	reserveOut := big.NewInt(1_000_000)
+
+	for _, side := range []FeeSide{FeeOnInput, FeeOnOutput} {
+		for _, amountOut := range []*big.Int{new(big.Int).Set(reserveOut), new(big.Int).Add(reserveOut, big.NewInt(1))} {
+			err := CalculateAmountInForSide(amountOut, reserveIn, reserveOut, new(big.Int), 30, side, GlobalBigIntPool)
+			if !errors.Is(err, ErrInsufficientLiquidity) {
+				t.Errorf("side=%s amountOut=%s: expected ErrInsufficientLiquidity, got %v", side, amountOut, err)
+			}
+		}
+	}
+}
+
+func TestCalculateAmountInFeeOnOutputRoundTrip(t *testing.T) {
+	reserveIn := big.NewInt(5_000_000)
+	reserveOut := big.NewInt(2_000_000)
+
+	for _, target := range []int64{1, 997, 12_345, 1_000_000} {
+		amountOut := big.NewInt(target)
+		amountIn := new(big.Int)
+		if err := CalculateAmountInFeeOnOutput(amountOut, reserveIn, reserveOut, amountIn, 30, GlobalBigIntPool); err != nil {
+			t.Fatalf("target %d: unexpected error: %v", target, err)
+		}
+
+		got := new(big.Int)
+		CalculateSwapAmountFeeOnOutput(amountIn, reserveIn, reserveOut, got, 30, GlobalBigIntPool)
+		if got.Cmp(amountOut) < 0 {
+			t.Fatalf("target %d: amountIn %s only yields %s", target, amountIn, got)
+		}
+
+		// One unit less must fall short, otherwise the input is not minimal
+		less := new(big.Int).Sub(amountIn, big.NewInt(1))
+		CalculateSwapAmountFeeOnOutput(less, reserveIn, reserveOut, got, 30, GlobalBigIntPool)
+		if got.Cmp(amountOut) >= 0 {
+			t.Fatalf("target %d: amountIn %s is not minimal", target, amountIn)
+		}
+	}
+}
+
+func TestCalculateSwapAmountWithMathMatches(t *testing.T) {
+	reserveIn := big.NewInt(1_000_000)
+	reserveOut := big.NewInt(2_000_000)
+
+	for _, fee := range []int{30, 50, 100, 25} {
+		expected := new(big.Int)
+		CalculateSwapAmount(big.NewInt(12_345), reserveIn, reserveOut, expected, fee, GlobalBigIntPool)
+
+		got := new(big.Int)
+		math := CalculateSwapAmountWithMath(big.NewInt(12_345), reserveIn, reserveOut, got, fee)
+		if got.Cmp(expected) != 0 {
+			t.Errorf("fee %d: expected %s, got %s", fee, expected, got)
+		}
+		if q := new(big.Int).Quo(math.Numerator, math.Denominator); q.Cmp(got) != 0 {
+			t.Errorf("fee %d: numerator/denominator = %s, want %s", fee, q, got)
+		}
+		if want := big.NewInt(12_345 * int64(10000-fee)); math.AmountInWithFee.Cmp(want) != 0 {
+			t.Errorf("fee %d: expected amountInWithFee %s, got %s", fee, want, math.AmountInWithFee)
+		}
+	}
+}
+
+func TestMaxAmountInForImpact(t *testing.T) {
+	reserveIn := big.NewInt(1_000_000_000)
+	reserveOut := big.NewInt(2_000_000_000)
+
+	for _, bps := range []int{1, 50, 100, 1_000, 5_000} {
+		amountIn := MaxAmountInForImpact(reserveIn, bps, 30)
+
+		// impact = 1 - (out * reserveIn) / (amountIn' * reserveOut), amountIn' = amountIn * 997 / 1000
+		impact := func(in *big.Int) *big.Rat {
+			out := new(big.Int)
+			CalculateSwapAmount(in, reserveIn, reserveOut, out, 30, GlobalBigIntPool)
+			exec := new(big.Rat).SetFrac(new(big.Int).Mul(out, reserveIn), new(big.Int).Mul(in, reserveOut))
+			exec.Mul(exec, big.NewRat(1000, 997))
+			return new(big.Rat).Sub(big.NewRat(1, 1), exec)
+		}
+		target := big.NewRat(int64(bps), 10_000)
+
+		// The quoted output is rounded down on-chain, so allow one unit of output
+		slack := new(big.Rat).SetFrac(new(big.Int).Mul(reserveIn, big.NewInt(1000)), new(big.Int).Mul(new(big.Int).Mul(amountIn, reserveOut), big.NewInt(997)))
+		if impact(amountIn).Cmp(new(big.Rat).Add(target, slack)) > 0 {
+			t.Errorf("bps %d: amountIn %s exceeds the target impact", bps, amountIn)
+		}
+
+		// Ten more units must exceed it, otherwise the bound is not tight
+		if impact(new(big.Int).Add(amountIn, big.NewInt(10))).Cmp(target) <= 0 {
+			t.Errorf("bps %d: amountIn %s is not the maximum", bps, amountIn)
+		}
+	}
+}
+
+func TestApplyProtocolCut(t *testing.T) {
+	// 1/6 of a 0.3% fee is 0.05% of output: 2_000_000 - 1_000 = 1_999_000
+	amountOut := big.NewInt(2_000_000)
+	ApplyProtocolCut(amountOut, 30, big.NewRat(1, 6), GlobalBigIntPool)
+	if amountOut.Cmp(big.NewInt(1_999_000)) != 0 {
+		t.Errorf("Expected 1999000, got %s", amountOut)
+	}
+
+	// The protocol's share rounds down, so tiny outputs are untouched
+	amountOut = big.NewInt(1_999)
+	ApplyProtocolCut(amountOut, 30, big.NewRat(1, 6), GlobalBigIntPool)
+	if amountOut.Cmp(big.NewInt(1_999)) != 0 {
+		t.Errorf("Expected 1999, got %s", amountOut)
+	}
+}
+
+func TestGrossUpForProtocolCutIsMinimal(t *testing.T) {
+	cut := big.NewRat(1, 6)
+
+	for _, target := range []int64{1, 1_999, 2_000, 1_999_000, 123_456_789} {
+		gross := GrossUpForProtocolCut(big.NewInt(target), 30, cut)
+
+		net := new(big.Int).Set(gross)
+		ApplyProtocolCut(net, 30, cut, GlobalBigIntPool)
+		if net.Cmp(big.NewInt(target)) < 0 {
+			t.Fatalf("target %d: gross %s only nets %s", target, gross, net)
+		}
+
+		net.Sub(gross, big.NewInt(1))
+		ApplyProtocolCut(net, 30, cut, GlobalBigIntPool)
+		if net.Cmp(big.NewInt(target)) >= 0 {
+			t.Fatalf("target %d: gross %s is not minimal", target, gross)
+		}
+	}
+}
+
 func BenchmarkCalculateUniswapV2SwapAmountAllocations(b *testing.B) {
 	reserveIn := new(big.Int).SetUint64(13_451_234_567_890)
 	reserveOut := new(big.Int).SetUint64(98_765_432_109_876)
@@ -89,3 +429,186 @@ func BenchmarkMemoryPressure(b *testing.B) {
 		GlobalBigIntPool.Put(result)
 	}
 }
+
+func TestBigIntPoolBalancedAcrossMathPaths(t *testing.T) {
+	pool := NewBigIntPool()
+	reserveIn := big.NewInt(1_000_000)
+	reserveOut := big.NewInt(2_000_000)
+	word := new(big.Int).Lsh(reserveOut, 112)
+	word.Or(word, reserveIn)
+	packed := word.FillBytes(make([]byte, StorageWordSize))
+
+	for i := 0; i < 100; i++ {
+		amountIn := big.NewInt(int64(1_000 + i))
+		out := new(big.Int)
+		// 7 isn't a precomputed multiplier, so it exercises the pooled branch
+		for _, fee := range []int{30, 7} {
+			CalculateSwapAmount(amountIn, reserveIn, reserveOut, out, fee, pool)
+			CalculateSwapAmountFeeOnOutput(amountIn, reserveIn, reserveOut, out, fee, pool)
+			CalculateAmountIn(out, reserveIn, reserveOut, new(big.Int), fee, pool)
+			CalculateAmountInFeeOnOutput(out, reserveIn, reserveOut, new(big.Int), fee, pool)
+			ApplyProtocolCut(out, fee, big.NewRat(1, 6), pool)
+		}
+		// Error paths must return their scratch values too
+		CalculateAmountIn(reserveOut, reserveIn, reserveOut, new(big.Int), 7, pool)
+		CalculateAmountInFeeOnOutput(reserveOut, reserveIn, reserveOut, new(big.Int), 7, pool)
+		ParseReservesWithPool(packed, pool)
+	}
+
+	stats := pool.Stats()
+	if stats.Gets == 0 {
+		t.Fatalf("expected the math paths to use the pool")
+	}
+	if stats.Outstanding() != 0 {
+		t.Fatalf("expected every Get to be matched by a Put, %d outstanding (%+v)", stats.Outstanding(), stats)
+	}
+	if stats.AllocsAvoided() == 0 {
+		t.Errorf("expected repeated calls to reuse pooled values, got %+v", stats)
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	huge, _ := new(big.Int).SetString("1000000000000000000000000", 10)
+	valid := map[string]*big.Int{
+		"1000":                      big.NewInt(1000),
+		"010":                       big.NewInt(10),
+		"+7":                        big.NewInt(7),
+		"-5":                        big.NewInt(-5),
+		"0x3e8":                     big.NewInt(1000),
+		"0X3E8":                     big.NewInt(1000),
+		"-0x10":                     big.NewInt(-16),
+		"0x0":                       big.NewInt(0),
+		"0xd3c21bcecceda1000000":    huge,
+		"1000000000000000000000000": huge,
+	}
+	for input, want := range valid {
+		got, err := ParseAmount(input)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", input, err)
+			continue
+		}
+		if got.Cmp(want) != 0 {
+			t.Errorf("%q: got %s, want %s", input, got, want)
+		}
+	}
+
+	for _, input := range []string{"", "0x", "-", "abc", "12ab", "0x1g", "0b101", "0o17", "1_000", "0x-5", "--5", "1e18", "1.5", " 1"} {
+		if _, err := ParseAmount(input); !errors.Is(err, ErrInvalidAmount) {
+			t.Errorf("%q: expected ErrInvalidAmount, got %v", input, err)
+		}
+	}
+}
+
+func TestFormatUnits(t *testing.T) {
+	oneEther, _ := new(big.Int).SetString("1234567890123456789", 10)
+	tests := []struct {
+		amount   *big.Int
+		decimals uint8
+		want     string
+	}{
+		{big.NewInt(1_500_000), 6, "1.500000"},
+		{big.NewInt(1), 6, "0.000001"},
+		{big.NewInt(0), 6, "0.000000"},
+		{big.NewInt(-2_500_000), 6, "-2.500000"},
+		{oneEther, 18, "1.234567890123456789"},
+		{big.NewInt(5), 18, "0.000000000000000005"},
+		{big.NewInt(42), 0, "42"},
+	}
+	for _, tt := range tests {
+		if got := FormatUnits(tt.amount, tt.decimals); got != tt.want {
+			t.Errorf("FormatUnits(%s, %d) = %q, want %q", tt.amount, tt.decimals, got, tt.want)
+		}
+	}
+}
+
+func TestParseUnits(t *testing.T) {
+	oneEther, _ := new(big.Int).SetString("1234567890123456789", 10)
+	valid := []struct {
+		input    string
+		decimals uint8
+		want     *big.Int
+	}{
+		{"1.5", 6, big.NewInt(1_500_000)},
+		{"1", 6, big.NewInt(1_000_000)},
+		{"0.000001", 6, big.NewInt(1)},
+		{"1.500000000", 6, big.NewInt(1_500_000)},
+		{"-2.5", 6, big.NewInt(-2_500_000)},
+		{"+7", 0, big.NewInt(7)},
+		{"1.234567890123456789", 18, oneEther},
+	}
+	for _, tt := range valid {
+		got, err := ParseUnits(tt.input, tt.decimals)
+		if err != nil {
+			t.Errorf("%q with %d decimals: unexpected error: %v", tt.input, tt.decimals, err)
+			continue
+		}
+		if got.Cmp(tt.want) != 0 {
+			t.Errorf("%q with %d decimals: got %s, want %s", tt.input, tt.decimals, got, tt.want)
+		}
+		if tt.want.Sign() >= 0 && tt.input[0] != '+' {
+			if back, _ := ParseUnits(FormatUnits(got, tt.decimals), tt.decimals); back.Cmp(got) != 0 {
+				t.Errorf("%q: FormatUnits round trip gave %s", tt.input, back)
+			}
+		}
+	}
+
+	for _, input := range []string{"", ".", "1.", ".5", "-", "abc", "1.2.3", "0x10", "1e18", "1_000", "--5", " 1", "1,5"} {
+		if _, err := ParseUnits(input, 6); !errors.Is(err, ErrInvalidAmount) {
+			t.Errorf("%q: expected ErrInvalidAmount, got %v", input, err)
+		}
+	}
+	for _, tt := range []struct {
+		input    string
+		decimals uint8
+	}{{"1.0000001", 6}, {"0.5", 0}} {
+		if _, err := ParseUnits(tt.input, tt.decimals); !errors.Is(err, ErrTooManyDecimals) {
+			t.Errorf("%q with %d decimals: expected ErrTooManyDecimals, got %v", tt.input, tt.decimals, err)
+		}
+	}
+}
+
+func TestBigRatPoolResetsOnPut(t *testing.T) {
+	pool := NewBigRatPool()
+	r := pool.Get()
+	r.SetFrac64(7, 3)
+	pool.Put(r)
+
+	if r.Sign() != 0 {
+		t.Fatalf("expected Put to reset the value to zero, got %s", r)
+	}
+	if stats := pool.Stats(); stats.Gets != 1 || stats.Puts != 1 || stats.Outstanding() != 0 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+// spotPriceScratch computes reserveOut/reserveIn scaled by 1e18 with two Rat
+// intermediates, the shape of a decimal price formatting path
+func spotPriceScratch(reserveIn, reserveOut *big.Int, price, scale *big.Rat, out *big.Int) {
+	price.SetFrac(reserveOut, reserveIn)
+	scale.SetInt(oneEther)
+	price.Mul(price, scale)
+	out.Quo(price.Num(), price.Denom())
+}
+
+var oneEther = new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+
+func BenchmarkSpotPriceRatAlloc(b *testing.B) {
+	reserveIn, reserveOut := big.NewInt(1_000_000_000), big.NewInt(2_500_000_000_000)
+	out := new(big.Int)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		spotPriceScratch(reserveIn, reserveOut, new(big.Rat), new(big.Rat), out)
+	}
+}
+
+func BenchmarkSpotPriceRatPooled(b *testing.B) {
+	reserveIn, reserveOut := big.NewInt(1_000_000_000), big.NewInt(2_500_000_000_000)
+	out := new(big.Int)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		price, scale := GlobalBigRatPool.Get(), GlobalBigRatPool.Get()
+		spotPriceScratch(reserveIn, reserveOut, price, scale, out)
+		GlobalBigRatPool.Put(price)
+		GlobalBigRatPool.Put(scale)
+	}
+}
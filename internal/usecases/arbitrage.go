@@ -0,0 +1,163 @@
+package estimate
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	apperrors "bigswapenergy/internal/shared/errors"
+	"bigswapenergy/internal/shared/logger"
+	"bigswapenergy/internal/shared/timing"
+	"bigswapenergy/internal/shared/utils"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+// ArbitrageRequest describes a round trip: src -> dst through PoolA, then dst -> src through PoolB
+type ArbitrageRequest struct {
+	PoolA     string
+	PoolB     string
+	SrcToken  string
+	DstToken  string
+	SrcAmount *big.Int
+}
+
+// ArbitrageResult holds the outcome of an arbitrage round trip simulation
+type ArbitrageResult struct {
+	// IntermediateAmount is the dst amount received from PoolA
+	IntermediateAmount *big.Int
+	// FinalAmount is the src amount received back from PoolB
+	FinalAmount *big.Int
+	// Profit is FinalAmount - SrcAmount; negative when the round trip loses money
+	Profit *big.Int
+	// BlockNumber is the block both pools were read at
+	BlockNumber uint64
+}
+
+// EstimateArbitrage simulates swapping src -> dst on PoolA and back on PoolB, reading
+// both pools concurrently at the same block
+func (s *EstimateServiceImpl) EstimateArbitrage(ctx context.Context, req ArbitrageRequest) (*ArbitrageResult, error) {
+	if req.PoolA == "" || req.PoolB == "" {
+		return nil, fmt.Errorf("%w: both pool addresses are required", apperrors.ErrValidation)
+	}
+	if req.SrcToken == "" {
+		return nil, fmt.Errorf("%w: source token address is required", apperrors.ErrValidation)
+	}
+	if req.DstToken == "" {
+		return nil, fmt.Errorf("%w: destination token address is required", apperrors.ErrValidation)
+	}
+	if req.SrcAmount == nil || req.SrcAmount.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: source amount must be positive", apperrors.ErrValidation)
+	}
+
+	if err := validateAddressFormat("pool_a", req.PoolA); err != nil {
+		return nil, err
+	}
+	if err := validateAddressFormat("pool_b", req.PoolB); err != nil {
+		return nil, err
+	}
+	if err := validateAddressFormat("source token", req.SrcToken); err != nil {
+		return nil, err
+	}
+	if err := validateAddressFormat("destination token", req.DstToken); err != nil {
+		return nil, err
+	}
+
+	poolA := common.HexToAddress(req.PoolA)
+	poolB := common.HexToAddress(req.PoolB)
+	src := common.HexToAddress(req.SrcToken)
+	dst := common.HexToAddress(req.DstToken)
+
+	if bytes.Equal(src[:], dst[:]) {
+		return nil, fmt.Errorf("%w: source and destination tokens cannot be the same", apperrors.ErrBusinessRule)
+	}
+	if bytes.Equal(poolA[:], poolB[:]) {
+		return nil, fmt.Errorf("%w: arbitrage requires two distinct pools", apperrors.ErrBusinessRule)
+	}
+
+	log := logger.FromContext(ctx, s.logger)
+	log.Info("Processing arbitrage estimation request",
+		zap.String("pool_a", poolA.Hex()),
+		zap.String("pool_b", poolB.Hex()),
+		zap.String("src_token", src.Hex()),
+		zap.String("dst_token", dst.Hex()),
+		zap.String("src_amount", req.SrcAmount.String()),
+	)
+
+	endBlock := timing.Start(ctx, timing.PhaseBlock)
+	blockNumber, err := s.uniswapV2Client.GetLatestBlockNumber(ctx)
+	endBlock()
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to connect to blockchain network: %v", apperrors.ErrExternalService, err)
+	}
+
+	// Both legs read from the same block so the round trip reflects one consistent state
+	var (
+		wg               sync.WaitGroup
+		legA, legB       *swapState
+		legAErr, legBErr error
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		legA, legAErr = s.readLeg(ctx, poolA, src, dst, blockNumber)
+	}()
+	go func() {
+		defer wg.Done()
+		legB, legBErr = s.readLeg(ctx, poolB, dst, src, blockNumber)
+	}()
+	wg.Wait()
+
+	if legAErr != nil {
+		return nil, fmt.Errorf("pool_a: %w", legAErr)
+	}
+	if legBErr != nil {
+		return nil, fmt.Errorf("pool_b: %w", legBErr)
+	}
+
+	intermediate, err := legA.quote(req.SrcAmount)
+	if err != nil {
+		return nil, fmt.Errorf("pool_a: %w", err)
+	}
+	final, err := legB.quote(intermediate)
+	if err != nil {
+		return nil, fmt.Errorf("pool_b: %w", err)
+	}
+	log.Debug("Computed arbitrage legs",
+		zap.Uint64("block", blockNumber),
+		zap.Stringer("pool_a_reserve_in", legA.reserveIn),
+		zap.Stringer("pool_a_reserve_out", legA.reserveOut),
+		zap.Stringer("pool_b_reserve_in", legB.reserveIn),
+		zap.Stringer("pool_b_reserve_out", legB.reserveOut),
+		zap.Stringer("intermediate_amount", intermediate),
+		zap.Stringer("final_amount", final),
+	)
+
+	return &ArbitrageResult{
+		IntermediateAmount: intermediate,
+		FinalAmount:        final,
+		Profit:             new(big.Int).Sub(final, req.SrcAmount),
+		BlockNumber:        blockNumber,
+	}, nil
+}
+
+// readLeg reads a single pool at blockNumber and prepares it for quoting src -> dst
+func (s *EstimateServiceImpl) readLeg(ctx context.Context, pool, src, dst common.Address, blockNumber uint64) (*swapState, error) {
+	reserveIn, reserveOut, _, err := s.readOrientedReserves(ctx, pool, src, dst, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	return &swapState{
+		pool:           pool,
+		src:            src,
+		dst:            dst,
+		blockNumber:    blockNumber,
+		reserveIn:      reserveIn,
+		reserveOut:     reserveOut,
+		feeBasisPoints: defaultFeeBasisPoints,
+		feeSide:        utils.FeeOnInput,
+	}, nil
+}
@@ -0,0 +1,86 @@
+package estimate
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	apperrors "bigswapenergy/internal/shared/errors"
+)
+
+// MaxGeometricCount bounds the number of sizes in a geometric sweep
+const MaxGeometricCount = 32
+
+// GeometricAmounts returns count input sizes start, start*factor, start*factor^2, ...
+// for a log-scale liquidity profile. factor must exceed 1 so the sizes are strictly
+// increasing, as EstimateLiquidityCurve requires.
+func GeometricAmounts(start *big.Int, factor int64, count int) ([]*big.Int, error) {
+	if start == nil || start.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: sweep start must be positive", apperrors.ErrValidation)
+	}
+	if factor <= 1 {
+		return nil, fmt.Errorf("%w: sweep factor must be greater than 1", apperrors.ErrValidation)
+	}
+	if count < 1 || count > MaxGeometricCount {
+		return nil, fmt.Errorf("%w: sweep count must be between 1 and %d", apperrors.ErrValidation, MaxGeometricCount)
+	}
+
+	step := big.NewInt(factor)
+	amounts := make([]*big.Int, count)
+	amounts[0] = new(big.Int).Set(start)
+	for i := 1; i < count; i++ {
+		amounts[i] = new(big.Int).Mul(amounts[i-1], step)
+	}
+	return amounts, nil
+}
+
+// CurvePoint is one sample of the liquidity curve
+type CurvePoint struct {
+	AmountIn  *big.Int
+	AmountOut *big.Int
+	// MarginalPrice approximates d(amountOut)/d(amountIn) between this point and the
+	// previous one (the origin for the first point)
+	MarginalPrice *big.Rat
+}
+
+// EstimateLiquidityCurve quotes each of req.SrcAmounts, which must be strictly
+// increasing, against a single reserve read and returns the marginal price at each
+// size via successive differences
+func (s *EstimateServiceImpl) EstimateLiquidityCurve(ctx context.Context, req EstimateRequest) ([]CurvePoint, error) {
+	if len(req.SrcAmounts) == 0 {
+		return nil, fmt.Errorf("%w: at least one source amount is required", apperrors.ErrValidation)
+	}
+	if err := ValidateAndNormalize(&req); err != nil {
+		return nil, err
+	}
+	for i, amount := range req.SrcAmounts {
+		if i > 0 && amount.Cmp(req.SrcAmounts[i-1]) <= 0 {
+			return nil, fmt.Errorf("%w: source amounts must be strictly increasing", apperrors.ErrValidation)
+		}
+	}
+
+	state, err := s.loadSwapState(ctx, req, req.SrcAmounts[0])
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]CurvePoint, len(req.SrcAmounts))
+	prevIn, prevOut := new(big.Int), new(big.Int)
+	for i, amountIn := range req.SrcAmounts {
+		amountOut, err := state.quote(amountIn)
+		if err != nil {
+			return nil, err
+		}
+
+		deltaOut := new(big.Int).Sub(amountOut, prevOut)
+		deltaIn := new(big.Int).Sub(amountIn, prevIn)
+		points[i] = CurvePoint{
+			AmountIn:      amountIn,
+			AmountOut:     amountOut,
+			MarginalPrice: new(big.Rat).SetFrac(deltaOut, deltaIn),
+		}
+		prevIn, prevOut = amountIn, amountOut
+	}
+
+	return points, nil
+}
@@ -1,116 +1,1152 @@
 package estimate
 
 import (
-	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"math/big"
+	"sort"
+	"strconv"
+	"time"
 
 	"bigswapenergy/internal/infrastructure/uniswap_v2"
 	apperrors "bigswapenergy/internal/shared/errors"
+	"bigswapenergy/internal/shared/logger"
+	"bigswapenergy/internal/shared/rpcbudget"
+	"bigswapenergy/internal/shared/timing"
 	"bigswapenergy/internal/shared/utils"
 
 	"github.com/ethereum/go-ethereum/common"
 	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
 )
 
+// defaultFeeBasisPoints is the standard Uniswap V2 0.3% fee. Fees throughout are
+// in basis points, 1/10000ths.
+const defaultFeeBasisPoints = 30
+
+// MaxRequestFeeBasisPoints bounds a fee override passed with a request, at 10%
+const MaxRequestFeeBasisPoints = 1000
+
+// FeeSource reports where a quote's fee came from
+type FeeSource string
+
+const (
+	// FeeSourceRequest is a fee override passed with the request
+	FeeSourceRequest FeeSource = "request"
+	// FeeSourceConfig is the factory's configured fee
+	FeeSourceConfig FeeSource = "config"
+	// FeeSourceOnChain is the fee read from the pool through the factory's fee method
+	FeeSourceOnChain FeeSource = "onchain"
+	// FeeSourceDefault is the standard 0.3% fee, used for a pool given by address
+	FeeSourceDefault FeeSource = "default"
+)
+
+// poolFee is a fee in basis points and where it came from
+type poolFee struct {
+	basisPoints int
+	source      FeeSource
+}
+
+// MaxBlockOffset bounds EstimateRequest.BlockOffset so reads stay within the
+// recent state that non-archive nodes retain
+const MaxBlockOffset = 128
+
+// ErrOutputRoundsToZero marks a dust input whose output truncates to zero
+var ErrOutputRoundsToZero = utils.ErrOutputRoundsToZero
+
+// ErrReserveImbalance marks a pool whose reserves exceed EstimateRequest.MaxReserveRatio
+var ErrReserveImbalance = errors.New("pool reserves are extremely imbalanced")
+
+// QuoteStatus classifies one entry of a batch quote
+type QuoteStatus string
+
+const (
+	QuoteStatusOK       QuoteStatus = "ok"
+	QuoteStatusDustZero QuoteStatus = "dust_zero"
+	QuoteStatusError    QuoteStatus = "error"
+)
+
+// QuoteItem is one entry of a batch quote; AmountOut is nil unless Status is ok
+type QuoteItem struct {
+	AmountIn  *big.Int
+	AmountOut *big.Int
+	Status    QuoteStatus
+	Err       error
+}
+
+// EstimateRequest describes a single swap estimation
+type EstimateRequest struct {
+	PoolAddress string
+	SrcToken    string
+	DstToken    string
+	SrcAmount   *big.Int
+
+	// SrcAmounts optionally lists several input amounts to quote against a
+	// single reserve read. When set, it takes precedence over SrcAmount.
+	SrcAmounts []*big.Int
+
+	// Factory names a registered factory; when set the pool is derived from
+	// the factory and token pair instead of being passed explicitly
+	Factory string
+
+	// FeeSide selects whether the pool fee is charged on the input (standard V2) or output leg
+	FeeSide utils.FeeSide
+
+	// FeeBasisPoints overrides the pool's fee for both directions; nil keeps the
+	// factory or default fee. In basis points (30 = 0.3%), at most MaxRequestFeeBasisPoints.
+	FeeBasisPoints *int
+
+	// FeeName selects a protocol's well-known fee (e.g. "uniswap"), or a numeric fee
+	// in tenths of a percent (3 = 0.3%), and resolves into FeeBasisPoints during
+	// validation. "custom" requires FeeBasisPoints to be set.
+	FeeName string
+
+	// FeeBasisPoints0To1 and FeeBasisPoints1To0 override the fee for a single swap
+	// direction on asymmetric-fee pools, taking precedence over FeeBasisPoints. Also in
+	// basis points, so fee_bps_0to1=30 is 0.3%.
+	FeeBasisPoints0To1 *int
+	FeeBasisPoints1To0 *int
+
+	// DstAmount requests an exact-out quote: the input needed to receive exactly
+	// this much of the destination token. Mutually exclusive with the src amounts.
+	DstAmount *big.Int
+
+	// BlockOffset reads reserves this many blocks behind head instead of at head,
+	// for quotes insulated from the newest, possibly reorged block
+	BlockOffset uint64
+
+	// Block reads reserves at this block instead of at head; nil quotes at head.
+	// Blocks older than the node's retained state need an archive node.
+	Block *uint64
+
+	// ItemStatus quotes every SrcAmounts entry independently, reporting dust and
+	// invalid amounts per item instead of failing the whole request
+	ItemStatus bool
+
+	// ShowMath returns the intermediates of the output formula for auditing. Only
+	// supported for a single input amount with the fee charged on input.
+	ShowMath bool
+
+	// PriceImpact reports the quote's price impact in EstimateResult.PriceImpactBps.
+	// Only supported for a single exact-in amount.
+	PriceImpact bool
+
+	// SlippageBps, when set, adds EstimateResult.AmountOutMin: the output less this
+	// tolerance in 1/10000ths, for a swap's amountOutMin. Between 0 and 10000, and
+	// only supported for a single exact-in amount.
+	SlippageBps *int
+
+	// FeeTiers, when set and the pool's fee is not known from the request or a
+	// factory, quotes each of these fees, in basis points, instead of assuming the default.
+	// Only supported for a single input amount.
+	FeeTiers []int
+
+	// AllowIdentity quotes src == dst as a pass-through that returns the amount
+	// unchanged without reading the chain, instead of rejecting it. Route builders
+	// use it for degenerate hops.
+	AllowIdentity bool
+
+	// MaxReserveRatio rejects pools whose larger reserve exceeds the smaller by
+	// more than this factor; 0 disables the check. With ReserveRatioWarnOnly the
+	// quote is returned with a warning instead.
+	MaxReserveRatio      uint64
+	ReserveRatioWarnOnly bool
+
+	// MaxReserveStaleness warns when the pool's reserves were last updated longer
+	// than this before the quote; 0 disables it. The pair's blockTimestampLast is
+	// compared against the wall clock, a stand-in for the head block's time that
+	// needs no extra read, so quotes at an explicit Block are not checked.
+	MaxReserveStaleness time.Duration
+
+	// EmptyReservesRetry, when positive, re-reads a pool whose reserves are empty
+	// at head once after this delay, at the newest head. Reads at a BlockOffset
+	// or Block are not retried.
+	EmptyReservesRetry time.Duration
+
+	// HumanAmounts asks for outputs in whole destination tokens, formatted to
+	// exactly the token's decimals. DstDecimals supplies them and overrides the
+	// token; when nil they are read from its decimals(). Only supported for
+	// exact-in plain amounts.
+	HumanAmounts bool
+	DstDecimals  *uint8
+
+	// SrcTokenAmount is a single exact-in amount in whole source tokens, e.g.
+	// "1.5", given instead of SrcAmount. It is scaled by the source token's
+	// decimals() before quoting and rejected if more precise than they allow.
+	SrcTokenAmount string
+}
+
+// EstimateResult holds the outcome of a swap estimation
+type EstimateResult struct {
+	AmountOut *big.Int
+
+	// AmountsOut holds one output per EstimateRequest.SrcAmounts entry
+	AmountsOut []*big.Int
+
+	// Items holds one classified entry per input amount when EstimateRequest.ItemStatus is set
+	Items []QuoteItem
+
+	// AmountIn holds the required input for an exact-out request
+	AmountIn *big.Int
+
+	// BlockNumber is the block the reserves were read at
+	BlockNumber uint64
+
+	// PoolAddress is the checksummed pool quoted, derived from the factory when
+	// the request named one instead of a pool
+	PoolAddress string
+
+	// Math holds the formula intermediates when EstimateRequest.ShowMath is set,
+	// including the factory protocol cut when one applies
+	Math *utils.SwapMath
+
+	// PriceImpactBps is the quote's price impact against the mid price, in
+	// 1/10000ths, when EstimateRequest.PriceImpact is set; see utils.PriceImpactBps
+	PriceImpactBps *int
+
+	// AmountOutMin is AmountOut less EstimateRequest.SlippageBps when that is set;
+	// see utils.MinAmountOut
+	AmountOutMin *big.Int
+
+	// Tiers holds one quote per fee tier when EstimateRequest.FeeTiers is set, ranked
+	// by output. FeeAssumed reports whether they are guesses; when the fee was
+	// known there is a single tier at that fee.
+	Tiers      []FeeTierQuote
+	FeeAssumed bool
+
+	// Warnings describe conditions the caller should know about but that did not
+	// fail the quote, such as a reserve imbalance in warn-only mode
+	Warnings []string
+
+	// QuoteID identifies the quote's inputs and block; see QuoteKey.ID. Empty for
+	// identity and fee tier quotes.
+	QuoteID string
+
+	// EffectiveFeeBasisPoints is the fee the quote applied, in basis points, and
+	// FeeSource where it came from. Unset for identity and fee tier quotes.
+	EffectiveFeeBasisPoints int
+	FeeSource               FeeSource
+
+	// ReserveAgeBlocks is how many blocks before BlockNumber the reserves were
+	// read: 0 for fresh reserves, higher when served from the reserves cache
+	ReserveAgeBlocks uint64
+
+	// DstDecimals is the destination token's decimals when EstimateRequest.HumanAmounts
+	// is set; nil when the token has none usable, with a warning explaining why
+	DstDecimals *uint8
+}
+
+// FeeTierQuote is the output for one fee tier
+type FeeTierQuote struct {
+	FeeBasisPoints int
+	AmountOut      *big.Int
+}
+
 // EstimateService defines the interface for swap estimation operations
 type EstimateService interface {
 	// EstimateSwapAmount calculates the estimated destination amount for a Uniswap V2 swap
 	// based on the latest blockchain state
 	EstimateSwapAmount(ctx context.Context, poolAddress, srcToken, dstToken string, srcAmount *big.Int) (*big.Int, error)
+
+	// EstimateSwapAmountIn calculates the source amount required to receive exactly dstAmount
+	EstimateSwapAmountIn(ctx context.Context, poolAddress, srcToken, dstToken string, dstAmount *big.Int) (*big.Int, error)
+
+	// EstimateSwapAmountPath calculates the output of swapping srcAmount through
+	// pools in order, plus each hop's output
+	EstimateSwapAmountPath(ctx context.Context, pools, tokens []string, srcAmount *big.Int) (*big.Int, []*big.Int, error)
+
+	// EstimateSwap calculates the estimated destination amount for the given request
+	EstimateSwap(ctx context.Context, req EstimateRequest) (*EstimateResult, error)
+
+	// EstimateArbitrage simulates a round trip through two pools for the same pair
+	EstimateArbitrage(ctx context.Context, req ArbitrageRequest) (*ArbitrageResult, error)
+
+	// EstimateRoute walks a multi-hop route, feeding each pool's output into the next
+	EstimateRoute(ctx context.Context, req RouteRequest) (*RouteResult, error)
+
+	// EstimateLiquidityCurve returns the output and marginal price for a sweep of input sizes
+	EstimateLiquidityCurve(ctx context.Context, req EstimateRequest) ([]CurvePoint, error)
+
+	// EstimateTwoWayQuote quotes a pool in both directions from a single reserve read
+	EstimateTwoWayQuote(ctx context.Context, req TwoWayQuoteRequest) (*TwoWayQuoteResult, error)
+
+	// EstimateMaxForImpact returns the largest input that keeps price impact within a target
+	EstimateMaxForImpact(ctx context.Context, req MaxImpactRequest) (*MaxImpactResult, error)
+
+	// CheckPool reports whether a pool can be quoted for a pair, with a reason when it cannot
+	CheckPool(ctx context.Context, req PoolCheckRequest) (*PoolCheck, error)
+
+	// ReadPoolTokens returns the pool's token0 and token1 so clients can orient src and dst
+	ReadPoolTokens(ctx context.Context, poolAddress string) (*PoolTokens, error)
+
+	// ReadRawPoolStorage returns the pool's token and reserves storage words unparsed
+	ReadRawPoolStorage(ctx context.Context, poolAddress string) (*RawPoolStorage, error)
+
+	// ReadPool returns one pool's tokens and reserves, optionally at a past block
+	ReadPool(ctx context.Context, req PoolRequest) (*PoolResult, error)
+
+	// ReadPools returns tokens and reserves for many pools, read at a single block
+	ReadPools(ctx context.Context, poolAddresses []string) (*PoolsResult, error)
+
+	// ReadPoolInvariant returns the pool's k and, for a hypothetical swap, k afterwards
+	ReadPoolInvariant(ctx context.Context, req InvariantRequest) (*PoolInvariant, error)
+
+	// ReadPoolPrice returns the pool's spot price and, on request, its reciprocal
+	ReadPoolPrice(ctx context.Context, req PriceRequest) (*PoolPrice, error)
 }
 
 // EstimateServiceImpl implements swap estimation operations
 type EstimateServiceImpl struct {
 	uniswapV2Client uniswap_v2.UniswapV2Client
+	factories       *uniswap_v2.FactoryRegistry
 	logger          *zap.Logger
+
+	// flights shares pool reads between concurrent requests; nil disables it
+	flights *singleflight.Group
 }
 
-// NewEstimateService creates a new estimate service
+// NewEstimateService creates a new estimate service. factories may be nil, in
+// which case requests must always name the pool explicitly.
 func NewEstimateService(
 	uniswapV2Client uniswap_v2.UniswapV2Client,
+	factories *uniswap_v2.FactoryRegistry,
 	logger *zap.Logger,
 ) EstimateService {
 	return &EstimateServiceImpl{
 		uniswapV2Client: uniswapV2Client,
+		factories:       factories,
 		logger:          logger,
 	}
 }
 
+// EnableSingleFlight makes concurrent requests for the same pool at the same block
+// share one read of its tokens and reserves, whatever their direction or amount.
+// Waiters get the leader's result, including its error, but stop waiting when their
+// own context ends. When the read failed only because the leader's request was
+// cancelled, timed out or ran out of RPC budget, waiters read the pool themselves.
+// Must be called before the service is used.
+func (s *EstimateServiceImpl) EnableSingleFlight() {
+	s.flights = &singleflight.Group{}
+}
+
 // EstimateSwapAmount calculates the estimated destination amount for a Uniswap V2 swap
 // based on the latest blockchain state
 func (s *EstimateServiceImpl) EstimateSwapAmount(ctx context.Context, poolAddress, srcToken, dstToken string, srcAmount *big.Int) (*big.Int, error) {
-	if poolAddress == "" {
-		return nil, fmt.Errorf("%w: pool address is required", apperrors.ErrValidation)
+	result, err := s.EstimateSwap(ctx, EstimateRequest{
+		PoolAddress: poolAddress,
+		SrcToken:    srcToken,
+		DstToken:    dstToken,
+		SrcAmount:   srcAmount,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.AmountOut, nil
+}
+
+// EstimateSwapAmountIn calculates the source amount required to receive exactly dstAmount
+// based on the latest blockchain state
+func (s *EstimateServiceImpl) EstimateSwapAmountIn(ctx context.Context, poolAddress, srcToken, dstToken string, dstAmount *big.Int) (*big.Int, error) {
+	if dstAmount == nil {
+		return nil, fmt.Errorf("%w: destination amount is required", apperrors.ErrValidation)
+	}
+	result, err := s.EstimateSwap(ctx, EstimateRequest{
+		PoolAddress: poolAddress,
+		SrcToken:    srcToken,
+		DstToken:    dstToken,
+		DstAmount:   dstAmount,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.AmountIn, nil
+}
+
+// EstimateSwapAmountPath calculates the output of swapping srcAmount of tokens[0]
+// through pools in order, pools[i] swapping tokens[i] for tokens[i+1], and returns
+// the final output plus each hop's output. It is EstimateRoute without partial results.
+func (s *EstimateServiceImpl) EstimateSwapAmountPath(ctx context.Context, pools, tokens []string, srcAmount *big.Int) (*big.Int, []*big.Int, error) {
+	result, err := s.EstimateRoute(ctx, RouteRequest{Pools: pools, Tokens: tokens, SrcAmount: srcAmount})
+	if err != nil {
+		return nil, nil, err
+	}
+	hopAmounts := make([]*big.Int, len(result.Hops))
+	for i, hop := range result.Hops {
+		hopAmounts[i] = hop.AmountOut
+	}
+	return result.AmountOut, hopAmounts, nil
+}
+
+// EstimateSwap calculates the estimated destination amount for the given request
+// based on the latest blockchain state
+func (s *EstimateServiceImpl) EstimateSwap(ctx context.Context, req EstimateRequest) (*EstimateResult, error) {
+	if err := ValidateAndNormalize(&req); err != nil {
+		return nil, err
+	}
+	if req.SrcTokenAmount != "" {
+		if err := s.resolveSrcTokenAmount(ctx, &req); err != nil {
+			return nil, err
+		}
+	}
+	result, err := s.estimateSwap(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if req.SlippageBps != nil {
+		result.AmountOutMin = utils.MinAmountOut(result.AmountOut, *req.SlippageBps)
+	}
+	if req.HumanAmounts {
+		if err := s.resolveDstDecimals(ctx, req, result); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// resolveDstDecimals sets result.DstDecimals from the request, or from the
+// destination token's decimals() when the client did not supply them. A token
+// without usable decimals() leaves the amounts raw, with a warning saying why.
+func (s *EstimateServiceImpl) resolveDstDecimals(ctx context.Context, req EstimateRequest, result *EstimateResult) error {
+	if req.DstDecimals != nil {
+		result.DstDecimals = req.DstDecimals
+		return nil
+	}
+	decimals, err := s.uniswapV2Client.LoadTokenDecimals(ctx, common.HexToAddress(req.DstToken))
+	if errors.Is(err, uniswap_v2.ErrNoDecimals) || errors.Is(err, uniswap_v2.ErrInvalidDecimals) {
+		result.Warnings = append(result.Warnings, "destination token has no usable decimals(); amounts are in base units")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%w: unable to read token decimals: %v", apperrors.ErrExternalService, err)
+	}
+	result.DstDecimals = &decimals
+	return nil
+}
+
+// resolveSrcTokenAmount scales SrcTokenAmount into SrcAmount using the source
+// token's decimals(), which the cached client reads once per token
+func (s *EstimateServiceImpl) resolveSrcTokenAmount(ctx context.Context, req *EstimateRequest) error {
+	decimals, err := s.uniswapV2Client.LoadTokenDecimals(ctx, common.HexToAddress(req.SrcToken))
+	if errors.Is(err, uniswap_v2.ErrNoDecimals) || errors.Is(err, uniswap_v2.ErrInvalidDecimals) {
+		return fmt.Errorf("%w: source token has no usable decimals(); give src_amount in base units", apperrors.ErrValidation)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: unable to read token decimals: %v", apperrors.ErrExternalService, err)
+	}
+
+	amount, err := utils.ParseUnits(req.SrcTokenAmount, decimals)
+	if errors.Is(err, utils.ErrTooManyDecimals) {
+		return fmt.Errorf("%w: source amount %q has more fractional digits than the token's %d decimals", apperrors.ErrValidation, req.SrcTokenAmount, decimals)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: source amount must be a decimal number of tokens, got %q", apperrors.ErrValidation, req.SrcTokenAmount)
+	}
+	req.SrcAmount, req.SrcTokenAmount = amount, ""
+	return req.validateAmounts()
+}
+
+// estimateSwap quotes a validated request
+func (s *EstimateServiceImpl) estimateSwap(ctx context.Context, req EstimateRequest) (*EstimateResult, error) {
+	if req.SrcToken == req.DstToken {
+		return identityQuote(req), nil
+	}
+	if req.DstAmount != nil {
+		return s.estimateExactOut(ctx, req)
+	}
+
+	srcAmounts := req.srcAmounts()
+	if req.ItemStatus {
+		return s.estimateItems(ctx, req, srcAmounts)
+	}
+	if len(req.FeeTiers) > 0 {
+		return s.estimateFeeTiers(ctx, req)
+	}
+
+	state, err := s.loadSwapState(ctx, req, srcAmounts[0])
+	if err != nil {
+		return nil, err
+	}
+
+	log := logger.FromContext(ctx, s.logger)
+	amountsOut := make([]*big.Int, len(srcAmounts))
+	for i, srcAmount := range srcAmounts {
+		amountOut, err := state.quote(srcAmount)
+		if err != nil {
+			return nil, err
+		}
+		log.Debug("Computed quote",
+			zap.Stringer("amount_in", srcAmount),
+			zap.Stringer("amount_out", amountOut),
+		)
+		amountsOut[i] = amountOut
+	}
+
+	result := &EstimateResult{
+		AmountOut:               amountsOut[0],
+		BlockNumber:             state.blockNumber,
+		PoolAddress:             state.pool.Hex(),
+		Warnings:                state.warnings,
+		ReserveAgeBlocks:        state.reserveAge,
+		EffectiveFeeBasisPoints: state.feeBasisPoints,
+		FeeSource:               state.feeSource,
+		QuoteID:                 state.quoteID(false, srcAmounts),
+	}
+	if len(req.SrcAmounts) > 0 {
+		result.AmountsOut = amountsOut
+	}
+	if req.PriceImpact {
+		impact := utils.PriceImpactBps(srcAmounts[0], amountsOut[0], state.reserveIn, state.reserveOut)
+		result.PriceImpactBps = &impact
+	}
+	if req.ShowMath {
+		beforeCut := new(big.Int)
+		math := utils.CalculateSwapAmountWithMath(srcAmounts[0], state.reserveIn, state.reserveOut, beforeCut, state.feeBasisPoints)
+		if state.protocolCut != nil {
+			math.AmountOutBeforeCut = beforeCut
+			math.ProtocolCut = new(big.Int).Sub(beforeCut, amountsOut[0])
+		}
+		result.Math = &math
+	}
+	return result, nil
+}
+
+// identityQuote returns a src == dst request's amounts unchanged. No pool is read,
+// so BlockNumber and PoolAddress are left empty.
+func identityQuote(req EstimateRequest) *EstimateResult {
+	if req.DstAmount != nil {
+		return &EstimateResult{AmountIn: new(big.Int).Set(req.DstAmount)}
+	}
+	result := &EstimateResult{AmountOut: new(big.Int).Set(req.SrcAmount)}
+	for _, amount := range req.SrcAmounts {
+		result.AmountsOut = append(result.AmountsOut, new(big.Int).Set(amount))
+	}
+	return result
+}
+
+// estimateItems quotes each amount against one reserve read, classifying each
+// entry instead of failing on the first dust or invalid amount
+func (s *EstimateServiceImpl) estimateItems(ctx context.Context, req EstimateRequest, srcAmounts []*big.Int) (*EstimateResult, error) {
+	state, err := s.loadSwapState(ctx, req, srcAmounts[0])
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]QuoteItem, len(srcAmounts))
+	for i, srcAmount := range srcAmounts {
+		items[i] = QuoteItem{AmountIn: srcAmount}
+		if srcAmount == nil || srcAmount.Sign() <= 0 {
+			items[i].Status = QuoteStatusError
+			items[i].Err = fmt.Errorf("%w: source amount must be positive", apperrors.ErrValidation)
+			continue
+		}
+
+		amountOut, err := state.quote(srcAmount)
+		switch {
+		case errors.Is(err, ErrOutputRoundsToZero):
+			items[i].Status = QuoteStatusDustZero
+			items[i].Err = err
+		case err != nil:
+			items[i].Status = QuoteStatusError
+			items[i].Err = err
+		default:
+			items[i].Status = QuoteStatusOK
+			items[i].AmountOut = amountOut
+		}
+	}
+
+	return &EstimateResult{
+		Items:                   items,
+		BlockNumber:             state.blockNumber,
+		PoolAddress:             state.pool.Hex(),
+		Warnings:                state.warnings,
+		ReserveAgeBlocks:        state.reserveAge,
+		EffectiveFeeBasisPoints: state.feeBasisPoints,
+		FeeSource:               state.feeSource,
+		QuoteID:                 state.quoteID(false, srcAmounts),
+	}, nil
+}
+
+// estimateFeeTiers quotes every assumed fee tier against one reserve read when the
+// request and pool leave the fee unknown, or the known fee alone otherwise
+func (s *EstimateServiceImpl) estimateFeeTiers(ctx context.Context, req EstimateRequest) (*EstimateResult, error) {
+	state, err := s.loadSwapState(ctx, req, req.SrcAmount)
+	if err != nil {
+		return nil, err
 	}
-	if srcToken == "" {
-		return nil, fmt.Errorf("%w: source token address is required", apperrors.ErrValidation)
+
+	result := &EstimateResult{BlockNumber: state.blockNumber, PoolAddress: state.pool.Hex(), Warnings: state.warnings}
+	fees := []int{state.feeBasisPoints}
+	if !req.feeKnown() {
+		fees = req.FeeTiers
+		result.FeeAssumed = true
 	}
-	if dstToken == "" {
-		return nil, fmt.Errorf("%w: destination token address is required", apperrors.ErrValidation)
+
+	for _, fee := range fees {
+		state.feeBasisPoints = fee
+		amountOut, err := state.quote(req.SrcAmount)
+		if err != nil {
+			return nil, fmt.Errorf("fee tier %d: %w", fee, err)
+		}
+		result.Tiers = append(result.Tiers, FeeTierQuote{FeeBasisPoints: fee, AmountOut: amountOut})
 	}
-	if srcAmount == nil || srcAmount.Sign() <= 0 {
-		return nil, fmt.Errorf("%w: source amount must be positive", apperrors.ErrValidation)
+	sort.SliceStable(result.Tiers, func(i, j int) bool {
+		return result.Tiers[i].AmountOut.Cmp(result.Tiers[j].AmountOut) > 0
+	})
+
+	result.AmountOut = result.Tiers[0].AmountOut
+	return result, nil
+}
+
+// feeKnown reports whether the fee comes from the request or a factory rather
+// than the default
+func (req EstimateRequest) feeKnown() bool {
+	return req.Factory != "" || req.FeeBasisPoints != nil || req.FeeBasisPoints0To1 != nil || req.FeeBasisPoints1To0 != nil
+}
+
+// estimateExactOut quotes the input required to receive exactly req.DstAmount
+func (s *EstimateServiceImpl) estimateExactOut(ctx context.Context, req EstimateRequest) (*EstimateResult, error) {
+	state, err := s.loadSwapState(ctx, req, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	amountIn, err := state.quoteIn(req.DstAmount)
+	if err != nil {
+		return nil, err
 	}
+	logger.FromContext(ctx, s.logger).Debug("Computed exact-out quote",
+		zap.Stringer("amount_out", req.DstAmount),
+		zap.Stringer("amount_in", amountIn),
+	)
+	return &EstimateResult{
+		AmountIn:                amountIn,
+		BlockNumber:             state.blockNumber,
+		PoolAddress:             state.pool.Hex(),
+		Warnings:                state.warnings,
+		ReserveAgeBlocks:        state.reserveAge,
+		EffectiveFeeBasisPoints: state.feeBasisPoints,
+		FeeSource:               state.feeSource,
+		QuoteID:                 state.quoteID(true, []*big.Int{req.DstAmount}),
+	}, nil
+}
+
+// swapState holds the oriented reserves and fee parameters for a validated request,
+// read once so several amounts can be quoted against the same snapshot
+type swapState struct {
+	pool           common.Address
+	src            common.Address
+	dst            common.Address
+	blockNumber    uint64
+	reserveAge     uint64
+	reserveIn      *big.Int
+	reserveOut     *big.Int
+	feeBasisPoints int
+	feeSource      FeeSource
+	feeSide        utils.FeeSide
+	// protocolCut is the factory's on-swap protocol share of the fee; nil for standard V2
+	protocolCut *big.Rat
+	// warnings are passed through to EstimateResult.Warnings
+	warnings []string
+}
+
+// quoteID returns the QuoteKey.ID of quoting amounts against this state
+func (st *swapState) quoteID(exactOut bool, amounts []*big.Int) string {
+	return QuoteKey{
+		Pool:           st.pool,
+		Src:            st.src,
+		Dst:            st.dst,
+		ExactOut:       exactOut,
+		Amounts:        amounts,
+		BlockNumber:    st.blockNumber,
+		FeeBasisPoints: st.feeBasisPoints,
+		FeeSide:        st.feeSide,
+	}.ID()
+}
+
+// quote computes the output for srcAmount against the state's reserves
+func (st *swapState) quote(srcAmount *big.Int) (*big.Int, error) {
+	// Allocated rather than pooled: the result escapes to the caller and would never be Put
+	amountOut := new(big.Int)
+	err := utils.CalculateSwapAmountForSide(srcAmount, st.reserveIn, st.reserveOut, amountOut, st.feeBasisPoints, st.feeSide, utils.GlobalBigIntPool)
+	if err == nil && st.protocolCut != nil {
+		utils.ApplyProtocolCut(amountOut, st.feeBasisPoints, st.protocolCut, utils.GlobalBigIntPool)
+		if amountOut.Sign() == 0 {
+			err = ErrOutputRoundsToZero
+		}
+	}
+	if err != nil {
+		return nil, swapMathError(err)
+	}
+	return amountOut, nil
+}
+
+// swapMathError maps a failed output calculation to ErrBusinessRule. Integer
+// division rounds dust inputs down to zero; that is reported explicitly so
+// clients don't mistake a "0" quote for a failure or an empty pool.
+func swapMathError(err error) error {
+	if errors.Is(err, ErrOutputRoundsToZero) {
+		return fmt.Errorf("%w: amount too small, %w", apperrors.ErrBusinessRule, err)
+	}
+	return fmt.Errorf("%w: %w", apperrors.ErrBusinessRule, err)
+}
+
+// quoteIn computes the input required to receive exactly dstAmount against the state's reserves
+func (st *swapState) quoteIn(dstAmount *big.Int) (*big.Int, error) {
+	// The pool must produce enough pre-cut output to leave dstAmount after the protocol's share
+	if st.protocolCut != nil {
+		dstAmount = utils.GrossUpForProtocolCut(dstAmount, st.feeBasisPoints, st.protocolCut)
+	}
+
+	// Checked up front so the math never sees (reserveOut - amountOut) <= 0
+	if dstAmount.Cmp(st.reserveOut) >= 0 {
+		return nil, fmt.Errorf("%w: %w: requested %s exceeds available reserve %s",
+			apperrors.ErrBusinessRule, utils.ErrInsufficientLiquidity, dstAmount, st.reserveOut)
+	}
+
+	amountIn := new(big.Int)
+	if err := utils.CalculateAmountInForSide(dstAmount, st.reserveIn, st.reserveOut, amountIn, st.feeBasisPoints, st.feeSide, utils.GlobalBigIntPool); err != nil {
+		return nil, fmt.Errorf("%w: %w", apperrors.ErrBusinessRule, err)
+	}
+	return amountIn, nil
+}
 
-	srcAmountStr := srcAmount.String()
-	s.logger.Info("Processing swap estimation request",
+// loadSwapState resolves the pool for a request already passed through
+// ValidateAndNormalize and reads its tokens and reserves at the latest block.
+// srcAmount is only logged and is nil for exact-out requests.
+func (s *EstimateServiceImpl) loadSwapState(ctx context.Context, req EstimateRequest, srcAmount *big.Int) (*swapState, error) {
+	poolAddress, srcToken, dstToken := req.PoolAddress, req.SrcToken, req.DstToken
+
+	amountField := zap.Stringer("src_amount", srcAmount)
+	if req.DstAmount != nil {
+		amountField = zap.Stringer("dst_amount", req.DstAmount)
+	}
+	log := logger.FromContext(ctx, s.logger)
+	log.Info("Processing swap estimation request",
 		zap.String("pool", poolAddress),
+		zap.String("factory", req.Factory),
 		zap.String("src_token", srcToken),
 		zap.String("dst_token", dstToken),
-		zap.String("src_amount", srcAmountStr),
+		amountField,
+		zap.Int("amounts", max(len(req.SrcAmounts), 1)),
+		zap.String("fee_side", req.FeeSide.String()),
 	)
 
-	if err := validateAddressFormat("pool", poolAddress); err != nil {
+	src := common.HexToAddress(srcToken)
+	dst := common.HexToAddress(dstToken)
+
+	pool, fee, protocolCut, err := s.resolvePool(ctx, req.Factory, poolAddress, src, dst)
+	if err != nil {
 		return nil, err
 	}
-	if err := validateAddressFormat("source token", srcToken); err != nil {
+
+	endBlock := timing.Start(ctx, timing.PhaseBlock)
+	head, err := s.uniswapV2Client.GetLatestBlockNumber(ctx)
+	endBlock()
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to connect to blockchain network: %v", apperrors.ErrExternalService, err)
+	}
+	blockNumber, err := requestBlock(head, req)
+	if err != nil {
 		return nil, err
 	}
-	if err := validateAddressFormat("destination token", dstToken); err != nil {
+
+	snapshot, err := s.readPoolSnapshot(ctx, pool, blockNumber)
+	if isEmptyReserves(err) && req.EmptyReservesRetry > 0 && req.BlockOffset == 0 && req.Block == nil {
+		log.Info("Pool reserves empty at head, retrying once",
+			zap.String("pool", pool.Hex()),
+			zap.Uint64("block", blockNumber),
+			zap.Duration("delay", req.EmptyReservesRetry),
+		)
+		blockNumber, err = s.awaitNextHead(ctx, blockNumber, req.EmptyReservesRetry)
+		if err != nil {
+			return nil, err
+		}
+		snapshot, err = s.readPoolSnapshot(ctx, pool, blockNumber)
+	}
+	if err != nil {
+		return nil, err
+	}
+	reserveIn, reserveOut, zeroForOne, err := s.orientReserves(snapshot, src, dst)
+	if err != nil {
 		return nil, err
 	}
 
-	pool := common.HexToAddress(poolAddress)
-	src := common.HexToAddress(srcToken)
-	dst := common.HexToAddress(dstToken)
+	var warnings []string
+	if err := checkReserveRatio(reserveIn, reserveOut, req.MaxReserveRatio); err != nil {
+		if !req.ReserveRatioWarnOnly {
+			return nil, fmt.Errorf("%w: %w", apperrors.ErrBusinessRule, err)
+		}
+		log.Warn("Quoting imbalanced pool", zap.String("pool", pool.Hex()), zap.Error(err))
+		warnings = append(warnings, err.Error())
+	}
+	if warning := staleReservesWarning(snapshot.blockTimestampLast, req); warning != "" {
+		log.Warn("Quoting pool with stale reserves",
+			zap.String("pool", pool.Hex()),
+			zap.Uint32("block_timestamp_last", snapshot.blockTimestampLast),
+		)
+		warnings = append(warnings, warning)
+	}
+
+	effectiveFee := req.feeFor(zeroForOne, fee)
+	state := &swapState{
+		pool:           pool,
+		src:            src,
+		dst:            dst,
+		blockNumber:    blockNumber,
+		reserveAge:     snapshot.reserveAge,
+		reserveIn:      reserveIn,
+		reserveOut:     reserveOut,
+		feeBasisPoints: effectiveFee.basisPoints,
+		feeSource:      effectiveFee.source,
+		feeSide:        req.FeeSide,
+		protocolCut:    protocolCut,
+		warnings:       warnings,
+	}
+	log.Debug("Loaded pool state",
+		zap.String("pool", pool.Hex()),
+		zap.Uint64("block", blockNumber),
+		zap.Uint64("reserve_age_blocks", state.reserveAge),
+		zap.Stringer("reserve_in", reserveIn),
+		zap.Stringer("reserve_out", reserveOut),
+		zap.Bool("zero_for_one", zeroForOne),
+		zap.Int("fee_basis_points", state.feeBasisPoints),
+		zap.String("fee_source", string(state.feeSource)),
+	)
+	return state, nil
+}
+
+// staleReservesWarning describes reserves last updated at blockTimestampLast
+// when that is more than req.MaxReserveStaleness ago, and is empty otherwise
+func staleReservesWarning(blockTimestampLast uint32, req EstimateRequest) string {
+	if req.MaxReserveStaleness <= 0 || blockTimestampLast == 0 || req.Block != nil {
+		return ""
+	}
+	age := time.Since(time.Unix(int64(blockTimestampLast), 0))
+	if age <= req.MaxReserveStaleness {
+		return ""
+	}
+	return fmt.Sprintf("pool reserves last updated %s ago", age.Truncate(time.Second))
+}
+
+// isEmptyReserves reports whether err is a pool read as uninitialized or drained
+func isEmptyReserves(err error) bool {
+	return errors.Is(err, apperrors.ErrPoolNotInitialized) || errors.Is(err, apperrors.ErrPoolDrained)
+}
 
-	if bytes.Equal(src[:], dst[:]) {
-		return nil, fmt.Errorf("%w: source and destination tokens cannot be the same", apperrors.ErrBusinessRule)
+// awaitNextHead waits delay, then returns the latest block, or block itself if
+// head has not moved past it yet
+func (s *EstimateServiceImpl) awaitNextHead(ctx context.Context, block uint64, delay time.Duration) (uint64, error) {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		return 0, fmt.Errorf("%w: unable to read pool reserves: %v", apperrors.ErrExternalService, ctx.Err())
 	}
 
-	blockNumber, err := s.uniswapV2Client.GetLatestBlockNumber(ctx)
+	head, err := s.uniswapV2Client.GetLatestBlockNumber(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("%w: unable to connect to blockchain network: %v", apperrors.ErrExternalService, err)
+		return 0, fmt.Errorf("%w: unable to connect to blockchain network: %v", apperrors.ErrExternalService, err)
+	}
+	return max(head, block), nil
+}
+
+// checkReserveRatio returns ErrReserveImbalance when the larger reserve exceeds
+// maxRatio times the smaller one. A ratio of exactly maxRatio passes; 0 disables it.
+func checkReserveRatio(reserveIn, reserveOut *big.Int, maxRatio uint64) error {
+	if maxRatio == 0 {
+		return nil
+	}
+	larger, smaller := reserveIn, reserveOut
+	if larger.Cmp(smaller) < 0 {
+		larger, smaller = smaller, larger
+	}
+	bound := new(big.Int).Mul(smaller, new(big.Int).SetUint64(maxRatio))
+	if larger.Cmp(bound) > 0 {
+		return fmt.Errorf("%w: reserves %s and %s differ by more than %dx", ErrReserveImbalance, reserveIn, reserveOut, maxRatio)
 	}
+	return nil
+}
+
+// srcAmounts returns the amounts to quote: SrcAmounts when given, otherwise SrcAmount
+func (req EstimateRequest) srcAmounts() []*big.Int {
+	if len(req.SrcAmounts) > 0 {
+		return req.SrcAmounts
+	}
+	return []*big.Int{req.SrcAmount}
+}
+
+// feeFor selects the fee for the resolved swap direction: a directional override
+// first, then the request-wide override, then the pool's fee
+func (req EstimateRequest) feeFor(zeroForOne bool, pool poolFee) poolFee {
+	directional := req.FeeBasisPoints1To0
+	if zeroForOne {
+		directional = req.FeeBasisPoints0To1
+	}
+	switch {
+	case directional != nil:
+		return poolFee{*directional, FeeSourceRequest}
+	case req.FeeBasisPoints != nil:
+		return poolFee{*req.FeeBasisPoints, FeeSourceRequest}
+	default:
+		return pool
+	}
+}
+
+// validateFees checks that every supplied fee override is within [0, MaxRequestFeeBasisPoints]
+func (req EstimateRequest) validateFees() error {
+	for _, fee := range []*int{req.FeeBasisPoints, req.FeeBasisPoints0To1, req.FeeBasisPoints1To0} {
+		if fee != nil && (*fee < 0 || *fee > MaxRequestFeeBasisPoints) {
+			return fmt.Errorf("%w: fee must be between 0 and %d bps, got %d", apperrors.ErrValidation, MaxRequestFeeBasisPoints, *fee)
+		}
+	}
+	return nil
+}
+
+// readOrientedReserves reads the pool's tokens and reserves at blockNumber and
+// orients the reserves for a src -> dst swap. zeroForOne reports whether src is token0.
+func (s *EstimateServiceImpl) readOrientedReserves(ctx context.Context, pool, src, dst common.Address, blockNumber uint64) (reserveIn, reserveOut *big.Int, zeroForOne bool, err error) {
+	snapshot, err := s.readPoolSnapshot(ctx, pool, blockNumber)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	return s.orientReserves(snapshot, src, dst)
+}
+
+// poolSnapshot holds a pool's tokens and unoriented reserves read at a single block
+type poolSnapshot struct {
+	token0   common.Address
+	token1   common.Address
+	reserve0 *big.Int
+	reserve1 *big.Int
+	// reserveAge is how many blocks before the requested block the reserves were
+	// read; non-zero only when they came from a reserves cache
+	reserveAge uint64
+	// blockTimestampLast is the pair's last reserves update, 0 when unknown
+	blockTimestampLast uint32
+}
+
+// readPoolSnapshot reads the pool's tokens and reserves at blockNumber, joining
+// an identical read already in flight when single-flight is enabled. The shared
+// snapshot is never modified by its readers.
+func (s *EstimateServiceImpl) readPoolSnapshot(ctx context.Context, pool common.Address, blockNumber uint64) (*poolSnapshot, error) {
+	if s.flights == nil {
+		return s.loadPoolSnapshot(ctx, pool, blockNumber)
+	}
+
+	key := pool.Hex() + "|" + strconv.FormatUint(blockNumber, 10)
+	flight := s.flights.DoChan(key, func() (any, error) {
+		snapshot, err := s.loadPoolSnapshot(ctx, pool, blockNumber)
+		if err != nil && requestSpent(ctx) {
+			return nil, &leaderRequestError{err: err}
+		}
+		return snapshot, err
+	})
+	select {
+	case result := <-flight:
+		var leaderErr *leaderRequestError
+		if errors.As(result.Err, &leaderErr) {
+			// The read failed on the leader's own deadline or budget; a waiter
+			// whose request can still afford it reads the pool itself
+			if requestSpent(ctx) {
+				return nil, leaderErr.err
+			}
+			return s.loadPoolSnapshot(ctx, pool, blockNumber)
+		}
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		return result.Val.(*poolSnapshot), nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("%w: unable to read pool reserves: %v", apperrors.ErrExternalService, ctx.Err())
+	}
+}
+
+// leaderRequestError marks a shared pool read that failed because the request
+// leading it was cancelled, timed out or ran out of RPC budget
+type leaderRequestError struct {
+	err error
+}
+
+func (e *leaderRequestError) Error() string { return e.err.Error() }
+
+func (e *leaderRequestError) Unwrap() error { return e.err }
+
+// requestSpent reports whether ctx's request can no longer make RPC calls: it is
+// done or has exceeded its RPC budget
+func requestSpent(ctx context.Context) bool {
+	if ctx.Err() != nil {
+		return true
+	}
+	budget := rpcbudget.FromContext(ctx)
+	return budget != nil && budget.Exceeded()
+}
+
+// loadPoolSnapshot reads the pool's tokens and reserves at blockNumber
+func (s *EstimateServiceImpl) loadPoolSnapshot(ctx context.Context, pool common.Address, blockNumber uint64) (*poolSnapshot, error) {
 	blockNum := utils.GlobalBigIntPool.Get()
 	blockNum.SetUint64(blockNumber)
 	defer utils.GlobalBigIntPool.Put(blockNum)
 
+	if loader, ok := s.uniswapV2Client.(uniswap_v2.PoolStateLoader); ok {
+		// One round trip for tokens and reserves, timed as the reserves read
+		endReserves := timing.Start(ctx, timing.PhaseReserves)
+		state, err := loader.LoadPoolState(ctx, pool, blockNum)
+		endReserves()
+		if errors.Is(err, uniswap_v2.ErrPoolTokensUnavailable) {
+			return nil, fmt.Errorf("%w: pool not found or invalid: %v", apperrors.ErrNotFound, err)
+		}
+		if err != nil {
+			return nil, reservesError(err)
+		}
+		snapshot := newPoolSnapshot(state.Token0, state.Token1, state.Reserve0, state.Reserve1, state.ReadAt, blockNumber)
+		snapshot.blockTimestampLast = state.BlockTimestampLast
+		return snapshot, nil
+	}
+
+	endTokens := timing.Start(ctx, timing.PhaseTokens)
 	token0, token1, err := s.uniswapV2Client.LoadTokens(ctx, pool, blockNum)
+	endTokens()
 	if err != nil {
 		return nil, fmt.Errorf("%w: pool not found or invalid: %v", apperrors.ErrNotFound, err)
 	}
 
-	reserve0, reserve1, err := s.uniswapV2Client.LoadReserves(ctx, pool, blockNum)
+	endReserves := timing.Start(ctx, timing.PhaseReserves)
+	reserve0, reserve1, readAt, err := s.loadReserves(ctx, pool, blockNum)
+	endReserves()
 	if err != nil {
-		return nil, fmt.Errorf("%w: unable to read pool reserves: %v", apperrors.ErrExternalService, err)
+		return nil, reservesError(err)
+	}
+	return newPoolSnapshot(token0, token1, reserve0, reserve1, readAt, blockNumber), nil
+}
+
+// newPoolSnapshot builds a snapshot requested at blockNumber from reserves read at readAt
+func newPoolSnapshot(token0, token1 common.Address, reserve0, reserve1 *big.Int, readAt, blockNumber uint64) *poolSnapshot {
+	snapshot := &poolSnapshot{token0: token0, token1: token1, reserve0: reserve0, reserve1: reserve1}
+	if readAt < blockNumber {
+		snapshot.reserveAge = blockNumber - readAt
+	}
+	return snapshot
+}
+
+// reservesError maps a reserves read failure to the service's error kinds
+func reservesError(err error) error {
+	switch {
+	case errors.Is(err, uniswap_v2.ErrPoolNotInitialized):
+		return fmt.Errorf("%w: %v", apperrors.ErrPoolNotInitialized, err)
+	case errors.Is(err, uniswap_v2.ErrPoolDrained):
+		return fmt.Errorf("%w: %v", apperrors.ErrPoolDrained, err)
+	}
+	return fmt.Errorf("%w: unable to read pool reserves: %v", apperrors.ErrExternalService, err)
+}
+
+// loadReserves reads the pool's reserves and the block they were read at, which
+// is earlier than blockNum when the client served them from a cache
+func (s *EstimateServiceImpl) loadReserves(ctx context.Context, pool common.Address, blockNum *big.Int) (*big.Int, *big.Int, uint64, error) {
+	if loader, ok := s.uniswapV2Client.(uniswap_v2.ReservesBlockLoader); ok {
+		return loader.LoadReservesWithBlock(ctx, pool, blockNum)
 	}
+	reserve0, reserve1, err := s.uniswapV2Client.LoadReserves(ctx, pool, blockNum)
+	return reserve0, reserve1, blockNum.Uint64(), err
+}
 
-	reserveIn, reserveOut, err := s.uniswapV2Client.DetermineReserveOrder(src, dst, token0, token1, reserve0, reserve1)
+// orientReserves orders the snapshot's reserves for a src -> dst swap
+func (s *EstimateServiceImpl) orientReserves(snapshot *poolSnapshot, src, dst common.Address) (reserveIn, reserveOut *big.Int, zeroForOne bool, err error) {
+	reserveIn, reserveOut, err = s.uniswapV2Client.DetermineReserveOrder(src, dst, snapshot.token0, snapshot.token1, snapshot.reserve0, snapshot.reserve1)
 	if err != nil {
-		return nil, err
+		return nil, nil, false, fmt.Errorf("%w: %w", apperrors.ErrValidation, err)
 	}
 
 	if reserveIn.Sign() == 0 || reserveOut.Sign() == 0 {
-		return nil, fmt.Errorf("%w: pool has empty reserves", apperrors.ErrBusinessRule)
+		return nil, nil, false, fmt.Errorf("%w: pool has empty reserves", apperrors.ErrBusinessRule)
 	}
 
-	amountOut := utils.GlobalBigIntPool.Get()
-	utils.CalculateUniswapV2SwapAmount(srcAmount, reserveIn, reserveOut, amountOut, utils.GlobalBigIntPool)
+	return reserveIn, reserveOut, src == snapshot.token0, nil
+}
 
-	return amountOut, nil
+// resolvePool returns the pool to quote against, the fee to apply and any on-swap
+// protocol cut. When a factory is named, the pool is derived via CREATE2 and the
+// factory's fee model is used, with the fee read from chain if it has a fee method.
+func (s *EstimateServiceImpl) resolvePool(ctx context.Context, factoryName, poolAddress string, src, dst common.Address) (common.Address, poolFee, *big.Rat, error) {
+	if factoryName == "" {
+		return common.HexToAddress(poolAddress), poolFee{defaultFeeBasisPoints, FeeSourceDefault}, nil, nil
+	}
+	if s.factories == nil {
+		return common.Address{}, poolFee{}, nil, fmt.Errorf("%w: factory lookups are not configured", apperrors.ErrValidation)
+	}
+
+	factory, err := s.factories.Get(factoryName)
+	if err != nil {
+		return common.Address{}, poolFee{}, nil, fmt.Errorf("%w: %v", apperrors.ErrValidation, err)
+	}
+
+	pool := factory.PairFor(src, dst)
+	s.logger.Debug("Derived pool from factory",
+		zap.String("factory", factory.Name),
+		zap.String("pool", pool.Hex()),
+	)
+	if factory.FeeMethod == nil {
+		return pool, poolFee{factory.FeeBasisPoints, FeeSourceConfig}, factory.ProtocolCut, nil
+	}
+	fee, err := s.onChainFee(ctx, pool, factory)
+	if err != nil {
+		return common.Address{}, poolFee{}, nil, err
+	}
+	return pool, fee, factory.ProtocolCut, nil
+}
+
+// onChainFee reads the pool's fee through the factory's fee method, falling back
+// to the configured fee when the pool has no such method or its fee is not a
+// whole number of basis points
+func (s *EstimateServiceImpl) onChainFee(ctx context.Context, pool common.Address, factory uniswap_v2.Factory) (poolFee, error) {
+	configured := poolFee{factory.FeeBasisPoints, FeeSourceConfig}
+	log := logger.FromContext(ctx, s.logger)
+	fee, err := s.uniswapV2Client.LoadPoolFee(ctx, pool, factory.FeeMethod.Selector)
+	if errors.Is(err, uniswap_v2.ErrNoFeeMethod) {
+		log.Debug("Pool has no fee method, using the configured fee",
+			zap.String("pool", pool.Hex()),
+			zap.String("method", factory.FeeMethod.Signature),
+		)
+		return configured, nil
+	}
+	if err != nil {
+		return poolFee{}, fmt.Errorf("%w: unable to read pool fee: %v", apperrors.ErrExternalService, err)
+	}
+
+	basisPoints, ok := factory.FeeMethod.BasisPoints(fee)
+	if !ok {
+		log.Warn("On-chain fee is not representable in basis points, using the configured fee",
+			zap.String("pool", pool.Hex()),
+			zap.Stringer("fee", fee),
+			zap.Uint64("denominator", factory.FeeMethod.Denominator),
+		)
+		return configured, nil
+	}
+	return poolFee{basisPoints, FeeSourceOnChain}, nil
+}
+
+// requestBlock returns the block req reads at: req.Block when set, which must not
+// be beyond head, otherwise req.BlockOffset blocks behind head
+func requestBlock(head uint64, req EstimateRequest) (uint64, error) {
+	if req.Block == nil {
+		return BlockAtOffset(head, req.BlockOffset)
+	}
+	if *req.Block > head {
+		return 0, fmt.Errorf("%w: block %d is beyond head block %d", apperrors.ErrValidation, *req.Block, head)
+	}
+	return *req.Block, nil
+}
+
+// BlockAtOffset returns the block offset blocks behind head
+func BlockAtOffset(head, offset uint64) (uint64, error) {
+	if offset > MaxBlockOffset {
+		return 0, fmt.Errorf("%w: block offset %d exceeds the maximum of %d", apperrors.ErrValidation, offset, MaxBlockOffset)
+	}
+	if offset > head {
+		return 0, fmt.Errorf("%w: block offset %d is beyond head block %d", apperrors.ErrValidation, offset, head)
+	}
+	return head - offset, nil
 }
 
 // validateAddressFormat validates that the given address string is a valid hex address format
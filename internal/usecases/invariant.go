@@ -0,0 +1,130 @@
+package estimate
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	apperrors "bigswapenergy/internal/shared/errors"
+	"bigswapenergy/internal/shared/logger"
+	"bigswapenergy/internal/shared/timing"
+	"bigswapenergy/internal/shared/utils"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+// InvariantRequest asks for a pool's constant-product invariant. When SrcAmount is
+// set, SrcToken names the pool token being sold in a hypothetical swap.
+type InvariantRequest struct {
+	PoolAddress string
+	SrcToken    string
+	SrcAmount   *big.Int
+}
+
+// PoolInvariant holds k = reserve0 * reserve1 at BlockNumber, and the effect of a
+// hypothetical swap when one was requested
+type PoolInvariant struct {
+	Pool        common.Address
+	BlockNumber uint64
+	Reserve0    *big.Int
+	Reserve1    *big.Int
+	K           *big.Int
+	Swap        *InvariantSwap
+}
+
+// InvariantSwap is the pool state after a hypothetical swap at the default fee.
+// KIncrease is the growth in k from the fee retained by the pool.
+type InvariantSwap struct {
+	AmountIn      *big.Int
+	AmountOut     *big.Int
+	Reserve0After *big.Int
+	Reserve1After *big.Int
+	KAfter        *big.Int
+	KIncrease     *big.Int
+}
+
+// ReadPoolInvariant reads the pool's reserves at the latest block and returns k,
+// plus k after selling SrcAmount of SrcToken when an amount is given
+func (s *EstimateServiceImpl) ReadPoolInvariant(ctx context.Context, req InvariantRequest) (*PoolInvariant, error) {
+	if req.PoolAddress == "" {
+		return nil, fmt.Errorf("%w: pool address is required", apperrors.ErrValidation)
+	}
+	if err := validateAddressFormat("pool", req.PoolAddress); err != nil {
+		return nil, err
+	}
+	if req.SrcAmount != nil {
+		if req.SrcAmount.Sign() <= 0 {
+			return nil, fmt.Errorf("%w: source amount must be positive", apperrors.ErrValidation)
+		}
+		if req.SrcToken == "" {
+			return nil, fmt.Errorf("%w: source token address is required with an amount", apperrors.ErrValidation)
+		}
+		if err := validateAddressFormat("source token", req.SrcToken); err != nil {
+			return nil, err
+		}
+	}
+	pool := common.HexToAddress(req.PoolAddress)
+
+	log := logger.FromContext(ctx, s.logger)
+	log.Info("Processing pool invariant request",
+		zap.String("pool", pool.Hex()),
+		zap.String("src_token", req.SrcToken),
+		zap.Stringer("src_amount", req.SrcAmount),
+	)
+
+	endBlock := timing.Start(ctx, timing.PhaseBlock)
+	blockNumber, err := s.uniswapV2Client.GetLatestBlockNumber(ctx)
+	endBlock()
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to connect to blockchain network: %v", apperrors.ErrExternalService, err)
+	}
+
+	snapshot, err := s.readPoolSnapshot(ctx, pool, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PoolInvariant{
+		Pool:        pool,
+		BlockNumber: blockNumber,
+		Reserve0:    snapshot.reserve0,
+		Reserve1:    snapshot.reserve1,
+		K:           utils.ComputeInvariant(snapshot.reserve0, snapshot.reserve1),
+	}
+	if req.SrcAmount == nil {
+		return result, nil
+	}
+
+	src := common.HexToAddress(req.SrcToken)
+	dst := snapshot.token0
+	if src == snapshot.token0 {
+		dst = snapshot.token1
+	}
+	reserveIn, reserveOut, zeroForOne, err := s.orientReserves(snapshot, src, dst)
+	if err != nil {
+		return nil, err
+	}
+
+	amountOut := new(big.Int)
+	if err := utils.CalculateSwapAmount(req.SrcAmount, reserveIn, reserveOut, amountOut, defaultFeeBasisPoints, utils.GlobalBigIntPool); err != nil {
+		return nil, swapMathError(err)
+	}
+
+	reserveInAfter := new(big.Int).Add(reserveIn, req.SrcAmount)
+	reserveOutAfter := new(big.Int).Sub(reserveOut, amountOut)
+	swap := &InvariantSwap{
+		AmountIn:      req.SrcAmount,
+		AmountOut:     amountOut,
+		Reserve0After: reserveInAfter,
+		Reserve1After: reserveOutAfter,
+	}
+	if !zeroForOne {
+		swap.Reserve0After, swap.Reserve1After = reserveOutAfter, reserveInAfter
+	}
+	swap.KAfter = utils.ComputeInvariant(swap.Reserve0After, swap.Reserve1After)
+	swap.KIncrease = new(big.Int).Sub(swap.KAfter, result.K)
+	result.Swap = swap
+
+	return result, nil
+}
@@ -0,0 +1,57 @@
+package estimate
+
+import (
+	"fmt"
+	"math/big"
+
+	apperrors "bigswapenergy/internal/shared/errors"
+	"bigswapenergy/internal/shared/utils"
+)
+
+// MaxReserve is the largest reserve a Uniswap V2 pair can hold (uint112)
+var MaxReserve = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 112), big.NewInt(1))
+
+// LocalQuoteRequest is a quote against reserves supplied by the caller, already
+// oriented for the swap, so no pool state is read
+type LocalQuoteRequest struct {
+	ReserveIn  *big.Int
+	ReserveOut *big.Int
+	SrcAmount  *big.Int
+
+	// FeeBasisPoints overrides the default 0.3% fee when set
+	FeeBasisPoints *int
+	FeeSide        utils.FeeSide
+}
+
+// QuoteFromReserves computes the output for req without any RPC. The reserves are
+// trusted as given; only their range is checked.
+func QuoteFromReserves(req LocalQuoteRequest) (*big.Int, error) {
+	for _, reserve := range []struct {
+		name  string
+		value *big.Int
+	}{{"reserve in", req.ReserveIn}, {"reserve out", req.ReserveOut}} {
+		if reserve.value == nil || reserve.value.Sign() <= 0 {
+			return nil, fmt.Errorf("%w: %s must be positive", apperrors.ErrValidation, reserve.name)
+		}
+		if reserve.value.Cmp(MaxReserve) > 0 {
+			return nil, fmt.Errorf("%w: %s exceeds uint112", apperrors.ErrValidation, reserve.name)
+		}
+	}
+	if req.SrcAmount == nil || req.SrcAmount.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: source amount must be positive", apperrors.ErrValidation)
+	}
+	if err := (EstimateRequest{FeeBasisPoints: req.FeeBasisPoints}).validateFees(); err != nil {
+		return nil, err
+	}
+
+	state := &swapState{
+		reserveIn:      req.ReserveIn,
+		reserveOut:     req.ReserveOut,
+		feeBasisPoints: defaultFeeBasisPoints,
+		feeSide:        req.FeeSide,
+	}
+	if req.FeeBasisPoints != nil {
+		state.feeBasisPoints = *req.FeeBasisPoints
+	}
+	return state.quote(req.SrcAmount)
+}
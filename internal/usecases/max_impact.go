@@ -0,0 +1,97 @@
+package estimate
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	apperrors "bigswapenergy/internal/shared/errors"
+	"bigswapenergy/internal/shared/logger"
+	"bigswapenergy/internal/shared/timing"
+	"bigswapenergy/internal/shared/utils"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+// MaxImpactRequest asks for the largest src -> dst trade whose price impact stays
+// within MaxImpactBps (1/10000ths)
+type MaxImpactRequest struct {
+	PoolAddress  string
+	SrcToken     string
+	DstToken     string
+	MaxImpactBps int
+}
+
+// MaxImpactResult holds the solved input and the output it receives
+type MaxImpactResult struct {
+	AmountIn    *big.Int
+	AmountOut   *big.Int
+	BlockNumber uint64
+}
+
+// EstimateMaxForImpact solves the constant-product relation for the input that
+// moves the price by MaxImpactBps, from a single reserve read
+func (s *EstimateServiceImpl) EstimateMaxForImpact(ctx context.Context, req MaxImpactRequest) (*MaxImpactResult, error) {
+	if req.PoolAddress == "" {
+		return nil, fmt.Errorf("%w: pool address is required", apperrors.ErrValidation)
+	}
+	if req.SrcToken == "" {
+		return nil, fmt.Errorf("%w: source token address is required", apperrors.ErrValidation)
+	}
+	if req.DstToken == "" {
+		return nil, fmt.Errorf("%w: destination token address is required", apperrors.ErrValidation)
+	}
+	if req.MaxImpactBps <= 0 || req.MaxImpactBps >= 10000 {
+		return nil, fmt.Errorf("%w: max impact must be between 1 and 9999 bps", apperrors.ErrValidation)
+	}
+
+	if err := validateAddressFormat("pool", req.PoolAddress); err != nil {
+		return nil, err
+	}
+	if err := validateAddressFormat("source token", req.SrcToken); err != nil {
+		return nil, err
+	}
+	if err := validateAddressFormat("destination token", req.DstToken); err != nil {
+		return nil, err
+	}
+
+	pool := common.HexToAddress(req.PoolAddress)
+	src := common.HexToAddress(req.SrcToken)
+	dst := common.HexToAddress(req.DstToken)
+
+	if src == dst {
+		return nil, fmt.Errorf("%w: source and destination tokens cannot be the same", apperrors.ErrBusinessRule)
+	}
+
+	log := logger.FromContext(ctx, s.logger)
+	log.Info("Processing max-for-impact request",
+		zap.String("pool", pool.Hex()),
+		zap.String("src_token", src.Hex()),
+		zap.String("dst_token", dst.Hex()),
+		zap.Int("max_impact_bps", req.MaxImpactBps),
+	)
+
+	endBlock := timing.Start(ctx, timing.PhaseBlock)
+	blockNumber, err := s.uniswapV2Client.GetLatestBlockNumber(ctx)
+	endBlock()
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to connect to blockchain network: %v", apperrors.ErrExternalService, err)
+	}
+
+	state, err := s.readLeg(ctx, pool, src, dst, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	amountIn := utils.MaxAmountInForImpact(state.reserveIn, req.MaxImpactBps, state.feeBasisPoints)
+	if amountIn.Sign() == 0 {
+		return nil, fmt.Errorf("%w: reserves too small for a %d bps impact", apperrors.ErrBusinessRule, req.MaxImpactBps)
+	}
+	amountOut, err := state.quote(amountIn)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MaxImpactResult{AmountIn: amountIn, AmountOut: amountOut, BlockNumber: blockNumber}, nil
+}
@@ -0,0 +1,138 @@
+package estimate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"bigswapenergy/internal/infrastructure/uniswap_v2"
+	apperrors "bigswapenergy/internal/shared/errors"
+	"bigswapenergy/internal/shared/logger"
+	"bigswapenergy/internal/shared/timing"
+	"bigswapenergy/internal/shared/utils"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+// CheckReason explains why a pool cannot be quoted for a pair
+type CheckReason string
+
+const (
+	CheckPoolNotFound       CheckReason = "pool_not_found"
+	CheckTokenPairMismatch  CheckReason = "token_pair_mismatch"
+	CheckPoolNotInitialized CheckReason = "pool_not_initialized"
+	CheckPoolDrained        CheckReason = "pool_drained"
+	CheckInvalidReserves    CheckReason = "invalid_reserves"
+)
+
+// PoolCheckRequest asks whether src -> dst can be quoted on a pool
+type PoolCheckRequest struct {
+	PoolAddress string
+	SrcToken    string
+	DstToken    string
+}
+
+// PoolCheck is the outcome of a pool check. When Supported is false, Reason and
+// Detail say why and the reserves are nil.
+type PoolCheck struct {
+	Supported   bool
+	Reason      CheckReason
+	Detail      string
+	ReserveIn   *big.Int
+	ReserveOut  *big.Int
+	BlockNumber uint64
+}
+
+// CheckPool reads the pool's tokens and reserves and reports whether src -> dst
+// can be quoted. Pool problems are returned as a reason, not an error; only
+// invalid requests and RPC failures fail the call.
+func (s *EstimateServiceImpl) CheckPool(ctx context.Context, req PoolCheckRequest) (*PoolCheck, error) {
+	if req.PoolAddress == "" {
+		return nil, fmt.Errorf("%w: pool address is required", apperrors.ErrValidation)
+	}
+	if req.SrcToken == "" {
+		return nil, fmt.Errorf("%w: source token address is required", apperrors.ErrValidation)
+	}
+	if req.DstToken == "" {
+		return nil, fmt.Errorf("%w: destination token address is required", apperrors.ErrValidation)
+	}
+
+	if err := validateAddressFormat("pool", req.PoolAddress); err != nil {
+		return nil, err
+	}
+	if err := validateAddressFormat("source token", req.SrcToken); err != nil {
+		return nil, err
+	}
+	if err := validateAddressFormat("destination token", req.DstToken); err != nil {
+		return nil, err
+	}
+
+	pool := common.HexToAddress(req.PoolAddress)
+	src := common.HexToAddress(req.SrcToken)
+	dst := common.HexToAddress(req.DstToken)
+
+	if src == dst {
+		return nil, fmt.Errorf("%w: source and destination tokens cannot be the same", apperrors.ErrBusinessRule)
+	}
+
+	log := logger.FromContext(ctx, s.logger)
+	log.Info("Processing pool check request",
+		zap.String("pool", pool.Hex()),
+		zap.String("src_token", src.Hex()),
+		zap.String("dst_token", dst.Hex()),
+	)
+
+	endBlock := timing.Start(ctx, timing.PhaseBlock)
+	blockNumber, err := s.uniswapV2Client.GetLatestBlockNumber(ctx)
+	endBlock()
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to connect to blockchain network: %v", apperrors.ErrExternalService, err)
+	}
+	blockNum := new(big.Int).SetUint64(blockNumber)
+	check := &PoolCheck{BlockNumber: blockNumber}
+
+	endTokens := timing.Start(ctx, timing.PhaseTokens)
+	token0, token1, err := s.uniswapV2Client.LoadTokens(ctx, pool, blockNum)
+	endTokens()
+	switch {
+	case errors.Is(err, uniswap_v2.ErrPoolNotFound):
+		return check.unsupported(CheckPoolNotFound, err), nil
+	case err != nil:
+		return nil, fmt.Errorf("%w: unable to read pool tokens: %v", apperrors.ErrExternalService, err)
+	}
+
+	zeroForOne, err := uniswap_v2.OrientPair(src, dst, token0, token1)
+	if err != nil {
+		return check.unsupported(CheckTokenPairMismatch, err), nil
+	}
+
+	endReserves := timing.Start(ctx, timing.PhaseReserves)
+	reserve0, reserve1, err := s.uniswapV2Client.LoadReserves(ctx, pool, blockNum)
+	endReserves()
+	switch {
+	case errors.Is(err, uniswap_v2.ErrPoolNotInitialized):
+		return check.unsupported(CheckPoolNotInitialized, err), nil
+	case errors.Is(err, uniswap_v2.ErrPoolDrained):
+		return check.unsupported(CheckPoolDrained, err), nil
+	case errors.Is(err, utils.ErrInvalidStorageWord):
+		return check.unsupported(CheckInvalidReserves, err), nil
+	case err != nil:
+		return nil, fmt.Errorf("%w: unable to read pool reserves: %v", apperrors.ErrExternalService, err)
+	}
+
+	check.Supported = true
+	check.ReserveIn, check.ReserveOut = reserve0, reserve1
+	if !zeroForOne {
+		check.ReserveIn, check.ReserveOut = reserve1, reserve0
+	}
+	return check, nil
+}
+
+// unsupported marks the check as failed for reason
+func (c *PoolCheck) unsupported(reason CheckReason, err error) *PoolCheck {
+	c.Reason = reason
+	c.Detail = err.Error()
+	return c
+}
@@ -0,0 +1,97 @@
+package estimate
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"bigswapenergy/internal/infrastructure/uniswap_v2"
+	apperrors "bigswapenergy/internal/shared/errors"
+	"bigswapenergy/internal/shared/timing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+// RawPoolStorage holds a pool's token and reserves storage words exactly as read, unparsed
+type RawPoolStorage struct {
+	Pool        common.Address
+	BlockNumber uint64
+	Token0      []byte
+	Token1      []byte
+	Reserves    []byte
+}
+
+// PoolTokens holds a pool's token pair in on-chain order
+type PoolTokens struct {
+	Pool        common.Address
+	BlockNumber uint64
+	Token0      common.Address
+	Token1      common.Address
+}
+
+// ReadPoolTokens reads token0 and token1 at the latest block
+func (s *EstimateServiceImpl) ReadPoolTokens(ctx context.Context, poolAddress string) (*PoolTokens, error) {
+	if poolAddress == "" {
+		return nil, fmt.Errorf("%w: pool address is required", apperrors.ErrValidation)
+	}
+	if err := validateAddressFormat("pool", poolAddress); err != nil {
+		return nil, err
+	}
+	pool := common.HexToAddress(poolAddress)
+
+	endBlock := timing.Start(ctx, timing.PhaseBlock)
+	blockNumber, err := s.uniswapV2Client.GetLatestBlockNumber(ctx)
+	endBlock()
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to connect to blockchain network: %v", apperrors.ErrExternalService, err)
+	}
+
+	token0, token1, err := s.uniswapV2Client.LoadTokens(ctx, pool, new(big.Int).SetUint64(blockNumber))
+	if err != nil {
+		return nil, fmt.Errorf("%w: pool not found or invalid: %v", apperrors.ErrNotFound, err)
+	}
+
+	return &PoolTokens{Pool: pool, BlockNumber: blockNumber, Token0: token0, Token1: token1}, nil
+}
+
+// ReadRawPoolStorage reads the token0, token1 and reserves slots of a pool at the
+// latest block without parsing them, for clients debugging storage layouts
+func (s *EstimateServiceImpl) ReadRawPoolStorage(ctx context.Context, poolAddress string) (*RawPoolStorage, error) {
+	if poolAddress == "" {
+		return nil, fmt.Errorf("%w: pool address is required", apperrors.ErrValidation)
+	}
+	if err := validateAddressFormat("pool", poolAddress); err != nil {
+		return nil, err
+	}
+	pool := common.HexToAddress(poolAddress)
+
+	s.logger.Info("Processing raw pool storage request", zap.String("pool", pool.Hex()))
+
+	endBlock := timing.Start(ctx, timing.PhaseBlock)
+	blockNumber, err := s.uniswapV2Client.GetLatestBlockNumber(ctx)
+	endBlock()
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to connect to blockchain network: %v", apperrors.ErrExternalService, err)
+	}
+	blockNum := new(big.Int).SetUint64(blockNumber)
+
+	raw := &RawPoolStorage{Pool: pool, BlockNumber: blockNumber}
+	slots := []struct {
+		slot uint64
+		dst  *[]byte
+	}{
+		{uniswap_v2.UniswapV2Token0StorageSlot, &raw.Token0},
+		{uniswap_v2.UniswapV2Token1StorageSlot, &raw.Token1},
+		{uniswap_v2.UniswapV2ReservesStorageSlot, &raw.Reserves},
+	}
+	for _, sl := range slots {
+		data, err := s.uniswapV2Client.ReadStorageSlot(ctx, pool, blockNum, sl.slot)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to read storage slot %d: %v", apperrors.ErrExternalService, sl.slot, err)
+		}
+		*sl.dst = data
+	}
+
+	return raw, nil
+}
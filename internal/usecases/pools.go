@@ -0,0 +1,150 @@
+package estimate
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	apperrors "bigswapenergy/internal/shared/errors"
+	"bigswapenergy/internal/shared/logger"
+	"bigswapenergy/internal/shared/timing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+// maxConcurrentPoolReads bounds how many pools a batch read fetches at once
+const maxConcurrentPoolReads = 8
+
+// PoolState is one pool's tokens and reserves from a batch read. Err is set
+// instead when that pool could not be read.
+type PoolState struct {
+	Pool     common.Address
+	Token0   common.Address
+	Token1   common.Address
+	Reserve0 *big.Int
+	Reserve1 *big.Int
+	Err      error
+}
+
+// PoolsResult holds a batch of pool states, all read at BlockNumber
+type PoolsResult struct {
+	Pools       []PoolState
+	BlockNumber uint64
+}
+
+// PoolRequest asks for one pool's tokens and reserves, at Block when set and at
+// the latest block otherwise
+type PoolRequest struct {
+	PoolAddress string
+	Block       *uint64
+}
+
+// PoolResult is one pool's tokens and reserves, read at BlockNumber
+type PoolResult struct {
+	Pool        common.Address
+	BlockNumber uint64
+	Token0      common.Address
+	Token1      common.Address
+	Reserve0    *big.Int
+	Reserve1    *big.Int
+}
+
+// ReadPool reads a single pool's tokens and reserves without quoting a swap
+func (s *EstimateServiceImpl) ReadPool(ctx context.Context, req PoolRequest) (*PoolResult, error) {
+	if req.PoolAddress == "" {
+		return nil, fmt.Errorf("%w: pool address is required", apperrors.ErrValidation)
+	}
+	if err := validateAddressFormat("pool", req.PoolAddress); err != nil {
+		return nil, err
+	}
+	pool := common.HexToAddress(req.PoolAddress)
+
+	logger.FromContext(ctx, s.logger).Info("Processing pool read", zap.String("pool", pool.Hex()))
+
+	endBlock := timing.Start(ctx, timing.PhaseBlock)
+	head, err := s.uniswapV2Client.GetLatestBlockNumber(ctx)
+	endBlock()
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to connect to blockchain network: %v", apperrors.ErrExternalService, err)
+	}
+	blockNumber, err := requestBlock(head, EstimateRequest{Block: req.Block})
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, err := s.readPoolSnapshot(ctx, pool, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	return &PoolResult{
+		Pool:        pool,
+		BlockNumber: blockNumber,
+		Token0:      snapshot.token0,
+		Token1:      snapshot.token1,
+		Reserve0:    snapshot.reserve0,
+		Reserve1:    snapshot.reserve1,
+	}, nil
+}
+
+// ReadPools reads tokens and reserves for each distinct pool, concurrently and at
+// a single block. Repeated addresses are read once and reported once, in order of
+// first appearance. A failing pool is reported inline rather than failing the batch.
+func (s *EstimateServiceImpl) ReadPools(ctx context.Context, poolAddresses []string) (*PoolsResult, error) {
+	if len(poolAddresses) == 0 {
+		return nil, fmt.Errorf("%w: at least one pool is required", apperrors.ErrValidation)
+	}
+
+	seen := make(map[common.Address]bool, len(poolAddresses))
+	pools := make([]common.Address, 0, len(poolAddresses))
+	for _, poolAddress := range poolAddresses {
+		if err := validateAddressFormat("pool", poolAddress); err != nil {
+			return nil, err
+		}
+		pool := common.HexToAddress(poolAddress)
+		if !seen[pool] {
+			seen[pool] = true
+			pools = append(pools, pool)
+		}
+	}
+
+	log := logger.FromContext(ctx, s.logger)
+	log.Info("Processing batch pool read",
+		zap.Int("requested", len(poolAddresses)),
+		zap.Int("distinct", len(pools)),
+	)
+
+	endBlock := timing.Start(ctx, timing.PhaseBlock)
+	blockNumber, err := s.uniswapV2Client.GetLatestBlockNumber(ctx)
+	endBlock()
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to connect to blockchain network: %v", apperrors.ErrExternalService, err)
+	}
+
+	result := &PoolsResult{Pools: make([]PoolState, len(pools)), BlockNumber: blockNumber}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxConcurrentPoolReads)
+	for i, pool := range pools {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			state := PoolState{Pool: pool}
+			snapshot, err := s.readPoolSnapshot(ctx, pool, blockNumber)
+			if err != nil {
+				state.Err = err
+			} else {
+				state.Token0, state.Token1 = snapshot.token0, snapshot.token1
+				state.Reserve0, state.Reserve1 = snapshot.reserve0, snapshot.reserve1
+			}
+			result.Pools[i] = state
+		}()
+	}
+	wg.Wait()
+
+	return result, nil
+}
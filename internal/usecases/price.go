@@ -0,0 +1,110 @@
+package estimate
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	apperrors "bigswapenergy/internal/shared/errors"
+	"bigswapenergy/internal/shared/logger"
+	"bigswapenergy/internal/shared/timing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+// PriceRequest asks for a pool's spot price. Both adds the reciprocal, computed
+// from the same reserves read so the two can't come from different blocks.
+// SrcToken and DstToken, given together, also ask for the price of src in dst.
+type PriceRequest struct {
+	PoolAddress string
+	SrcToken    string
+	DstToken    string
+	Both        bool
+}
+
+// PoolPrice holds the pool's spot prices in base units, unadjusted for token decimals.
+// Price0Per1 is nil unless PriceRequest.Both was set.
+type PoolPrice struct {
+	Pool        common.Address
+	BlockNumber uint64
+	Token0      common.Address
+	Token1      common.Address
+	// Price1Per0 is token1 per token0: reserve1 / reserve0
+	Price1Per0 *big.Rat
+	// Price0Per1 is token0 per token1: reserve0 / reserve1
+	Price0Per1 *big.Rat
+
+	// Src, Dst and Price are set when PriceRequest named src and dst. Price is
+	// the marginal dst per src, reserveOut / reserveIn, before fees and slippage.
+	Src   common.Address
+	Dst   common.Address
+	Price *big.Rat
+}
+
+// ReadPoolPrice reads the pool's reserves at the latest block and returns its spot
+// price, plus the reciprocal when req.Both is set
+func (s *EstimateServiceImpl) ReadPoolPrice(ctx context.Context, req PriceRequest) (*PoolPrice, error) {
+	if req.PoolAddress == "" {
+		return nil, fmt.Errorf("%w: pool address is required", apperrors.ErrValidation)
+	}
+	if err := validateAddressFormat("pool", req.PoolAddress); err != nil {
+		return nil, err
+	}
+	pool := common.HexToAddress(req.PoolAddress)
+	if (req.SrcToken == "") != (req.DstToken == "") {
+		return nil, fmt.Errorf("%w: src and dst must be given together", apperrors.ErrValidation)
+	}
+	oriented := req.SrcToken != ""
+	if oriented {
+		if err := validateAddressFormat("source token", req.SrcToken); err != nil {
+			return nil, err
+		}
+		if err := validateAddressFormat("destination token", req.DstToken); err != nil {
+			return nil, err
+		}
+	}
+
+	log := logger.FromContext(ctx, s.logger)
+	log.Info("Processing pool price request",
+		zap.String("pool", pool.Hex()),
+		zap.Bool("both", req.Both),
+		zap.String("src", req.SrcToken),
+		zap.String("dst", req.DstToken),
+	)
+
+	endBlock := timing.Start(ctx, timing.PhaseBlock)
+	blockNumber, err := s.uniswapV2Client.GetLatestBlockNumber(ctx)
+	endBlock()
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to connect to blockchain network: %v", apperrors.ErrExternalService, err)
+	}
+
+	snapshot, err := s.readPoolSnapshot(ctx, pool, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot.reserve0.Sign() == 0 || snapshot.reserve1.Sign() == 0 {
+		return nil, fmt.Errorf("%w: pool has empty reserves", apperrors.ErrBusinessRule)
+	}
+
+	result := &PoolPrice{
+		Pool:        pool,
+		BlockNumber: blockNumber,
+		Token0:      snapshot.token0,
+		Token1:      snapshot.token1,
+		Price1Per0:  new(big.Rat).SetFrac(snapshot.reserve1, snapshot.reserve0),
+	}
+	if req.Both {
+		result.Price0Per1 = new(big.Rat).SetFrac(snapshot.reserve0, snapshot.reserve1)
+	}
+	if oriented {
+		result.Src, result.Dst = common.HexToAddress(req.SrcToken), common.HexToAddress(req.DstToken)
+		reserveIn, reserveOut, _, err := s.orientReserves(snapshot, result.Src, result.Dst)
+		if err != nil {
+			return nil, err
+		}
+		result.Price = new(big.Rat).SetFrac(reserveOut, reserveIn)
+	}
+	return result, nil
+}
@@ -0,0 +1,52 @@
+package estimate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"bigswapenergy/internal/shared/utils"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// QuoteKey is everything that determines a quote's result
+type QuoteKey struct {
+	Pool           common.Address
+	Src            common.Address
+	Dst            common.Address
+	ExactOut       bool
+	Amounts        []*big.Int
+	BlockNumber    uint64
+	FeeBasisPoints int
+	FeeSide        utils.FeeSide
+}
+
+// ID returns a deterministic quote ID: identical inputs at the same block give
+// the same ID, so a client-reported quote can be traced to its inputs in the logs.
+// Unlike a request ID it is not unique per call.
+func (k QuoteKey) ID() string {
+	amounts := make([]string, len(k.Amounts))
+	for i, amount := range k.Amounts {
+		amounts[i] = amount.String()
+	}
+	direction := "in"
+	if k.ExactOut {
+		direction = "out"
+	}
+
+	canonical := strings.Join([]string{
+		k.Pool.Hex(),
+		k.Src.Hex(),
+		k.Dst.Hex(),
+		direction,
+		strings.Join(amounts, ","),
+		strconv.FormatUint(k.BlockNumber, 10),
+		strconv.Itoa(k.FeeBasisPoints),
+		k.FeeSide.String(),
+	}, "\n")
+	sum := sha256.Sum256([]byte(canonical))
+	return hex.EncodeToString(sum[:16])
+}
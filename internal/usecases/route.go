@@ -0,0 +1,131 @@
+package estimate
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	apperrors "bigswapenergy/internal/shared/errors"
+	"bigswapenergy/internal/shared/logger"
+	"bigswapenergy/internal/shared/timing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+// RouteRequest describes a multi-hop swap: Tokens[i] -> Tokens[i+1] through Pools[i]
+type RouteRequest struct {
+	Pools     []string
+	Tokens    []string
+	SrcAmount *big.Int
+
+	// Partial returns the hops that succeeded plus the failing hop instead of
+	// failing the whole request
+	Partial bool
+}
+
+// RouteHop is the outcome of a single hop
+type RouteHop struct {
+	Pool      common.Address
+	SrcToken  common.Address
+	DstToken  common.Address
+	AmountIn  *big.Int
+	AmountOut *big.Int
+	// Err is set on the failing hop of a partial route
+	Err error
+}
+
+// RouteResult holds every attempted hop in order
+type RouteResult struct {
+	Hops []RouteHop
+	// AmountOut is the final hop's output; nil if the route did not complete
+	AmountOut *big.Int
+	// Complete is false when a partial route stopped at a failing hop
+	Complete    bool
+	BlockNumber uint64
+}
+
+// EstimateRoute walks the route, feeding each hop's output into the next, with
+// every pool read at the same block
+func (s *EstimateServiceImpl) EstimateRoute(ctx context.Context, req RouteRequest) (*RouteResult, error) {
+	if len(req.Pools) == 0 {
+		return nil, fmt.Errorf("%w: route requires at least one pool", apperrors.ErrValidation)
+	}
+	if len(req.Tokens) != len(req.Pools)+1 {
+		return nil, fmt.Errorf("%w: route with %d pools requires %d tokens, got %d",
+			apperrors.ErrValidation, len(req.Pools), len(req.Pools)+1, len(req.Tokens))
+	}
+	if req.SrcAmount == nil || req.SrcAmount.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: source amount must be positive", apperrors.ErrValidation)
+	}
+
+	pools := make([]common.Address, len(req.Pools))
+	for i, pool := range req.Pools {
+		if err := validateAddressFormat("pool", pool); err != nil {
+			return nil, err
+		}
+		pools[i] = common.HexToAddress(pool)
+	}
+	tokens := make([]common.Address, len(req.Tokens))
+	for i, token := range req.Tokens {
+		if err := validateAddressFormat("token", token); err != nil {
+			return nil, err
+		}
+		tokens[i] = common.HexToAddress(token)
+	}
+	for i := range pools {
+		if tokens[i] == tokens[i+1] {
+			return nil, fmt.Errorf("%w: hop %d swaps a token for itself", apperrors.ErrBusinessRule, i)
+		}
+	}
+
+	log := logger.FromContext(ctx, s.logger)
+	log.Info("Processing route estimation request",
+		zap.Int("hops", len(pools)),
+		zap.String("src_token", tokens[0].Hex()),
+		zap.String("dst_token", tokens[len(tokens)-1].Hex()),
+		zap.Stringer("src_amount", req.SrcAmount),
+		zap.Bool("partial", req.Partial),
+	)
+
+	endBlock := timing.Start(ctx, timing.PhaseBlock)
+	blockNumber, err := s.uniswapV2Client.GetLatestBlockNumber(ctx)
+	endBlock()
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to connect to blockchain network: %v", apperrors.ErrExternalService, err)
+	}
+
+	result := &RouteResult{BlockNumber: blockNumber}
+	amount := req.SrcAmount
+	for i, pool := range pools {
+		hop := RouteHop{Pool: pool, SrcToken: tokens[i], DstToken: tokens[i+1], AmountIn: amount}
+
+		amountOut, err := s.quoteHop(ctx, pool, tokens[i], tokens[i+1], blockNumber, amount)
+		if err != nil {
+			err = fmt.Errorf("hop %d: %w", i, err)
+			if !req.Partial {
+				return nil, err
+			}
+			hop.Err = err
+			result.Hops = append(result.Hops, hop)
+			return result, nil
+		}
+
+		hop.AmountOut = amountOut
+		result.Hops = append(result.Hops, hop)
+		amount = amountOut
+	}
+
+	result.AmountOut = amount
+	result.Complete = true
+	return result, nil
+}
+
+// quoteHop reads a single pool at blockNumber and quotes amount of src -> dst
+func (s *EstimateServiceImpl) quoteHop(ctx context.Context, pool, src, dst common.Address, blockNumber uint64, amount *big.Int) (*big.Int, error) {
+	state, err := s.readLeg(ctx, pool, src, dst, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	return state.quote(amount)
+}
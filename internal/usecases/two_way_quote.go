@@ -0,0 +1,120 @@
+package estimate
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+
+	apperrors "bigswapenergy/internal/shared/errors"
+	"bigswapenergy/internal/shared/logger"
+	"bigswapenergy/internal/shared/timing"
+	"bigswapenergy/internal/shared/utils"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+// TwoWayQuoteRequest asks for both sides of a market on one pool. Amount is spent
+// in the source token of each leg: base when selling, quote when buying.
+type TwoWayQuoteRequest struct {
+	PoolAddress string
+	BaseToken   string
+	QuoteToken  string
+	Amount      *big.Int
+}
+
+// TwoWayQuoteResult holds both legs, computed from the same reserve snapshot
+type TwoWayQuoteResult struct {
+	// Sell is the quote token received for Amount of base (base -> quote)
+	Sell *big.Int
+	// Buy is the base token received for Amount of quote (quote -> base)
+	Buy *big.Int
+	// BlockNumber is the block the reserves were read at
+	BlockNumber uint64
+}
+
+// EstimateTwoWayQuote quotes base -> quote and quote -> base from a single
+// LoadReserves call so both sides reflect the same state
+func (s *EstimateServiceImpl) EstimateTwoWayQuote(ctx context.Context, req TwoWayQuoteRequest) (*TwoWayQuoteResult, error) {
+	if req.PoolAddress == "" {
+		return nil, fmt.Errorf("%w: pool address is required", apperrors.ErrValidation)
+	}
+	if req.BaseToken == "" {
+		return nil, fmt.Errorf("%w: base token address is required", apperrors.ErrValidation)
+	}
+	if req.QuoteToken == "" {
+		return nil, fmt.Errorf("%w: quote token address is required", apperrors.ErrValidation)
+	}
+	if req.Amount == nil || req.Amount.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: amount must be positive", apperrors.ErrValidation)
+	}
+
+	if err := validateAddressFormat("pool", req.PoolAddress); err != nil {
+		return nil, err
+	}
+	if err := validateAddressFormat("base token", req.BaseToken); err != nil {
+		return nil, err
+	}
+	if err := validateAddressFormat("quote token", req.QuoteToken); err != nil {
+		return nil, err
+	}
+
+	pool := common.HexToAddress(req.PoolAddress)
+	base := common.HexToAddress(req.BaseToken)
+	quote := common.HexToAddress(req.QuoteToken)
+
+	if bytes.Equal(base[:], quote[:]) {
+		return nil, fmt.Errorf("%w: base and quote tokens cannot be the same", apperrors.ErrBusinessRule)
+	}
+
+	log := logger.FromContext(ctx, s.logger)
+	log.Info("Processing two-way quote request",
+		zap.String("pool", pool.Hex()),
+		zap.String("base_token", base.Hex()),
+		zap.String("quote_token", quote.Hex()),
+		zap.Stringer("amount", req.Amount),
+	)
+
+	endBlock := timing.Start(ctx, timing.PhaseBlock)
+	blockNumber, err := s.uniswapV2Client.GetLatestBlockNumber(ctx)
+	endBlock()
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to connect to blockchain network: %v", apperrors.ErrExternalService, err)
+	}
+
+	snapshot, err := s.readPoolSnapshot(ctx, pool, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	sell, err := s.quoteSnapshot(snapshot, pool, base, quote, blockNumber, req.Amount)
+	if err != nil {
+		return nil, fmt.Errorf("sell: %w", err)
+	}
+	buy, err := s.quoteSnapshot(snapshot, pool, quote, base, blockNumber, req.Amount)
+	if err != nil {
+		return nil, fmt.Errorf("buy: %w", err)
+	}
+
+	return &TwoWayQuoteResult{Sell: sell, Buy: buy, BlockNumber: blockNumber}, nil
+}
+
+// quoteSnapshot quotes amount of src -> dst against an already-read snapshot
+func (s *EstimateServiceImpl) quoteSnapshot(snapshot *poolSnapshot, pool, src, dst common.Address, blockNumber uint64, amount *big.Int) (*big.Int, error) {
+	reserveIn, reserveOut, _, err := s.orientReserves(snapshot, src, dst)
+	if err != nil {
+		return nil, err
+	}
+	state := &swapState{
+		pool:           pool,
+		src:            src,
+		dst:            dst,
+		blockNumber:    blockNumber,
+		reserveIn:      reserveIn,
+		reserveOut:     reserveOut,
+		feeBasisPoints: defaultFeeBasisPoints,
+		feeSide:        utils.FeeOnInput,
+	}
+	return state.quote(amount)
+}
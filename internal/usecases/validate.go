@@ -0,0 +1,181 @@
+package estimate
+
+import (
+	"fmt"
+	"strings"
+
+	apperrors "bigswapenergy/internal/shared/errors"
+	"bigswapenergy/internal/shared/utils"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ValidateAndNormalize checks an EstimateRequest and rewrites its addresses in
+// checksummed form. It is the single source of request validation: the HTTP
+// handler calls it after parsing and the service calls it again for direct
+// callers, so both entry points accept exactly the same requests.
+func ValidateAndNormalize(req *EstimateRequest) error {
+	req.PoolAddress = strings.TrimSpace(req.PoolAddress)
+	req.SrcToken = strings.TrimSpace(req.SrcToken)
+	req.DstToken = strings.TrimSpace(req.DstToken)
+	req.Factory = strings.TrimSpace(req.Factory)
+
+	switch {
+	case req.PoolAddress == "" && req.Factory == "":
+		return fmt.Errorf("%w: pool address is required", apperrors.ErrValidation)
+	case req.PoolAddress != "" && req.Factory != "":
+		return fmt.Errorf("%w: pool and factory are mutually exclusive", apperrors.ErrValidation)
+	case req.SrcToken == "":
+		return fmt.Errorf("%w: source token address is required", apperrors.ErrValidation)
+	case req.DstToken == "":
+		return fmt.Errorf("%w: destination token address is required", apperrors.ErrValidation)
+	}
+
+	if req.PoolAddress != "" {
+		if err := validateAddressFormat("pool", req.PoolAddress); err != nil {
+			return err
+		}
+		req.PoolAddress = common.HexToAddress(req.PoolAddress).Hex()
+	}
+	if err := validateAddressFormat("source token", req.SrcToken); err != nil {
+		return err
+	}
+	if err := validateAddressFormat("destination token", req.DstToken); err != nil {
+		return err
+	}
+	req.SrcToken = common.HexToAddress(req.SrcToken).Hex()
+	req.DstToken = common.HexToAddress(req.DstToken).Hex()
+	if req.SrcToken == req.DstToken {
+		if !req.AllowIdentity {
+			return fmt.Errorf("%w: source and destination tokens cannot be the same", apperrors.ErrBusinessRule)
+		}
+		if req.ItemStatus || req.ShowMath || req.PriceImpact || len(req.FeeTiers) > 0 {
+			return fmt.Errorf("%w: an identity quote supports only plain amounts", apperrors.ErrValidation)
+		}
+	}
+
+	if err := req.validateAmounts(); err != nil {
+		return err
+	}
+	if err := req.resolveFeeName(); err != nil {
+		return err
+	}
+	if err := req.validateFees(); err != nil {
+		return err
+	}
+	if req.Block != nil && req.BlockOffset > 0 {
+		return fmt.Errorf("%w: block and block_offset are mutually exclusive", apperrors.ErrValidation)
+	}
+	if req.BlockOffset > MaxBlockOffset {
+		return fmt.Errorf("%w: block offset %d exceeds the maximum of %d", apperrors.ErrValidation, req.BlockOffset, MaxBlockOffset)
+	}
+	if req.ShowMath && (req.DstAmount != nil || len(req.SrcAmounts) > 1 || req.FeeSide != utils.FeeOnInput) {
+		return fmt.Errorf("%w: show_math supports a single src_amount with the fee on input", apperrors.ErrValidation)
+	}
+	if req.PriceImpact && (req.DstAmount != nil || len(req.SrcAmounts) > 1 || req.ItemStatus || req.ShowMath || req.HumanAmounts || len(req.FeeTiers) > 0) {
+		return fmt.Errorf("%w: price_impact supports a single src_amount", apperrors.ErrValidation)
+	}
+	if req.SlippageBps != nil {
+		if *req.SlippageBps < 0 || *req.SlippageBps > 10000 {
+			return fmt.Errorf("%w: slippage_bps must be between 0 and 10000, got %d", apperrors.ErrValidation, *req.SlippageBps)
+		}
+		if req.DstAmount != nil || len(req.SrcAmounts) > 1 || req.ItemStatus || len(req.FeeTiers) > 0 {
+			return fmt.Errorf("%w: slippage_bps supports a single src_amount", apperrors.ErrValidation)
+		}
+	}
+	if req.DstDecimals != nil && !req.HumanAmounts {
+		return fmt.Errorf("%w: dst_decimals requires human-readable output", apperrors.ErrValidation)
+	}
+	if req.HumanAmounts && (req.DstAmount != nil || req.ItemStatus || req.ShowMath || len(req.FeeTiers) > 0) {
+		return fmt.Errorf("%w: human-readable output supports exact-in plain amounts", apperrors.ErrValidation)
+	}
+	if len(req.FeeTiers) > 0 {
+		if req.DstAmount != nil || len(req.SrcAmounts) > 1 || req.ItemStatus || req.ShowMath {
+			return fmt.Errorf("%w: fee_tiers supports a single src_amount", apperrors.ErrValidation)
+		}
+		for _, fee := range req.FeeTiers {
+			if fee < 0 || fee >= 10000 {
+				return fmt.Errorf("%w: fee must be between 0 and 9999 bps, got %d", apperrors.ErrValidation, fee)
+			}
+		}
+	}
+	return nil
+}
+
+// resolveFeeName turns a protocol fee name into FeeBasisPoints. The name becomes
+// FeeNameCustom once resolved, so validating the request again is a no-op.
+func (req *EstimateRequest) resolveFeeName() error {
+	req.FeeName = strings.TrimSpace(req.FeeName)
+	switch req.FeeName {
+	case "":
+		return nil
+	case utils.FeeNameCustom:
+		if req.FeeBasisPoints == nil {
+			return fmt.Errorf("%w: fee=custom requires fee_bps", apperrors.ErrValidation)
+		}
+		return nil
+	}
+
+	if req.FeeBasisPoints != nil {
+		return fmt.Errorf("%w: fee_bps can only be combined with fee=custom", apperrors.ErrValidation)
+	}
+	fee, err := utils.ResolveFeeName(req.FeeName)
+	if err != nil {
+		return fmt.Errorf("%w: %w", apperrors.ErrValidation, err)
+	}
+	req.FeeBasisPoints = &fee
+	req.FeeName = utils.FeeNameCustom
+	return nil
+}
+
+// validateAmounts checks the exact-in or exact-out amounts and makes SrcAmount
+// the first of SrcAmounts when a list is given
+func (req *EstimateRequest) validateAmounts() error {
+	// A token amount is scaled and checked once the token's decimals are read
+	if req.SrcTokenAmount != "" {
+		if req.SrcAmount != nil || len(req.SrcAmounts) > 0 || req.DstAmount != nil || req.ItemStatus {
+			return fmt.Errorf("%w: an amount in source tokens must be the only, plain exact-in amount", apperrors.ErrValidation)
+		}
+		return nil
+	}
+	if req.DstAmount != nil {
+		if req.SrcAmount != nil || len(req.SrcAmounts) > 0 {
+			return fmt.Errorf("%w: src_amount and dst_amount are mutually exclusive", apperrors.ErrValidation)
+		}
+		if req.DstAmount.Sign() <= 0 {
+			return fmt.Errorf("%w: destination amount must be positive", apperrors.ErrValidation)
+		}
+		return nil
+	}
+
+	if len(req.SrcAmounts) > 0 {
+		req.SrcAmount = req.SrcAmounts[0]
+	}
+	if req.SrcAmount == nil {
+		return fmt.Errorf("%w: one of src_amount (exact-in) or dst_amount (exact-out) is required", apperrors.ErrValidation)
+	}
+
+	// Item status mode reports non-positive amounts per item instead
+	if req.ItemStatus {
+		return nil
+	}
+	for _, amount := range req.srcAmounts() {
+		if amount == nil || amount.Sign() <= 0 {
+			return fmt.Errorf("%w: source amount must be positive", apperrors.ErrValidation)
+		}
+	}
+	return nil
+}
+
+// ValidateChecksumAddress checks that address is 0x followed by 40 hex digits in
+// exactly its EIP-55 mixed-case form, so a mistyped character is caught rather
+// than silently naming another address. param names the offending input.
+func ValidateChecksumAddress(param, address string) error {
+	if len(address) != 42 || !common.IsHexAddress(address) || !strings.HasPrefix(address, "0x") {
+		return fmt.Errorf("%w: %s must be a 0x-prefixed 40 hex digit address, got %q", apperrors.ErrValidation, param, address)
+	}
+	if checksummed := common.HexToAddress(address).Hex(); address != checksummed {
+		return fmt.Errorf("%w: %s fails its EIP-55 checksum, expected %s", apperrors.ErrValidation, param, checksummed)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package tests
+
+import (
+	"context"
+	"net"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"bigswapenergy/internal/app"
+	"bigswapenergy/internal/shared/config"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// closeTrackingEthClient records whether the app closed its RPC client
+type closeTrackingEthClient struct {
+	fakeEthereumClient
+	closed bool
+}
+
+func (c *closeTrackingEthClient) Close() error {
+	c.closed = true
+	return nil
+}
+
+func freeAddress(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to reserve a port: %v", err)
+	}
+	defer listener.Close()
+	return listener.Addr().String()
+}
+
+func TestApp_ShutdownStopsEverythingInOrder(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Address:         freeAddress(t),
+			ShutdownTimeout: 5 * time.Second,
+		},
+		RateLimit: config.RateLimitConfig{RequestsPerMinute: 100},
+		Cache:     config.CacheConfig{TokenTTL: time.Hour},
+	}
+	core, logs := observer.New(zapcore.InfoLevel)
+	ethClient := &closeTrackingEthClient{}
+
+	application, err := app.New(cfg, ethClient, zap.New(core))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- application.Run(ctx) }()
+
+	// Wait until the server accepts connections so shutdown has something to drain
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		conn, err := net.Dial("tcp", cfg.Server.Address)
+		if err == nil {
+			conn.Close()
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("server never started listening: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Expected a clean shutdown, got %v", err)
+		}
+	case <-time.After(cfg.Server.ShutdownTimeout):
+		t.Fatal("Run did not return within the shutdown timeout")
+	}
+
+	if !ethClient.closed {
+		t.Error("Expected the Ethereum client to be closed")
+	}
+
+	// Every goroutine the app started, janitors included, must have exited. Only
+	// the app's own goroutines are checked: fasthttp's worker pool cleaner notices
+	// the shutdown on its own schedule.
+	deadline = time.Now().Add(cfg.Server.ShutdownTimeout)
+	for {
+		leaked := appGoroutines()
+		if len(leaked) == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected every app goroutine to exit after shutdown, still running:\n%s", strings.Join(leaked, "\n\n"))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	order := []string{
+		"Server shutdown completed successfully",
+		"Stopped background janitors",
+		"Closed Ethereum client",
+	}
+	entries := logs.All()
+	next := 0
+	for _, entry := range entries {
+		if next < len(order) && entry.Message == order[next] {
+			next++
+		}
+	}
+	if next != len(order) {
+		t.Errorf("Expected shutdown steps in order %v, got log %v", order, messages(entries))
+	}
+}
+
+// appGoroutines returns the stacks of running goroutines executing code from this
+// repository's internal packages
+func appGoroutines() []string {
+	buf := make([]byte, 1<<20)
+	stacks := strings.Split(string(buf[:runtime.Stack(buf, true)]), "\n\n")
+
+	var running []string
+	for _, stack := range stacks {
+		if strings.Contains(stack, "bigswapenergy/internal/") {
+			running = append(running, stack)
+		}
+	}
+	return running
+}
+
+func messages(entries []observer.LoggedEntry) []string {
+	out := make([]string, len(entries))
+	for i, entry := range entries {
+		out[i] = entry.Message
+	}
+	return out
+}
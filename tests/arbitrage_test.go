@@ -0,0 +1,211 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"bigswapenergy/internal/presentation/http"
+	"bigswapenergy/internal/shared/config"
+	apperrors "bigswapenergy/internal/shared/errors"
+	usecases "bigswapenergy/internal/usecases"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+const testPoolB = "0xA478c2975Ab1Ea89e8196811F51A7B7Ade33eB11"
+
+func arbitrageRequest(amount int64) usecases.ArbitrageRequest {
+	return usecases.ArbitrageRequest{
+		PoolA:     testPool,
+		PoolB:     testPoolB,
+		SrcToken:  testToken0.Hex(),
+		DstToken:  testToken1.Hex(),
+		SrcAmount: big.NewInt(amount),
+	}
+}
+
+func TestEstimateArbitrage_Profit(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	client.poolReserves = map[common.Address][2]*big.Int{
+		common.HexToAddress(testPoolB): {big.NewInt(1_100_000), big.NewInt(1_000_000)},
+	}
+	service := createEstimateService(client)
+
+	result, err := service.EstimateArbitrage(context.Background(), arbitrageRequest(1000))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result.IntermediateAmount.Cmp(big.NewInt(996)) != 0 {
+		t.Errorf("Expected intermediate amount 996, got %s", result.IntermediateAmount)
+	}
+	if result.FinalAmount.Cmp(big.NewInt(1091)) != 0 {
+		t.Errorf("Expected final amount 1091, got %s", result.FinalAmount)
+	}
+	if result.Profit.Cmp(big.NewInt(91)) != 0 {
+		t.Errorf("Expected profit 91, got %s", result.Profit)
+	}
+}
+
+func TestEstimateArbitrage_LossIsNegative(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	service := createEstimateService(client)
+
+	result, err := service.EstimateArbitrage(context.Background(), arbitrageRequest(1000))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Identical pools only lose the fee on each leg
+	if result.Profit.Cmp(big.NewInt(-8)) != 0 {
+		t.Errorf("Expected profit -8, got %s", result.Profit)
+	}
+}
+
+func TestEstimateArbitrage_ReadsBothPoolsAtSameBlock(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	service := createEstimateService(client)
+
+	result, err := service.EstimateArbitrage(context.Background(), arbitrageRequest(1000))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(client.reservesBlocks) != 2 {
+		t.Fatalf("Expected 2 reserve reads, got %d", len(client.reservesBlocks))
+	}
+	for _, block := range client.reservesBlocks {
+		if block != result.BlockNumber {
+			t.Errorf("Expected reads at block %d, got %d", result.BlockNumber, block)
+		}
+	}
+}
+
+func TestEstimateArbitrage_Validation(t *testing.T) {
+	service := createEstimateService(newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000)))
+
+	samePools := arbitrageRequest(1000)
+	samePools.PoolB = samePools.PoolA
+	if _, err := service.EstimateArbitrage(context.Background(), samePools); !errors.Is(err, apperrors.ErrBusinessRule) {
+		t.Errorf("Expected business rule error for identical pools, got %v", err)
+	}
+
+	missingPool := arbitrageRequest(1000)
+	missingPool.PoolB = ""
+	if _, err := service.EstimateArbitrage(context.Background(), missingPool); !errors.Is(err, apperrors.ErrValidation) {
+		t.Errorf("Expected validation error for missing pool, got %v", err)
+	}
+
+	if _, err := service.EstimateArbitrage(context.Background(), arbitrageRequest(0)); !errors.Is(err, apperrors.ErrValidation) {
+		t.Errorf("Expected validation error for zero amount, got %v", err)
+	}
+}
+
+func TestEstimateArbitrageHandler_Success(t *testing.T) {
+	mockService := &mockEstimateService{
+		arbitrageResult: &usecases.ArbitrageResult{
+			IntermediateAmount: big.NewInt(996),
+			FinalAmount:        big.NewInt(1091),
+			Profit:             big.NewInt(91),
+			BlockNumber:        20_000_000,
+		},
+	}
+	handler := createEstimateHandler(mockService)
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	req.SetRequestURI("/estimate/arb?pool_a=0x1&pool_b=0x2&src=0x3&dst=0x4&src_amount=1000")
+	req.Header.SetMethod("GET")
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(req, nil, nil)
+
+	handler.EstimateArbitrage(ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d", ctx.Response.StatusCode())
+	}
+
+	var body map[string]any
+	if err := json.Unmarshal(ctx.Response.Body(), &body); err != nil {
+		t.Fatalf("Expected JSON body, got %q", ctx.Response.Body())
+	}
+	if body["profit"] != "91" || body["final_amount"] != "1091" || body["intermediate_amount"] != "996" {
+		t.Errorf("Unexpected response body: %s", ctx.Response.Body())
+	}
+	if mockService.lastArbitrage.PoolA != "0x1" || mockService.lastArbitrage.PoolB != "0x2" {
+		t.Errorf("Expected pools to be forwarded, got %+v", mockService.lastArbitrage)
+	}
+}
+
+func TestEstimateArbitrageHandler_MissingPool(t *testing.T) {
+	mockService := &mockEstimateService{}
+	handler := createEstimateHandler(mockService)
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	req.SetRequestURI("/estimate/arb?pool_a=0x1&src=0x3&dst=0x4&src_amount=1000")
+	req.Header.SetMethod("GET")
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(req, nil, nil)
+
+	handler.EstimateArbitrage(ctx)
+
+	if ctx.Response.StatusCode() == fasthttp.StatusOK {
+		t.Error("Expected an error status for missing pool_b")
+	}
+	if mockService.lastArbitrage.PoolA != "" {
+		t.Error("Expected service not to be called")
+	}
+}
+
+func runArbitrageWithPoolLimit(t *testing.T, limit int) (*mockEstimateService, int) {
+	t.Helper()
+
+	mockService := &mockEstimateService{
+		arbitrageResult: &usecases.ArbitrageResult{
+			IntermediateAmount: big.NewInt(996),
+			FinalAmount:        big.NewInt(992),
+			Profit:             big.NewInt(-8),
+		},
+	}
+	cfg := &config.Config{
+		Server:    config.ServerConfig{MaxPoolsPerRequest: limit},
+		RateLimit: config.RateLimitConfig{RequestsPerMinute: 100},
+	}
+	handler := http.NewEstimateHandler(mockService, zap.NewNop(), cfg)
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	req.SetRequestURI("/estimate/arb?pool_a=0x1&pool_b=0x2&src=0x3&dst=0x4&src_amount=1000")
+	req.Header.SetMethod("GET")
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(req, nil, nil)
+	handler.EstimateArbitrage(ctx)
+
+	return mockService, ctx.Response.StatusCode()
+}
+
+func TestEstimateArbitrageHandler_AtPoolLimit(t *testing.T) {
+	_, status := runArbitrageWithPoolLimit(t, 2)
+	if status != fasthttp.StatusOK {
+		t.Errorf("Expected status 200 at the pool limit, got %d", status)
+	}
+}
+
+func TestEstimateArbitrageHandler_OverPoolLimit(t *testing.T) {
+	mockService, status := runArbitrageWithPoolLimit(t, 1)
+	if status == fasthttp.StatusOK {
+		t.Error("Expected an error status over the pool limit")
+	}
+	if mockService.lastArbitrage.PoolA != "" {
+		t.Error("Expected service not to be called")
+	}
+}
@@ -0,0 +1,105 @@
+package tests
+
+import (
+	"slices"
+	"testing"
+
+	"bigswapenergy/internal/presentation/http"
+	"bigswapenergy/internal/shared/config"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// apiKeyConfigurable requires key-one and rate limits to one request per minute
+type apiKeyConfigurable struct{}
+
+func (apiKeyConfigurable) GetAPIKeys() []string {
+	return []string{"key-one"}
+}
+
+func (apiKeyConfigurable) GetRateLimitConfig() http.HTTPRateLimitConfig {
+	return http.HTTPRateLimitConfig{RequestsPerMinute: 1}
+}
+
+func doKeyedRequest(handler fasthttp.RequestHandler, path, key string) *fasthttp.RequestCtx {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI(path)
+	if key != "" {
+		ctx.Request.Header.Set("X-API-Key", key)
+	}
+	handler(ctx)
+	return ctx
+}
+
+func TestAPIKeyMiddleware_RejectsMissingAndInvalidKeys(t *testing.T) {
+	var seen string
+	handler := http.NewAPIKeyMiddleware([]string{"key-one", "key-two"}, zap.NewNop()).Apply(func(ctx *fasthttp.RequestCtx) {
+		seen = http.APIKeyFromContext(ctx)
+	})
+
+	for _, key := range []string{"", "wrong", "key-on"} {
+		ctx := doKeyedRequest(handler, "/estimate", key)
+		if ctx.Response.StatusCode() != fasthttp.StatusUnauthorized {
+			t.Errorf("key %q: expected 401, got %d", key, ctx.Response.StatusCode())
+		}
+		if body := string(ctx.Response.Body()); body != `{"error":{"code":"UNAUTHORIZED","message":"Missing or invalid API key"}}` {
+			t.Errorf("key %q: unexpected body %s", key, body)
+		}
+	}
+
+	if ctx := doKeyedRequest(handler, "/estimate", "key-two"); ctx.Response.StatusCode() != fasthttp.StatusOK || seen != "key-two" {
+		t.Errorf("Expected key-two accepted and exposed, got %d %q", ctx.Response.StatusCode(), seen)
+	}
+}
+
+func TestAPIKeyMiddleware_ProbesExempt(t *testing.T) {
+	handler := http.NewAPIKeyMiddleware([]string{"key-one"}, zap.NewNop()).Apply(okHandler)
+	for _, path := range []string{"/health", "/ready"} {
+		if ctx := doKeyedRequest(handler, path, ""); ctx.Response.StatusCode() != fasthttp.StatusOK {
+			t.Errorf("Expected %s served without a key, got %d", path, ctx.Response.StatusCode())
+		}
+	}
+}
+
+func TestApplyMiddleware_AuthAheadOfRateLimit(t *testing.T) {
+	handler, rateLimiter := http.ApplyMiddleware(okHandler, zap.NewNop(), apiKeyConfigurable{})
+	defer rateLimiter.Stop()
+
+	// Rejected requests don't spend the client's budget
+	for i := 0; i < 3; i++ {
+		if ctx := doKeyedRequest(handler, "/estimate", ""); ctx.Response.StatusCode() != fasthttp.StatusUnauthorized {
+			t.Fatalf("request %d: expected 401, got %d", i, ctx.Response.StatusCode())
+		}
+	}
+	if ctx := doKeyedRequest(handler, "/estimate", "key-one"); ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected an authenticated request served, got %d", ctx.Response.StatusCode())
+	}
+	if ctx := doKeyedRequest(handler, "/estimate", "key-one"); ctx.Response.StatusCode() != fasthttp.StatusTooManyRequests {
+		t.Errorf("Expected the rate limit to still apply, got %d", ctx.Response.StatusCode())
+	}
+}
+
+type noAPIKeys struct{}
+
+func (noAPIKeys) GetAPIKeys() []string { return nil }
+
+func TestApplyMiddleware_NoKeysDisablesAuth(t *testing.T) {
+	handler, _ := http.ApplyMiddleware(okHandler, zap.NewNop(), noAPIKeys{})
+	if ctx := doKeyedRequest(handler, "/estimate", ""); ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Errorf("Expected requests served without keys configured, got %d", ctx.Response.StatusCode())
+	}
+}
+
+func TestLoadConfig_APIKeysFromEnv(t *testing.T) {
+	t.Setenv("ETHEREUM_RPC_URL", "http://localhost:8545")
+	t.Setenv("API_KEYS", " key-one, ,key-two")
+
+	cfg, err := config.LoadConfig("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(cfg.Server.APIKeys, []string{"key-one", "key-two"}) {
+		t.Errorf("Expected both keys trimmed, got %q", cfg.Server.APIKeys)
+	}
+}
@@ -0,0 +1,139 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"bigswapenergy/internal/presentation/http"
+	"bigswapenergy/internal/shared/config"
+	usecases "bigswapenergy/internal/usecases"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+func runBatch(handler *http.EstimateHandler, method, body string) *fasthttp.RequestCtx {
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	req.SetRequestURI("/estimate/batch")
+	req.Header.SetMethod(method)
+	req.SetBodyString(body)
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(req, nil, nil)
+	handler.EstimateBatch(ctx)
+	return ctx
+}
+
+func createBatchHandler(service usecases.EstimateService, workers int) *http.EstimateHandler {
+	cfg := &config.Config{
+		Server:    config.ServerConfig{BatchWorkers: workers},
+		RateLimit: config.RateLimitConfig{RequestsPerMinute: 100},
+	}
+	return http.NewEstimateHandler(service, zap.NewNop(), cfg)
+}
+
+func batchItem(src, dst, amount string) string {
+	return fmt.Sprintf(`{"pool":%q,"src":%q,"dst":%q,"src_amount":%q}`, testPool, src, dst, amount)
+}
+
+func decodeBatch(t *testing.T, ctx *fasthttp.RequestCtx) []http.BatchEstimateResult {
+	t.Helper()
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	var results []http.BatchEstimateResult
+	if err := json.Unmarshal(ctx.Response.Body(), &results); err != nil {
+		t.Fatalf("invalid JSON response: %v", err)
+	}
+	return results
+}
+
+func TestEstimateBatch_PerItemResults(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	handler := createBatchHandler(createEstimateService(client), 4)
+
+	body := "[" + strings.Join([]string{
+		batchItem(testToken0.Hex(), testToken1.Hex(), "1000"),
+		batchItem(testToken0.Hex(), testToken0.Hex(), "1000"),
+		batchItem(testToken0.Hex(), testToken1.Hex(), "-1"),
+		batchItem(testToken1.Hex(), testToken0.Hex(), "0x3e8"),
+	}, ",") + "]"
+
+	results := decodeBatch(t, runBatch(handler, "POST", body))
+	if len(results) != 4 {
+		t.Fatalf("Expected 4 results, got %+v", results)
+	}
+	if results[0].AmountOut != "996" || results[0].Error != "" {
+		t.Errorf("Unexpected first result: %+v", results[0])
+	}
+	if results[1].AmountOut != "" || !strings.Contains(results[1].Error, "cannot be the same") {
+		t.Errorf("Expected an inline error for identical tokens, got %+v", results[1])
+	}
+	if results[2].AmountOut != "" || !strings.Contains(results[2].Error, "must be positive") {
+		t.Errorf("Expected an inline error for a negative amount, got %+v", results[2])
+	}
+	if results[3].AmountOut != "996" {
+		t.Errorf("Expected the hex amount to be quoted in order, got %+v", results[3])
+	}
+}
+
+func TestEstimateBatch_RunsConcurrently(t *testing.T) {
+	service := &mockEstimateService{estimateAmount: big.NewInt(996), delay: 100 * time.Millisecond}
+	handler := createBatchHandler(service, 10)
+
+	items := make([]string, 10)
+	for i := range items {
+		items[i] = batchItem(testToken0.Hex(), testToken1.Hex(), "100")
+	}
+
+	start := time.Now()
+	results := decodeBatch(t, runBatch(handler, "POST", "["+strings.Join(items, ",")+"]"))
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected items to be quoted concurrently, took %v", elapsed)
+	}
+	for i, result := range results {
+		if result.AmountOut != "996" {
+			t.Errorf("item %d: unexpected result %+v", i, result)
+		}
+	}
+}
+
+func TestEstimateBatch_RejectsOversizedBatch(t *testing.T) {
+	service := &mockEstimateService{estimateAmount: big.NewInt(996)}
+	handler := createBatchHandler(service, 4)
+
+	items := make([]string, 51)
+	for i := range items {
+		items[i] = batchItem(testToken0.Hex(), testToken1.Hex(), "100")
+	}
+
+	ctx := runBatch(handler, "POST", "["+strings.Join(items, ",")+"]")
+	if ctx.Response.StatusCode() != fasthttp.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d", ctx.Response.StatusCode())
+	}
+	var resp map[string]http.ErrorResponse
+	if err := json.Unmarshal(ctx.Response.Body(), &resp); err != nil || resp["error"].Code != "VALIDATION_ERROR" {
+		t.Errorf("Expected a validation error, got %s", ctx.Response.Body())
+	}
+	if service.lastRequest.PoolAddress != "" {
+		t.Error("Expected the service not to be called")
+	}
+}
+
+func TestEstimateBatch_InvalidRequests(t *testing.T) {
+	handler := createBatchHandler(&mockEstimateService{}, 4)
+
+	if ctx := runBatch(handler, "GET", ""); ctx.Response.StatusCode() != fasthttp.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405 for GET, got %d", ctx.Response.StatusCode())
+	}
+	for _, body := range []string{`not json`, `{"pool":"x"}`, `[]`} {
+		if ctx := runBatch(handler, "POST", body); ctx.Response.StatusCode() != fasthttp.StatusBadRequest {
+			t.Errorf("body %q: expected status 400, got %d", body, ctx.Response.StatusCode())
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package tests
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"bigswapenergy/internal/presentation/http"
+	"bigswapenergy/internal/shared/metrics"
+	"bigswapenergy/internal/shared/utils"
+	usecases "bigswapenergy/internal/usecases"
+)
+
+func TestEstimateService_BigIntPoolBalanced(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(2_000_000))
+	service := createEstimateService(client)
+	fee := 7
+
+	before := utils.GlobalBigIntPool.Stats().Outstanding()
+	for i := 0; i < 50; i++ {
+		reqs := []usecases.EstimateRequest{
+			{PoolAddress: testPool, SrcToken: testToken0.Hex(), DstToken: testToken1.Hex(), SrcAmount: big.NewInt(int64(1_000 + i))},
+			{PoolAddress: testPool, SrcToken: testToken0.Hex(), DstToken: testToken1.Hex(), SrcAmounts: []*big.Int{big.NewInt(10), big.NewInt(20)}, FeeBasisPoints: &fee},
+			{PoolAddress: testPool, SrcToken: testToken0.Hex(), DstToken: testToken1.Hex(), DstAmount: big.NewInt(int64(500 + i)), FeeSide: utils.FeeOnOutput},
+			// Rounds to zero, taking the early-return error path
+			{PoolAddress: testPool, SrcToken: testToken1.Hex(), DstToken: testToken0.Hex(), SrcAmount: big.NewInt(1)},
+		}
+		for _, req := range reqs {
+			service.EstimateSwap(context.Background(), req)
+		}
+	}
+
+	if after := utils.GlobalBigIntPool.Stats().Outstanding(); after != before {
+		t.Fatalf("Expected balanced Get/Put across estimates, outstanding went from %d to %d", before, after)
+	}
+}
+
+func TestRegisterBigIntPoolMetrics(t *testing.T) {
+	registry := metrics.NewRegistry()
+	pool := utils.NewBigIntPool()
+	http.RegisterBigIntPoolMetrics(registry, pool)
+
+	pool.Put(pool.Get())
+	pool.Put(pool.Get())
+	held := pool.Get()
+
+	snapshot := registry.Snapshot()
+	if snapshot[http.MetricBigIntPoolGets] != 3 || snapshot[http.MetricBigIntPoolPuts] != 2 {
+		t.Errorf("Expected 3 gets and 2 puts, got %v", snapshot)
+	}
+	if snapshot[http.MetricBigIntPoolOutstanding] != 1 {
+		t.Errorf("Expected 1 outstanding value, got %d", snapshot[http.MetricBigIntPoolOutstanding])
+	}
+	pool.Put(held)
+	if got := registry.Snapshot()[http.MetricBigIntPoolOutstanding]; got != 0 {
+		t.Errorf("Expected the gauge to track the Put, got %d", got)
+	}
+}
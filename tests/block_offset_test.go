@@ -0,0 +1,150 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	apperrors "bigswapenergy/internal/shared/errors"
+	usecases "bigswapenergy/internal/usecases"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestBlockAtOffset(t *testing.T) {
+	cases := []struct {
+		head, offset, want uint64
+		wantErr            bool
+	}{
+		{head: 20_000_000, offset: 0, want: 20_000_000},
+		{head: 20_000_000, offset: 5, want: 19_999_995},
+		{head: 20_000_000, offset: usecases.MaxBlockOffset, want: 20_000_000 - usecases.MaxBlockOffset},
+		{head: 20_000_000, offset: usecases.MaxBlockOffset + 1, wantErr: true},
+		{head: 3, offset: 3, want: 0},
+		{head: 3, offset: 4, wantErr: true},
+	}
+	for _, c := range cases {
+		got, err := usecases.BlockAtOffset(c.head, c.offset)
+		if c.wantErr {
+			if !errors.Is(err, apperrors.ErrValidation) {
+				t.Errorf("head %d offset %d: expected ErrValidation, got %v", c.head, c.offset, err)
+			}
+			continue
+		}
+		if err != nil || got != c.want {
+			t.Errorf("head %d offset %d: expected %d, got %d (%v)", c.head, c.offset, c.want, got, err)
+		}
+	}
+}
+
+func TestEstimateService_BlockOffsetPinsReads(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	service := createEstimateService(client)
+
+	result, err := service.EstimateSwap(context.Background(), usecases.EstimateRequest{
+		PoolAddress: testPool,
+		SrcToken:    testToken0.Hex(),
+		DstToken:    testToken1.Hex(),
+		SrcAmount:   big.NewInt(1_000),
+		BlockOffset: 12,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := client.blockNumber - 12
+	if result.BlockNumber != want {
+		t.Errorf("Expected result at block %d, got %d", want, result.BlockNumber)
+	}
+	if len(client.reservesBlocks) != 1 || client.reservesBlocks[0] != want {
+		t.Errorf("Expected reserves read at block %d, got %v", want, client.reservesBlocks)
+	}
+}
+
+func TestEstimateHandler_BlockOffsetParam(t *testing.T) {
+	for _, tc := range []struct {
+		offset string
+		ok     bool
+	}{{"7", true}, {"-1", false}, {"abc", false}} {
+		service := &mockEstimateService{estimateAmount: big.NewInt(996)}
+		handler := createEstimateHandler(service)
+
+		req := fasthttp.AcquireRequest()
+		req.SetRequestURI("/estimate?pool=0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc&src=0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48&dst=0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2&src_amount=1000&block_offset=" + tc.offset)
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Init(req, nil, nil)
+		handler.EstimateSwapAmount(ctx)
+		fasthttp.ReleaseRequest(req)
+
+		if ok := ctx.Response.StatusCode() == fasthttp.StatusOK; ok != tc.ok {
+			t.Errorf("block_offset=%s: expected ok=%v, got status %d", tc.offset, tc.ok, ctx.Response.StatusCode())
+		}
+		if tc.ok && service.lastRequest.BlockOffset != 7 {
+			t.Errorf("Expected BlockOffset 7, got %d", service.lastRequest.BlockOffset)
+		}
+	}
+}
+
+func TestEstimateService_HistoricalBlock(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	service := createEstimateService(client)
+	req := usecases.EstimateRequest{
+		PoolAddress: testPool,
+		SrcToken:    testToken0.Hex(),
+		DstToken:    testToken1.Hex(),
+		SrcAmount:   big.NewInt(1_000),
+	}
+
+	block := uint64(15_000_000)
+	req.Block = &block
+	result, err := service.EstimateSwap(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.BlockNumber != block || len(client.reservesBlocks) != 1 || client.reservesBlocks[0] != block {
+		t.Errorf("Expected reads at block %d, got result at %d and reads %v", block, result.BlockNumber, client.reservesBlocks)
+	}
+
+	head := client.blockNumber
+	req.Block = &head
+	if _, err := service.EstimateSwap(context.Background(), req); err != nil {
+		t.Errorf("Expected the head block itself to be accepted, got %v", err)
+	}
+
+	beyond := head + 1
+	req.Block = &beyond
+	if _, err := service.EstimateSwap(context.Background(), req); !errors.Is(err, apperrors.ErrValidation) {
+		t.Errorf("Expected ErrValidation for a block beyond head, got %v", err)
+	}
+
+	req.Block, req.BlockOffset = &block, 5
+	if _, err := service.EstimateSwap(context.Background(), req); !errors.Is(err, apperrors.ErrValidation) {
+		t.Errorf("Expected ErrValidation for block with block_offset, got %v", err)
+	}
+}
+
+func TestEstimateHandler_BlockParam(t *testing.T) {
+	base := "/estimate?pool=" + testPool + "&src=" + testToken0.Hex() + "&dst=" + testToken1.Hex() + "&src_amount=1000"
+
+	service := &mockEstimateService{estimateAmount: big.NewInt(996)}
+	ctx := runQuery(createEstimateHandler(service).EstimateSwapAmount, base+"&block=15000000")
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d", ctx.Response.StatusCode())
+	}
+	if got := service.lastRequest.Block; got == nil || *got != 15_000_000 {
+		t.Errorf("Expected block 15000000 on the request, got %v", got)
+	}
+
+	ctx = runQuery(createEstimateHandler(service).EstimateSwapAmount, base)
+	if service.lastRequest.Block != nil {
+		t.Errorf("Expected no block without the param, got %d", *service.lastRequest.Block)
+	}
+
+	for _, value := range []string{"-1", "0x10", "abc", "1.5"} {
+		ctx = runQuery(createEstimateHandler(service).EstimateSwapAmount, base+"&block="+value)
+		if ctx.Response.StatusCode() != fasthttp.StatusBadRequest {
+			t.Errorf("block=%s: expected status 400, got %d", value, ctx.Response.StatusCode())
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"bigswapenergy/internal/infrastructure/uniswap_v2"
+	"bigswapenergy/internal/shared/clock"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+func TestCachedUniswapV2Client_TokenTTL(t *testing.T) {
+	fake := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	fakeClock := clock.NewFakeClock(time.Unix(1_700_000_000, 0))
+	cached := uniswap_v2.NewCachedUniswapV2Client(fake, time.Hour, fakeClock, zap.NewNop())
+
+	pool := common.HexToAddress(testPool)
+	for i := 0; i < 3; i++ {
+		token0, token1, err := cached.LoadTokens(context.Background(), pool, big.NewInt(1))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if token0 != testToken0 || token1 != testToken1 {
+			t.Fatalf("unexpected tokens: %s %s", token0.Hex(), token1.Hex())
+		}
+	}
+	if fake.tokensCalls != 1 {
+		t.Fatalf("Expected a single underlying read within the TTL, got %d", fake.tokensCalls)
+	}
+
+	fakeClock.Advance(59 * time.Minute)
+	cached.LoadTokens(context.Background(), pool, big.NewInt(1))
+	if fake.tokensCalls != 1 {
+		t.Fatalf("Expected entry to still be fresh, got %d reads", fake.tokensCalls)
+	}
+
+	fakeClock.Advance(2 * time.Minute)
+	cached.LoadTokens(context.Background(), pool, big.NewInt(1))
+	if fake.tokensCalls != 2 {
+		t.Fatalf("Expected entry to expire after the TTL, got %d reads", fake.tokensCalls)
+	}
+}
+
+func TestCachedUniswapV2Client_TokenCacheDisabled(t *testing.T) {
+	fake := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	cached := uniswap_v2.NewCachedUniswapV2Client(fake, 0, clock.New(), zap.NewNop())
+
+	pool := common.HexToAddress(testPool)
+	cached.LoadTokens(context.Background(), pool, big.NewInt(1))
+	cached.LoadTokens(context.Background(), pool, big.NewInt(1))
+
+	if fake.tokensCalls != 2 {
+		t.Fatalf("Expected every call to hit storage with caching disabled, got %d", fake.tokensCalls)
+	}
+}
+
+func TestCachedUniswapV2Client_ErrorsNotCached(t *testing.T) {
+	fake := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	fake.tokensErr = uniswap_v2.ErrPoolNotFound
+	cached := uniswap_v2.NewCachedUniswapV2Client(fake, time.Hour, clock.New(), zap.NewNop())
+
+	pool := common.HexToAddress(testPool)
+	if _, _, err := cached.LoadTokens(context.Background(), pool, big.NewInt(1)); err == nil {
+		t.Fatalf("Expected error to propagate")
+	}
+
+	fake.tokensErr = nil
+	if _, _, err := cached.LoadTokens(context.Background(), pool, big.NewInt(1)); err != nil {
+		t.Fatalf("Expected recovery once the underlying read succeeds, got %v", err)
+	}
+	if fake.tokensCalls != 2 {
+		t.Fatalf("Expected failed reads not to be cached, got %d reads", fake.tokensCalls)
+	}
+}
+
+// blockingTokensClient holds every LoadTokens call until release is closed
+type blockingTokensClient struct {
+	*fakeUniswapV2Client
+	started chan struct{}
+	release chan struct{}
+	calls   atomic.Int32
+}
+
+func (b *blockingTokensClient) LoadTokens(ctx context.Context, pool common.Address, blockNum *big.Int) (common.Address, common.Address, error) {
+	if b.calls.Add(1) == 1 {
+		close(b.started)
+	}
+	<-b.release
+	return b.fakeUniswapV2Client.LoadTokens(ctx, pool, blockNum)
+}
+
+func TestCachedUniswapV2Client_SingleFlight(t *testing.T) {
+	blocking := &blockingTokensClient{
+		fakeUniswapV2Client: newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000)),
+		started:             make(chan struct{}),
+		release:             make(chan struct{}),
+	}
+	cached := uniswap_v2.NewCachedUniswapV2Client(blocking, time.Hour, clock.New(), zap.NewNop())
+	cached.EnableSingleFlight()
+
+	pool := common.HexToAddress(testPool)
+	const callers = 10
+	errs := make(chan error, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			token0, _, err := cached.LoadTokens(context.Background(), pool, big.NewInt(1))
+			if err == nil && token0 != testToken0 {
+				err = fmt.Errorf("unexpected token0 %s", token0.Hex())
+			}
+			errs <- err
+		}()
+	}
+
+	<-blocking.started
+	// Give the other callers time to find the in-flight read before it completes
+	time.Sleep(20 * time.Millisecond)
+	close(blocking.release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if got := blocking.calls.Load(); got != 1 {
+		t.Fatalf("Expected concurrent misses to share one read, got %d", got)
+	}
+}
+
+func TestCachedUniswapV2Client_SingleFlightLeaderCancelDoesNotFailWaiters(t *testing.T) {
+	client := &leaderCancelClient{
+		fakeUniswapV2Client: newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000)),
+		started:             make(chan struct{}),
+	}
+	cached := uniswap_v2.NewCachedUniswapV2Client(client, time.Hour, clock.New(), zap.NewNop())
+	cached.EnableSingleFlight()
+	pool := common.HexToAddress(testPool)
+
+	leaderCtx, cancelLeader := context.WithCancel(context.Background())
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, _, err := cached.LoadTokens(leaderCtx, pool, big.NewInt(1))
+		leaderErr <- err
+	}()
+	<-client.started
+
+	type outcome struct {
+		token0 common.Address
+		err    error
+	}
+	waiter := make(chan outcome, 1)
+	go func() {
+		// A different block, which the service-level flight would not merge
+		token0, _, err := cached.LoadTokens(context.Background(), pool, big.NewInt(2))
+		waiter <- outcome{token0, err}
+	}()
+	// Give the waiter time to join the in-flight read before the leader gives up
+	time.Sleep(20 * time.Millisecond)
+	cancelLeader()
+
+	if err := <-leaderErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the cancelled leader to fail with context.Canceled, got %v", err)
+	}
+	got := <-waiter
+	if got.err != nil {
+		t.Fatalf("Expected the live waiter to succeed, got %v", got.err)
+	}
+	if got.token0 != testToken0 {
+		t.Errorf("Expected token0 %s, got %s", testToken0.Hex(), got.token0.Hex())
+	}
+}
+
+func TestCachedUniswapV2Client_SingleFlightWaiterStopsOnItsOwnContext(t *testing.T) {
+	blocking := &blockingTokensClient{
+		fakeUniswapV2Client: newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000)),
+		started:             make(chan struct{}),
+		release:             make(chan struct{}),
+	}
+	defer close(blocking.release)
+	cached := uniswap_v2.NewCachedUniswapV2Client(blocking, time.Hour, clock.New(), zap.NewNop())
+	cached.EnableSingleFlight()
+	pool := common.HexToAddress(testPool)
+
+	go cached.LoadTokens(context.Background(), pool, big.NewInt(1))
+	<-blocking.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, _, err := cached.LoadTokens(ctx, pool, big.NewInt(1)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the waiter to stop on its own deadline, got %v", err)
+	}
+}
+
+func TestCachedUniswapV2Client_TTLJitterSpreadsExpiry(t *testing.T) {
+	fake := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	fakeClock := clock.NewFakeClock(time.Unix(1_700_000_000, 0))
+	cached := uniswap_v2.NewCachedUniswapV2Client(fake, time.Hour, fakeClock, zap.NewNop())
+	cached.SetTTLJitter(0.1)
+
+	const pools = 100
+	for i := 0; i < pools; i++ {
+		cached.LoadTokens(context.Background(), common.BigToAddress(big.NewInt(int64(i+1))), big.NewInt(1))
+	}
+
+	// 57m is inside the ±6m jitter window, so some entries have expired and some haven't
+	fakeClock.Advance(57 * time.Minute)
+	for i := 0; i < pools; i++ {
+		cached.LoadTokens(context.Background(), common.BigToAddress(big.NewInt(int64(i+1))), big.NewInt(1))
+	}
+	refreshed := fake.tokensCalls - pools
+	if refreshed == 0 || refreshed == pools {
+		t.Fatalf("Expected expirations to be spread out, got %d of %d refreshed at once", refreshed, pools)
+	}
+
+	// Past the upper bound of the window, everything loaded initially has expired
+	fakeClock.Advance(10 * time.Minute)
+	before := fake.tokensCalls
+	for i := 0; i < pools; i++ {
+		cached.LoadTokens(context.Background(), common.BigToAddress(big.NewInt(int64(i+1))), big.NewInt(1))
+	}
+	if fake.tokensCalls-before < pools-refreshed {
+		t.Fatalf("Expected all original entries to expire by TTL+jitter, got %d refreshed", fake.tokensCalls-before)
+	}
+}
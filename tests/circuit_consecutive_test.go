@@ -0,0 +1,127 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"bigswapenergy/internal/infrastructure/ethereum"
+	"bigswapenergy/internal/infrastructure/uniswap_v2"
+	"bigswapenergy/internal/presentation/http"
+	"bigswapenergy/internal/shared/circuit"
+	"bigswapenergy/internal/shared/clock"
+	"bigswapenergy/internal/shared/config"
+	apperrors "bigswapenergy/internal/shared/errors"
+	usecases "bigswapenergy/internal/usecases"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// consecutiveCircuitConfig only opens on a run of failures, never on the error rate
+var consecutiveCircuitConfig = config.CircuitConfig{
+	ErrorRate:           1,
+	Window:              time.Minute,
+	MinRequests:         1_000,
+	Cooldown:            30 * time.Second,
+	ConsecutiveFailures: 3,
+}
+
+func newSingleEndpointBreaker(clk clock.Clock) (*ethereum.FailoverEthereumClient, *flakyEthClient) {
+	node := &flakyEthClient{}
+	client := ethereum.NewFailoverEthereumClient([]ethereum.Endpoint{
+		{Name: "primary", URL: "https://mainnet.infura.io/v3/secret-key", Client: node},
+	}, consecutiveCircuitConfig, clk, zap.NewNop())
+	return client, node
+}
+
+func TestBreaker_OpensOnConsecutiveFailures(t *testing.T) {
+	breaker := circuit.NewBreaker(consecutiveCircuitConfig, clock.NewFakeClock(time.Unix(1_700_000_000, 0)))
+
+	// A success breaks the run
+	for _, success := range []bool{false, false, true, false, false} {
+		if breaker.Record(success) {
+			t.Fatal("Expected a broken run of failures not to open the circuit")
+		}
+	}
+	if got := breaker.Snapshot().ConsecutiveFailures; got != 2 {
+		t.Errorf("Expected 2 consecutive failures, got %d", got)
+	}
+	if !breaker.Record(false) || breaker.State() != circuit.Open {
+		t.Errorf("Expected the third failure in a row to open the circuit, got %s", breaker.State())
+	}
+}
+
+func TestSingleEndpointBreaker_FailsFastAndRecovers(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Unix(1_700_000_000, 0))
+	client, node := newSingleEndpointBreaker(fakeClock)
+	node.failing = true
+	service := usecases.NewEstimateService(uniswap_v2.NewUniswapV2Client(client, zap.NewNop()), nil, zap.NewNop())
+
+	for i := 0; i < 5; i++ {
+		_, err := service.EstimateSwapAmount(context.Background(), testPool, testToken0.Hex(), testToken1.Hex(), big.NewInt(1_000))
+		if !errors.Is(err, apperrors.ErrExternalService) {
+			t.Fatalf("call %d: expected ErrExternalService, got %v", i, err)
+		}
+	}
+	if node.calls != 3 {
+		t.Fatalf("Expected calls to fail fast once the circuit opened, got %d calls", node.calls)
+	}
+	if _, err := client.GetLatestBlockNumber(context.Background()); !errors.Is(err, ethereum.ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen while open, got %v", err)
+	}
+	if client.CheckConnectionHealth(context.Background()) {
+		t.Error("Expected the connection unhealthy while the circuit is open")
+	}
+
+	// After the cooldown a successful probe closes the circuit
+	node.failing = false
+	fakeClock.Advance(30 * time.Second)
+	if _, err := client.GetLatestBlockNumber(context.Background()); err != nil {
+		t.Fatalf("Expected the probe to succeed, got %v", err)
+	}
+	if state := client.EndpointStatuses()[0].Circuit.State; state != circuit.Closed {
+		t.Errorf("Expected the circuit closed after the probe, got %s", state)
+	}
+}
+
+func TestRetryingEthereumClient_DoesNotRetryOpenCircuit(t *testing.T) {
+	client, node := newSingleEndpointBreaker(clock.NewFakeClock(time.Unix(1_700_000_000, 0)))
+	node.failing = true
+	for i := 0; i < 3; i++ {
+		client.GetLatestBlockNumber(context.Background())
+	}
+
+	retrying := ethereum.NewRetryingEthereumClient(client, config.RetryConfig{MaxAttempts: 3, BaseDelay: time.Second}, zap.NewNop())
+	start := time.Now()
+	if _, err := retrying.GetLatestBlockNumber(context.Background()); !errors.Is(err, ethereum.ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected an open circuit not to be retried, took %v", elapsed)
+	}
+}
+
+func TestReady_ReportsCircuitStates(t *testing.T) {
+	client, node := newSingleEndpointBreaker(clock.NewFakeClock(time.Unix(1_700_000_000, 0)))
+	node.failing = true
+	for i := 0; i < 3; i++ {
+		client.GetLatestBlockNumber(context.Background())
+	}
+
+	handler := http.NewReadinessHandler(client, &mockEstimateService{}, config.ReadinessConfig{}, zap.NewNop())
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/ready")
+	handler.GetReady(ctx)
+
+	var resp http.ReadinessResponse
+	if err := json.Unmarshal(ctx.Response.Body(), &resp); err != nil {
+		t.Fatalf("Expected JSON body, got %q", ctx.Response.Body())
+	}
+	if ctx.Response.StatusCode() != fasthttp.StatusServiceUnavailable || resp.Circuits["primary"] != "open" {
+		t.Errorf("Expected 503 with the open circuit reported, got %d %+v", ctx.Response.StatusCode(), resp)
+	}
+}
@@ -0,0 +1,356 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"bigswapenergy/internal/infrastructure/ethereum"
+	"bigswapenergy/internal/presentation/http"
+	"bigswapenergy/internal/shared/circuit"
+	"bigswapenergy/internal/shared/clock"
+	"bigswapenergy/internal/shared/config"
+	"bigswapenergy/internal/shared/metrics"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// flakyEthClient fails every call while failing is set and counts calls made
+type flakyEthClient struct {
+	fakeEthereumClient
+	failing bool
+	calls   int
+}
+
+func (f *flakyEthClient) GetLatestBlockNumber(ctx context.Context) (uint64, error) {
+	f.calls++
+	if f.failing {
+		return 0, ethereum.ErrConnectionFailed
+	}
+	return f.fakeEthereumClient.GetLatestBlockNumber(ctx)
+}
+
+func (f *flakyEthClient) CallContract(ctx context.Context, contractAddress common.Address, data []byte, blockNumber *big.Int) ([]byte, error) {
+	f.calls++
+	if f.failing {
+		return nil, ethereum.ErrConnectionFailed
+	}
+	return f.fakeEthereumClient.CallContract(ctx, contractAddress, data, blockNumber)
+}
+
+var testCircuitConfig = config.CircuitConfig{
+	ErrorRate:   0.5,
+	Window:      time.Minute,
+	MinRequests: 4,
+	Cooldown:    30 * time.Second,
+}
+
+func newTestFailover(clk clock.Clock) (*ethereum.FailoverEthereumClient, *flakyEthClient, *flakyEthClient) {
+	primary, fallback := &flakyEthClient{}, &flakyEthClient{}
+	client := ethereum.NewFailoverEthereumClient([]ethereum.Endpoint{
+		{Name: "primary", URL: "https://mainnet.infura.io/v3/secret-key", Client: primary},
+		{Name: "fallback1", URL: "https://eth.example.com/key", Client: fallback},
+	}, testCircuitConfig, clk, zap.NewNop())
+	return client, primary, fallback
+}
+
+func TestFailover_OpensCircuitAndRecovers(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Unix(1_700_000_000, 0))
+	client, primary, fallback := newTestFailover(fakeClock)
+	primary.failing = true
+
+	// Every call fails over; after MinRequests failures the primary is skipped
+	for i := 0; i < 6; i++ {
+		if _, err := client.GetLatestBlockNumber(context.Background()); err != nil {
+			t.Fatalf("call %d: expected failover to succeed, got %v", i, err)
+		}
+	}
+	if primary.calls != 4 || fallback.calls != 6 {
+		t.Fatalf("Expected the primary to be skipped once open, got primary=%d fallback=%d", primary.calls, fallback.calls)
+	}
+	if state := client.EndpointStatuses()[0].Circuit.State; state != circuit.Open {
+		t.Fatalf("Expected the primary circuit open, got %s", state)
+	}
+
+	// Still cooling down
+	fakeClock.Advance(29 * time.Second)
+	client.GetLatestBlockNumber(context.Background())
+	if primary.calls != 4 {
+		t.Fatalf("Expected no calls to the primary during the cooldown, got %d", primary.calls)
+	}
+
+	// Half-open probe succeeds and closes the circuit
+	primary.failing = false
+	fakeClock.Advance(2 * time.Second)
+	client.GetLatestBlockNumber(context.Background())
+	if primary.calls != 5 || fallback.calls != 7 {
+		t.Fatalf("Expected the probe to go to the primary, got primary=%d fallback=%d", primary.calls, fallback.calls)
+	}
+	status := client.EndpointStatuses()[0]
+	if status.Circuit.State != circuit.Closed || status.Circuit.Opens != 1 {
+		t.Fatalf("Expected the circuit closed after one open, got %+v", status.Circuit)
+	}
+}
+
+func TestFailover_FailedProbeReopens(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Unix(1_700_000_000, 0))
+	client, primary, _ := newTestFailover(fakeClock)
+	primary.failing = true
+	for i := 0; i < 4; i++ {
+		client.GetLatestBlockNumber(context.Background())
+	}
+
+	fakeClock.Advance(31 * time.Second)
+	client.GetLatestBlockNumber(context.Background())
+	if primary.calls != 5 {
+		t.Fatalf("Expected one probe call, got %d", primary.calls)
+	}
+	status := client.EndpointStatuses()[0].Circuit
+	if status.State != circuit.Open || status.Opens != 2 {
+		t.Fatalf("Expected the failed probe to reopen the circuit, got %+v", status)
+	}
+
+	client.GetLatestBlockNumber(context.Background())
+	if primary.calls != 5 {
+		t.Fatalf("Expected a fresh cooldown after the failed probe, got %d calls", primary.calls)
+	}
+}
+
+func TestFailover_AllOpen(t *testing.T) {
+	client, primary, fallback := newTestFailover(clock.NewFakeClock(time.Unix(1_700_000_000, 0)))
+	primary.failing, fallback.failing = true, true
+
+	for i := 0; i < 4; i++ {
+		client.GetLatestBlockNumber(context.Background())
+	}
+	_, err := client.GetLatestBlockNumber(context.Background())
+	if !errors.Is(err, ethereum.ErrConnectionFailed) {
+		t.Fatalf("Expected ErrConnectionFailed with every circuit open, got %v", err)
+	}
+	if primary.calls != 4 || fallback.calls != 4 {
+		t.Errorf("Expected open circuits to refuse calls, got primary=%d fallback=%d", primary.calls, fallback.calls)
+	}
+	if client.CheckConnectionHealth(context.Background()) {
+		t.Error("Expected unhealthy with every circuit open")
+	}
+}
+
+func TestFailover_RevertIsNotAnEndpointFailure(t *testing.T) {
+	client, primary, fallback := newTestFailover(clock.NewFakeClock(time.Unix(1_700_000_000, 0)))
+
+	for i := 0; i < 6; i++ {
+		if _, err := client.CallContract(context.Background(), common.HexToAddress(testPool), nil, nil); !errors.Is(err, ethereum.ErrExecutionReverted) {
+			t.Fatalf("Expected the revert to be returned, got %v", err)
+		}
+	}
+	if primary.calls != 6 || fallback.calls != 0 {
+		t.Fatalf("Expected reverts to stay on the primary, got primary=%d fallback=%d", primary.calls, fallback.calls)
+	}
+	if state := client.EndpointStatuses()[0].Circuit.State; state != circuit.Closed {
+		t.Errorf("Expected the circuit to stay closed, got %s", state)
+	}
+}
+
+// answeringEthClient answers every call with err, as a reachable node would
+type answeringEthClient struct {
+	fakeEthereumClient
+	err   error
+	calls int
+}
+
+func (a *answeringEthClient) GetLatestBlockNumber(ctx context.Context) (uint64, error) {
+	a.calls++
+	return 0, a.err
+}
+
+func TestFailover_OnlyConnectionErrorsFailOver(t *testing.T) {
+	tests := []struct {
+		err          error
+		wantFailover bool
+	}{
+		{ethereum.ErrConnectionFailed, true},
+		{ethereum.ErrRPCTimeout, true},
+		{ethereum.ErrStorageReadFailed, false},
+		{ethereum.ErrCallFailed, false},
+		{errors.New("header not found"), false},
+	}
+	for _, tc := range tests {
+		primary, fallback := &answeringEthClient{err: tc.err}, &flakyEthClient{}
+		client := ethereum.NewFailoverEthereumClient([]ethereum.Endpoint{
+			{Name: "primary", URL: "https://primary.example.com", Client: primary},
+			{Name: "fallback1", URL: "https://fallback.example.com", Client: fallback},
+		}, testCircuitConfig, clock.NewFakeClock(time.Unix(1_700_000_000, 0)), zap.NewNop())
+
+		for i := 0; i < 6; i++ {
+			_, err := client.GetLatestBlockNumber(context.Background())
+			if tc.wantFailover && err != nil {
+				t.Fatalf("%v: expected the fallback to serve the call, got %v", tc.err, err)
+			}
+			if !tc.wantFailover && !errors.Is(err, tc.err) {
+				t.Fatalf("%v: expected the error to be returned as is, got %v", tc.err, err)
+			}
+		}
+
+		if tc.wantFailover {
+			if fallback.calls == 0 {
+				t.Errorf("%v: expected the fallback to be tried", tc.err)
+			}
+			continue
+		}
+		if primary.calls != 6 || fallback.calls != 0 {
+			t.Errorf("%v: expected every call on the primary, got primary=%d fallback=%d", tc.err, primary.calls, fallback.calls)
+		}
+		if state := client.EndpointStatuses()[0].Circuit.State; state != circuit.Closed {
+			t.Errorf("%v: expected the circuit to stay closed, got %s", tc.err, state)
+		}
+	}
+}
+
+func TestFailover_CallContractFailsOverFromUnreachableNode(t *testing.T) {
+	var batches [][]jsonRPCRequest
+	server := newStorageRPCServer(t, nil, &batches)
+	primary, err := ethereum.NewEthereumClient(server.URL, zap.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer primary.Close()
+	server.Close()
+
+	token := common.HexToAddress(testPool)
+	fallback := &fakeEthereumClient{calls: map[common.Address][]byte{token: common.LeftPadBytes([]byte{18}, 32)}}
+	client := ethereum.NewFailoverEthereumClient([]ethereum.Endpoint{
+		{Name: "primary", URL: server.URL, Client: primary},
+		{Name: "fallback1", URL: "https://fallback.example.com", Client: fallback},
+	}, testCircuitConfig, clock.NewFakeClock(time.Unix(1_700_000_000, 0)), zap.NewNop())
+
+	result, err := client.CallContract(context.Background(), token, nil, nil)
+	if err != nil {
+		t.Fatalf("Expected eth_call to fail over to the fallback, got %v", err)
+	}
+	if new(big.Int).SetBytes(result).Uint64() != 18 {
+		t.Errorf("Expected the fallback's answer, got %x", result)
+	}
+}
+
+func TestBreaker_FailuresInOldWindowsDoNotCount(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Unix(1_700_000_000, 0))
+	breaker := circuit.NewBreaker(testCircuitConfig, fakeClock)
+
+	for i := 0; i < 3; i++ {
+		breaker.Record(false)
+	}
+	fakeClock.Advance(time.Minute)
+	breaker.Record(false)
+	breaker.Record(true)
+	if breaker.State() != circuit.Closed {
+		t.Fatalf("Expected the window reset to keep the circuit closed, got %s", breaker.State())
+	}
+}
+
+func TestBreaker_ReleasedProbeAllowsAnother(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Unix(1_700_000_000, 0))
+	breaker := circuit.NewBreaker(testCircuitConfig, fakeClock)
+	for i := 0; i < 4; i++ {
+		breaker.Record(false)
+	}
+	fakeClock.Advance(time.Minute)
+
+	if !breaker.Allow() {
+		t.Fatal("Expected a probe after the cooldown")
+	}
+	if breaker.Allow() {
+		t.Fatal("Expected a single probe at a time")
+	}
+	breaker.Release()
+	if !breaker.Allow() {
+		t.Fatal("Expected another probe once the first was released")
+	}
+}
+
+func TestEndpointPoolHandler_ReportsCircuits(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Unix(1_700_000_000, 0))
+	client, primary, _ := newTestFailover(fakeClock)
+	primary.failing = true
+	for i := 0; i < 4; i++ {
+		client.GetLatestBlockNumber(context.Background())
+	}
+
+	ctx := runQuery(http.NewEndpointPoolHandler(client).GetPool, "/debug/pool")
+	var resp []http.EndpointStatusResponse
+	if err := json.Unmarshal(ctx.Response.Body(), &resp); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(resp) != 2 {
+		t.Fatalf("Expected two endpoints, got %+v", resp)
+	}
+	if resp[0].Name != "primary" || resp[0].State != "open" || resp[0].Opens != 1 || resp[0].OpenedAt == nil {
+		t.Errorf("Unexpected primary status: %+v", resp[0])
+	}
+	if resp[0].Host != "https://mainnet.infura.io" {
+		t.Errorf("Expected the URL redacted to its host, got %q", resp[0].Host)
+	}
+	if resp[1].State != "closed" || resp[1].OpenedAt != nil {
+		t.Errorf("Unexpected fallback status: %+v", resp[1])
+	}
+
+	registry := metrics.NewRegistry()
+	http.RegisterCircuitMetrics(registry, client)
+	snapshot := registry.Snapshot()
+	if snapshot["rpc_circuit_state.primary"] != int64(circuit.Open) || snapshot["rpc_circuit_opens_total.primary"] != 1 {
+		t.Errorf("Unexpected circuit metrics: %v", snapshot)
+	}
+	if snapshot["rpc_circuit_state.fallback1"] != int64(circuit.Closed) {
+		t.Errorf("Unexpected fallback metrics: %v", snapshot)
+	}
+}
+
+func TestLoadConfig_FallbackRPCURLs(t *testing.T) {
+	t.Setenv("ETHEREUM_RPC_URL", "http://localhost:8545")
+	t.Setenv("ETHEREUM_FALLBACK_RPC_URLS", "http://a:8545, ,http://b:8545")
+
+	cfg, err := config.LoadConfig("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := cfg.Blockchain.FallbackRPCURLs; len(got) != 2 || got[0] != "http://a:8545" || got[1] != "http://b:8545" {
+		t.Errorf("Unexpected fallback URLs: %v", got)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("blockchain:\n  circuit:\n    error_rate: 1.5\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if _, err := config.LoadConfig(path); err == nil {
+		t.Error("Expected an error for an error rate above 1")
+	}
+}
+
+func TestFailover_LogsFailoverAndServingEndpoint(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	primary, fallback := &flakyEthClient{failing: true}, &flakyEthClient{}
+	client := ethereum.NewFailoverEthereumClient([]ethereum.Endpoint{
+		{Name: "primary", Client: primary},
+		{Name: "fallback1", Client: fallback},
+	}, testCircuitConfig, clock.NewFakeClock(time.Unix(1_700_000_000, 0)), zap.New(core))
+
+	if _, err := client.GetLatestBlockNumber(context.Background()); err != nil {
+		t.Fatalf("Expected failover to succeed, got %v", err)
+	}
+
+	failed := logs.FilterMessage("RPC call failed, trying next endpoint").All()
+	if len(failed) != 1 || failed[0].Level != zapcore.WarnLevel || failed[0].ContextMap()["endpoint"] != "primary" {
+		t.Errorf("Expected the failover from primary to be logged, got %v", failed)
+	}
+	served := logs.FilterMessage("RPC call served").All()
+	if len(served) != 1 || served[0].Level != zapcore.DebugLevel || served[0].ContextMap()["endpoint"] != "fallback1" {
+		t.Errorf("Expected the serving endpoint at Debug, got %v", served)
+	}
+}
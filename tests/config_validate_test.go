@@ -0,0 +1,78 @@
+package tests
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"bigswapenergy/internal/shared/config"
+	apperrors "bigswapenergy/internal/shared/errors"
+)
+
+func TestLoadConfig_ReportsEveryInvalidField(t *testing.T) {
+	t.Setenv("ETHEREUM_RPC_URL", "")
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	yaml := `server:
+  address: ""
+  shutdown_timeout: "0s"
+  request_timeout: "-1s"
+blockchain:
+  pool_size: 0
+rate_limit:
+  requests_per_minute: -5
+readiness:
+  timeout: "0s"
+reserve_guard:
+  max_staleness: "-1h"
+`
+	if err := os.WriteFile(path, []byte(yaml), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	_, err := config.LoadConfig(path)
+	if !errors.Is(err, apperrors.ErrValidation) {
+		t.Fatalf("Expected a validation error, got %v", err)
+	}
+	for _, field := range []string{
+		"ETHEREUM_RPC_URL",
+		"server.address",
+		"server.shutdown_timeout",
+		"server.request_timeout",
+		"blockchain.pool_size",
+		"rate_limit.requests_per_minute",
+		"readiness.timeout",
+		"reserve_guard.max_staleness",
+	} {
+		if !strings.Contains(err.Error(), field) {
+			t.Errorf("Expected %s reported, got:\n%v", field, err)
+		}
+	}
+}
+
+func TestLoadConfig_ShippedConfigLoads(t *testing.T) {
+	t.Setenv("ETHEREUM_RPC_URL", "http://localhost:8545")
+
+	if _, err := config.LoadConfig(filepath.Join("..", "internal", "shared", "config", "config.yaml")); err != nil {
+		t.Fatalf("Expected the shipped config.yaml to load, got %v", err)
+	}
+}
+
+func TestConfig_ValidateDefaults(t *testing.T) {
+	t.Setenv("ETHEREUM_RPC_URL", "http://localhost:8545")
+
+	cfg, err := config.LoadConfig("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected the defaults to be valid, got %v", err)
+	}
+
+	cfg.RateLimit.RequestsPerMinute = 0
+	if err := cfg.Validate(); !errors.Is(err, apperrors.ErrValidation) || !strings.Contains(err.Error(), "rate_limit.requests_per_minute") {
+		t.Errorf("Expected a zero rate limit rejected, got %v", err)
+	}
+}
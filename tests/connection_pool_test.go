@@ -0,0 +1,140 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"bigswapenergy/internal/infrastructure/ethereum"
+	"bigswapenergy/internal/shared/config"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+// pooledConnection is one pool member, recording its calls and closes
+type pooledConnection struct {
+	fakeEthereumClient
+	block   uint64
+	healthy bool
+	reads   int
+	closed  bool
+}
+
+func (c *pooledConnection) GetLatestBlockNumber(ctx context.Context) (uint64, error) {
+	return c.block, nil
+}
+
+func (c *pooledConnection) ReadContractStorage(ctx context.Context, contractAddress common.Address, storageKey common.Hash, blockNumber *big.Int) ([]byte, error) {
+	c.reads++
+	return nil, nil
+}
+
+func (c *pooledConnection) Close() error {
+	c.closed = true
+	return nil
+}
+
+func (c *pooledConnection) CheckConnectionHealth(ctx context.Context) bool {
+	return c.healthy
+}
+
+func newTestPool(connections ...*pooledConnection) *ethereum.EthereumClientPool {
+	clients := make([]ethereum.EthereumClient, len(connections))
+	for i, connection := range connections {
+		clients[i] = connection
+	}
+	return ethereum.NewEthereumClientPoolOf(clients)
+}
+
+func TestEthereumClientPool_RoundRobin(t *testing.T) {
+	a, b, c := &pooledConnection{block: 1}, &pooledConnection{block: 2}, &pooledConnection{block: 3}
+	pool := newTestPool(a, b, c)
+
+	var blocks []uint64
+	for i := 0; i < 4; i++ {
+		block, _ := pool.GetLatestBlockNumber(context.Background())
+		blocks = append(blocks, block)
+	}
+	if blocks[0] != 1 || blocks[1] != 2 || blocks[2] != 3 || blocks[3] != 1 {
+		t.Errorf("Expected calls to rotate through the connections, got %v", blocks)
+	}
+
+	for i := 0; i < 6; i++ {
+		pool.ReadContractStorage(context.Background(), common.HexToAddress(testPool), common.Hash{}, nil)
+	}
+	if a.reads != 2 || b.reads != 2 || c.reads != 2 {
+		t.Errorf("Expected reads spread evenly, got %d %d %d", a.reads, b.reads, c.reads)
+	}
+}
+
+func TestEthereumClientPool_Health(t *testing.T) {
+	pool := newTestPool(&pooledConnection{healthy: true}, &pooledConnection{healthy: false})
+
+	if count := pool.GetConnectionCount(); count != 2 {
+		t.Errorf("Expected 2 connections, got %d", count)
+	}
+	health := pool.CheckConnectionsHealth(context.Background())
+	if len(health) != 2 || !health[0] || health[1] {
+		t.Errorf("Expected per-connection health, got %v", health)
+	}
+	if !pool.CheckConnectionHealth(context.Background()) {
+		t.Error("Expected the pool to be healthy while any connection is")
+	}
+	if newTestPool(&pooledConnection{}).CheckConnectionHealth(context.Background()) {
+		t.Error("Expected the pool to be unhealthy with no healthy connection")
+	}
+}
+
+func TestEthereumClientPool_CloseClosesAll(t *testing.T) {
+	a, b := &pooledConnection{}, &pooledConnection{}
+	newTestPool(a, b).Close()
+	if !a.closed || !b.closed {
+		t.Error("Expected every connection to be closed")
+	}
+}
+
+func TestNewEthereumClientPool_Dials(t *testing.T) {
+	var batches [][]jsonRPCRequest
+	server := newStorageRPCServer(t, nil, &batches)
+	defer server.Close()
+
+	pool, err := ethereum.NewEthereumClientPool(server.URL, 3, zap.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer pool.Close()
+	if count := pool.GetConnectionCount(); count != 3 {
+		t.Errorf("Expected 3 connections, got %d", count)
+	}
+
+	if _, err := ethereum.NewEthereumClientPool(server.URL, 0, zap.NewNop()); !errors.Is(err, ethereum.ErrConnectionFailed) {
+		t.Errorf("Expected an empty pool to be rejected, got %v", err)
+	}
+}
+
+func TestLoadConfig_PoolSize(t *testing.T) {
+	t.Setenv("ETHEREUM_RPC_URL", "http://localhost:8545")
+
+	cfg, err := config.LoadConfig("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Blockchain.PoolSize != 1 {
+		t.Errorf("Expected one connection per endpoint by default, got %d", cfg.Blockchain.PoolSize)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	for _, size := range []int{0, -1} {
+		if err := os.WriteFile(path, []byte(fmt.Sprintf("blockchain:\n  pool_size: %d\n", size)), 0o644); err != nil {
+			t.Fatalf("write config: %v", err)
+		}
+		if _, err := config.LoadConfig(path); err == nil {
+			t.Errorf("Expected pool size %d to be rejected", size)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"bigswapenergy/internal/presentation/http"
+	"bigswapenergy/internal/shared/config"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// corsConfigurable enables CORS and a one request per minute rate limit
+type corsConfigurable struct {
+	origins []string
+}
+
+func (c corsConfigurable) GetCORSConfig() http.HTTPCORSConfig {
+	return http.HTTPCORSConfig{
+		AllowedOrigins: c.origins,
+		AllowedMethods: []string{"GET", "OPTIONS"},
+		AllowedHeaders: []string{"Content-Type"},
+	}
+}
+
+func (c corsConfigurable) GetRateLimitConfig() http.HTTPRateLimitConfig {
+	return http.HTTPRateLimitConfig{RequestsPerMinute: 1}
+}
+
+func doCORSRequest(handler fasthttp.RequestHandler, method, origin string) *fasthttp.RequestCtx {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/estimate")
+	ctx.Request.Header.SetMethod(method)
+	ctx.Request.Header.Set("Origin", origin)
+	if method == fasthttp.MethodOptions {
+		ctx.Request.Header.Set("Access-Control-Request-Method", fasthttp.MethodGet)
+	}
+	handler(ctx)
+	return ctx
+}
+
+func TestCORS_AllowedOrigin(t *testing.T) {
+	handler := http.NewCORSMiddleware(corsConfigurable{origins: []string{"https://app.example"}}.GetCORSConfig()).Apply(okHandler)
+
+	ctx := doCORSRequest(handler, fasthttp.MethodGet, "https://app.example")
+	if got := string(ctx.Response.Header.Peek("Access-Control-Allow-Origin")); got != "https://app.example" {
+		t.Errorf("Expected the origin echoed, got %q", got)
+	}
+	if got := string(ctx.Response.Header.Peek("Vary")); got != "Origin" {
+		t.Errorf("Expected Vary: Origin, got %q", got)
+	}
+
+	ctx = doCORSRequest(handler, fasthttp.MethodGet, "https://evil.example")
+	if got := ctx.Response.Header.Peek("Access-Control-Allow-Origin"); got != nil {
+		t.Errorf("Expected no CORS headers for another origin, got %q", got)
+	}
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Errorf("Expected the request itself to be served, got %d", ctx.Response.StatusCode())
+	}
+}
+
+func TestCORS_Preflight(t *testing.T) {
+	called := false
+	handler := http.NewCORSMiddleware(corsConfigurable{origins: []string{"*"}}.GetCORSConfig()).Apply(func(ctx *fasthttp.RequestCtx) {
+		called = true
+	})
+
+	ctx := doCORSRequest(handler, fasthttp.MethodOptions, "http://localhost:3000")
+	if called || ctx.Response.StatusCode() != fasthttp.StatusNoContent {
+		t.Fatalf("Expected the preflight answered with 204, got %d (handler called: %v)", ctx.Response.StatusCode(), called)
+	}
+	for header, want := range map[string]string{
+		"Access-Control-Allow-Origin":  "*",
+		"Access-Control-Allow-Methods": "GET, OPTIONS",
+		"Access-Control-Allow-Headers": "Content-Type",
+	} {
+		if got := string(ctx.Response.Header.Peek(header)); got != want {
+			t.Errorf("Expected %s %q, got %q", header, want, got)
+		}
+	}
+}
+
+func TestApplyMiddleware_CORSComposesWithRateLimit(t *testing.T) {
+	handler, rateLimiter := http.ApplyMiddleware(okHandler, zap.NewNop(), corsConfigurable{origins: []string{"https://app.example"}})
+	defer rateLimiter.Stop()
+
+	// Preflights don't spend the client's budget
+	for i := 0; i < 3; i++ {
+		if ctx := doCORSRequest(handler, fasthttp.MethodOptions, "https://app.example"); ctx.Response.StatusCode() != fasthttp.StatusNoContent {
+			t.Fatalf("preflight %d: expected 204, got %d", i, ctx.Response.StatusCode())
+		}
+	}
+	if ctx := doCORSRequest(handler, fasthttp.MethodGet, "https://app.example"); ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected the first request served, got %d", ctx.Response.StatusCode())
+	}
+
+	// A rejection stays readable by the browser
+	ctx := doCORSRequest(handler, fasthttp.MethodGet, "https://app.example")
+	if ctx.Response.StatusCode() != fasthttp.StatusTooManyRequests || string(ctx.Response.Header.Peek("Access-Control-Allow-Origin")) != "https://app.example" {
+		t.Errorf("Expected a 429 with CORS headers, got %d %q", ctx.Response.StatusCode(), ctx.Response.Header.Peek("Access-Control-Allow-Origin"))
+	}
+}
+
+func TestLoadConfig_CORS(t *testing.T) {
+	t.Setenv("ETHEREUM_RPC_URL", "http://localhost:8545")
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("cors:\n  allowed_origins: [\"*\"]\n  allowed_methods: [\"GET\"]\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(cfg.CORS.AllowedOrigins, []string{"*"}) || !slices.Equal(cfg.CORS.AllowedMethods, []string{"GET"}) ||
+		!slices.Equal(cfg.CORS.AllowedHeaders, []string{"Content-Type"}) {
+		t.Errorf("Unexpected CORS config: %+v", cfg.CORS)
+	}
+
+	if err := os.WriteFile(path, []byte("cors:\n  allowed_origins: [\"*\"]\n  allowed_methods: []\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if _, err := config.LoadConfig(path); err == nil {
+		t.Error("Expected an error for origins without methods")
+	}
+}
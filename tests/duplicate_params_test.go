@@ -0,0 +1,71 @@
+package tests
+
+import (
+	"math/big"
+	"testing"
+
+	"bigswapenergy/internal/presentation/http"
+
+	"github.com/valyala/fasthttp"
+)
+
+func runQuery(handler fasthttp.RequestHandler, uri string) *fasthttp.RequestCtx {
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	req.SetRequestURI(uri)
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(req, nil, nil)
+	handler(ctx)
+	return ctx
+}
+
+func TestDuplicateParams_Rejected(t *testing.T) {
+	pair := "&src=" + testToken0.Hex() + "&dst=" + testToken1.Hex()
+	tests := []struct {
+		name  string
+		route func(h *http.EstimateHandler) fasthttp.RequestHandler
+		uri   string
+	}{
+		{name: "estimate_pool", route: func(h *http.EstimateHandler) fasthttp.RequestHandler { return h.EstimateSwapAmount },
+			uri: "/estimate?pool=" + testPool + "&pool=" + testToken0.Hex() + pair + "&src_amount=1000"},
+		{name: "estimate_src", route: func(h *http.EstimateHandler) fasthttp.RequestHandler { return h.EstimateSwapAmount },
+			uri: "/estimate?pool=" + testPool + pair + "&src=" + testToken1.Hex() + "&src_amount=1000"},
+		{name: "estimate_dst", route: func(h *http.EstimateHandler) fasthttp.RequestHandler { return h.EstimateSwapAmount },
+			uri: "/estimate?pool=" + testPool + pair + "&dst=" + testToken0.Hex() + "&src_amount=1000"},
+		{name: "estimate_same_value_twice", route: func(h *http.EstimateHandler) fasthttp.RequestHandler { return h.EstimateSwapAmount },
+			uri: "/estimate?pool=" + testPool + "&pool=" + testPool + pair + "&src_amount=1000"},
+		{name: "check_pool", route: func(h *http.EstimateHandler) fasthttp.RequestHandler { return h.CheckPool },
+			uri: "/estimate/check?pool=" + testPool + "&pool=" + testToken0.Hex() + pair},
+		{name: "pool_tokens", route: func(h *http.EstimateHandler) fasthttp.RequestHandler { return h.GetPoolTokens },
+			uri: "/pool/tokens?pool=" + testPool + "&pool=" + testToken0.Hex()},
+		{name: "route_src_amount", route: func(h *http.EstimateHandler) fasthttp.RequestHandler { return h.EstimateRoute },
+			uri: "/estimate/route?pool=" + testPool + "&token=" + testToken0.Hex() + "&token=" + testToken1.Hex() + "&src_amount=1&src_amount=2"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			service := &mockEstimateService{estimateAmount: big.NewInt(996)}
+			ctx := runQuery(tc.route(createEstimateHandler(service)), tc.uri)
+
+			if ctx.Response.StatusCode() == fasthttp.StatusOK {
+				t.Fatalf("Expected a duplicated parameter to be rejected")
+			}
+			if service.lastRequest.SrcToken != "" {
+				t.Errorf("Expected the service not to be called")
+			}
+		})
+	}
+}
+
+func TestDuplicateParams_MultiValuedAllowed(t *testing.T) {
+	service := &mockEstimateService{estimateAmount: big.NewInt(996)}
+	uri := "/estimate?pool=" + testPool + "&src=" + testToken0.Hex() + "&dst=" + testToken1.Hex() + "&src_amount=1000&src_amount=2000"
+	ctx := runQuery(createEstimateHandler(service).EstimateSwapAmount, uri)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected repeated src_amount to be accepted, got %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	if len(service.lastRequest.SrcAmounts) != 2 {
+		t.Errorf("Expected both amounts, got %v", service.lastRequest.SrcAmounts)
+	}
+}
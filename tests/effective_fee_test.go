@@ -0,0 +1,131 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"bigswapenergy/internal/presentation/http"
+	"bigswapenergy/internal/shared/config"
+	usecases "bigswapenergy/internal/usecases"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+func TestEffectiveFee_Sources(t *testing.T) {
+	feeOverride, directional := 50, 70
+	cases := []struct {
+		name       string
+		onChainFee *big.Int
+		req        func(*usecases.EstimateRequest)
+		wantFee    int
+		wantSource usecases.FeeSource
+	}{
+		{
+			name:       "default",
+			req:        func(r *usecases.EstimateRequest) { r.Factory, r.PoolAddress = "", testPool },
+			wantFee:    30,
+			wantSource: usecases.FeeSourceDefault,
+		},
+		{
+			name:       "config",
+			wantFee:    30,
+			wantSource: usecases.FeeSourceConfig,
+		},
+		{
+			name:       "onchain",
+			onChainFee: big.NewInt(1000),
+			wantFee:    100,
+			wantSource: usecases.FeeSourceOnChain,
+		},
+		{
+			name:       "request",
+			onChainFee: big.NewInt(1000),
+			req:        func(r *usecases.EstimateRequest) { r.FeeBasisPoints = &feeOverride },
+			wantFee:    50,
+			wantSource: usecases.FeeSourceRequest,
+		},
+		{
+			name:       "request_directional",
+			req:        func(r *usecases.EstimateRequest) { r.FeeBasisPoints, r.FeeBasisPoints0To1 = &feeOverride, &directional },
+			wantFee:    70,
+			wantSource: usecases.FeeSourceRequest,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			registry := feeForkRegistry(t)
+			factory, _ := registry.Get("feefork")
+			client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+			if tc.onChainFee != nil {
+				client.poolFees = map[common.Address]*big.Int{factory.PairFor(testToken0, testToken1): tc.onChainFee}
+			}
+			service := usecases.NewEstimateService(client, registry, zap.NewNop())
+
+			req := feeForkRequest()
+			if tc.req != nil {
+				tc.req(&req)
+			}
+			result, err := service.EstimateSwap(context.Background(), req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.EffectiveFeeBasisPoints != tc.wantFee || result.FeeSource != tc.wantSource {
+				t.Errorf("Expected fee %d from %s, got %d from %s", tc.wantFee, tc.wantSource, result.EffectiveFeeBasisPoints, result.FeeSource)
+			}
+		})
+	}
+}
+
+func TestEstimateHandler_EffectiveFeeInResponse(t *testing.T) {
+	cfg := &config.Config{
+		RateLimit: config.RateLimitConfig{RequestsPerMinute: 100},
+		Debug:     config.DebugConfig{Enabled: true},
+	}
+	handler := http.NewEstimateHandler(createEstimateService(newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))), zap.NewNop(), cfg)
+	base := "/estimate?pool=" + testPool + "&src=" + testToken0.Hex() + "&dst=" + testToken1.Hex() + "&src_amount=1000"
+
+	ctx := runQuery(handler.EstimateSwapAmount, base+"&show_math=true&fee_bps=0")
+	var resp map[string]any
+	if err := json.Unmarshal(ctx.Response.Body(), &resp); err != nil {
+		t.Fatalf("invalid JSON %q: %v", ctx.Response.Body(), err)
+	}
+	if resp["effective_fee_bps"] != float64(0) || resp["fee_source"] != "request" {
+		t.Errorf("Expected a zero request fee to be reported, got %v", resp)
+	}
+
+	ctx = runQuery(handler.EstimateSwapAmount, base)
+	if fee, source := string(ctx.Response.Header.Peek("X-Effective-Fee-Bps")), string(ctx.Response.Header.Peek("X-Fee-Source")); fee != "30" || source != "default" {
+		t.Errorf("Expected the default fee in headers, got %q from %q", fee, source)
+	}
+}
+
+func TestEstimateHandler_EffectiveFeeInBasisPoints(t *testing.T) {
+	base := "/estimate?pool=" + testPool + "&src=" + testToken0.Hex() + "&dst=" + testToken1.Hex() + "&src_amount=1000&format=json"
+	tests := []struct {
+		query string
+		want  float64
+	}{
+		{query: "", want: 30},
+		{query: "&fee=pancake", want: 25},
+		{query: "&fee_bps_0to1=100", want: 100},
+	}
+	for _, tc := range tests {
+		handler := createEstimateHandler(createEstimateService(newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))))
+		ctx := runQuery(handler.EstimateSwapAmount, base+tc.query)
+		var resp map[string]any
+		if err := json.Unmarshal(ctx.Response.Body(), &resp); err != nil {
+			t.Fatalf("%q: invalid JSON %q: %v", tc.query, ctx.Response.Body(), err)
+		}
+		if resp["effective_fee_bps"] != tc.want {
+			t.Errorf("%q: expected effective_fee_bps %v, got %v", tc.query, tc.want, resp["effective_fee_bps"])
+		}
+		if header := string(ctx.Response.Header.Peek("X-Effective-Fee-Bps")); header != fmt.Sprint(tc.want) {
+			t.Errorf("%q: expected X-Effective-Fee-Bps %v, got %q", tc.query, tc.want, header)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"bigswapenergy/internal/infrastructure/uniswap_v2"
+	"bigswapenergy/internal/presentation/http"
+	"bigswapenergy/internal/shared/config"
+	apperrors "bigswapenergy/internal/shared/errors"
+	usecases "bigswapenergy/internal/usecases"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// freshPoolClient reads as uninitialized until emptyReads reserve reads have
+// been served, advancing head by one block with each empty read
+type freshPoolClient struct {
+	*fakeUniswapV2Client
+	emptyReads int
+}
+
+func (f *freshPoolClient) LoadReserves(ctx context.Context, pool common.Address, blockNum *big.Int) (*big.Int, *big.Int, error) {
+	if f.emptyReads > 0 {
+		f.emptyReads--
+		f.reservesBlocks = append(f.reservesBlocks, blockNum.Uint64())
+		f.blockNumber++
+		return nil, nil, fmt.Errorf("%w: %w for pool %s", uniswap_v2.ErrInsufficientLiquidity, uniswap_v2.ErrPoolNotInitialized, pool.Hex())
+	}
+	return f.fakeUniswapV2Client.LoadReserves(ctx, pool, blockNum)
+}
+
+func freshPoolRequest(retry time.Duration) usecases.EstimateRequest {
+	return usecases.EstimateRequest{
+		PoolAddress:        testPool,
+		SrcToken:           testToken0.Hex(),
+		DstToken:           testToken1.Hex(),
+		SrcAmount:          big.NewInt(1_000),
+		EmptyReservesRetry: retry,
+	}
+}
+
+func TestEmptyReservesRetry_SucceedsAtNextHead(t *testing.T) {
+	client := &freshPoolClient{fakeUniswapV2Client: newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000)), emptyReads: 1}
+	service := usecases.NewEstimateService(client, nil, zap.NewNop())
+
+	result, err := service.EstimateSwap(context.Background(), freshPoolRequest(time.Millisecond))
+	if err != nil {
+		t.Fatalf("Expected the retry to succeed, got %v", err)
+	}
+	if result.AmountOut.Cmp(big.NewInt(996)) != 0 {
+		t.Errorf("Expected 996, got %s", result.AmountOut)
+	}
+	if result.BlockNumber != 20_000_001 {
+		t.Errorf("Expected the quote to report the retried block, got %d", result.BlockNumber)
+	}
+	if got := client.reservesBlocks; len(got) != 2 || got[0] != 20_000_000 || got[1] != 20_000_001 {
+		t.Errorf("Expected reads at head then head+1, got %v", got)
+	}
+}
+
+func TestEmptyReservesRetry_OnlyOnce(t *testing.T) {
+	client := &freshPoolClient{fakeUniswapV2Client: newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000)), emptyReads: 2}
+	service := usecases.NewEstimateService(client, nil, zap.NewNop())
+
+	_, err := service.EstimateSwap(context.Background(), freshPoolRequest(time.Millisecond))
+	if !errors.Is(err, apperrors.ErrPoolNotInitialized) {
+		t.Fatalf("Expected ErrPoolNotInitialized after one retry, got %v", err)
+	}
+	if len(client.reservesBlocks) != 2 {
+		t.Errorf("Expected exactly two reads, got %v", client.reservesBlocks)
+	}
+}
+
+func TestEmptyReservesRetry_DisabledByDefault(t *testing.T) {
+	client := &freshPoolClient{fakeUniswapV2Client: newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000)), emptyReads: 1}
+	service := usecases.NewEstimateService(client, nil, zap.NewNop())
+
+	_, err := service.EstimateSwap(context.Background(), freshPoolRequest(0))
+	if !errors.Is(err, apperrors.ErrPoolNotInitialized) {
+		t.Fatalf("Expected an immediate ErrPoolNotInitialized, got %v", err)
+	}
+	if len(client.reservesBlocks) != 1 {
+		t.Errorf("Expected a single read, got %v", client.reservesBlocks)
+	}
+}
+
+func TestEmptyReservesRetry_HonoursContext(t *testing.T) {
+	client := &freshPoolClient{fakeUniswapV2Client: newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000)), emptyReads: 1}
+	service := usecases.NewEstimateService(client, nil, zap.NewNop())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := service.EstimateSwap(ctx, freshPoolRequest(time.Hour)); err == nil {
+		t.Fatal("Expected the cancelled wait to fail")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the wait to stop with the context, took %v", elapsed)
+	}
+}
+
+func TestEstimateHandler_EmptyReservesRetryFromConfig(t *testing.T) {
+	service := &mockEstimateService{estimateAmount: big.NewInt(996)}
+	cfg := &config.Config{Blockchain: config.BlockchainConfig{EmptyReservesRetry: 250 * time.Millisecond}}
+	handler := http.NewEstimateHandler(service, zap.NewNop(), cfg)
+
+	ctx := runQuery(handler.EstimateSwapAmount, "/estimate?pool="+testPool+"&src="+testToken0.Hex()+"&dst="+testToken1.Hex()+"&src_amount=100")
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d", ctx.Response.StatusCode())
+	}
+	if got := service.lastRequest.EmptyReservesRetry; got != 250*time.Millisecond {
+		t.Errorf("Expected the configured retry delay on the request, got %v", got)
+	}
+}
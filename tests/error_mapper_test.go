@@ -0,0 +1,61 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"bigswapenergy/internal/infrastructure/uniswap_v2"
+	apperrors "bigswapenergy/internal/shared/errors"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestHandleError_MapsWrappedErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{"validation", fmt.Errorf("%w: source amount must be positive", apperrors.ErrValidation), fasthttp.StatusBadRequest, "VALIDATION_ERROR"},
+		{"invalid_input", fmt.Errorf("%w: bad pool", apperrors.ErrInvalidInput), fasthttp.StatusBadRequest, "INVALID_INPUT"},
+		{"not_found", fmt.Errorf("%w: pool not found or invalid: %v", apperrors.ErrNotFound, errors.New("no code")), fasthttp.StatusNotFound, "NOT_FOUND"},
+		{"business_rule", fmt.Errorf("%w: pool has empty reserves", apperrors.ErrBusinessRule), fasthttp.StatusBadRequest, "BUSINESS_RULE_VIOLATION"},
+		{"pool_not_initialized", fmt.Errorf("%w: %w", apperrors.ErrPoolNotInitialized, uniswap_v2.ErrPoolNotInitialized), fasthttp.StatusBadRequest, "POOL_NOT_INITIALIZED"},
+		{"drained_over_business_rule", fmt.Errorf("%w: %w", apperrors.ErrBusinessRule, apperrors.ErrPoolDrained), fasthttp.StatusBadRequest, "POOL_DRAINED"},
+		{"external_service", fmt.Errorf("%w: unable to read pool reserves: %v", apperrors.ErrExternalService, errors.New("connection refused")), fasthttp.StatusBadGateway, "EXTERNAL_SERVICE_ERROR"},
+		{"timeout", fmt.Errorf("%w: deadline", apperrors.ErrTimeout), fasthttp.StatusGatewayTimeout, "TIMEOUT_ERROR"},
+		{"internal", fmt.Errorf("%w: broken invariant", apperrors.ErrInternal), fasthttp.StatusInternalServerError, "INTERNAL_ERROR"},
+		{"double_wrapped", fmt.Errorf("fee tier 3: %w", fmt.Errorf("%w: overflow", apperrors.ErrValidation)), fasthttp.StatusBadRequest, "VALIDATION_ERROR"},
+		{"unmapped", errors.New("unexpected failure"), fasthttp.StatusInternalServerError, "UNKNOWN_ERROR"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := createEstimateHandler(&mockEstimateService{estimateAmount: big.NewInt(996), estimateError: tc.err})
+			ctx := runQuery(handler.EstimateSwapAmount, "/estimate?pool="+testPool+"&src="+testToken0.Hex()+"&dst="+testToken1.Hex()+"&src_amount=1000")
+
+			if ctx.Response.StatusCode() != tc.wantStatus {
+				t.Errorf("Expected status %d, got %d", tc.wantStatus, ctx.Response.StatusCode())
+			}
+			var resp struct {
+				Error struct {
+					Code    string `json:"code"`
+					Details string `json:"details"`
+				} `json:"error"`
+			}
+			if err := json.Unmarshal(ctx.Response.Body(), &resp); err != nil {
+				t.Fatalf("invalid JSON: %v", err)
+			}
+			if resp.Error.Code != tc.wantCode {
+				t.Errorf("Expected code %s, got %s", tc.wantCode, resp.Error.Code)
+			}
+			if tc.wantStatus < 500 && resp.Error.Details != tc.err.Error() {
+				t.Errorf("Expected client errors to carry details, got %q", resp.Error.Details)
+			}
+		})
+	}
+}
@@ -5,10 +5,14 @@ import (
 	"fmt"
 	"math/big"
 	"net/url"
+	"sync"
 	"testing"
+	"time"
 
 	"bigswapenergy/internal/presentation/http"
 	"bigswapenergy/internal/shared/config"
+	apperrors "bigswapenergy/internal/shared/errors"
+	"bigswapenergy/internal/shared/utils"
 	usecases "bigswapenergy/internal/usecases"
 
 	"github.com/valyala/fasthttp"
@@ -18,12 +22,150 @@ import (
 type mockEstimateService struct {
 	estimateAmount *big.Int
 	estimateError  error
+	lastRequest    usecases.EstimateRequest
+	quoteID        string
+
+	arbitrageResult *usecases.ArbitrageResult
+	lastArbitrage   usecases.ArbitrageRequest
+
+	rawStorage *usecases.RawPoolStorage
+
+	routeResult *usecases.RouteResult
+	lastRoute   usecases.RouteRequest
+
+	poolsResult *usecases.PoolsResult
+	lastPools   []string
+
+	poolResult *usecases.PoolResult
+	lastPool   usecases.PoolRequest
+
+	poolCheck *usecases.PoolCheck
+
+	// warnings are attached to every EstimateSwap result
+	warnings []string
+
+	// delay stalls EstimateSwap like a slow RPC call that honours the context
+	delay time.Duration
+
+	// mu guards lastRequest for handlers that call EstimateSwap concurrently
+	mu sync.Mutex
 }
 
 func (m *mockEstimateService) EstimateSwapAmount(ctx context.Context, poolAddress, srcToken, dstToken string, srcAmount *big.Int) (*big.Int, error) {
 	return m.estimateAmount, m.estimateError
 }
 
+func (m *mockEstimateService) EstimateSwapAmountIn(ctx context.Context, poolAddress, srcToken, dstToken string, dstAmount *big.Int) (*big.Int, error) {
+	return m.estimateAmount, m.estimateError
+}
+
+func (m *mockEstimateService) EstimateSwap(ctx context.Context, req usecases.EstimateRequest) (*usecases.EstimateResult, error) {
+	m.mu.Lock()
+	m.lastRequest = req
+	m.mu.Unlock()
+	if m.delay > 0 {
+		select {
+		case <-time.After(m.delay):
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: unable to read pool reserves: %v", apperrors.ErrExternalService, ctx.Err())
+		}
+	}
+	if m.estimateError != nil {
+		return nil, m.estimateError
+	}
+	result := &usecases.EstimateResult{AmountOut: m.estimateAmount, PoolAddress: req.PoolAddress, Warnings: m.warnings, DstDecimals: req.DstDecimals, QuoteID: m.quoteID}
+	if req.DstAmount != nil {
+		result.AmountIn = m.estimateAmount
+	}
+	for range req.SrcAmounts {
+		result.AmountsOut = append(result.AmountsOut, m.estimateAmount)
+	}
+	return result, nil
+}
+
+func (m *mockEstimateService) EstimateArbitrage(ctx context.Context, req usecases.ArbitrageRequest) (*usecases.ArbitrageResult, error) {
+	m.lastArbitrage = req
+	if m.estimateError != nil {
+		return nil, m.estimateError
+	}
+	return m.arbitrageResult, nil
+}
+
+func (m *mockEstimateService) ReadRawPoolStorage(ctx context.Context, poolAddress string) (*usecases.RawPoolStorage, error) {
+	if m.estimateError != nil {
+		return nil, m.estimateError
+	}
+	return m.rawStorage, nil
+}
+
+func (m *mockEstimateService) EstimateLiquidityCurve(ctx context.Context, req usecases.EstimateRequest) ([]usecases.CurvePoint, error) {
+	return nil, m.estimateError
+}
+
+func (m *mockEstimateService) EstimateTwoWayQuote(ctx context.Context, req usecases.TwoWayQuoteRequest) (*usecases.TwoWayQuoteResult, error) {
+	if m.estimateError != nil {
+		return nil, m.estimateError
+	}
+	return &usecases.TwoWayQuoteResult{Sell: m.estimateAmount, Buy: m.estimateAmount}, nil
+}
+
+func (m *mockEstimateService) EstimateMaxForImpact(ctx context.Context, req usecases.MaxImpactRequest) (*usecases.MaxImpactResult, error) {
+	if m.estimateError != nil {
+		return nil, m.estimateError
+	}
+	return &usecases.MaxImpactResult{AmountIn: m.estimateAmount, AmountOut: m.estimateAmount}, nil
+}
+
+func (m *mockEstimateService) ReadPoolInvariant(ctx context.Context, req usecases.InvariantRequest) (*usecases.PoolInvariant, error) {
+	return nil, m.estimateError
+}
+
+func (m *mockEstimateService) ReadPoolPrice(ctx context.Context, req usecases.PriceRequest) (*usecases.PoolPrice, error) {
+	return nil, m.estimateError
+}
+
+func (m *mockEstimateService) CheckPool(ctx context.Context, req usecases.PoolCheckRequest) (*usecases.PoolCheck, error) {
+	if m.estimateError != nil {
+		return nil, m.estimateError
+	}
+	return m.poolCheck, nil
+}
+
+func (m *mockEstimateService) EstimateSwapAmountPath(ctx context.Context, pools, tokens []string, srcAmount *big.Int) (*big.Int, []*big.Int, error) {
+	return m.estimateAmount, nil, m.estimateError
+}
+
+func (m *mockEstimateService) EstimateRoute(ctx context.Context, req usecases.RouteRequest) (*usecases.RouteResult, error) {
+	m.lastRoute = req
+	if m.estimateError != nil {
+		return nil, m.estimateError
+	}
+	return m.routeResult, nil
+}
+
+func (m *mockEstimateService) ReadPoolTokens(ctx context.Context, poolAddress string) (*usecases.PoolTokens, error) {
+	if m.estimateError != nil {
+		return nil, m.estimateError
+	}
+	return &usecases.PoolTokens{Token0: testToken0, Token1: testToken1}, nil
+}
+
+func (m *mockEstimateService) ReadPool(ctx context.Context, req usecases.PoolRequest) (*usecases.PoolResult, error) {
+	m.lastPool = req
+	if m.estimateError != nil {
+		return nil, m.estimateError
+	}
+	return m.poolResult, nil
+}
+
+func (m *mockEstimateService) ReadPools(ctx context.Context, poolAddresses []string) (*usecases.PoolsResult, error) {
+	m.lastPools = poolAddresses
+	if m.estimateError != nil {
+		return nil, m.estimateError
+	}
+	return m.poolsResult, nil
+}
+
 func createEstimateHandler(estimateService usecases.EstimateService) *http.EstimateHandler {
 	logger, _ := zap.NewDevelopment()
 	cfg := &config.Config{
@@ -44,7 +186,7 @@ func TestEstimateSwapAmount_Success(t *testing.T) {
 	req := fasthttp.AcquireRequest()
 	defer fasthttp.ReleaseRequest(req)
 
-	req.SetRequestURI("/estimate?pool=0x123&src=0x456&dst=0x789&src_amount=1000")
+	req.SetRequestURI("/estimate?pool=0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc&src=0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48&dst=0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2&src_amount=1000")
 	req.Header.SetMethod("GET")
 
 	ctx := &fasthttp.RequestCtx{}
@@ -75,7 +217,7 @@ func TestEstimateSwapAmount_MissingPool(t *testing.T) {
 	req := fasthttp.AcquireRequest()
 	defer fasthttp.ReleaseRequest(req)
 
-	req.SetRequestURI("/estimate?src=0x456&dst=0x789&src_amount=1000")
+	req.SetRequestURI("/estimate?src=0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48&dst=0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2&src_amount=1000")
 	req.Header.SetMethod("GET")
 
 	ctx := &fasthttp.RequestCtx{}
@@ -95,7 +237,7 @@ func TestEstimateSwapAmount_MissingSrc(t *testing.T) {
 	req := fasthttp.AcquireRequest()
 	defer fasthttp.ReleaseRequest(req)
 
-	req.SetRequestURI("/estimate?pool=0x123&dst=0x789&src_amount=1000")
+	req.SetRequestURI("/estimate?pool=0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc&dst=0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2&src_amount=1000")
 	req.Header.SetMethod("GET")
 
 	ctx := &fasthttp.RequestCtx{}
@@ -115,7 +257,7 @@ func TestEstimateSwapAmount_MissingDst(t *testing.T) {
 	req := fasthttp.AcquireRequest()
 	defer fasthttp.ReleaseRequest(req)
 
-	req.SetRequestURI("/estimate?pool=0x123&src=0x456&src_amount=1000")
+	req.SetRequestURI("/estimate?pool=0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc&src=0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48&src_amount=1000")
 	req.Header.SetMethod("GET")
 
 	ctx := &fasthttp.RequestCtx{}
@@ -135,7 +277,7 @@ func TestEstimateSwapAmount_MissingSrcAmount(t *testing.T) {
 	req := fasthttp.AcquireRequest()
 	defer fasthttp.ReleaseRequest(req)
 
-	req.SetRequestURI("/estimate?pool=0x123&src=0x456&dst=0x789")
+	req.SetRequestURI("/estimate?pool=0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc&src=0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48&dst=0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2")
 	req.Header.SetMethod("GET")
 
 	ctx := &fasthttp.RequestCtx{}
@@ -155,7 +297,7 @@ func TestEstimateSwapAmount_InvalidSrcAmount(t *testing.T) {
 	req := fasthttp.AcquireRequest()
 	defer fasthttp.ReleaseRequest(req)
 
-	req.SetRequestURI("/estimate?pool=0x123&src=0x456&dst=0x789&src_amount=invalid")
+	req.SetRequestURI("/estimate?pool=0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc&src=0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48&dst=0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2&src_amount=invalid")
 	req.Header.SetMethod("GET")
 
 	ctx := &fasthttp.RequestCtx{}
@@ -175,7 +317,7 @@ func TestEstimateSwapAmount_ZeroSrcAmount(t *testing.T) {
 	req := fasthttp.AcquireRequest()
 	defer fasthttp.ReleaseRequest(req)
 
-	req.SetRequestURI("/estimate?pool=0x123&src=0x456&dst=0x789&src_amount=0")
+	req.SetRequestURI("/estimate?pool=0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc&src=0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48&dst=0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2&src_amount=0")
 	req.Header.SetMethod("GET")
 
 	ctx := &fasthttp.RequestCtx{}
@@ -195,7 +337,7 @@ func TestEstimateSwapAmount_NegativeSrcAmount(t *testing.T) {
 	req := fasthttp.AcquireRequest()
 	defer fasthttp.ReleaseRequest(req)
 
-	req.SetRequestURI("/estimate?pool=0x123&src=0x456&dst=0x789&src_amount=-100")
+	req.SetRequestURI("/estimate?pool=0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc&src=0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48&dst=0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2&src_amount=-100")
 	req.Header.SetMethod("GET")
 
 	ctx := &fasthttp.RequestCtx{}
@@ -218,7 +360,7 @@ func TestEstimateSwapAmount_LargeNumbers(t *testing.T) {
 	req := fasthttp.AcquireRequest()
 	defer fasthttp.ReleaseRequest(req)
 
-	req.SetRequestURI("/estimate?pool=0x123&src=0x456&dst=0x789&src_amount=1000000000000")
+	req.SetRequestURI("/estimate?pool=0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc&src=0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48&dst=0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2&src_amount=1000000000000")
 	req.Header.SetMethod("GET")
 
 	ctx := &fasthttp.RequestCtx{}
@@ -247,7 +389,7 @@ func TestEstimateSwapAmount_ServiceError(t *testing.T) {
 	req := fasthttp.AcquireRequest()
 	defer fasthttp.ReleaseRequest(req)
 
-	req.SetRequestURI("/estimate?pool=0x123&src=0x456&dst=0x789&src_amount=1000")
+	req.SetRequestURI("/estimate?pool=0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc&src=0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48&dst=0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2&src_amount=1000")
 	req.Header.SetMethod("GET")
 
 	ctx := &fasthttp.RequestCtx{}
@@ -325,7 +467,7 @@ func TestEstimateSwapAmount_EdgeCases(t *testing.T) {
 			req := fasthttp.AcquireRequest()
 			defer fasthttp.ReleaseRequest(req)
 
-			req.SetRequestURI(fmt.Sprintf("/estimate?pool=0x123&src=0x456&dst=0x789&src_amount=%s", tc.srcAmount))
+			req.SetRequestURI(fmt.Sprintf("/estimate?pool=0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc&src=0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48&dst=0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2&src_amount=%s", tc.srcAmount))
 			req.Header.SetMethod("GET")
 
 			ctx := &fasthttp.RequestCtx{}
@@ -340,6 +482,128 @@ func TestEstimateSwapAmount_EdgeCases(t *testing.T) {
 	}
 }
 
+func TestEstimateSwapAmount_FeeSide(t *testing.T) {
+	testCases := []struct {
+		name         string
+		query        string
+		expectedSide utils.FeeSide
+	}{
+		{"default", "", utils.FeeOnInput},
+		{"input", "&fee_side=in", utils.FeeOnInput},
+		{"output", "&fee_side=out", utils.FeeOnOutput},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := &mockEstimateService{
+				estimateAmount: big.NewInt(996),
+			}
+			handler := createEstimateHandler(mockService)
+
+			req := fasthttp.AcquireRequest()
+			defer fasthttp.ReleaseRequest(req)
+
+			req.SetRequestURI("/estimate?pool=0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc&src=0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48&dst=0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2&src_amount=1000" + tc.query)
+			req.Header.SetMethod("GET")
+
+			ctx := &fasthttp.RequestCtx{}
+			ctx.Init(req, nil, nil)
+
+			handler.EstimateSwapAmount(ctx)
+
+			if ctx.Response.StatusCode() != fasthttp.StatusOK {
+				t.Fatalf("Expected status %d, got %d", fasthttp.StatusOK, ctx.Response.StatusCode())
+			}
+			if mockService.lastRequest.FeeSide != tc.expectedSide {
+				t.Errorf("Expected fee side %s, got %s", tc.expectedSide, mockService.lastRequest.FeeSide)
+			}
+		})
+	}
+}
+
+func TestEstimateSwapAmount_InvalidFeeSide(t *testing.T) {
+	mockService := &mockEstimateService{
+		estimateAmount: big.NewInt(996),
+	}
+	handler := createEstimateHandler(mockService)
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+
+	req.SetRequestURI("/estimate?pool=0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc&src=0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48&dst=0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2&src_amount=1000&fee_side=both")
+	req.Header.SetMethod("GET")
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(req, nil, nil)
+
+	handler.EstimateSwapAmount(ctx)
+
+	if ctx.Response.StatusCode() == fasthttp.StatusOK {
+		t.Errorf("Expected an error status for invalid fee_side, got %d", ctx.Response.StatusCode())
+	}
+	if mockService.lastRequest.PoolAddress != "" {
+		t.Errorf("Service should not be called for invalid fee_side")
+	}
+}
+
+func TestEstimateSwapAmount_MultipleAmounts(t *testing.T) {
+	mockService := &mockEstimateService{
+		estimateAmount: big.NewInt(996),
+	}
+	handler := createEstimateHandler(mockService)
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+
+	req.SetRequestURI("/estimate?pool=0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc&src=0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48&dst=0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2&src_amount=1000&src_amount=2000&src_amount=3000")
+	req.Header.SetMethod("GET")
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(req, nil, nil)
+
+	handler.EstimateSwapAmount(ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status %d, got %d", fasthttp.StatusOK, ctx.Response.StatusCode())
+	}
+	if len(mockService.lastRequest.SrcAmounts) != 3 {
+		t.Fatalf("Expected 3 amounts passed to the service, got %d", len(mockService.lastRequest.SrcAmounts))
+	}
+	if mockService.lastRequest.SrcAmounts[1].Cmp(big.NewInt(2000)) != 0 {
+		t.Errorf("Expected second amount 2000, got %s", mockService.lastRequest.SrcAmounts[1])
+	}
+
+	expectedBody := "996\n996\n996"
+	if actualBody := string(ctx.Response.Body()); actualBody != expectedBody {
+		t.Errorf("Expected body %q, got %q", expectedBody, actualBody)
+	}
+}
+
+func TestEstimateSwapAmount_MultipleAmountsInvalid(t *testing.T) {
+	mockService := &mockEstimateService{
+		estimateAmount: big.NewInt(996),
+	}
+	handler := createEstimateHandler(mockService)
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+
+	req.SetRequestURI("/estimate?pool=0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc&src=0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48&dst=0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2&src_amount=1000&src_amount=abc")
+	req.Header.SetMethod("GET")
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(req, nil, nil)
+
+	handler.EstimateSwapAmount(ctx)
+
+	if ctx.Response.StatusCode() == fasthttp.StatusOK {
+		t.Errorf("Expected an error status when one amount is invalid")
+	}
+	if mockService.lastRequest.SrcAmount != nil {
+		t.Errorf("Service should not be called when an amount is invalid")
+	}
+}
+
 func BenchmarkEstimateSwapAmount(b *testing.B) {
 	mockService := &mockEstimateService{
 		estimateAmount: big.NewInt(996),
@@ -350,7 +614,7 @@ func BenchmarkEstimateSwapAmount(b *testing.B) {
 	req := fasthttp.AcquireRequest()
 	defer fasthttp.ReleaseRequest(req)
 
-	req.SetRequestURI("/estimate?pool=0x123&src=0x456&dst=0x789&src_amount=1000")
+	req.SetRequestURI("/estimate?pool=0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc&src=0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48&dst=0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2&src_amount=1000")
 	req.Header.SetMethod("GET")
 
 	b.ResetTimer()
@@ -360,3 +624,112 @@ func BenchmarkEstimateSwapAmount(b *testing.B) {
 		handler.EstimateSwapAmount(ctx)
 	}
 }
+
+func TestEstimateSwapAmount_DirectionalFeeParams(t *testing.T) {
+	mockService := &mockEstimateService{
+		estimateAmount: big.NewInt(989),
+	}
+	handler := createEstimateHandler(mockService)
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+
+	req.SetRequestURI("/estimate?pool=0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc&src=0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48&dst=0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2&src_amount=1000&fee_bps_0to1=100&fee_bps_1to0=50")
+	req.Header.SetMethod("GET")
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(req, nil, nil)
+
+	handler.EstimateSwapAmount(ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status %d, got %d", fasthttp.StatusOK, ctx.Response.StatusCode())
+	}
+	got := mockService.lastRequest
+	if got.FeeBasisPoints != nil {
+		t.Errorf("Expected no request-wide fee, got %d", *got.FeeBasisPoints)
+	}
+	if got.FeeBasisPoints0To1 == nil || *got.FeeBasisPoints0To1 != 100 {
+		t.Errorf("Expected fee_bps_0to1=100, got %v", got.FeeBasisPoints0To1)
+	}
+	if got.FeeBasisPoints1To0 == nil || *got.FeeBasisPoints1To0 != 50 {
+		t.Errorf("Expected fee_bps_1to0=50, got %v", got.FeeBasisPoints1To0)
+	}
+}
+
+func TestEstimateHandler_EighteenDecimalAmounts(t *testing.T) {
+	base := "/estimate?pool=" + testPool + "&src=" + testToken0.Hex() + "&dst=" + testToken1.Hex() + "&src_amount="
+	for _, amount := range []string{
+		"10000000000000000000",               // 10 tokens at 18 decimals, just past int64
+		"1000000000000000000000",             // 1000 tokens
+		"5192296858534827628530496329220095", // max uint112, the largest possible reserve
+	} {
+		service := &mockEstimateService{estimateAmount: big.NewInt(996)}
+		ctx := runQuery(createEstimateHandler(service).EstimateSwapAmount, base+amount)
+		if ctx.Response.StatusCode() != fasthttp.StatusOK {
+			t.Errorf("%s: expected status 200, got %d: %s", amount, ctx.Response.StatusCode(), ctx.Response.Body())
+			continue
+		}
+		if got := service.lastRequest.SrcAmount; got == nil || got.String() != amount {
+			t.Errorf("Expected src_amount %s to be passed through exactly, got %v", amount, got)
+		}
+	}
+
+	for _, amount := range []string{"", "0", "-1000000000000000000000", "1e21", "1000000000000000000000.5"} {
+		service := &mockEstimateService{estimateAmount: big.NewInt(996)}
+		ctx := runQuery(createEstimateHandler(service).EstimateSwapAmount, base+amount)
+		if ctx.Response.StatusCode() == fasthttp.StatusOK {
+			t.Errorf("Expected src_amount %q to be rejected", amount)
+		}
+	}
+}
+
+func TestEstimateHandler_HexAndDecimalAmounts(t *testing.T) {
+	base := "/estimate?pool=" + testPool + "&src=" + testToken0.Hex() + "&dst=" + testToken1.Hex()
+	huge, _ := new(big.Int).SetString("100000000000000000000", 10)
+	tests := []struct {
+		name  string
+		query string
+		want  *big.Int
+	}{
+		{name: "decimal", query: "&src_amount=1000", want: big.NewInt(1000)},
+		{name: "hex", query: "&src_amount=0x3e8", want: big.NewInt(1000)},
+		{name: "hex_beyond_int64", query: "&src_amount=0x56bc75e2d63100000", want: huge},
+		{name: "decimal_beyond_int64", query: "&src_amount=100000000000000000000", want: huge},
+		{name: "hex_dst_amount", query: "&dst_amount=0x10", want: big.NewInt(16)},
+		{name: "mixed_malformed", query: "&src_amount=0x12zz"},
+		{name: "decimal_with_hex_digits", query: "&src_amount=12ab"},
+		{name: "negative_hex", query: "&src_amount=-0x10"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			service := &mockEstimateService{estimateAmount: big.NewInt(996)}
+			handler := createEstimateHandler(service)
+
+			req := fasthttp.AcquireRequest()
+			defer fasthttp.ReleaseRequest(req)
+			req.SetRequestURI(base + tc.query)
+			ctx := &fasthttp.RequestCtx{}
+			ctx.Init(req, nil, nil)
+			handler.EstimateSwapAmount(ctx)
+
+			if tc.want == nil {
+				if ctx.Response.StatusCode() == fasthttp.StatusOK {
+					t.Fatalf("Expected %q to be rejected", tc.query)
+				}
+				return
+			}
+			if ctx.Response.StatusCode() != fasthttp.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+			}
+			got := service.lastRequest.SrcAmount
+			if service.lastRequest.DstAmount != nil {
+				got = service.lastRequest.DstAmount
+			}
+			if got == nil || got.Cmp(tc.want) != 0 {
+				t.Errorf("Expected amount %s, got %v", tc.want, got)
+			}
+		})
+	}
+}
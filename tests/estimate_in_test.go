@@ -0,0 +1,54 @@
+package tests
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"bigswapenergy/internal/presentation/http"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestEstimateInHandler(t *testing.T) {
+	base := "/estimate-in?pool=" + testPool + "&src=" + testToken0.Hex() + "&dst=" + testToken1.Hex()
+	tests := []struct {
+		name       string
+		uri        string
+		wantStatus int
+		wantBody   string
+		wantCode   string
+	}{
+		{name: "exact_out", uri: base + "&dst_amount=996", wantStatus: fasthttp.StatusOK, wantBody: "1000"},
+		{name: "hex_amount", uri: base + "&dst_amount=0x3e4", wantStatus: fasthttp.StatusOK, wantBody: "1000"},
+		{name: "equal_to_reserve", uri: base + "&dst_amount=1000000", wantStatus: fasthttp.StatusBadRequest, wantCode: "BUSINESS_RULE_VIOLATION"},
+		{name: "beyond_reserve", uri: base + "&dst_amount=1000001", wantStatus: fasthttp.StatusBadRequest, wantCode: "BUSINESS_RULE_VIOLATION"},
+		{name: "missing_amount", uri: base, wantStatus: fasthttp.StatusBadRequest, wantCode: "VALIDATION_ERROR"},
+		{name: "zero_amount", uri: base + "&dst_amount=0", wantStatus: fasthttp.StatusBadRequest, wantCode: "VALIDATION_ERROR"},
+		{name: "negative_amount", uri: base + "&dst_amount=-5", wantStatus: fasthttp.StatusBadRequest, wantCode: "VALIDATION_ERROR"},
+		{name: "src_amount", uri: base + "&dst_amount=996&src_amount=1000", wantStatus: fasthttp.StatusBadRequest, wantCode: "VALIDATION_ERROR"},
+		{name: "invalid_pool", uri: "/estimate-in?pool=0x123&src=" + testToken0.Hex() + "&dst=" + testToken1.Hex() + "&dst_amount=996", wantStatus: fasthttp.StatusBadRequest, wantCode: "VALIDATION_ERROR"},
+		{name: "duplicate_param", uri: base + "&dst_amount=996&dst_amount=997", wantStatus: fasthttp.StatusBadRequest, wantCode: "VALIDATION_ERROR"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := createEstimateHandler(createEstimateService(newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))))
+			ctx := runQuery(handler.EstimateSwapAmountIn, tc.uri)
+
+			if ctx.Response.StatusCode() != tc.wantStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tc.wantStatus, ctx.Response.StatusCode(), ctx.Response.Body())
+			}
+			if tc.wantBody != "" && string(ctx.Response.Body()) != tc.wantBody {
+				t.Errorf("Expected %s, got %s", tc.wantBody, ctx.Response.Body())
+			}
+			if tc.wantCode != "" {
+				var resp map[string]http.ErrorResponse
+				json.Unmarshal(ctx.Response.Body(), &resp)
+				if resp["error"].Code != tc.wantCode {
+					t.Errorf("Expected code %s, got %s", tc.wantCode, ctx.Response.Body())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,389 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+
+	"bigswapenergy/internal/infrastructure/uniswap_v2"
+	apperrors "bigswapenergy/internal/shared/errors"
+	"bigswapenergy/internal/shared/utils"
+	usecases "bigswapenergy/internal/usecases"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+var (
+	testPool   = "0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc"
+	testToken0 = common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+	testToken1 = common.HexToAddress("0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2")
+)
+
+type fakeUniswapV2Client struct {
+	blockNumber uint64
+	token0      common.Address
+	token1      common.Address
+	reserve0    *big.Int
+	reserve1    *big.Int
+
+	blockErr    error
+	tokensErr   error
+	reservesErr error
+
+	// storage backs ReadStorageSlot, keyed by slot
+	storage map[uint64][]byte
+
+	// poolReserves overrides reserve0/reserve1 for specific pools
+	poolReserves map[common.Address][2]*big.Int
+	// poolReservesErr fails LoadReserves for specific pools
+	poolReservesErr map[common.Address]error
+	// decimals backs LoadTokenDecimals; tokens missing from it have no decimals()
+	decimals    map[common.Address]uint8
+	decimalsErr error
+	// poolFees backs LoadPoolFee; pools missing from it have no fee method
+	poolFees map[common.Address]*big.Int
+
+	mu             sync.Mutex
+	lastPool       common.Address
+	tokensCalls    int
+	reservesCalls  int
+	reservesBlocks []uint64
+	decimalsCalls  int
+	feeCalls       int
+}
+
+func newFakeUniswapV2Client(reserve0, reserve1 *big.Int) *fakeUniswapV2Client {
+	return &fakeUniswapV2Client{
+		blockNumber: 20_000_000,
+		token0:      testToken0,
+		token1:      testToken1,
+		reserve0:    reserve0,
+		reserve1:    reserve1,
+	}
+}
+
+func (f *fakeUniswapV2Client) ReadStorageSlot(ctx context.Context, pool common.Address, blockNum *big.Int, slot uint64) ([]byte, error) {
+	data, ok := f.storage[slot]
+	if !ok {
+		return nil, errors.New("slot not set")
+	}
+	return data, nil
+}
+
+func (f *fakeUniswapV2Client) GetLatestBlockNumber(ctx context.Context) (uint64, error) {
+	return f.blockNumber, f.blockErr
+}
+
+func (f *fakeUniswapV2Client) LoadTokens(ctx context.Context, pool common.Address, blockNum *big.Int) (common.Address, common.Address, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lastPool = pool
+	f.tokensCalls++
+	if f.tokensErr != nil {
+		return common.Address{}, common.Address{}, f.tokensErr
+	}
+	return f.token0, f.token1, nil
+}
+
+func (f *fakeUniswapV2Client) LoadReserves(ctx context.Context, pool common.Address, blockNum *big.Int) (*big.Int, *big.Int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reservesCalls++
+	f.reservesBlocks = append(f.reservesBlocks, blockNum.Uint64())
+	if f.reservesErr != nil {
+		return nil, nil, f.reservesErr
+	}
+	if err := f.poolReservesErr[pool]; err != nil {
+		return nil, nil, err
+	}
+	if reserves, ok := f.poolReserves[pool]; ok {
+		return new(big.Int).Set(reserves[0]), new(big.Int).Set(reserves[1]), nil
+	}
+	return new(big.Int).Set(f.reserve0), new(big.Int).Set(f.reserve1), nil
+}
+
+func (f *fakeUniswapV2Client) LoadTokenDecimals(ctx context.Context, token common.Address) (uint8, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.decimalsCalls++
+	if f.decimalsErr != nil {
+		return 0, f.decimalsErr
+	}
+	decimals, ok := f.decimals[token]
+	if !ok {
+		return 0, uniswap_v2.ErrNoDecimals
+	}
+	return decimals, nil
+}
+
+func (f *fakeUniswapV2Client) LoadPoolFee(ctx context.Context, pool common.Address, selector []byte) (*big.Int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.feeCalls++
+	fee, ok := f.poolFees[pool]
+	if !ok {
+		return nil, uniswap_v2.ErrNoFeeMethod
+	}
+	return fee, nil
+}
+
+func (f *fakeUniswapV2Client) DetermineReserveOrder(src, dst, token0, token1 common.Address, reserve0, reserve1 *big.Int) (*big.Int, *big.Int, error) {
+	zeroForOne, err := uniswap_v2.OrientPair(src, dst, token0, token1)
+	if err != nil {
+		return nil, nil, err
+	}
+	if zeroForOne {
+		return reserve0, reserve1, nil
+	}
+	return reserve1, reserve0, nil
+}
+
+func createEstimateService(client *fakeUniswapV2Client) usecases.EstimateService {
+	return usecases.NewEstimateService(client, nil, zap.NewNop())
+}
+
+func TestEstimateService_Success(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	service := createEstimateService(client)
+
+	amountOut, err := service.EstimateSwapAmount(context.Background(), testPool, testToken0.Hex(), testToken1.Hex(), big.NewInt(1_000))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if amountOut.Cmp(big.NewInt(996)) != 0 {
+		t.Errorf("Expected 996, got %s", amountOut)
+	}
+}
+
+func TestEstimateService_DustRoundsToZero(t *testing.T) {
+	reserve := new(big.Int).Exp(big.NewInt(10), big.NewInt(24), nil)
+	client := newFakeUniswapV2Client(reserve, new(big.Int).Set(reserve))
+	service := createEstimateService(client)
+
+	_, err := service.EstimateSwapAmount(context.Background(), testPool, testToken0.Hex(), testToken1.Hex(), big.NewInt(1))
+	if !errors.Is(err, apperrors.ErrBusinessRule) {
+		t.Fatalf("Expected ErrBusinessRule for dust amount, got %v", err)
+	}
+}
+
+func TestEstimateService_DustRoundsToZeroBothFeeSides(t *testing.T) {
+	service := createEstimateService(newFakeUniswapV2Client(new(big.Int).Exp(big.NewInt(10), big.NewInt(30), nil), big.NewInt(1_000)))
+
+	for _, side := range []utils.FeeSide{utils.FeeOnInput, utils.FeeOnOutput} {
+		result, err := service.EstimateSwap(context.Background(), usecases.EstimateRequest{
+			PoolAddress: testPool,
+			SrcToken:    testToken0.Hex(),
+			DstToken:    testToken1.Hex(),
+			SrcAmount:   big.NewInt(1_000),
+			FeeSide:     side,
+		})
+		if !errors.Is(err, usecases.ErrOutputRoundsToZero) || !errors.Is(err, apperrors.ErrBusinessRule) {
+			t.Errorf("fee %s: expected a dust ErrBusinessRule instead of a 0 quote, got %v (%v)", side, err, result)
+		}
+	}
+}
+
+func TestEstimateService_MultipleAmountsSingleRead(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	service := createEstimateService(client)
+
+	result, err := service.EstimateSwap(context.Background(), usecases.EstimateRequest{
+		PoolAddress: testPool,
+		SrcToken:    testToken0.Hex(),
+		DstToken:    testToken1.Hex(),
+		SrcAmounts:  []*big.Int{big.NewInt(1_000), big.NewInt(10_000), big.NewInt(100_000)},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if client.reservesCalls != 1 {
+		t.Errorf("Expected a single reserve read, got %d", client.reservesCalls)
+	}
+	if len(result.AmountsOut) != 3 {
+		t.Fatalf("Expected 3 outputs, got %d", len(result.AmountsOut))
+	}
+
+	expected := []int64{996, 9_871, 90_661}
+	for i, amountOut := range result.AmountsOut {
+		if amountOut.Cmp(big.NewInt(expected[i])) != 0 {
+			t.Errorf("amount %d: expected %d, got %s", i, expected[i], amountOut)
+		}
+	}
+}
+
+func TestEstimateService_ExactOut(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	service := createEstimateService(client)
+
+	amountIn, err := service.EstimateSwapAmountIn(context.Background(), testPool, testToken0.Hex(), testToken1.Hex(), big.NewInt(996))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if amountIn.Cmp(big.NewInt(1000)) != 0 {
+		t.Errorf("Expected amount in 1000, got %s", amountIn)
+	}
+}
+
+func TestEstimateService_ExactOutInsufficientLiquidity(t *testing.T) {
+	reserveOut := big.NewInt(1_000_000)
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), reserveOut)
+	service := createEstimateService(client)
+
+	for _, dstAmount := range []*big.Int{new(big.Int).Set(reserveOut), new(big.Int).Add(reserveOut, big.NewInt(1))} {
+		_, err := service.EstimateSwapAmountIn(context.Background(), testPool, testToken0.Hex(), testToken1.Hex(), dstAmount)
+		if !errors.Is(err, utils.ErrInsufficientLiquidity) {
+			t.Errorf("dst_amount=%s: expected insufficient liquidity error, got %v", dstAmount, err)
+		}
+		if !errors.Is(err, apperrors.ErrBusinessRule) {
+			t.Errorf("dst_amount=%s: expected business rule error, got %v", dstAmount, err)
+		}
+	}
+}
+
+func TestEstimateService_ExactOutRejectsSrcAmount(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	service := createEstimateService(client)
+
+	_, err := service.EstimateSwap(context.Background(), usecases.EstimateRequest{
+		PoolAddress: testPool,
+		SrcToken:    testToken0.Hex(),
+		DstToken:    testToken1.Hex(),
+		SrcAmount:   big.NewInt(1000),
+		DstAmount:   big.NewInt(996),
+	})
+	if !errors.Is(err, apperrors.ErrValidation) {
+		t.Errorf("Expected validation error, got %v", err)
+	}
+	if client.reservesCalls != 0 {
+		t.Errorf("Expected no reserve reads, got %d", client.reservesCalls)
+	}
+}
+
+func intPtr(v int) *int { return &v }
+
+func TestEstimateService_DirectionalFees(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	service := createEstimateService(client)
+
+	tests := []struct {
+		name     string
+		src, dst common.Address
+		expected int64
+	}{
+		{"zero_to_one", testToken0, testToken1, 989},
+		{"one_to_zero", testToken1, testToken0, 994},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := service.EstimateSwap(context.Background(), usecases.EstimateRequest{
+				PoolAddress:        testPool,
+				SrcToken:           tt.src.Hex(),
+				DstToken:           tt.dst.Hex(),
+				SrcAmount:          big.NewInt(1000),
+				FeeBasisPoints0To1: intPtr(100),
+				FeeBasisPoints1To0: intPtr(50),
+			})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if result.AmountOut.Cmp(big.NewInt(tt.expected)) != 0 {
+				t.Errorf("Expected %d, got %s", tt.expected, result.AmountOut)
+			}
+		})
+	}
+}
+
+func TestEstimateService_FeeOverrideFallsBackForUnsetDirection(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	service := createEstimateService(client)
+
+	// Only 0->1 is overridden, so a 1->0 swap uses the request-wide fee
+	result, err := service.EstimateSwap(context.Background(), usecases.EstimateRequest{
+		PoolAddress:        testPool,
+		SrcToken:           testToken1.Hex(),
+		DstToken:           testToken0.Hex(),
+		SrcAmount:          big.NewInt(1000),
+		FeeBasisPoints:     intPtr(100),
+		FeeBasisPoints0To1: intPtr(50),
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.AmountOut.Cmp(big.NewInt(989)) != 0 {
+		t.Errorf("Expected 989, got %s", result.AmountOut)
+	}
+}
+
+func TestEstimateService_InvalidFeeOverride(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	service := createEstimateService(client)
+
+	_, err := service.EstimateSwap(context.Background(), usecases.EstimateRequest{
+		PoolAddress:        testPool,
+		SrcToken:           testToken0.Hex(),
+		DstToken:           testToken1.Hex(),
+		SrcAmount:          big.NewInt(1000),
+		FeeBasisPoints1To0: intPtr(1001),
+	})
+	if !errors.Is(err, apperrors.ErrValidation) {
+		t.Errorf("Expected validation error, got %v", err)
+	}
+}
+
+func TestEstimateService_LiquidityCurve(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	service := createEstimateService(client)
+
+	points, err := service.EstimateLiquidityCurve(context.Background(), usecases.EstimateRequest{
+		PoolAddress: testPool,
+		SrcToken:    testToken0.Hex(),
+		DstToken:    testToken1.Hex(),
+		SrcAmounts:  []*big.Int{big.NewInt(1000), big.NewInt(10_000), big.NewInt(100_000)},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []struct {
+		out   int64
+		price *big.Rat
+	}{
+		{996, big.NewRat(996, 1000)},
+		{9871, big.NewRat(8875, 9000)},
+		{90661, big.NewRat(80790, 90000)},
+	}
+	for i, e := range expected {
+		if points[i].AmountOut.Cmp(big.NewInt(e.out)) != 0 {
+			t.Errorf("point %d: expected amount out %d, got %s", i, e.out, points[i].AmountOut)
+		}
+		if points[i].MarginalPrice.Cmp(e.price) != 0 {
+			t.Errorf("point %d: expected marginal price %s, got %s", i, e.price, points[i].MarginalPrice)
+		}
+		// The curve steepens: each marginal price is below the previous one
+		if i > 0 && points[i].MarginalPrice.Cmp(points[i-1].MarginalPrice) >= 0 {
+			t.Errorf("point %d: marginal price did not decrease", i)
+		}
+	}
+	if client.reservesCalls != 1 {
+		t.Errorf("Expected a single reserve read, got %d", client.reservesCalls)
+	}
+}
+
+func TestEstimateService_LiquidityCurveRequiresIncreasingAmounts(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	service := createEstimateService(client)
+
+	_, err := service.EstimateLiquidityCurve(context.Background(), usecases.EstimateRequest{
+		PoolAddress: testPool,
+		SrcToken:    testToken0.Hex(),
+		DstToken:    testToken1.Hex(),
+		SrcAmounts:  []*big.Int{big.NewInt(10_000), big.NewInt(1000)},
+	})
+	if !errors.Is(err, apperrors.ErrValidation) {
+		t.Errorf("Expected validation error, got %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	apperrors "bigswapenergy/internal/shared/errors"
+	usecases "bigswapenergy/internal/usecases"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+func newSingleFlightService(t *testing.T) (*usecases.EstimateServiceImpl, *blockingTokensClient) {
+	t.Helper()
+	blocking := &blockingTokensClient{
+		fakeUniswapV2Client: newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000)),
+		started:             make(chan struct{}),
+		release:             make(chan struct{}),
+	}
+	service, ok := usecases.NewEstimateService(blocking, nil, zap.NewNop()).(*usecases.EstimateServiceImpl)
+	if !ok {
+		t.Fatalf("Expected an *EstimateServiceImpl")
+	}
+	service.EnableSingleFlight()
+	return service, blocking
+}
+
+func TestEstimateService_SingleFlightSharesPoolReads(t *testing.T) {
+	service, blocking := newSingleFlightService(t)
+
+	const callers = 10
+	amounts := make([]*big.Int, callers)
+	errs := make([]error, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// Opposite directions and different amounts still read the same pool state
+			src, dst := testToken0, testToken1
+			if i%2 == 1 {
+				src, dst = dst, src
+			}
+			amounts[i], errs[i] = service.EstimateSwapAmount(context.Background(), testPool, src.Hex(), dst.Hex(), big.NewInt(int64(1_000+i)))
+		}(i)
+	}
+
+	<-blocking.started
+	// Give the other callers time to join the in-flight read before it completes
+	time.Sleep(20 * time.Millisecond)
+	close(blocking.release)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("caller %d: unexpected error: %v", i, err)
+		}
+		if amounts[i] == nil || amounts[i].Sign() <= 0 {
+			t.Fatalf("caller %d: unexpected amount %v", i, amounts[i])
+		}
+	}
+	if amounts[0].Cmp(amounts[2]) >= 0 {
+		t.Errorf("Expected each caller's own amount quoted, got %s for 1000 and %s for 1002", amounts[0], amounts[2])
+	}
+	if got := blocking.calls.Load(); got != 1 {
+		t.Errorf("Expected one token read, got %d", got)
+	}
+	if got := blocking.reservesCalls; got != 1 {
+		t.Errorf("Expected one reserves read, got %d", got)
+	}
+}
+
+func TestEstimateService_SingleFlightWaiterHonoursContext(t *testing.T) {
+	service, blocking := newSingleFlightService(t)
+	defer close(blocking.release)
+
+	go service.EstimateSwapAmount(context.Background(), testPool, testToken0.Hex(), testToken1.Hex(), big.NewInt(1_000))
+	<-blocking.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := service.EstimateSwapAmount(ctx, testPool, testToken0.Hex(), testToken1.Hex(), big.NewInt(1_000))
+	if !errors.Is(err, apperrors.ErrExternalService) {
+		t.Fatalf("Expected the waiter to give up with its context, got %v", err)
+	}
+}
+
+// leaderCancelClient blocks the first token read until its caller's context ends,
+// failing with the context's error; later reads go straight through
+type leaderCancelClient struct {
+	*fakeUniswapV2Client
+	started chan struct{}
+	calls   atomic.Int32
+}
+
+func (c *leaderCancelClient) LoadTokens(ctx context.Context, pool common.Address, blockNum *big.Int) (common.Address, common.Address, error) {
+	if c.calls.Add(1) == 1 {
+		close(c.started)
+		<-ctx.Done()
+		return common.Address{}, common.Address{}, ctx.Err()
+	}
+	return c.fakeUniswapV2Client.LoadTokens(ctx, pool, blockNum)
+}
+
+func TestEstimateService_SingleFlightLeaderCancelDoesNotFailWaiters(t *testing.T) {
+	client := &leaderCancelClient{
+		fakeUniswapV2Client: newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000)),
+		started:             make(chan struct{}),
+	}
+	service, ok := usecases.NewEstimateService(client, nil, zap.NewNop()).(*usecases.EstimateServiceImpl)
+	if !ok {
+		t.Fatalf("Expected an *EstimateServiceImpl")
+	}
+	service.EnableSingleFlight()
+
+	leaderCtx, cancelLeader := context.WithCancel(context.Background())
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, err := service.EstimateSwapAmount(leaderCtx, testPool, testToken0.Hex(), testToken1.Hex(), big.NewInt(1_000))
+		leaderErr <- err
+	}()
+	<-client.started
+
+	type outcome struct {
+		amount *big.Int
+		err    error
+	}
+	follower := make(chan outcome, 1)
+	go func() {
+		amount, err := service.EstimateSwapAmount(context.Background(), testPool, testToken0.Hex(), testToken1.Hex(), big.NewInt(1_000))
+		follower <- outcome{amount, err}
+	}()
+	// Give the follower time to join the in-flight read before the leader gives up
+	time.Sleep(20 * time.Millisecond)
+	cancelLeader()
+
+	if err := <-leaderErr; err == nil {
+		t.Error("Expected the cancelled leader to fail")
+	}
+	got := <-follower
+	if got.err != nil {
+		t.Fatalf("Expected the live follower to succeed, got %v", got.err)
+	}
+	if got.amount.Cmp(big.NewInt(996)) != 0 {
+		t.Errorf("Expected 996, got %s", got.amount)
+	}
+	if calls := client.calls.Load(); calls != 2 {
+		t.Errorf("Expected the follower to read the pool again, got %d token reads", calls)
+	}
+}
@@ -0,0 +1,224 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"bigswapenergy/internal/infrastructure/uniswap_v2"
+	"bigswapenergy/internal/shared/config"
+	apperrors "bigswapenergy/internal/shared/errors"
+	usecases "bigswapenergy/internal/usecases"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+func defaultFactoryRegistry(t *testing.T) *uniswap_v2.FactoryRegistry {
+	t.Helper()
+	registry, err := uniswap_v2.NewFactoryRegistry(map[string]config.FactoryConfig{
+		"uniswap": {
+			Address:        "0x5C69bEe701ef814a2B6a3EDD4B1652CB9cc5aA6f",
+			InitCodeHash:   "0x96e8ac4277198ff8b6f785478aa9a39f403cb768dd02cbee326c3e7da348845f",
+			FeeBasisPoints: 30,
+		},
+		"customswap": {
+			Address:        "0x1111111111111111111111111111111111111111",
+			InitCodeHash:   "0x2222222222222222222222222222222222222222222222222222222222222222",
+			FeeBasisPoints: 100,
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to build factory registry: %v", err)
+	}
+	return registry
+}
+
+func TestFactoryPairFor_KnownMainnetPair(t *testing.T) {
+	factory, err := defaultFactoryRegistry(t).Get("uniswap")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// USDC/WETH Uniswap V2 pair; token order must not matter
+	expected := common.HexToAddress(testPool)
+	if pair := factory.PairFor(testToken1, testToken0); pair != expected {
+		t.Errorf("Expected pair %s, got %s", expected.Hex(), pair.Hex())
+	}
+	if pair := factory.PairFor(testToken0, testToken1); pair != expected {
+		t.Errorf("Expected pair %s, got %s", expected.Hex(), pair.Hex())
+	}
+}
+
+func TestFactoryPairFor_DependsOnFactory(t *testing.T) {
+	registry := defaultFactoryRegistry(t)
+	uniswap, _ := registry.Get("uniswap")
+	custom, _ := registry.Get("customswap")
+
+	if uniswap.PairFor(testToken0, testToken1) == custom.PairFor(testToken0, testToken1) {
+		t.Fatalf("Different factories must derive different pair addresses")
+	}
+}
+
+func TestFactoryRegistry_UnknownFactory(t *testing.T) {
+	registry := defaultFactoryRegistry(t)
+
+	if _, err := registry.Get("pancakeswap"); !errors.Is(err, uniswap_v2.ErrUnknownFactory) {
+		t.Fatalf("Expected ErrUnknownFactory, got %v", err)
+	}
+}
+
+func TestFactoryRegistry_InvalidConfig(t *testing.T) {
+	_, err := uniswap_v2.NewFactoryRegistry(map[string]config.FactoryConfig{
+		"broken": {Address: "0x5C69bEe701ef814a2B6a3EDD4B1652CB9cc5aA6f", InitCodeHash: "0x1234"},
+	})
+	if !errors.Is(err, uniswap_v2.ErrInvalidFactory) {
+		t.Fatalf("Expected ErrInvalidFactory, got %v", err)
+	}
+}
+
+func TestEstimateService_FactoryDerivesPool(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	service := usecases.NewEstimateService(client, defaultFactoryRegistry(t), zap.NewNop())
+
+	_, err := service.EstimateSwap(context.Background(), usecases.EstimateRequest{
+		Factory:   "uniswap",
+		SrcToken:  testToken0.Hex(),
+		DstToken:  testToken1.Hex(),
+		SrcAmount: big.NewInt(1_000),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := common.HexToAddress(testPool)
+	if client.lastPool != expected {
+		t.Errorf("Expected derived pool %s, got %s", expected.Hex(), client.lastPool.Hex())
+	}
+}
+
+func TestEstimateService_FactoryFeeApplied(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	service := usecases.NewEstimateService(client, defaultFactoryRegistry(t), zap.NewNop())
+
+	result, err := service.EstimateSwap(context.Background(), usecases.EstimateRequest{
+		Factory:   "customswap",
+		SrcToken:  testToken0.Hex(),
+		DstToken:  testToken1.Hex(),
+		SrcAmount: big.NewInt(1_000),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 1% fee: 1000 * 9900 * 1e6 / (1e6 * 10000 + 1000 * 9900) = 989
+	if result.AmountOut.Cmp(big.NewInt(989)) != 0 {
+		t.Errorf("Expected factory fee to be applied (989), got %s", result.AmountOut)
+	}
+}
+
+func TestEstimateService_UnknownFactory(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	service := usecases.NewEstimateService(client, defaultFactoryRegistry(t), zap.NewNop())
+
+	_, err := service.EstimateSwap(context.Background(), usecases.EstimateRequest{
+		Factory:   "pancakeswap",
+		SrcToken:  testToken0.Hex(),
+		DstToken:  testToken1.Hex(),
+		SrcAmount: big.NewInt(1_000),
+	})
+	if !errors.Is(err, apperrors.ErrValidation) {
+		t.Fatalf("Expected ErrValidation, got %v", err)
+	}
+}
+
+// cutFactoryRegistry registers a fork that pays 1/6 of its 0.3% fee to the protocol on every swap
+func cutFactoryRegistry(t *testing.T) *uniswap_v2.FactoryRegistry {
+	t.Helper()
+	registry, err := uniswap_v2.NewFactoryRegistry(map[string]config.FactoryConfig{
+		"cutswap": {
+			Address:        "0x1111111111111111111111111111111111111111",
+			InitCodeHash:   "0x2222222222222222222222222222222222222222222222222222222222222222",
+			FeeBasisPoints: 30,
+			ProtocolCut:    "1/6",
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to build factory registry: %v", err)
+	}
+	return registry
+}
+
+func TestFactoryRegistry_ProtocolCutDefaultsOff(t *testing.T) {
+	factory, err := defaultFactoryRegistry(t).Get("uniswap")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if factory.ProtocolCut != nil {
+		t.Errorf("Expected no protocol cut by default, got %s", factory.ProtocolCut)
+	}
+}
+
+func TestFactoryRegistry_InvalidProtocolCut(t *testing.T) {
+	for _, cut := range []string{"abc", "-1/6", "1", "7/6"} {
+		_, err := uniswap_v2.NewFactoryRegistry(map[string]config.FactoryConfig{
+			"broken": {
+				Address:        "0x5C69bEe701ef814a2B6a3EDD4B1652CB9cc5aA6f",
+				InitCodeHash:   "0x96e8ac4277198ff8b6f785478aa9a39f403cb768dd02cbee326c3e7da348845f",
+				FeeBasisPoints: 30,
+				ProtocolCut:    cut,
+			},
+		})
+		if !errors.Is(err, uniswap_v2.ErrInvalidFactory) {
+			t.Errorf("cut %q: expected ErrInvalidFactory, got %v", cut, err)
+		}
+	}
+}
+
+func TestEstimateService_FactoryProtocolCut(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000_000), big.NewInt(1_000_000_000))
+	service := usecases.NewEstimateService(client, cutFactoryRegistry(t), zap.NewNop())
+
+	result, err := service.EstimateSwap(context.Background(), usecases.EstimateRequest{
+		Factory:   "cutswap",
+		SrcToken:  testToken0.Hex(),
+		DstToken:  testToken1.Hex(),
+		SrcAmount: big.NewInt(1_000_000),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Standard V2 gives 996_006; the protocol then takes 996_006 * 30 / 60000 = 498
+	if result.AmountOut.Cmp(big.NewInt(995_508)) != 0 {
+		t.Errorf("Expected protocol cut to be applied (995508), got %s", result.AmountOut)
+	}
+}
+
+func TestEstimateService_FactoryProtocolCutExactOut(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000_000), big.NewInt(1_000_000_000))
+	service := usecases.NewEstimateService(client, cutFactoryRegistry(t), zap.NewNop())
+
+	req := usecases.EstimateRequest{
+		Factory:   "cutswap",
+		SrcToken:  testToken0.Hex(),
+		DstToken:  testToken1.Hex(),
+		DstAmount: big.NewInt(995_508),
+	}
+	result, err := service.EstimateSwap(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The quoted input must deliver the target after the cut
+	req.DstAmount = nil
+	req.SrcAmount = result.AmountIn
+	forward, err := service.EstimateSwap(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if forward.AmountOut.Cmp(big.NewInt(995_508)) < 0 {
+		t.Errorf("Input %s only yields %s after the protocol cut", result.AmountIn, forward.AmountOut)
+	}
+}
@@ -0,0 +1,59 @@
+package tests
+
+import (
+	"testing"
+
+	"bigswapenergy/internal/presentation/http"
+
+	"github.com/valyala/fasthttp"
+)
+
+func serveRoute(router *http.Router, path string) int {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI(path)
+	router.Handler(ctx)
+	return ctx.Response.StatusCode()
+}
+
+func featureRouter(t *testing.T, features map[string]bool) *http.Router {
+	t.Helper()
+	flags, err := http.NewFeatureFlags(features)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ok := func(ctx *fasthttp.RequestCtx) { ctx.SetStatusCode(fasthttp.StatusOK) }
+
+	router := http.NewRouter()
+	router.Handle("/estimate", ok)
+	router.HandleFeature(flags, http.FeatureRoute, "/estimate/route", ok)
+	router.HandleFeature(flags, http.FeatureArbitrage, "/estimate/arb", ok)
+	return router
+}
+
+func TestFeatureFlags_DisabledRouteReturns404(t *testing.T) {
+	router := featureRouter(t, map[string]bool{http.FeatureRoute: false})
+
+	if status := serveRoute(router, "/estimate/route"); status != fasthttp.StatusNotFound {
+		t.Errorf("Expected disabled route to return 404, got %d", status)
+	}
+	if status := serveRoute(router, "/estimate/arb"); status != fasthttp.StatusOK {
+		t.Errorf("Expected unlisted feature to stay enabled, got %d", status)
+	}
+	if status := serveRoute(router, "/estimate"); status != fasthttp.StatusOK {
+		t.Errorf("Expected core /estimate to stay enabled, got %d", status)
+	}
+}
+
+func TestFeatureFlags_DefaultsToEnabled(t *testing.T) {
+	router := featureRouter(t, nil)
+
+	if status := serveRoute(router, "/estimate/route"); status != fasthttp.StatusOK {
+		t.Errorf("Expected route to be enabled by default, got %d", status)
+	}
+}
+
+func TestFeatureFlags_UnknownFeatureRejected(t *testing.T) {
+	if _, err := http.NewFeatureFlags(map[string]bool{"estimate": false}); err == nil {
+		t.Fatalf("Expected an error for a feature that can't be toggled")
+	}
+}
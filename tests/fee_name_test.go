@@ -0,0 +1,191 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	apperrors "bigswapenergy/internal/shared/errors"
+	"bigswapenergy/internal/shared/utils"
+	usecases "bigswapenergy/internal/usecases"
+
+	"github.com/valyala/fasthttp"
+)
+
+func feeNameRequest(name string, feeBps *int) usecases.EstimateRequest {
+	return usecases.EstimateRequest{
+		PoolAddress:    testPool,
+		SrcToken:       testToken0.Hex(),
+		DstToken:       testToken1.Hex(),
+		SrcAmount:      big.NewInt(1000),
+		FeeName:        name,
+		FeeBasisPoints: feeBps,
+	}
+}
+
+func TestFeeName_KnownNames(t *testing.T) {
+	tests := []struct {
+		name string
+		want int64
+	}{
+		// 0.3%: 1e6 * 9970 * 1e9 / (1e9 * 10000 + 1e6 * 9970)
+		{"uniswap", 996006},
+		{"sushiswap", 996006},
+		// 0.25%: 1e6 * 9975 * 1e9 / (1e9 * 10000 + 1e6 * 9975)
+		{"pancake", 996505},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			service := createEstimateService(newFakeUniswapV2Client(big.NewInt(1_000_000_000), big.NewInt(1_000_000_000)))
+
+			req := feeNameRequest(tc.name, nil)
+			req.SrcAmount = big.NewInt(1_000_000)
+			result, err := service.EstimateSwap(context.Background(), req)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if result.AmountOut.Cmp(big.NewInt(tc.want)) != 0 {
+				t.Errorf("Expected %d, got %s", tc.want, result.AmountOut)
+			}
+		})
+	}
+}
+
+func TestFeeName_Custom(t *testing.T) {
+	service := createEstimateService(newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000)))
+	fee := 100
+
+	result, err := service.EstimateSwap(context.Background(), feeNameRequest("custom", &fee))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.AmountOut.Cmp(big.NewInt(989)) != 0 {
+		t.Errorf("Expected 989, got %s", result.AmountOut)
+	}
+}
+
+func TestFeeName_Rejected(t *testing.T) {
+	fee := 30
+	tests := []struct {
+		name    string
+		req     usecases.EstimateRequest
+		wantErr error
+	}{
+		{"unknown", feeNameRequest("curve", nil), utils.ErrUnknownFeeName},
+		{"numeric_out_of_range", feeNameRequest("101", nil), utils.ErrFeeOutOfRange},
+		{"numeric_negative", feeNameRequest("-1", nil), utils.ErrFeeOutOfRange},
+		{"numeric_with_fee_bps", feeNameRequest("3", &fee), apperrors.ErrValidation},
+		{"custom_without_fee_bps", feeNameRequest("custom", nil), apperrors.ErrValidation},
+		{"name_with_fee_bps", feeNameRequest("uniswap", &fee), apperrors.ErrValidation},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+			service := createEstimateService(client)
+
+			_, err := service.EstimateSwap(context.Background(), tc.req)
+			if !errors.Is(err, apperrors.ErrValidation) || !errors.Is(err, tc.wantErr) {
+				t.Errorf("Expected %v as a validation error, got %v", tc.wantErr, err)
+			}
+			if client.reservesCalls != 0 {
+				t.Errorf("Expected no RPC for an invalid fee name")
+			}
+		})
+	}
+}
+
+func TestFeeName_HandlerResolvesName(t *testing.T) {
+	mockService := &mockEstimateService{estimateAmount: big.NewInt(996)}
+	handler := createEstimateHandler(mockService)
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	req.SetRequestURI("/estimate?pool=" + testPool + "&src=" + testToken0.Hex() + "&dst=" + testToken1.Hex() + "&src_amount=1000&fee=uniswap")
+	req.Header.SetMethod("GET")
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(req, nil, nil)
+	handler.EstimateSwapAmount(ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	fee := mockService.lastRequest.FeeBasisPoints
+	if fee == nil || *fee != 30 {
+		t.Errorf("Expected fee=uniswap to resolve to 30, got %v", fee)
+	}
+}
+
+func TestEstimateHandler_FeeBpsTiers(t *testing.T) {
+	base := "/estimate?pool=" + testPool + "&src=" + testToken0.Hex() + "&dst=" + testToken1.Hex() + "&src_amount=1000"
+	tests := []struct {
+		query      string
+		wantStatus int
+		wantBody   string
+	}{
+		{query: "", wantStatus: fasthttp.StatusOK, wantBody: "996"},
+		{query: "&fee_bps=30", wantStatus: fasthttp.StatusOK, wantBody: "996"},
+		{query: "&fee_bps=50", wantStatus: fasthttp.StatusOK, wantBody: "994"},
+		{query: "&fee_bps=100", wantStatus: fasthttp.StatusOK, wantBody: "989"},
+		{query: "&fee_bps=1000", wantStatus: fasthttp.StatusOK, wantBody: "899"},
+		{query: "&fee_bps=-1", wantStatus: fasthttp.StatusBadRequest},
+		{query: "&fee_bps=1001", wantStatus: fasthttp.StatusBadRequest},
+		{query: "&fee_bps=abc", wantStatus: fasthttp.StatusBadRequest},
+		{query: "&fee=uniswap", wantStatus: fasthttp.StatusOK, wantBody: "996"},
+		{query: "&fee=custom&fee_bps=100", wantStatus: fasthttp.StatusOK, wantBody: "989"},
+		{query: "&fee=uniswap&fee_bps=30", wantStatus: fasthttp.StatusBadRequest},
+	}
+	for _, tc := range tests {
+		handler := createEstimateHandler(createEstimateService(newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))))
+		ctx := runQuery(handler.EstimateSwapAmount, base+tc.query)
+		if ctx.Response.StatusCode() != tc.wantStatus {
+			t.Errorf("%q: expected status %d, got %d: %s", tc.query, tc.wantStatus, ctx.Response.StatusCode(), ctx.Response.Body())
+			continue
+		}
+		if tc.wantBody != "" && string(ctx.Response.Body()) != tc.wantBody {
+			t.Errorf("%q: expected %s, got %s", tc.query, tc.wantBody, ctx.Response.Body())
+		}
+	}
+}
+
+func TestEstimateHandler_NumericFee(t *testing.T) {
+	base := "/estimate?pool=" + testPool + "&src=" + testToken0.Hex() + "&dst=" + testToken1.Hex() + "&src_amount=1000"
+	tests := []struct {
+		query      string
+		wantStatus int
+		wantBody   string
+	}{
+		{query: "&fee=3", wantStatus: fasthttp.StatusOK, wantBody: "996"},
+		{query: "&fee=5", wantStatus: fasthttp.StatusOK, wantBody: "994"},
+		{query: "&fee=10", wantStatus: fasthttp.StatusOK, wantBody: "989"},
+		{query: "&fee=0", wantStatus: fasthttp.StatusOK, wantBody: "999"},
+		{query: "&fee=100", wantStatus: fasthttp.StatusOK, wantBody: "899"},
+		{query: "&fee=101", wantStatus: fasthttp.StatusBadRequest},
+		{query: "&fee=-1", wantStatus: fasthttp.StatusBadRequest},
+	}
+	for _, tc := range tests {
+		handler := createEstimateHandler(createEstimateService(newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))))
+		ctx := runQuery(handler.EstimateSwapAmount, base+tc.query)
+		if ctx.Response.StatusCode() != tc.wantStatus {
+			t.Errorf("%q: expected status %d, got %d: %s", tc.query, tc.wantStatus, ctx.Response.StatusCode(), ctx.Response.Body())
+			continue
+		}
+		if tc.wantBody != "" && string(ctx.Response.Body()) != tc.wantBody {
+			t.Errorf("%q: expected %s, got %s", tc.query, tc.wantBody, ctx.Response.Body())
+		}
+	}
+}
+
+func TestEstimateHandler_NumericFeeResolvesToBasisPoints(t *testing.T) {
+	mockService := &mockEstimateService{estimateAmount: big.NewInt(989)}
+	handler := createEstimateHandler(mockService)
+
+	ctx := runQuery(handler.EstimateSwapAmount, "/estimate?pool="+testPool+"&src="+testToken0.Hex()+"&dst="+testToken1.Hex()+"&src_amount=1000&fee=10")
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	if fee := mockService.lastRequest.FeeBasisPoints; fee == nil || *fee != 100 {
+		t.Errorf("Expected fee=10 to resolve to 100 bps, got %v", fee)
+	}
+}
@@ -0,0 +1,169 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"bigswapenergy/internal/presentation/http"
+	"bigswapenergy/internal/shared/config"
+	usecases "bigswapenergy/internal/usecases"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+func feeTiersRequest(tiers []int) usecases.EstimateRequest {
+	return usecases.EstimateRequest{
+		PoolAddress: testPool,
+		SrcToken:    testToken0.Hex(),
+		DstToken:    testToken1.Hex(),
+		SrcAmount:   big.NewInt(1000),
+		FeeTiers:    tiers,
+	}
+}
+
+func TestEstimateFeeTiers_AssumedAndRanked(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	service := createEstimateService(client)
+
+	result, err := service.EstimateSwap(context.Background(), feeTiersRequest([]int{100, 30, 50}))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !result.FeeAssumed {
+		t.Error("Expected the fee to be reported as assumed")
+	}
+
+	want := []usecases.FeeTierQuote{
+		{FeeBasisPoints: 30, AmountOut: big.NewInt(996)},
+		{FeeBasisPoints: 50, AmountOut: big.NewInt(994)},
+		{FeeBasisPoints: 100, AmountOut: big.NewInt(989)},
+	}
+	if len(result.Tiers) != len(want) {
+		t.Fatalf("Expected %d tiers, got %+v", len(want), result.Tiers)
+	}
+	for i, tier := range want {
+		got := result.Tiers[i]
+		if got.FeeBasisPoints != tier.FeeBasisPoints || got.AmountOut.Cmp(tier.AmountOut) != 0 {
+			t.Errorf("tier %d: expected fee %d -> %s, got fee %d -> %s", i, tier.FeeBasisPoints, tier.AmountOut, got.FeeBasisPoints, got.AmountOut)
+		}
+	}
+	if client.reservesCalls != 1 {
+		t.Errorf("Expected every tier from one reserve read, got %d", client.reservesCalls)
+	}
+}
+
+func TestEstimateFeeTiers_DefaultConfigTiers(t *testing.T) {
+	t.Setenv("ETHEREUM_RPC_URL", "http://localhost:8545")
+	defaults, err := config.LoadConfig("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	shipped, err := config.LoadConfig(filepath.Join("..", "internal", "shared", "config", "config.yaml"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []usecases.FeeTierQuote{
+		{FeeBasisPoints: 25, AmountOut: big.NewInt(996505)},
+		{FeeBasisPoints: 30, AmountOut: big.NewInt(996006)},
+		{FeeBasisPoints: 100, AmountOut: big.NewInt(989020)},
+	}
+	for name, cfg := range map[string]*config.Config{"defaults": defaults, "config.yaml": shipped} {
+		service := createEstimateService(newFakeUniswapV2Client(big.NewInt(1_000_000_000), big.NewInt(1_000_000_000)))
+		req := feeTiersRequest(cfg.AssumedFeeTiers)
+		req.SrcAmount = big.NewInt(1_000_000)
+
+		result, err := service.EstimateSwap(context.Background(), req)
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", name, err)
+		}
+		if len(result.Tiers) != len(want) {
+			t.Fatalf("%s: expected %d tiers, got %+v", name, len(want), result.Tiers)
+		}
+		for i, tier := range want {
+			got := result.Tiers[i]
+			if got.FeeBasisPoints != tier.FeeBasisPoints || got.AmountOut.Cmp(tier.AmountOut) != 0 {
+				t.Errorf("%s tier %d: expected fee %d -> %s, got fee %d -> %s", name, i, tier.FeeBasisPoints, tier.AmountOut, got.FeeBasisPoints, got.AmountOut)
+			}
+		}
+	}
+}
+
+func TestEstimateFeeTiers_KnownFeeIsNotAssumed(t *testing.T) {
+	service := createEstimateService(newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000)))
+	req := feeTiersRequest([]int{30, 100})
+	fee := 50
+	req.FeeBasisPoints = &fee
+
+	result, err := service.EstimateSwap(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.FeeAssumed || len(result.Tiers) != 1 || result.Tiers[0].FeeBasisPoints != 50 {
+		t.Errorf("Expected a single tier at the known fee, got assumed=%v %+v", result.FeeAssumed, result.Tiers)
+	}
+}
+
+func feeTiersHandlerRequest(tiers []int, extraQuery ...string) *fasthttp.RequestCtx {
+	service := createEstimateService(newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000)))
+	handler := http.NewEstimateHandler(service, zap.NewNop(), &config.Config{AssumedFeeTiers: tiers})
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	req.SetRequestURI("/estimate?pool=" + testPool + "&src=" + testToken0.Hex() + "&dst=" + testToken1.Hex() + "&src_amount=1000&fee_tiers=true" + strings.Join(extraQuery, ""))
+	req.Header.SetMethod("GET")
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(req, nil, nil)
+	handler.EstimateSwapAmount(ctx)
+	return ctx
+}
+
+func TestEstimateFeeTiersHandler(t *testing.T) {
+	ctx := feeTiersHandlerRequest([]int{100, 30})
+
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	var body http.FeeTiersResponse
+	if err := json.Unmarshal(ctx.Response.Body(), &body); err != nil {
+		t.Fatalf("Expected JSON body, got %q", ctx.Response.Body())
+	}
+	if !body.FeeAssumed || body.Note == "" {
+		t.Errorf("Expected an assumed-fee note, got %+v", body)
+	}
+	if len(body.Quotes) != 2 || body.Quotes[0].FeeBasisPoints != 30 || body.Quotes[1].FeeBasisPoints != 100 {
+		t.Errorf("Expected both configured tiers ranked 30 then 100, got %+v", body.Quotes)
+	}
+	if body.Quotes[0].AmountOut != "996" || body.Quotes[1].AmountOut != "989" {
+		t.Errorf("Unexpected tier outputs: %+v", body.Quotes)
+	}
+}
+
+func TestEstimateFeeTiersHandler_JSONFormatKeepsTiers(t *testing.T) {
+	ctx := feeTiersHandlerRequest([]int{100, 30}, "&format=json")
+
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	var body http.FeeTiersResponse
+	if err := json.Unmarshal(ctx.Response.Body(), &body); err != nil {
+		t.Fatalf("Expected JSON body, got %q", ctx.Response.Body())
+	}
+	if len(body.Quotes) != 2 || body.Quotes[0].AmountOut != "996" || body.Quotes[1].AmountOut != "989" {
+		t.Errorf("Expected format=json to keep the tier quotes, got %s", ctx.Response.Body())
+	}
+}
+
+func TestEstimateFeeTiersHandler_NoTiersConfigured(t *testing.T) {
+	ctx := feeTiersHandlerRequest(nil)
+
+	if ctx.Response.StatusCode() == fasthttp.StatusOK {
+		t.Errorf("Expected an error status without configured tiers")
+	}
+}
@@ -0,0 +1,135 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	apperrors "bigswapenergy/internal/shared/errors"
+	usecases "bigswapenergy/internal/usecases"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestGeometricAmounts_Sequence(t *testing.T) {
+	amounts, err := usecases.GeometricAmounts(big.NewInt(1_000_000), 10, 6)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []int64{1e6, 1e7, 1e8, 1e9, 1e10, 1e11}
+	if len(amounts) != len(want) {
+		t.Fatalf("Expected %d amounts, got %d", len(want), len(amounts))
+	}
+	for i, amount := range amounts {
+		if amount.Cmp(big.NewInt(want[i])) != 0 {
+			t.Errorf("amount %d: got %s, want %d", i, amount, want[i])
+		}
+	}
+}
+
+func TestGeometricAmounts_BeyondInt64(t *testing.T) {
+	start := new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+	amounts, err := usecases.GeometricAmounts(start, 10, 6)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	last := new(big.Int).Exp(big.NewInt(10), big.NewInt(23), nil)
+	if amounts[5].Cmp(last) != 0 {
+		t.Errorf("Expected 1e23, got %s", amounts[5])
+	}
+	if start.Cmp(new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)) != 0 {
+		t.Errorf("Expected start not to be modified, got %s", start)
+	}
+}
+
+func TestGeometricAmounts_Bounds(t *testing.T) {
+	tests := []struct {
+		name   string
+		start  *big.Int
+		factor int64
+		count  int
+		valid  bool
+	}{
+		{name: "count_min", start: big.NewInt(1), factor: 2, count: 1, valid: true},
+		{name: "count_max", start: big.NewInt(1), factor: 2, count: usecases.MaxGeometricCount, valid: true},
+		{name: "count_zero", start: big.NewInt(1), factor: 2, count: 0},
+		{name: "count_over_max", start: big.NewInt(1), factor: 2, count: usecases.MaxGeometricCount + 1},
+		{name: "factor_one", start: big.NewInt(1), factor: 1, count: 3},
+		{name: "factor_negative", start: big.NewInt(1), factor: -2, count: 3},
+		{name: "start_zero", start: big.NewInt(0), factor: 2, count: 3},
+		{name: "start_nil", factor: 2, count: 3},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			amounts, err := usecases.GeometricAmounts(tc.start, tc.factor, tc.count)
+			if !tc.valid {
+				if !errors.Is(err, apperrors.ErrValidation) {
+					t.Fatalf("Expected ErrValidation, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(amounts) != tc.count {
+				t.Errorf("Expected %d amounts, got %d", tc.count, len(amounts))
+			}
+		})
+	}
+}
+
+func TestEstimateLiquidityCurve_GeometricSweepOneRead(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000_000_000), big.NewInt(1_000_000_000_000))
+	service := createEstimateService(client)
+
+	amounts, err := usecases.GeometricAmounts(big.NewInt(1_000), 10, 6)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	points, err := service.EstimateLiquidityCurve(context.Background(), usecases.EstimateRequest{
+		PoolAddress: testPool, SrcToken: testToken0.Hex(), DstToken: testToken1.Hex(), SrcAmounts: amounts,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(points) != 6 {
+		t.Fatalf("Expected 6 points, got %d", len(points))
+	}
+	if client.reservesCalls != 1 {
+		t.Errorf("Expected a single reserve read for the sweep, got %d", client.reservesCalls)
+	}
+}
+
+func TestEstimateHandler_GeometricSweep(t *testing.T) {
+	base := "/estimate?pool=" + testPool + "&src=" + testToken0.Hex() + "&dst=" + testToken1.Hex()
+
+	service := &mockEstimateService{estimateAmount: big.NewInt(996)}
+	ctx := runQuery(createEstimateHandler(service).EstimateSwapAmount, base+"&start=1000000&factor=10&count=6")
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	if got := service.lastRequest.SrcAmounts; len(got) != 6 || got[5].Cmp(big.NewInt(100_000_000_000)) != 0 {
+		t.Errorf("Expected the expanded sweep, got %v", got)
+	}
+	if lines := strings.Split(string(ctx.Response.Body()), "\n"); len(lines) != 6 {
+		t.Errorf("Expected one output per size, got %q", ctx.Response.Body())
+	}
+
+	for name, query := range map[string]string{
+		"with_src_amount": "&start=1000&factor=10&count=3&src_amount=5",
+		"missing_count":   "&start=1000&factor=10",
+		"factor_one":      "&start=1000&factor=1&count=3",
+		"count_too_large": "&start=1000&factor=2&count=33",
+		"fractional":      "&start=1000&factor=1.5&count=3",
+	} {
+		t.Run(name, func(t *testing.T) {
+			service := &mockEstimateService{estimateAmount: big.NewInt(996)}
+			ctx := runQuery(createEstimateHandler(service).EstimateSwapAmount, base+query)
+			if ctx.Response.StatusCode() == fasthttp.StatusOK {
+				t.Fatalf("Expected %q to be rejected", query)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package tests
+
+import (
+	"io"
+	"math/big"
+	nethttp "net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bigswapenergy/internal/presentation/http"
+	"bigswapenergy/internal/shared/config"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestNetHTTPHandler_ServesPriorKnowledgeH2C(t *testing.T) {
+	estimateHandler := createEstimateHandler(&mockEstimateService{estimateAmount: big.NewInt(996)})
+
+	srv := httptest.NewUnstartedServer(http.NewNetHTTPHandler(estimateHandler.EstimateSwapAmount))
+	srv.Config.Protocols = new(nethttp.Protocols)
+	srv.Config.Protocols.SetHTTP1(true)
+	srv.Config.Protocols.SetUnencryptedHTTP2(true)
+	srv.Start()
+	defer srv.Close()
+
+	transport := &nethttp.Transport{Protocols: new(nethttp.Protocols)}
+	transport.Protocols.SetUnencryptedHTTP2(true)
+	client := &nethttp.Client{Transport: transport}
+
+	resp, err := client.Get(srv.URL + "/estimate?pool=0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc&src=0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48&dst=0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2&src_amount=1000")
+	if err != nil {
+		t.Fatalf("h2c request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.ProtoMajor != 2 {
+		t.Errorf("Expected HTTP/2, got %s", resp.Proto)
+	}
+	if resp.StatusCode != nethttp.StatusOK {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/plain" {
+		t.Errorf("Expected text/plain, got %q", ct)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "996" {
+		t.Errorf("Expected body 996, got %q", body)
+	}
+}
+
+func TestNetHTTPHandler_PreservesErrorStatus(t *testing.T) {
+	estimateHandler := createEstimateHandler(&mockEstimateService{})
+
+	srv := httptest.NewServer(http.NewNetHTTPHandler(estimateHandler.EstimateSwapAmount))
+	defer srv.Close()
+
+	resp, err := nethttp.Get(srv.URL + "/estimate?src=0x2")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode == nethttp.StatusOK {
+		t.Error("Expected an error status for an invalid request")
+	}
+}
+
+func TestNewServer_DefaultsToFastHTTP(t *testing.T) {
+	server := http.NewServer(func(ctx *fasthttp.RequestCtx) {}, config.ServerConfig{})
+	if _, ok := server.(*fasthttp.Server); !ok {
+		t.Errorf("Expected a fasthttp server when h2c is disabled, got %T", server)
+	}
+}
@@ -0,0 +1,176 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"bigswapenergy/internal/app"
+	"bigswapenergy/internal/infrastructure/ethereum"
+	"bigswapenergy/internal/presentation/http"
+	"bigswapenergy/internal/shared/clock"
+	"bigswapenergy/internal/shared/config"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// countingHealthChecker reports fixed per-connection health and counts checks
+type countingHealthChecker struct {
+	health map[int]bool
+	checks int
+}
+
+func (c *countingHealthChecker) CheckConnectionHealth(ctx context.Context) bool {
+	return true
+}
+
+func (c *countingHealthChecker) CheckConnectionsHealth(ctx context.Context) map[int]bool {
+	c.checks++
+	return c.health
+}
+
+func runHealth(t *testing.T, handler *http.HealthHandler) (int, http.HealthResponse) {
+	t.Helper()
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/health")
+	handler.GetHealth(ctx)
+
+	var resp http.HealthResponse
+	if err := json.Unmarshal(ctx.Response.Body(), &resp); err != nil {
+		t.Fatalf("Expected JSON body, got %q", ctx.Response.Body())
+	}
+	return ctx.Response.StatusCode(), resp
+}
+
+func TestHealth_CountsConnections(t *testing.T) {
+	checker := &countingHealthChecker{health: map[int]bool{0: true, 1: false, 2: true}}
+	status, resp := runHealth(t, http.NewHealthHandler(checker, time.Second, clock.NewFakeClock(time.Unix(1_700_000_000, 0))))
+	if status != fasthttp.StatusOK || resp.Healthy != 2 || resp.Total != 3 {
+		t.Errorf("Expected 200 with 2 of 3 healthy, got %d %+v", status, resp)
+	}
+
+	checker = &countingHealthChecker{health: map[int]bool{0: false, 1: false}}
+	status, resp = runHealth(t, http.NewHealthHandler(checker, time.Second, clock.NewFakeClock(time.Unix(1_700_000_000, 0))))
+	if status != fasthttp.StatusServiceUnavailable || resp.Healthy != 0 || resp.Total != 2 {
+		t.Errorf("Expected 503 with none healthy, got %d %+v", status, resp)
+	}
+}
+
+func TestHealth_CachesForASecond(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Unix(1_700_000_000, 0))
+	checker := &countingHealthChecker{health: map[int]bool{0: true}}
+	handler := http.NewHealthHandler(checker, time.Second, fakeClock)
+
+	runHealth(t, handler)
+	fakeClock.Advance(999 * time.Millisecond)
+	runHealth(t, handler)
+	if checker.checks != 1 {
+		t.Fatalf("Expected polls within a second to share one check, got %d", checker.checks)
+	}
+
+	checker.health = map[int]bool{0: false}
+	fakeClock.Advance(time.Millisecond)
+	if status, _ := runHealth(t, handler); status != fasthttp.StatusServiceUnavailable || checker.checks != 2 {
+		t.Errorf("Expected a fresh check after a second, got %d after %d checks", status, checker.checks)
+	}
+}
+
+func TestHealth_SingleConnectionChecker(t *testing.T) {
+	handler := http.NewHealthHandler(fakeHealthChecker{healthy: false}, time.Second, clock.NewFakeClock(time.Unix(1_700_000_000, 0)))
+	if status, resp := runHealth(t, handler); status != fasthttp.StatusServiceUnavailable || resp.Total != 1 {
+		t.Errorf("Expected 503 for one unhealthy connection, got %d %+v", status, resp)
+	}
+}
+
+func TestFailover_CheckConnectionsHealthFlattensPools(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Unix(1_700_000_000, 0))
+	pool := newTestPool(&pooledConnection{healthy: true}, &pooledConnection{healthy: false})
+	down := &flakyEthClient{failing: true}
+	client := ethereum.NewFailoverEthereumClient([]ethereum.Endpoint{
+		{Name: "primary", Client: pool},
+		{Name: "fallback1", Client: down},
+	}, consecutiveCircuitConfig, fakeClock, zap.NewNop())
+
+	health := client.CheckConnectionsHealth(context.Background())
+	if len(health) != 3 || !health[0] || health[1] || !health[2] {
+		t.Errorf("Expected the pool's connections then the fallback, got %v", health)
+	}
+
+	// An endpoint whose circuit is open reads as unhealthy without a check
+	tripped := ethereum.NewFailoverEthereumClient([]ethereum.Endpoint{{Name: "primary", Client: down}}, consecutiveCircuitConfig, fakeClock, zap.NewNop())
+	for i := 0; i < 3; i++ {
+		tripped.GetLatestBlockNumber(context.Background())
+	}
+	if health := tripped.CheckConnectionsHealth(context.Background()); len(health) != 1 || health[0] {
+		t.Errorf("Expected an open circuit's connection unhealthy, got %v", health)
+	}
+}
+
+func TestLoadConfig_HealthCheckPeriod(t *testing.T) {
+	t.Setenv("ETHEREUM_RPC_URL", "http://localhost:8545")
+
+	cfg, err := config.LoadConfig("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Server.HealthCheckPeriod != 30*time.Second {
+		t.Errorf("Expected a 30s health check period by default, got %v", cfg.Server.HealthCheckPeriod)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("server:\n  health_check_period: \"5s\"\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if cfg, err = config.LoadConfig(path); err != nil || cfg.Server.HealthCheckPeriod != 5*time.Second {
+		t.Errorf("Expected the overridden 5s period, got %v (err %v)", cfg.Server.HealthCheckPeriod, err)
+	}
+
+	if err := os.WriteFile(path, []byte("server:\n  health_check_period: \"0s\"\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if _, err := config.LoadConfig(path); err == nil {
+		t.Error("Expected an error for a zero health check period")
+	}
+}
+
+func TestApp_LogsUnhealthyConnectionsPeriodically(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Address:           freeAddress(t),
+			ShutdownTimeout:   5 * time.Second,
+			HealthCheckPeriod: time.Millisecond,
+		},
+		RateLimit: config.RateLimitConfig{RequestsPerMinute: 100},
+		Cache:     config.CacheConfig{TokenTTL: time.Hour},
+	}
+	core, logs := observer.New(zapcore.InfoLevel)
+	pool := newTestPool(&pooledConnection{healthy: true}, &pooledConnection{healthy: false})
+	application, err := app.New(cfg, pool, zap.New(core))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- application.Run(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for logs.FilterMessage("RPC connection unhealthy").Len() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the unhealthy connection to be logged")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if entry := logs.FilterMessage("RPC connection unhealthy").All()[0]; entry.ContextMap()["connection"] != int64(1) {
+		t.Errorf("Expected connection 1 reported, got %v", entry.ContextMap())
+	}
+}
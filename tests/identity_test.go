@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	apperrors "bigswapenergy/internal/shared/errors"
+	usecases "bigswapenergy/internal/usecases"
+
+	"github.com/valyala/fasthttp"
+)
+
+func identityRequest(allow bool) usecases.EstimateRequest {
+	return usecases.EstimateRequest{
+		PoolAddress:   testPool,
+		SrcToken:      testToken0.Hex(),
+		DstToken:      testToken0.Hex(),
+		SrcAmount:     big.NewInt(1_234),
+		AllowIdentity: allow,
+	}
+}
+
+func TestIdentityQuote_RejectedByDefault(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	service := createEstimateService(client)
+
+	if _, err := service.EstimateSwap(context.Background(), identityRequest(false)); !errors.Is(err, apperrors.ErrBusinessRule) {
+		t.Fatalf("Expected ErrBusinessRule, got %v", err)
+	}
+}
+
+func TestIdentityQuote_PassThrough(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	service := createEstimateService(client)
+
+	result, err := service.EstimateSwap(context.Background(), identityRequest(true))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.AmountOut.Cmp(big.NewInt(1_234)) != 0 {
+		t.Errorf("Expected the amount unchanged, got %s", result.AmountOut)
+	}
+	if client.reservesCalls != 0 || client.tokensCalls != 0 {
+		t.Errorf("Expected no chain reads, got %d reserve and %d token reads", client.reservesCalls, client.tokensCalls)
+	}
+
+	list := identityRequest(true)
+	list.SrcAmount, list.SrcAmounts = nil, []*big.Int{big.NewInt(5), big.NewInt(10)}
+	result, err = service.EstimateSwap(context.Background(), list)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.AmountsOut) != 2 || result.AmountsOut[1].Cmp(big.NewInt(10)) != 0 {
+		t.Errorf("Expected each amount unchanged, got %v", result.AmountsOut)
+	}
+
+	exactOut := identityRequest(true)
+	exactOut.SrcAmount, exactOut.DstAmount = nil, big.NewInt(77)
+	result, err = service.EstimateSwap(context.Background(), exactOut)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.AmountIn.Cmp(big.NewInt(77)) != 0 {
+		t.Errorf("Expected the required input to equal dst_amount, got %s", result.AmountIn)
+	}
+}
+
+func TestIdentityQuote_Handler(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	handler := createEstimateHandler(createEstimateService(client))
+	base := "/estimate?pool=" + testPool + "&src=" + testToken0.Hex() + "&dst=" + testToken0.Hex() + "&src_amount=1234"
+
+	if ctx := runQuery(handler.EstimateSwapAmount, base); ctx.Response.StatusCode() == fasthttp.StatusOK {
+		t.Errorf("Expected src == dst to be rejected without allow_identity")
+	}
+
+	ctx := runQuery(handler.EstimateSwapAmount, base+"&allow_identity=true")
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	if body := string(ctx.Response.Body()); body != "1234" {
+		t.Errorf("Expected the amount unchanged, got %q", body)
+	}
+}
@@ -0,0 +1,117 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"bigswapenergy/internal/presentation/http"
+	apperrors "bigswapenergy/internal/shared/errors"
+	usecases "bigswapenergy/internal/usecases"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestReadPoolInvariant_K(t *testing.T) {
+	service := createEstimateService(newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(2_000_000)))
+
+	result, err := service.ReadPoolInvariant(context.Background(), usecases.InvariantRequest{PoolAddress: testPool})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.K.Cmp(big.NewInt(2_000_000_000_000)) != 0 {
+		t.Errorf("Expected k = 2e12, got %s", result.K)
+	}
+	if result.Swap != nil {
+		t.Errorf("Expected no swap without an amount, got %+v", result.Swap)
+	}
+}
+
+func TestReadPoolInvariant_SwapGrowsK(t *testing.T) {
+	for _, src := range []string{testToken0.Hex(), testToken1.Hex()} {
+		t.Run(src, func(t *testing.T) {
+			service := createEstimateService(newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(2_000_000)))
+
+			result, err := service.ReadPoolInvariant(context.Background(), usecases.InvariantRequest{
+				PoolAddress: testPool,
+				SrcToken:    src,
+				SrcAmount:   big.NewInt(10_000),
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			swap := result.Swap
+			if swap == nil {
+				t.Fatalf("Expected a swap result")
+			}
+			if swap.KIncrease.Sign() <= 0 || swap.KAfter.Cmp(result.K) <= 0 {
+				t.Errorf("Expected the fee to grow k, got k=%s k_after=%s", result.K, swap.KAfter)
+			}
+			if new(big.Int).Sub(swap.KAfter, result.K).Cmp(swap.KIncrease) != 0 {
+				t.Errorf("Expected k_increase = k_after - k")
+			}
+
+			sold, bought := swap.Reserve0After, swap.Reserve1After
+			soldBefore, boughtBefore := result.Reserve0, result.Reserve1
+			if src == testToken1.Hex() {
+				sold, bought = bought, sold
+				soldBefore, boughtBefore = boughtBefore, soldBefore
+			}
+			if new(big.Int).Sub(sold, soldBefore).Cmp(swap.AmountIn) != 0 {
+				t.Errorf("Expected the sold reserve to grow by amount_in")
+			}
+			if new(big.Int).Sub(boughtBefore, bought).Cmp(swap.AmountOut) != 0 {
+				t.Errorf("Expected the bought reserve to shrink by amount_out")
+			}
+		})
+	}
+}
+
+func TestReadPoolInvariant_RejectsBadSwap(t *testing.T) {
+	tests := []struct {
+		name string
+		req  usecases.InvariantRequest
+		want error
+	}{
+		{name: "amount_without_src", req: usecases.InvariantRequest{PoolAddress: testPool, SrcAmount: big.NewInt(1)}, want: apperrors.ErrValidation},
+		{name: "src_not_in_pool", req: usecases.InvariantRequest{PoolAddress: testPool, SrcToken: testPool, SrcAmount: big.NewInt(1)}, want: apperrors.ErrValidation},
+		{name: "zero_amount", req: usecases.InvariantRequest{PoolAddress: testPool, SrcToken: testToken0.Hex(), SrcAmount: big.NewInt(0)}, want: apperrors.ErrValidation},
+		{name: "dust_amount", req: usecases.InvariantRequest{PoolAddress: testPool, SrcToken: testToken1.Hex(), SrcAmount: big.NewInt(1)}, want: usecases.ErrOutputRoundsToZero},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			service := createEstimateService(newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(2_000_000)))
+			if _, err := service.ReadPoolInvariant(context.Background(), tc.req); !errors.Is(err, tc.want) {
+				t.Fatalf("Expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestGetPoolInvariant_Handler(t *testing.T) {
+	service := createEstimateService(newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(2_000_000)))
+	handler := createEstimateHandler(service)
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	req.SetRequestURI("/pool/invariant?pool=" + testPool + "&src=" + testToken0.Hex() + "&src_amount=10000")
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(req, nil, nil)
+	handler.GetPoolInvariant(ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	var resp http.PoolInvariantResponse
+	if err := json.Unmarshal(ctx.Response.Body(), &resp); err != nil {
+		t.Fatalf("Expected JSON, got %q: %v", ctx.Response.Body(), err)
+	}
+	if resp.K != "2000000000000" || resp.Pool != testPool {
+		t.Errorf("Unexpected response: %+v", resp)
+	}
+	if resp.Swap == nil || resp.Swap.KAfter == "" || resp.Swap.KIncrease == "" {
+		t.Errorf("Expected swap fields as decimal strings, got %+v", resp.Swap)
+	}
+}
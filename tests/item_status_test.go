@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"bigswapenergy/internal/presentation/http"
+	apperrors "bigswapenergy/internal/shared/errors"
+	usecases "bigswapenergy/internal/usecases"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestEstimateService_ItemStatusMixesOkDustAndError(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	service := createEstimateService(client)
+
+	result, err := service.EstimateSwap(context.Background(), usecases.EstimateRequest{
+		PoolAddress: testPool,
+		SrcToken:    testToken0.Hex(),
+		DstToken:    testToken1.Hex(),
+		SrcAmounts:  []*big.Int{big.NewInt(1_000), big.NewInt(1), big.NewInt(0)},
+		ItemStatus:  true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(result.Items) != 3 {
+		t.Fatalf("Expected 3 items, got %d", len(result.Items))
+	}
+	if item := result.Items[0]; item.Status != usecases.QuoteStatusOK || item.AmountOut.Cmp(big.NewInt(996)) != 0 {
+		t.Errorf("Expected ok 996, got %s %v", item.Status, item.AmountOut)
+	}
+	if item := result.Items[1]; item.Status != usecases.QuoteStatusDustZero || item.AmountOut != nil || !errors.Is(item.Err, usecases.ErrOutputRoundsToZero) {
+		t.Errorf("Expected dust_zero without an amount, got %+v", item)
+	}
+	if item := result.Items[2]; item.Status != usecases.QuoteStatusError || !errors.Is(item.Err, apperrors.ErrValidation) {
+		t.Errorf("Expected error with ErrValidation, got %+v", item)
+	}
+	if client.reservesCalls != 1 {
+		t.Errorf("Expected a single reserve read, got %d", client.reservesCalls)
+	}
+}
+
+func TestEstimateService_DustStillFailsWithoutItemStatus(t *testing.T) {
+	service := createEstimateService(newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000)))
+
+	_, err := service.EstimateSwap(context.Background(), usecases.EstimateRequest{
+		PoolAddress: testPool,
+		SrcToken:    testToken0.Hex(),
+		DstToken:    testToken1.Hex(),
+		SrcAmounts:  []*big.Int{big.NewInt(1_000), big.NewInt(1)},
+	})
+	if !errors.Is(err, usecases.ErrOutputRoundsToZero) || !errors.Is(err, apperrors.ErrBusinessRule) {
+		t.Fatalf("Expected dust to fail the request, got %v", err)
+	}
+}
+
+// itemStatusService returns one item of each status
+type itemStatusService struct {
+	mockEstimateService
+}
+
+func (s *itemStatusService) EstimateSwap(ctx context.Context, req usecases.EstimateRequest) (*usecases.EstimateResult, error) {
+	s.lastRequest = req
+	return &usecases.EstimateResult{BlockNumber: 7, Items: []usecases.QuoteItem{
+		{AmountIn: big.NewInt(1_000), AmountOut: big.NewInt(996), Status: usecases.QuoteStatusOK},
+		{AmountIn: big.NewInt(1), Status: usecases.QuoteStatusDustZero, Err: usecases.ErrOutputRoundsToZero},
+		{AmountIn: big.NewInt(0), Status: usecases.QuoteStatusError, Err: errors.New("source amount must be positive")},
+	}}, nil
+}
+
+func TestEstimateHandler_ItemStatusResponse(t *testing.T) {
+	service := &itemStatusService{}
+	handler := createEstimateHandler(service)
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	req.SetRequestURI("/estimate?pool=0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc&src=0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48&dst=0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2&src_amount=1000&src_amount=1&src_amount=0&item_status=true")
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(req, nil, nil)
+	handler.EstimateSwapAmount(ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	if !service.lastRequest.ItemStatus || len(service.lastRequest.SrcAmounts) != 3 {
+		t.Fatalf("Expected all three amounts passed through with item status, got %+v", service.lastRequest)
+	}
+
+	var resp http.QuoteItemsResponse
+	if err := json.Unmarshal(ctx.Response.Body(), &resp); err != nil {
+		t.Fatalf("invalid JSON response: %v", err)
+	}
+	want := []struct{ status, amountOut string }{{"ok", "996"}, {"dust_zero", ""}, {"error", ""}}
+	if len(resp.Items) != len(want) {
+		t.Fatalf("Expected %d items, got %s", len(want), ctx.Response.Body())
+	}
+	for i, w := range want {
+		if resp.Items[i].Status != w.status || resp.Items[i].AmountOut != w.amountOut {
+			t.Errorf("item %d: expected %s %q, got %+v", i, w.status, w.amountOut, resp.Items[i])
+		}
+	}
+	if resp.Items[1].Error == "" || resp.Items[2].Error == "" {
+		t.Errorf("Expected dust and error items to carry a reason, got %s", ctx.Response.Body())
+	}
+}
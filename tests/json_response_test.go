@@ -0,0 +1,88 @@
+package tests
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"bigswapenergy/internal/presentation/http"
+
+	"github.com/valyala/fasthttp"
+)
+
+func runEstimateWithAccept(handler *http.EstimateHandler, uri, accept string) *fasthttp.RequestCtx {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI(uri)
+	ctx.Request.Header.Set(fasthttp.HeaderAccept, accept)
+	handler.EstimateSwapAmount(ctx)
+	return ctx
+}
+
+func decodeEstimateResponse(t *testing.T, ctx *fasthttp.RequestCtx) http.EstimateResponse {
+	t.Helper()
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	if got := string(ctx.Response.Header.ContentType()); got != "application/json" {
+		t.Fatalf("Expected a JSON response, got %q", got)
+	}
+	var resp http.EstimateResponse
+	if err := json.Unmarshal(ctx.Response.Body(), &resp); err != nil {
+		t.Fatalf("invalid JSON response: %v", err)
+	}
+	return resp
+}
+
+func TestEstimateHandler_JSONNegotiation(t *testing.T) {
+	handler := createEstimateHandler(&mockEstimateService{estimateAmount: big.NewInt(996)})
+	uri := "/estimate?pool=" + testPool + "&src=" + testToken0.Hex() + "&dst=" + testToken1.Hex() + "&src_amount=1000"
+
+	for _, accept := range []string{"application/json", "text/html, Application/JSON;q=0.9"} {
+		resp := decodeEstimateResponse(t, runEstimateWithAccept(handler, uri, accept))
+		if resp.AmountOut != "996" {
+			t.Errorf("Accept %q: expected amount_out 996, got %+v", accept, resp)
+		}
+	}
+
+	if resp := decodeEstimateResponse(t, runQuery(handler.EstimateSwapAmount, uri+"&format=json")); resp.AmountOut != "996" {
+		t.Errorf("format=json: expected amount_out 996, got %+v", resp)
+	}
+
+	for _, accept := range []string{"", "text/plain", "*/*"} {
+		ctx := runEstimateWithAccept(handler, uri, accept)
+		if got := string(ctx.Response.Header.ContentType()); got != "text/plain" || string(ctx.Response.Body()) != "996" {
+			t.Errorf("Accept %q: expected the text/plain default, got %q %q", accept, got, ctx.Response.Body())
+		}
+		if got := string(ctx.Response.Header.Peek(fasthttp.HeaderVary)); got != fasthttp.HeaderAccept {
+			t.Errorf("Accept %q: expected Vary: Accept, got %q", accept, got)
+		}
+	}
+}
+
+func TestEstimateHandler_JSONAmountShapes(t *testing.T) {
+	handler := createEstimateHandler(&mockEstimateService{estimateAmount: big.NewInt(996)})
+	base := "/estimate?pool=" + testPool + "&src=" + testToken0.Hex() + "&dst=" + testToken1.Hex() + "&format=json"
+
+	exactOut := decodeEstimateResponse(t, runQuery(handler.EstimateSwapAmount, base+"&dst_amount=996"))
+	if exactOut.AmountIn != "996" || exactOut.AmountOut != "" {
+		t.Errorf("Expected amount_in for an exact-out quote, got %+v", exactOut)
+	}
+
+	list := decodeEstimateResponse(t, runQuery(handler.EstimateSwapAmount, base+"&src_amount=1000&src_amount=2000"))
+	if len(list.AmountsOut) != 2 || list.AmountsOut[1] != "996" || list.AmountOut != "" {
+		t.Errorf("Expected amounts_out for several amounts, got %+v", list)
+	}
+}
+
+func TestEstimateHandler_JSONErrorsKeepEnvelope(t *testing.T) {
+	handler := createEstimateHandler(&mockEstimateService{estimateAmount: big.NewInt(996)})
+
+	ctx := runEstimateWithAccept(handler, "/estimate?pool="+testPool+"&src="+testToken0.Hex()+"&dst="+testToken1.Hex(), "application/json")
+	if ctx.Response.StatusCode() != fasthttp.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d", ctx.Response.StatusCode())
+	}
+	var resp map[string]http.ErrorResponse
+	if err := json.Unmarshal(ctx.Response.Body(), &resp); err != nil || resp["error"].Code != "VALIDATION_ERROR" {
+		t.Errorf("Expected the JSON error envelope, got %s", ctx.Response.Body())
+	}
+}
@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"bigswapenergy/internal/presentation/http"
+	"bigswapenergy/internal/shared/config"
+	"bigswapenergy/internal/shared/metrics"
+	"bigswapenergy/internal/shared/timing"
+	usecases "bigswapenergy/internal/usecases"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// slowReservesService spends its time in a reserves span, like a slow storage read
+type slowReservesService struct {
+	mockEstimateService
+	delay time.Duration
+}
+
+func (s *slowReservesService) EstimateSwap(ctx context.Context, req usecases.EstimateRequest) (*usecases.EstimateResult, error) {
+	end := timing.Start(ctx, timing.PhaseReserves)
+	time.Sleep(s.delay)
+	end()
+	return &usecases.EstimateResult{AmountOut: big.NewInt(996)}, nil
+}
+
+func runEstimateWithSLO(t *testing.T, service usecases.EstimateService, slo time.Duration) *observer.ObservedLogs {
+	t.Helper()
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	cfg := &config.Config{
+		Server:    config.ServerConfig{LatencySLO: slo, LatencySLOLog: true},
+		RateLimit: config.RateLimitConfig{RequestsPerMinute: 100},
+	}
+	handler := http.NewEstimateHandler(service, zap.New(core), cfg)
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	req.SetRequestURI("/estimate?pool=0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc&src=0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48&dst=0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2&src_amount=1000")
+	req.Header.SetMethod("GET")
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(req, nil, nil)
+	handler.EstimateSwapAmount(ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d", ctx.Response.StatusCode())
+	}
+	return logs
+}
+
+func TestLatencySLO_BreachTaggedByDominantPhase(t *testing.T) {
+	total := metrics.Global.Counter(http.MetricLatencySLOBreaches).Value()
+	reserves := metrics.Global.Counter(http.MetricLatencySLOBreaches + ".reserves").Value()
+
+	logs := runEstimateWithSLO(t, &slowReservesService{delay: 20 * time.Millisecond}, 5*time.Millisecond)
+
+	if got := metrics.Global.Counter(http.MetricLatencySLOBreaches).Value(); got != total+1 {
+		t.Errorf("Expected SLO breach counter to increase by 1, went from %d to %d", total, got)
+	}
+	if got := metrics.Global.Counter(http.MetricLatencySLOBreaches + ".reserves").Value(); got != reserves+1 {
+		t.Errorf("Expected the breach to be tagged with the reserves phase")
+	}
+
+	breaches := logs.FilterMessage("Latency SLO exceeded").All()
+	if len(breaches) != 1 {
+		t.Fatalf("Expected 1 SLO breach log, got %d", len(breaches))
+	}
+	if phase := breaches[0].ContextMap()["phase"]; phase != "reserves" {
+		t.Errorf("Expected dominant phase reserves, got %v", phase)
+	}
+}
+
+func TestLatencySLO_FastRequestIsNotCounted(t *testing.T) {
+	total := metrics.Global.Counter(http.MetricLatencySLOBreaches).Value()
+
+	logs := runEstimateWithSLO(t, &mockEstimateService{estimateAmount: big.NewInt(996)}, time.Minute)
+
+	if got := metrics.Global.Counter(http.MetricLatencySLOBreaches).Value(); got != total {
+		t.Errorf("Expected no SLO breach, counter went from %d to %d", total, got)
+	}
+	if n := logs.FilterMessage("Latency SLO exceeded").Len(); n != 0 {
+		t.Errorf("Expected no SLO breach log, got %d", n)
+	}
+}
@@ -0,0 +1,102 @@
+package tests
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	apperrors "bigswapenergy/internal/shared/errors"
+	usecases "bigswapenergy/internal/usecases"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestQuoteFromReserves(t *testing.T) {
+	amountOut, err := usecases.QuoteFromReserves(usecases.LocalQuoteRequest{
+		ReserveIn:  big.NewInt(1_000_000),
+		ReserveOut: big.NewInt(1_000_000),
+		SrcAmount:  big.NewInt(1000),
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if amountOut.Cmp(big.NewInt(996)) != 0 {
+		t.Errorf("Expected 996, got %s", amountOut)
+	}
+}
+
+func TestQuoteFromReserves_InvalidReserves(t *testing.T) {
+	tooLarge := new(big.Int).Add(usecases.MaxReserve, big.NewInt(1))
+	for _, reserve := range []*big.Int{nil, big.NewInt(0), big.NewInt(-1), tooLarge} {
+		_, err := usecases.QuoteFromReserves(usecases.LocalQuoteRequest{
+			ReserveIn:  reserve,
+			ReserveOut: big.NewInt(1_000_000),
+			SrcAmount:  big.NewInt(1000),
+		})
+		if !errors.Is(err, apperrors.ErrValidation) {
+			t.Errorf("reserve %v: expected validation error, got %v", reserve, err)
+		}
+	}
+}
+
+func localQuoteRequest(reserveIn, reserveOut, query string) *fasthttp.RequestCtx {
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	req.SetRequestURI("/estimate/local?" + query)
+	req.Header.SetMethod("GET")
+	if reserveIn != "" {
+		req.Header.Set("X-Reserve-In", reserveIn)
+	}
+	if reserveOut != "" {
+		req.Header.Set("X-Reserve-Out", reserveOut)
+	}
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(req, nil, nil)
+	return ctx
+}
+
+func TestQuoteFromReservesHandler(t *testing.T) {
+	mock := &mockEstimateService{}
+	handler := createEstimateHandler(mock)
+
+	ctx := localQuoteRequest("1000000", "1000000", "src_amount=1000&fee_bps=0")
+	handler.QuoteFromReserves(ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	if string(ctx.Response.Body()) != "999" {
+		t.Errorf("Expected 999, got %s", ctx.Response.Body())
+	}
+}
+
+func TestQuoteFromReservesHandler_MissingHeader(t *testing.T) {
+	handler := createEstimateHandler(&mockEstimateService{})
+
+	ctx := localQuoteRequest("1000000", "", "src_amount=1000")
+	handler.QuoteFromReserves(ctx)
+
+	if ctx.Response.StatusCode() == fasthttp.StatusOK {
+		t.Errorf("Expected an error status without X-Reserve-Out")
+	}
+}
+
+func BenchmarkQuoteFromReservesHandler(b *testing.B) {
+	handler := createEstimateHandler(&mockEstimateService{})
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	req.SetRequestURI("/estimate/local?src_amount=1000000")
+	req.Header.SetMethod("GET")
+	req.Header.Set("X-Reserve-In", "52034529817163")
+	req.Header.Set("X-Reserve-Out", "13820464373468277341519")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Init(req, nil, nil)
+		handler.QuoteFromReserves(ctx)
+	}
+}
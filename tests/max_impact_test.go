@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	apperrors "bigswapenergy/internal/shared/errors"
+	usecases "bigswapenergy/internal/usecases"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestEstimateMaxForImpact_ClosedForm(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(2_000_000))
+	service := createEstimateService(client)
+
+	result, err := service.EstimateMaxForImpact(context.Background(), usecases.MaxImpactRequest{
+		PoolAddress:  testPool,
+		SrcToken:     testToken0.Hex(),
+		DstToken:     testToken1.Hex(),
+		MaxImpactBps: 100,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// 1e6 * 100 * 1000 / (9900 * 997), rounded down
+	if result.AmountIn.Cmp(big.NewInt(10131)) != 0 {
+		t.Errorf("Expected amount in 10131, got %s", result.AmountIn)
+	}
+	if result.AmountOut.Cmp(big.NewInt(19999)) != 0 {
+		t.Errorf("Expected amount out 19999, got %s", result.AmountOut)
+	}
+	if client.reservesCalls != 1 {
+		t.Errorf("Expected a single reserve read, got %d", client.reservesCalls)
+	}
+}
+
+func TestEstimateMaxForImpact_InvalidImpact(t *testing.T) {
+	service := createEstimateService(newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(2_000_000)))
+
+	for _, bps := range []int{0, -5, 10000} {
+		_, err := service.EstimateMaxForImpact(context.Background(), usecases.MaxImpactRequest{
+			PoolAddress:  testPool,
+			SrcToken:     testToken0.Hex(),
+			DstToken:     testToken1.Hex(),
+			MaxImpactBps: bps,
+		})
+		if !errors.Is(err, apperrors.ErrValidation) {
+			t.Errorf("bps %d: expected validation error, got %v", bps, err)
+		}
+	}
+}
+
+func TestEstimateMaxForImpact_ReservesTooSmall(t *testing.T) {
+	service := createEstimateService(newFakeUniswapV2Client(big.NewInt(10), big.NewInt(10)))
+
+	_, err := service.EstimateMaxForImpact(context.Background(), usecases.MaxImpactRequest{
+		PoolAddress:  testPool,
+		SrcToken:     testToken0.Hex(),
+		DstToken:     testToken1.Hex(),
+		MaxImpactBps: 1,
+	})
+	if !errors.Is(err, apperrors.ErrBusinessRule) {
+		t.Errorf("Expected business rule error, got %v", err)
+	}
+}
+
+func TestEstimateMaxForImpactHandler(t *testing.T) {
+	handler := createEstimateHandler(&mockEstimateService{estimateAmount: big.NewInt(42)})
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	req.SetRequestURI("/estimate/max-for-impact?pool=" + testPool + "&src=" + testToken0.Hex() + "&dst=" + testToken1.Hex() + "&max_impact_bps=50")
+	req.Header.SetMethod("GET")
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(req, nil, nil)
+	handler.EstimateMaxForImpact(ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d", ctx.Response.StatusCode())
+	}
+	var body map[string]any
+	if err := json.Unmarshal(ctx.Response.Body(), &body); err != nil {
+		t.Fatalf("Expected JSON body, got %q", ctx.Response.Body())
+	}
+	if body["amount_in"] != "42" || body["amount_out"] != "42" {
+		t.Errorf("Unexpected response body: %s", ctx.Response.Body())
+	}
+}
+
+func TestEstimateMaxForImpactHandler_InvalidBps(t *testing.T) {
+	mock := &mockEstimateService{estimateAmount: big.NewInt(42)}
+	handler := createEstimateHandler(mock)
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	req.SetRequestURI("/estimate/max-for-impact?pool=" + testPool + "&src=" + testToken0.Hex() + "&dst=" + testToken1.Hex() + "&max_impact_bps=abc")
+	req.Header.SetMethod("GET")
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(req, nil, nil)
+	handler.EstimateMaxForImpact(ctx)
+
+	if ctx.Response.StatusCode() == fasthttp.StatusOK {
+		t.Errorf("Expected an error status, got 200")
+	}
+}
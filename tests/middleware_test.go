@@ -0,0 +1,293 @@
+package tests
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"bigswapenergy/internal/presentation/http"
+	"bigswapenergy/internal/shared/clock"
+	"bigswapenergy/internal/shared/config"
+	"bigswapenergy/internal/shared/metrics"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+func okHandler(ctx *fasthttp.RequestCtx) {
+	ctx.SetStatusCode(fasthttp.StatusOK)
+}
+
+// doRequest sends a request from peer clientIP, or an unset peer when empty
+func doRequest(handler fasthttp.RequestHandler, clientIP string) int {
+	return doForwardedRequest(handler, clientIP, "")
+}
+
+// doForwardedRequest sends a request from peer with forwardedFor, when set, as
+// its X-Forwarded-For header
+func doForwardedRequest(handler fasthttp.RequestHandler, peer, forwardedFor string) int {
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+
+	req.SetRequestURI("/estimate")
+	req.Header.SetMethod("GET")
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+
+	var remoteAddr net.Addr
+	if peer != "" {
+		remoteAddr = &net.TCPAddr{IP: net.ParseIP(peer)}
+	}
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(req, remoteAddr, nil)
+	handler(ctx)
+	return ctx.Response.StatusCode()
+}
+
+func TestRateLimitMiddleware_RefillsSteadily(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Unix(1_700_000_000, 0))
+	middleware := http.NewRateLimitMiddleware(http.HTTPRateLimitConfig{RequestsPerMinute: 2}, zap.NewNop(), fakeClock)
+	handler := middleware.Apply(okHandler)
+
+	for i := 0; i < 2; i++ {
+		if status := doRequest(handler, "10.0.0.1"); status != fasthttp.StatusOK {
+			t.Fatalf("request %d: expected status %d, got %d", i, fasthttp.StatusOK, status)
+		}
+	}
+
+	if status := doRequest(handler, "10.0.0.1"); status != fasthttp.StatusTooManyRequests {
+		t.Fatalf("Expected status %d once the burst is spent, got %d", fasthttp.StatusTooManyRequests, status)
+	}
+
+	// Two requests per minute refill one token every 30 seconds
+	fakeClock.Advance(29 * time.Second)
+	if status := doRequest(handler, "10.0.0.1"); status != fasthttp.StatusTooManyRequests {
+		t.Fatalf("Expected no token before 30 seconds, got %d", status)
+	}
+
+	fakeClock.Advance(time.Second)
+	if status := doRequest(handler, "10.0.0.1"); status != fasthttp.StatusOK {
+		t.Fatalf("Expected one token after 30 seconds, got %d", status)
+	}
+	if status := doRequest(handler, "10.0.0.1"); status != fasthttp.StatusTooManyRequests {
+		t.Fatalf("Expected only one token refilled, got %d", status)
+	}
+}
+
+func TestRateLimitMiddleware_BurstBoundedAcrossMinute(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Unix(1_700_000_000, 0))
+	middleware := http.NewRateLimitMiddleware(http.HTTPRateLimitConfig{RequestsPerMinute: 60, Burst: 10}, zap.NewNop(), fakeClock)
+	handler := middleware.Apply(okHandler)
+
+	accepted := func() int {
+		n := 0
+		for i := 0; i < 100; i++ {
+			if doRequest(handler, "10.0.0.1") == fasthttp.StatusOK {
+				n++
+			}
+		}
+		return n
+	}
+
+	doRequest(handler, "10.0.0.1")
+
+	// Either side of where a fixed one-minute window would reset
+	fakeClock.Advance(59 * time.Second)
+	before := accepted()
+	fakeClock.Advance(2 * time.Second)
+	after := accepted()
+
+	// A full burst, then only the two seconds' refill at one request per second
+	if before != 10 || after != 2 {
+		t.Errorf("Expected 10 then 2 requests accepted, got %d then %d", before, after)
+	}
+}
+
+func TestRateLimitMiddleware_PerClient(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Unix(1_700_000_000, 0))
+	middleware := http.NewRateLimitMiddleware(http.HTTPRateLimitConfig{RequestsPerMinute: 1}, zap.NewNop(), fakeClock)
+	handler := middleware.Apply(okHandler)
+
+	if status := doRequest(handler, "10.0.0.1"); status != fasthttp.StatusOK {
+		t.Fatalf("Expected status %d, got %d", fasthttp.StatusOK, status)
+	}
+	if status := doRequest(handler, "10.0.0.2"); status != fasthttp.StatusOK {
+		t.Fatalf("Expected separate budget for a different client, got %d", status)
+	}
+	if status := doRequest(handler, "10.0.0.1"); status != fasthttp.StatusTooManyRequests {
+		t.Fatalf("Expected status %d, got %d", fasthttp.StatusTooManyRequests, status)
+	}
+}
+
+func TestRateLimitMiddleware_MaxClientsBounded(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Unix(1_700_000_000, 0))
+	middleware := http.NewRateLimitMiddleware(http.HTTPRateLimitConfig{RequestsPerMinute: 1, MaxClients: 10}, zap.NewNop(), fakeClock)
+	handler := middleware.Apply(okHandler)
+
+	for i := 0; i < 100; i++ {
+		fakeClock.Advance(time.Millisecond)
+		doRequest(handler, fmt.Sprintf("10.0.%d.%d", i/256, i%256))
+		if size := middleware.Size(); size > 10 {
+			t.Fatalf("request %d: expected at most 10 tracked clients, got %d", i, size)
+		}
+	}
+
+	if got := metrics.Global.Snapshot()[http.MetricRateLimitClients]; got != int64(middleware.Size()) {
+		t.Errorf("Expected %s to report %d, got %d", http.MetricRateLimitClients, middleware.Size(), got)
+	}
+}
+
+func TestRateLimitMiddleware_EvictsLeastRecentlySeen(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Unix(1_700_000_000, 0))
+	middleware := http.NewRateLimitMiddleware(http.HTTPRateLimitConfig{RequestsPerMinute: 1, MaxClients: 3}, zap.NewNop(), fakeClock)
+	handler := middleware.Apply(okHandler)
+
+	doRequest(handler, "10.0.0.1")
+	fakeClock.Advance(time.Second)
+	doRequest(handler, "10.0.0.2")
+	fakeClock.Advance(time.Second)
+	doRequest(handler, "10.0.0.3")
+	fakeClock.Advance(time.Second)
+
+	// A fourth client pushes past the cap, evicting the oldest
+	doRequest(handler, "10.0.0.4")
+
+	// 10.0.0.3 is still tracked and stays limited; 10.0.0.1 was forgotten
+	if status := doRequest(handler, "10.0.0.3"); status != fasthttp.StatusTooManyRequests {
+		t.Errorf("Expected the most recent client to keep its state, got %d", status)
+	}
+	if status := doRequest(handler, "10.0.0.1"); status != fasthttp.StatusOK {
+		t.Errorf("Expected the oldest client to have been evicted, got %d", status)
+	}
+}
+
+func TestRateLimitMiddleware_JanitorSweepsIdleClients(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Unix(1_700_000_000, 0))
+	middleware := http.NewRateLimitMiddleware(http.HTTPRateLimitConfig{RequestsPerMinute: 1}, zap.NewNop(), fakeClock)
+	handler := middleware.Apply(okHandler)
+
+	doRequest(handler, "10.0.0.1")
+	doRequest(handler, "10.0.0.2")
+
+	middleware.StartJanitor(time.Millisecond)
+	defer middleware.Stop()
+
+	fakeClock.Advance(61 * time.Second)
+	doRequest(handler, "10.0.0.3")
+
+	deadline := time.Now().Add(time.Second)
+	for middleware.Size() != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected only the active client to remain, got %d", middleware.Size())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestLoadConfig_RateLimitCleanupInterval(t *testing.T) {
+	t.Setenv("ETHEREUM_RPC_URL", "http://localhost:8545")
+
+	cfg, err := config.LoadConfig("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.RateLimit.CleanupInterval != time.Minute {
+		t.Errorf("Expected a one minute cleanup interval by default, got %v", cfg.RateLimit.CleanupInterval)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("rate_limit:\n  cleanup_interval: \"0s\"\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if _, err := config.LoadConfig(path); err == nil {
+		t.Error("Expected an error for a zero cleanup interval")
+	}
+}
+
+func TestLoadConfig_RateLimitBurst(t *testing.T) {
+	t.Setenv("ETHEREUM_RPC_URL", "http://localhost:8545")
+
+	cfg, err := config.LoadConfig("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.RateLimit.Burst != 20 {
+		t.Errorf("Expected a burst of 20 by default, got %d", cfg.RateLimit.Burst)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("rate_limit:\n  burst: -1\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if _, err := config.LoadConfig(path); err == nil {
+		t.Error("Expected an error for a negative burst")
+	}
+}
+
+func TestRateLimitMiddleware_IgnoresForwardedForFromUntrustedPeer(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Unix(1_700_000_000, 0))
+	middleware := http.NewRateLimitMiddleware(http.HTTPRateLimitConfig{
+		RequestsPerMinute: 1,
+		TrustedProxies:    []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")},
+	}, zap.NewNop(), fakeClock)
+	handler := middleware.Apply(okHandler)
+
+	if status := doForwardedRequest(handler, "203.0.113.7", "198.51.100.1"); status != fasthttp.StatusOK {
+		t.Fatalf("Expected status %d, got %d", fasthttp.StatusOK, status)
+	}
+	// A fresh spoofed address from the same peer is still the same client
+	if status := doForwardedRequest(handler, "203.0.113.7", "198.51.100.2"); status != fasthttp.StatusTooManyRequests {
+		t.Errorf("Expected a spoofed header not to reset the limit, got %d", status)
+	}
+}
+
+func TestRateLimitMiddleware_HonorsForwardedForFromTrustedProxy(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Unix(1_700_000_000, 0))
+	middleware := http.NewRateLimitMiddleware(http.HTTPRateLimitConfig{
+		RequestsPerMinute: 1,
+		TrustedProxies:    []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")},
+	}, zap.NewNop(), fakeClock)
+	handler := middleware.Apply(okHandler)
+
+	// Clients behind the proxy are limited separately
+	if status := doForwardedRequest(handler, "10.0.0.1", "198.51.100.1"); status != fasthttp.StatusOK {
+		t.Fatalf("Expected status %d, got %d", fasthttp.StatusOK, status)
+	}
+	if status := doForwardedRequest(handler, "10.0.0.1", "198.51.100.2"); status != fasthttp.StatusOK {
+		t.Fatalf("Expected a separate budget for another forwarded client, got %d", status)
+	}
+
+	// Hops left of the first untrusted one are the client's own and ignored, while
+	// further trusted proxies are skipped
+	if status := doForwardedRequest(handler, "10.0.0.1", "192.0.2.9, 198.51.100.1, 10.0.0.2"); status != fasthttp.StatusTooManyRequests {
+		t.Errorf("Expected the forged leftmost hop to be ignored, got %d", status)
+	}
+}
+
+func TestLoadConfig_RateLimitTrustedProxies(t *testing.T) {
+	t.Setenv("ETHEREUM_RPC_URL", "http://localhost:8545")
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("rate_limit:\n  trusted_proxies: [\"10.0.0.0/8\", \"fd00::/8\"]\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.RateLimit.TrustedProxies) != 2 || !cfg.RateLimit.TrustedProxies[0].Contains(netip.MustParseAddr("10.1.2.3")) {
+		t.Errorf("Expected both ranges parsed, got %v", cfg.RateLimit.TrustedProxies)
+	}
+
+	if err := os.WriteFile(path, []byte("rate_limit:\n  trusted_proxies: [\"10.0.0.1\"]\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if _, err := config.LoadConfig(path); err == nil {
+		t.Error("Expected an error for an address without a prefix length")
+	}
+}
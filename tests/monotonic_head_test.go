@@ -0,0 +1,60 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"bigswapenergy/internal/infrastructure/ethereum"
+	"bigswapenergy/internal/shared/clock"
+)
+
+// sequenceHeadClient reports the next head in heads on each call
+type sequenceHeadClient struct {
+	fakeEthereumClient
+	heads []uint64
+}
+
+func (s *sequenceHeadClient) GetLatestBlockNumber(ctx context.Context) (uint64, error) {
+	head := s.heads[0]
+	s.heads = s.heads[1:]
+	return head, nil
+}
+
+func TestMonotonicHead_NeverDecreasesWithinWindow(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Unix(1_700_000_000, 0))
+	inner := &sequenceHeadClient{heads: []uint64{100, 102, 101, 99, 102, 103, 101}}
+	client := ethereum.NewMonotonicHeadEthereumClient(inner, 10*time.Second, fakeClock)
+
+	want := []uint64{100, 102, 102, 102, 102, 103, 103}
+	for i, expected := range want {
+		head, err := client.GetLatestBlockNumber(context.Background())
+		if err != nil {
+			t.Fatalf("call %d: unexpected error: %v", i, err)
+		}
+		if head != expected {
+			t.Errorf("call %d: expected head %d, got %d", i, expected, head)
+		}
+		fakeClock.Advance(time.Second)
+	}
+}
+
+func TestMonotonicHead_AcceptsLowerHeadAfterWindow(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Unix(1_700_000_000, 0))
+	inner := &sequenceHeadClient{heads: []uint64{105, 100, 100, 101}}
+	client := ethereum.NewMonotonicHeadEthereumClient(inner, 10*time.Second, fakeClock)
+
+	client.GetLatestBlockNumber(context.Background())
+	fakeClock.Advance(5 * time.Second)
+	if head, _ := client.GetLatestBlockNumber(context.Background()); head != 105 {
+		t.Fatalf("Expected 105 within the window, got %d", head)
+	}
+
+	fakeClock.Advance(6 * time.Second)
+	if head, _ := client.GetLatestBlockNumber(context.Background()); head != 100 {
+		t.Fatalf("Expected the lower head once 105 went unseen for the window, got %d", head)
+	}
+	if head, _ := client.GetLatestBlockNumber(context.Background()); head != 101 {
+		t.Errorf("Expected tracking to resume from the accepted head, got %d", head)
+	}
+}
@@ -0,0 +1,166 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"bigswapenergy/internal/infrastructure/uniswap_v2"
+	apperrors "bigswapenergy/internal/shared/errors"
+	"bigswapenergy/internal/shared/utils"
+	usecases "bigswapenergy/internal/usecases"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/valyala/fasthttp"
+)
+
+func poolCheckRequest(src, dst common.Address) usecases.PoolCheckRequest {
+	return usecases.PoolCheckRequest{PoolAddress: testPool, SrcToken: src.Hex(), DstToken: dst.Hex()}
+}
+
+func TestCheckPool_Supported(t *testing.T) {
+	service := createEstimateService(newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(2_000_000)))
+
+	// token1 -> token0 reports the reserves oriented for the swap
+	check, err := service.CheckPool(context.Background(), poolCheckRequest(testToken1, testToken0))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !check.Supported || check.Reason != "" {
+		t.Fatalf("Expected a supported pool, got %+v", check)
+	}
+	if check.ReserveIn.Cmp(big.NewInt(2_000_000)) != 0 || check.ReserveOut.Cmp(big.NewInt(1_000_000)) != 0 {
+		t.Errorf("Expected reserves oriented 2000000 -> 1000000, got %s -> %s", check.ReserveIn, check.ReserveOut)
+	}
+}
+
+func TestCheckPool_UnsupportedReasons(t *testing.T) {
+	otherToken := common.HexToAddress("0xdAC17F958D2ee523a2206206994597C13D831ec7")
+	tests := []struct {
+		name  string
+		setup func(*fakeUniswapV2Client)
+		dst   common.Address
+		want  usecases.CheckReason
+	}{
+		{
+			name: "pool_not_found",
+			setup: func(f *fakeUniswapV2Client) {
+				f.tokensErr = fmt.Errorf("%w for pool %s", uniswap_v2.ErrPoolNotFound, testPool)
+			},
+			dst:  testToken1,
+			want: usecases.CheckPoolNotFound,
+		},
+		{
+			name: "token_pair_mismatch",
+			dst:  otherToken,
+			want: usecases.CheckTokenPairMismatch,
+		},
+		{
+			name: "pool_not_initialized",
+			setup: func(f *fakeUniswapV2Client) {
+				f.reservesErr = fmt.Errorf("%w: %w", uniswap_v2.ErrInsufficientLiquidity, uniswap_v2.ErrPoolNotInitialized)
+			},
+			dst:  testToken1,
+			want: usecases.CheckPoolNotInitialized,
+		},
+		{
+			name: "pool_drained",
+			setup: func(f *fakeUniswapV2Client) {
+				f.reservesErr = fmt.Errorf("%w: %w", uniswap_v2.ErrInsufficientLiquidity, uniswap_v2.ErrPoolDrained)
+			},
+			dst:  testToken1,
+			want: usecases.CheckPoolDrained,
+		},
+		{
+			name: "invalid_reserves",
+			setup: func(f *fakeUniswapV2Client) {
+				f.reservesErr = fmt.Errorf("failed to parse reserves: %w", utils.ErrInvalidStorageWord)
+			},
+			dst:  testToken1,
+			want: usecases.CheckInvalidReserves,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(2_000_000))
+			if tc.setup != nil {
+				tc.setup(client)
+			}
+			service := createEstimateService(client)
+
+			check, err := service.CheckPool(context.Background(), poolCheckRequest(testToken0, tc.dst))
+			if err != nil {
+				t.Fatalf("Expected a non-fatal reason, got error %v", err)
+			}
+			if check.Supported || check.Reason != tc.want {
+				t.Errorf("Expected unsupported with reason %s, got %+v", tc.want, check)
+			}
+			if check.Detail == "" || check.ReserveIn != nil || check.ReserveOut != nil {
+				t.Errorf("Expected a detail and no reserves, got %+v", check)
+			}
+		})
+	}
+}
+
+func TestCheckPool_RPCFailureIsAnError(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(2_000_000))
+	client.reservesErr = errors.New("connection refused")
+	service := createEstimateService(client)
+
+	_, err := service.CheckPool(context.Background(), poolCheckRequest(testToken0, testToken1))
+	if !errors.Is(err, apperrors.ErrExternalService) {
+		t.Errorf("Expected external service error, got %v", err)
+	}
+}
+
+func TestCheckPoolHandler(t *testing.T) {
+	tests := []struct {
+		name  string
+		check *usecases.PoolCheck
+		want  map[string]any
+	}{
+		{
+			name:  "supported",
+			check: &usecases.PoolCheck{Supported: true, ReserveIn: big.NewInt(10), ReserveOut: big.NewInt(20), BlockNumber: 7},
+			want:  map[string]any{"supported": true, "reserve_in": "10", "reserve_out": "20", "block_number": float64(7)},
+		},
+		{
+			name:  "unsupported",
+			check: &usecases.PoolCheck{Reason: usecases.CheckPoolDrained, Detail: "drained", BlockNumber: 7},
+			want:  map[string]any{"supported": false, "reason": "pool_drained", "detail": "drained", "block_number": float64(7)},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := createEstimateHandler(&mockEstimateService{poolCheck: tc.check})
+
+			req := fasthttp.AcquireRequest()
+			defer fasthttp.ReleaseRequest(req)
+			req.SetRequestURI("/estimate/check?pool=" + testPool + "&src=" + testToken0.Hex() + "&dst=" + testToken1.Hex())
+			req.Header.SetMethod("GET")
+
+			ctx := &fasthttp.RequestCtx{}
+			ctx.Init(req, nil, nil)
+			handler.CheckPool(ctx)
+
+			if ctx.Response.StatusCode() != fasthttp.StatusOK {
+				t.Fatalf("Expected status 200, got %d", ctx.Response.StatusCode())
+			}
+			var body map[string]any
+			if err := json.Unmarshal(ctx.Response.Body(), &body); err != nil {
+				t.Fatalf("Expected JSON body, got %q", ctx.Response.Body())
+			}
+			if len(body) != len(tc.want) {
+				t.Errorf("Expected fields %v, got %v", tc.want, body)
+			}
+			for key, want := range tc.want {
+				if body[key] != want {
+					t.Errorf("Expected %s=%v, got %v", key, want, body[key])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"bigswapenergy/internal/infrastructure/uniswap_v2"
+	"bigswapenergy/internal/shared/clock"
+	"bigswapenergy/internal/shared/config"
+	usecases "bigswapenergy/internal/usecases"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"go.uber.org/zap"
+)
+
+// feeForkRegistry registers a fork whose pairs expose swapFee() in per-100000 units
+func feeForkRegistry(t *testing.T) *uniswap_v2.FactoryRegistry {
+	t.Helper()
+	registry, err := uniswap_v2.NewFactoryRegistry(map[string]config.FactoryConfig{
+		"feefork": {
+			Address:              "0x1111111111111111111111111111111111111111",
+			InitCodeHash:         "0x2222222222222222222222222222222222222222222222222222222222222222",
+			FeeBasisPoints:       30,
+			FeeMethod:            "swapFee()",
+			FeeMethodDenominator: 100_000,
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to build factory registry: %v", err)
+	}
+	return registry
+}
+
+func feeForkRequest() usecases.EstimateRequest {
+	return usecases.EstimateRequest{
+		Factory:   "feefork",
+		SrcToken:  testToken0.Hex(),
+		DstToken:  testToken1.Hex(),
+		SrcAmount: big.NewInt(10_000),
+	}
+}
+
+func TestOnChainFee_UsedInQuote(t *testing.T) {
+	registry := feeForkRegistry(t)
+	factory, _ := registry.Get("feefork")
+	pool := factory.PairFor(testToken0, testToken1)
+
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	// 1000/100000 = 1%, i.e. 100 bps instead of the configured 30
+	client.poolFees = map[common.Address]*big.Int{pool: big.NewInt(1000)}
+	service := usecases.NewEstimateService(client, registry, zap.NewNop())
+
+	result, err := service.EstimateSwap(context.Background(), feeForkRequest())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 10000*9900*1e6 / (1e6*10000 + 10000*9900) = 9802
+	if result.AmountOut.Cmp(big.NewInt(9_802)) != 0 {
+		t.Fatalf("Expected the on-chain 1%% fee to give 9802, got %s", result.AmountOut)
+	}
+	if client.feeCalls != 1 {
+		t.Errorf("Expected one fee read, got %d", client.feeCalls)
+	}
+}
+
+func TestOnChainFee_FallsBackWithoutMethod(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	service := usecases.NewEstimateService(client, feeForkRegistry(t), zap.NewNop())
+
+	result, err := service.EstimateSwap(context.Background(), feeForkRequest())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The configured 0.3%: 10000*9970*1e6 / (1e6*10000 + 10000*9970) = 9871
+	if result.AmountOut.Cmp(big.NewInt(9_871)) != 0 {
+		t.Fatalf("Expected the configured fee to give 9871, got %s", result.AmountOut)
+	}
+}
+
+func TestOnChainFee_UnrepresentableFallsBack(t *testing.T) {
+	registry := feeForkRegistry(t)
+	factory, _ := registry.Get("feefork")
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	// 25/100000 = 2.5 bps, which the basis-point fee can't carry
+	client.poolFees = map[common.Address]*big.Int{factory.PairFor(testToken0, testToken1): big.NewInt(25)}
+	service := usecases.NewEstimateService(client, registry, zap.NewNop())
+
+	result, err := service.EstimateSwap(context.Background(), feeForkRequest())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.AmountOut.Cmp(big.NewInt(9_871)) != 0 {
+		t.Fatalf("Expected the configured fee to give 9871, got %s", result.AmountOut)
+	}
+}
+
+func TestOnChainFee_NotReadWithoutFeeMethod(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	service := usecases.NewEstimateService(client, defaultFactoryRegistry(t), zap.NewNop())
+
+	req := feeForkRequest()
+	req.Factory = "uniswap"
+	if _, err := service.EstimateSwap(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if client.feeCalls != 0 {
+		t.Errorf("Expected no fee read for a factory without fee_method, got %d", client.feeCalls)
+	}
+}
+
+func TestLoadPoolFee_CallsSelector(t *testing.T) {
+	pool := common.HexToAddress(testPool)
+	client := uniswap_v2.NewUniswapV2Client(&fakeEthereumClient{calls: map[common.Address][]byte{
+		pool: common.LeftPadBytes([]byte{30}, 32),
+	}}, zap.NewNop())
+
+	fee, err := client.LoadPoolFee(context.Background(), pool, crypto.Keccak256([]byte("swapFee()"))[:4])
+	if err != nil || fee.Cmp(big.NewInt(30)) != 0 {
+		t.Fatalf("Expected 30, got %v, %v", fee, err)
+	}
+
+	other := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	if _, err := client.LoadPoolFee(context.Background(), other, []byte{1, 2, 3, 4}); !errors.Is(err, uniswap_v2.ErrNoFeeMethod) {
+		t.Fatalf("Expected ErrNoFeeMethod for a reverting call, got %v", err)
+	}
+}
+
+func TestCachedUniswapV2Client_CachesPoolFee(t *testing.T) {
+	pool := common.HexToAddress(testPool)
+	fake := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	fake.poolFees = map[common.Address]*big.Int{pool: big.NewInt(30)}
+	fakeClock := clock.NewFakeClock(time.Unix(1_700_000_000, 0))
+	cached := uniswap_v2.NewCachedUniswapV2Client(fake, time.Hour, fakeClock, zap.NewNop())
+
+	for i := 0; i < 3; i++ {
+		if fee, err := cached.LoadPoolFee(context.Background(), pool, nil); err != nil || fee.Int64() != 30 {
+			t.Fatalf("got %v, %v", fee, err)
+		}
+	}
+	if fake.feeCalls != 1 {
+		t.Fatalf("Expected a single fee read within the TTL, got %d", fake.feeCalls)
+	}
+
+	fakeClock.Advance(61 * time.Minute)
+	cached.LoadPoolFee(context.Background(), pool, nil)
+	if fake.feeCalls != 2 {
+		t.Fatalf("Expected the entry to expire after the TTL, got %d reads", fake.feeCalls)
+	}
+}
+
+func TestFactoryRegistry_InvalidFeeMethod(t *testing.T) {
+	for _, fc := range []config.FactoryConfig{
+		{FeeMethod: "swapFee", FeeMethodDenominator: 1000},
+		{FeeMethod: "swapFee(uint256)", FeeMethodDenominator: 1000},
+		{FeeMethod: "swapFee()"},
+	} {
+		fc.Address = "0x1111111111111111111111111111111111111111"
+		fc.InitCodeHash = "0x2222222222222222222222222222222222222222222222222222222222222222"
+		if _, err := uniswap_v2.NewFactoryRegistry(map[string]config.FactoryConfig{"bad": fc}); !errors.Is(err, uniswap_v2.ErrInvalidFactory) {
+			t.Errorf("%q/%d: expected ErrInvalidFactory, got %v", fc.FeeMethod, fc.FeeMethodDenominator, err)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	nethttp "net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"bigswapenergy/internal/infrastructure/ethereum"
+	"bigswapenergy/internal/infrastructure/uniswap_v2"
+	"bigswapenergy/internal/shared/clock"
+	apperrors "bigswapenergy/internal/shared/errors"
+	"bigswapenergy/internal/shared/rpcbudget"
+	usecases "bigswapenergy/internal/usecases"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"go.uber.org/zap"
+)
+
+// pairStorage lays out a standard pair's token and reserves slots
+func pairStorage(reserve0, reserve1 int64) map[common.Hash][]byte {
+	return map[common.Hash][]byte{
+		slotKey(uniswap_v2.UniswapV2Token0StorageSlot):   common.LeftPadBytes(testToken0.Bytes(), 32),
+		slotKey(uniswap_v2.UniswapV2Token1StorageSlot):   common.LeftPadBytes(testToken1.Bytes(), 32),
+		slotKey(uniswap_v2.UniswapV2ReservesStorageSlot): reservesWord(reserve0, reserve1),
+	}
+}
+
+func loadPoolState(t *testing.T, client uniswap_v2.UniswapV2Client) (*uniswap_v2.PoolState, error) {
+	t.Helper()
+	loader, ok := client.(uniswap_v2.PoolStateLoader)
+	if !ok {
+		t.Fatalf("Expected %T to load pool state", client)
+	}
+	return loader.LoadPoolState(context.Background(), common.HexToAddress(testPool), big.NewInt(100))
+}
+
+func TestLoadPoolState_SingleBatch(t *testing.T) {
+	eth := &countingEthereumClient{fakeEthereumClient: fakeEthereumClient{storage: pairStorage(1_000, 2_000)}}
+	state, err := loadPoolState(t, uniswap_v2.NewUniswapV2Client(eth, zap.NewNop()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state.Token0 != testToken0 || state.Token1 != testToken1 || state.Reserve0.Int64() != 1_000 || state.Reserve1.Int64() != 2_000 || state.ReadAt != 100 {
+		t.Errorf("Unexpected state: %+v", state)
+	}
+	if eth.batches != 1 || eth.reads != 0 {
+		t.Errorf("Expected one batch and no single reads, got %d batches and %d reads", eth.batches, eth.reads)
+	}
+}
+
+func TestLoadPoolState_Errors(t *testing.T) {
+	missing := pairStorage(1_000, 2_000)
+	delete(missing, slotKey(uniswap_v2.UniswapV2ReservesStorageSlot))
+	noPair := pairStorage(1_000, 2_000)
+	noPair[slotKey(uniswap_v2.UniswapV2Token1StorageSlot)] = make([]byte, 32)
+
+	for _, tc := range []struct {
+		name     string
+		storage  map[common.Hash][]byte
+		want     error
+		isTokens bool
+	}{
+		{"batch fails", missing, uniswap_v2.ErrPoolTokensUnavailable, true},
+		{"no pair", noPair, uniswap_v2.ErrPoolNotFound, true},
+		{"empty pool", pairStorage(0, 0), uniswap_v2.ErrPoolNotInitialized, false},
+		{"drained pool", pairStorage(0, 5), uniswap_v2.ErrPoolDrained, false},
+	} {
+		_, err := loadPoolState(t, uniswap_v2.NewUniswapV2Client(&fakeEthereumClient{storage: tc.storage}, zap.NewNop()))
+		if !errors.Is(err, tc.want) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, err)
+		}
+		if errors.Is(err, uniswap_v2.ErrPoolTokensUnavailable) != tc.isTokens {
+			t.Errorf("%s: unexpected token classification for %v", tc.name, err)
+		}
+	}
+}
+
+func TestEstimateService_BatchedReadKeepsErrorKinds(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		storage map[common.Hash][]byte
+		want    error
+	}{
+		{"no pair", map[common.Hash][]byte{}, apperrors.ErrNotFound},
+		{"empty pool", pairStorage(0, 0), apperrors.ErrPoolNotInitialized},
+		{"drained pool", pairStorage(0, 5), apperrors.ErrPoolDrained},
+	} {
+		eth := &fakeEthereumClient{storage: tc.storage}
+		service := usecases.NewEstimateService(uniswap_v2.NewUniswapV2Client(eth, zap.NewNop()), nil, zap.NewNop())
+		_, err := service.EstimateSwapAmount(context.Background(), testPool, testToken0.Hex(), testToken1.Hex(), big.NewInt(1_000))
+		if !errors.Is(err, tc.want) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, err)
+		}
+		if eth.batches != 1 {
+			t.Errorf("%s: expected the pool to be read in one batch, got %d", tc.name, eth.batches)
+		}
+	}
+}
+
+func TestLoadPoolState_CachedTokensSkipBatch(t *testing.T) {
+	eth := &countingEthereumClient{fakeEthereumClient: fakeEthereumClient{storage: pairStorage(1_000, 2_000)}}
+	cached := uniswap_v2.NewCachedUniswapV2Client(uniswap_v2.NewUniswapV2Client(eth, zap.NewNop()), time.Hour, clock.NewFakeClock(time.Unix(1_700_000_000, 0)), zap.NewNop())
+
+	if _, err := loadPoolState(t, cached); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	state, err := loadPoolState(t, cached)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state.Token0 != testToken0 || state.Reserve1.Int64() != 2_000 {
+		t.Errorf("Unexpected state: %+v", state)
+	}
+	if eth.batches != 1 || eth.reads != 1 {
+		t.Errorf("Expected one batch, then only the reserves read, got %d batches and %d reads", eth.batches, eth.reads)
+	}
+}
+
+func TestLoadPoolState_ReservesCacheHitReadsTokensOnly(t *testing.T) {
+	eth := &countingEthereumClient{fakeEthereumClient: fakeEthereumClient{storage: pairStorage(1_000, 2_000)}}
+	cache := uniswap_v2.NewReservesCachingUniswapV2Client(uniswap_v2.NewUniswapV2Client(eth, zap.NewNop()), time.Minute, 100, clock.NewFakeClock(time.Unix(1_700_000_000, 0)))
+
+	loadPoolState(t, cache)
+	state, err := loadPoolState(t, cache)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state.Reserve0.Int64() != 1_000 || cache.Stats().Hits != 1 {
+		t.Errorf("Expected the reserves from the cache, got %+v and %+v", state, cache.Stats())
+	}
+	if eth.batches != 1 || eth.reads != 2 {
+		t.Errorf("Expected one batch, then only the token reads, got %d batches and %d reads", eth.batches, eth.reads)
+	}
+}
+
+func TestLoadPoolState_SlotDetectionIsNotBatched(t *testing.T) {
+	client := uniswap_v2.NewSlotDetectingUniswapV2Client(uniswap_v2.NewUniswapV2Client(&fakeEthereumClient{}, zap.NewNop()), 15, zap.NewNop())
+	if _, ok := uniswap_v2.UniswapV2Client(client).(uniswap_v2.PoolStateLoader); ok {
+		t.Error("Expected slot detection to keep its own reserves read")
+	}
+}
+
+func TestBudgetedEthereumClient_BatchSpendsPerSlot(t *testing.T) {
+	budget := rpcbudget.New(2)
+	ctx := rpcbudget.WithBudget(context.Background(), budget)
+	eth := ethereum.NewBudgetedEthereumClient(&fakeEthereumClient{storage: pairStorage(1_000, 2_000)})
+
+	keys := []common.Hash{slotKey(uniswap_v2.UniswapV2Token0StorageSlot), slotKey(uniswap_v2.UniswapV2Token1StorageSlot), slotKey(uniswap_v2.UniswapV2ReservesStorageSlot)}
+	if _, err := eth.ReadContractStorageBatch(ctx, common.HexToAddress(testPool), keys, big.NewInt(1)); !errors.Is(err, rpcbudget.ErrExceeded) {
+		t.Errorf("Expected a three-slot batch to exceed a budget of two, got %v", err)
+	}
+}
+
+// jsonRPCRequest is one call of a JSON-RPC batch
+type jsonRPCRequest struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params []string        `json:"params"`
+}
+
+// newStorageRPCServer answers eth_getStorageAt batches from storage, failing slots
+// it doesn't hold, and records each batch's requests
+func newStorageRPCServer(t *testing.T, storage map[common.Hash][]byte, batches *[][]jsonRPCRequest) *httptest.Server {
+	return httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		var batch []jsonRPCRequest
+		if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+			t.Errorf("Expected a JSON-RPC batch: %v", err)
+			return
+		}
+		*batches = append(*batches, batch)
+
+		responses := make([]map[string]any, len(batch))
+		for i, req := range batch {
+			responses[i] = map[string]any{"jsonrpc": "2.0", "id": req.ID}
+			if word, ok := storage[common.HexToHash(req.Params[1])]; ok {
+				responses[i]["result"] = hexutil.Encode(word)
+			} else {
+				responses[i]["error"] = map[string]any{"code": -32000, "message": "missing trie node"}
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(responses)
+	}))
+}
+
+func TestOptimizedEthereumClient_ReadContractStorageBatch(t *testing.T) {
+	var batches [][]jsonRPCRequest
+	server := newStorageRPCServer(t, pairStorage(1_000, 2_000), &batches)
+	defer server.Close()
+
+	eth, err := ethereum.NewEthereumClient(server.URL, zap.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer eth.Close()
+
+	state, err := loadPoolState(t, uniswap_v2.NewUniswapV2Client(eth, zap.NewNop()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state.Token1 != testToken1 || state.Reserve1.Int64() != 2_000 {
+		t.Errorf("Unexpected state: %+v", state)
+	}
+	if len(batches) != 1 || len(batches[0]) != 3 {
+		t.Fatalf("Expected a single HTTP request carrying three calls, got %v", batches)
+	}
+	if call := batches[0][2]; call.Method != "eth_getStorageAt" || call.Params[2] != "0x64" {
+		t.Errorf("Expected the reserves read at block 0x64, got %+v", call)
+	}
+
+	keys := []common.Hash{slotKey(uniswap_v2.UniswapV2Token0StorageSlot), slotKey(42)}
+	if _, err := eth.ReadContractStorageBatch(context.Background(), common.HexToAddress(testPool), keys, nil); !errors.Is(err, ethereum.ErrStorageReadFailed) {
+		t.Errorf("Expected a failed slot to surface as ErrStorageReadFailed, got %v", err)
+	}
+	if call := batches[1][0]; call.Params[2] != "latest" {
+		t.Errorf("Expected a nil block to read latest, got %+v", call)
+	}
+}
@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"bigswapenergy/internal/infrastructure/uniswap_v2"
+	apperrors "bigswapenergy/internal/shared/errors"
+	usecases "bigswapenergy/internal/usecases"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/valyala/fasthttp"
+)
+
+func TestReadRawPoolStorage_Passthrough(t *testing.T) {
+	reserves := bytes.Repeat([]byte{0xab}, 32)
+	client := newFakeUniswapV2Client(big.NewInt(1), big.NewInt(1))
+	client.storage = map[uint64][]byte{
+		uniswap_v2.UniswapV2Token0StorageSlot:   common.LeftPadBytes(testToken0.Bytes(), 32),
+		uniswap_v2.UniswapV2Token1StorageSlot:   common.LeftPadBytes(testToken1.Bytes(), 32),
+		uniswap_v2.UniswapV2ReservesStorageSlot: reserves,
+	}
+	service := createEstimateService(client)
+
+	raw, err := service.ReadRawPoolStorage(context.Background(), testPool)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !bytes.Equal(raw.Reserves, reserves) {
+		t.Errorf("Expected raw reserves word, got %x", raw.Reserves)
+	}
+	if common.BytesToAddress(raw.Token1) != testToken1 {
+		t.Errorf("Expected token1 word for %s, got %x", testToken1.Hex(), raw.Token1)
+	}
+	if raw.BlockNumber != client.blockNumber {
+		t.Errorf("Expected block %d, got %d", client.blockNumber, raw.BlockNumber)
+	}
+}
+
+func TestReadRawPoolStorage_InvalidPool(t *testing.T) {
+	service := createEstimateService(newFakeUniswapV2Client(big.NewInt(1), big.NewInt(1)))
+
+	if _, err := service.ReadRawPoolStorage(context.Background(), "not-an-address"); !errors.Is(err, apperrors.ErrValidation) {
+		t.Errorf("Expected validation error, got %v", err)
+	}
+}
+
+func TestGetRawPoolStorageHandler(t *testing.T) {
+	mockService := &mockEstimateService{
+		rawStorage: &usecases.RawPoolStorage{
+			Pool:        common.HexToAddress(testPool),
+			BlockNumber: 20_000_000,
+			Token0:      []byte{0x01},
+			Token1:      []byte{0x02},
+			Reserves:    []byte{0x0a, 0x0b},
+		},
+	}
+	handler := createEstimateHandler(mockService)
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	req.SetRequestURI("/pool/raw?pool=" + testPool)
+	req.Header.SetMethod("GET")
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(req, nil, nil)
+	handler.GetRawPoolStorage(ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d", ctx.Response.StatusCode())
+	}
+	var body map[string]any
+	if err := json.Unmarshal(ctx.Response.Body(), &body); err != nil {
+		t.Fatalf("Expected JSON body, got %q", ctx.Response.Body())
+	}
+	if body["reserves"] != "0x0a0b" || body["block_number"] != float64(20_000_000) {
+		t.Errorf("Unexpected response body: %s", ctx.Response.Body())
+	}
+}
@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	apperrors "bigswapenergy/internal/shared/errors"
+	usecases "bigswapenergy/internal/usecases"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/valyala/fasthttp"
+)
+
+func TestReadPool(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(2_000_000))
+	service := createEstimateService(client)
+
+	result, err := service.ReadPool(context.Background(), usecases.PoolRequest{PoolAddress: testPool})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Token0 != testToken0 || result.Token1 != testToken1 {
+		t.Errorf("Expected %s/%s, got %s/%s", testToken0.Hex(), testToken1.Hex(), result.Token0.Hex(), result.Token1.Hex())
+	}
+	if result.Reserve0.Cmp(big.NewInt(1_000_000)) != 0 || result.Reserve1.Cmp(big.NewInt(2_000_000)) != 0 {
+		t.Errorf("Unexpected reserves %s/%s", result.Reserve0, result.Reserve1)
+	}
+	if result.BlockNumber != client.blockNumber {
+		t.Errorf("Expected a read at head %d, got %d", client.blockNumber, result.BlockNumber)
+	}
+}
+
+func TestReadPool_HistoricalBlock(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(2_000_000))
+	service := createEstimateService(client)
+
+	block := uint64(15_000_000)
+	result, err := service.ReadPool(context.Background(), usecases.PoolRequest{PoolAddress: testPool, Block: &block})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.BlockNumber != block || len(client.reservesBlocks) != 1 || client.reservesBlocks[0] != block {
+		t.Errorf("Expected reads at block %d, got result at %d and reads %v", block, result.BlockNumber, client.reservesBlocks)
+	}
+
+	beyond := client.blockNumber + 1
+	if _, err := service.ReadPool(context.Background(), usecases.PoolRequest{PoolAddress: testPool, Block: &beyond}); !errors.Is(err, apperrors.ErrValidation) {
+		t.Errorf("Expected ErrValidation for a block beyond head, got %v", err)
+	}
+}
+
+func TestReadPool_MissingPoolIsNotFound(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1), big.NewInt(1))
+	client.tokensErr = errors.New("execution reverted")
+	service := createEstimateService(client)
+
+	if _, err := service.ReadPool(context.Background(), usecases.PoolRequest{PoolAddress: testPool}); !errors.Is(err, apperrors.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestGetPoolHandler(t *testing.T) {
+	service := &mockEstimateService{poolResult: &usecases.PoolResult{
+		Pool:        common.HexToAddress(testPool),
+		BlockNumber: 15_000_000,
+		Token0:      testToken0,
+		Token1:      testToken1,
+		Reserve0:    big.NewInt(10),
+		Reserve1:    big.NewInt(20),
+	}}
+	handler := createEstimateHandler(service)
+
+	ctx := runQuery(handler.GetPool, "/pool?pool="+testPool+"&block=15000000")
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	if got := service.lastPool.Block; got == nil || *got != 15_000_000 {
+		t.Errorf("Expected block 15000000 on the request, got %v", got)
+	}
+	var body map[string]any
+	if err := json.Unmarshal(ctx.Response.Body(), &body); err != nil {
+		t.Fatalf("Expected JSON body, got %q", ctx.Response.Body())
+	}
+	if body["token0"] != testToken0.Hex() || body["token1"] != testToken1.Hex() || body["reserve0"] != "10" || body["reserve1"] != "20" {
+		t.Errorf("Unexpected response body: %s", ctx.Response.Body())
+	}
+}
+
+func TestGetPoolHandler_Errors(t *testing.T) {
+	cases := []struct {
+		name   string
+		uri    string
+		err    error
+		status int
+	}{
+		{"missing_pool", "/pool", nil, fasthttp.StatusBadRequest},
+		{"bad_block", "/pool?pool=" + testPool + "&block=abc", nil, fasthttp.StatusBadRequest},
+		{"not_found", "/pool?pool=" + testPool, apperrors.ErrNotFound, fasthttp.StatusNotFound},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := createEstimateHandler(&mockEstimateService{estimateError: tc.err})
+			ctx := runQuery(handler.GetPool, tc.uri)
+			if ctx.Response.StatusCode() != tc.status {
+				t.Errorf("Expected status %d, got %d", tc.status, ctx.Response.StatusCode())
+			}
+		})
+	}
+}
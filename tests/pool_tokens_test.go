@@ -0,0 +1,101 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"bigswapenergy/internal/infrastructure/uniswap_v2"
+	apperrors "bigswapenergy/internal/shared/errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/valyala/fasthttp"
+)
+
+func TestOrientPair(t *testing.T) {
+	other := common.HexToAddress("0x6B175474E89094C44Da98b954EedeAC495271d0F")
+
+	zeroForOne, err := uniswap_v2.OrientPair(testToken0, testToken1, testToken0, testToken1)
+	if err != nil || !zeroForOne {
+		t.Errorf("Expected token0 -> token1 to be zeroForOne, got %v %v", zeroForOne, err)
+	}
+	zeroForOne, err = uniswap_v2.OrientPair(testToken1, testToken0, testToken0, testToken1)
+	if err != nil || zeroForOne {
+		t.Errorf("Expected token1 -> token0 not to be zeroForOne, got %v %v", zeroForOne, err)
+	}
+
+	mismatches := []struct {
+		name     string
+		src, dst common.Address
+		reason   string
+	}{
+		{"src_missing", other, testToken1, "src " + other.Hex() + " is not in the pool"},
+		{"dst_missing", testToken0, other, "dst " + other.Hex() + " is not in the pool"},
+		{"both_missing", other, other, "neither src"},
+		{"same_side", testToken0, testToken0, "src and dst are both"},
+	}
+	for _, tc := range mismatches {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := uniswap_v2.OrientPair(tc.src, tc.dst, testToken0, testToken1)
+			if !errors.Is(err, uniswap_v2.ErrTokenPairMismatch) {
+				t.Fatalf("Expected ErrTokenPairMismatch, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tc.reason) || !strings.Contains(err.Error(), "token0="+testToken0.Hex()) {
+				t.Errorf("Expected message to explain the mismatch, got %q", err)
+			}
+		})
+	}
+}
+
+func TestEstimateService_TokenMismatchIsValidationError(t *testing.T) {
+	service := createEstimateService(newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000)))
+
+	other := common.HexToAddress("0x6B175474E89094C44Da98b954EedeAC495271d0F")
+	_, err := service.EstimateSwapAmount(context.Background(), testPool, other.Hex(), testToken1.Hex(), big.NewInt(1000))
+	if !errors.Is(err, apperrors.ErrValidation) || !errors.Is(err, uniswap_v2.ErrTokenPairMismatch) {
+		t.Errorf("Expected a validation error wrapping ErrTokenPairMismatch, got %v", err)
+	}
+}
+
+func TestReadPoolTokens(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1), big.NewInt(1))
+	service := createEstimateService(client)
+
+	tokens, err := service.ReadPoolTokens(context.Background(), testPool)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if tokens.Token0 != testToken0 || tokens.Token1 != testToken1 {
+		t.Errorf("Expected %s/%s, got %s/%s", testToken0.Hex(), testToken1.Hex(), tokens.Token0.Hex(), tokens.Token1.Hex())
+	}
+	if client.reservesCalls != 0 {
+		t.Errorf("Expected no reserve reads, got %d", client.reservesCalls)
+	}
+}
+
+func TestGetPoolTokensHandler(t *testing.T) {
+	handler := createEstimateHandler(&mockEstimateService{})
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	req.SetRequestURI("/pool/tokens?pool=" + testPool)
+	req.Header.SetMethod("GET")
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(req, nil, nil)
+	handler.GetPoolTokens(ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d", ctx.Response.StatusCode())
+	}
+	var body map[string]any
+	if err := json.Unmarshal(ctx.Response.Body(), &body); err != nil {
+		t.Fatalf("Expected JSON body, got %q", ctx.Response.Body())
+	}
+	if body["token0"] != testToken0.Hex() || body["token1"] != testToken1.Hex() {
+		t.Errorf("Unexpected response body: %s", ctx.Response.Body())
+	}
+}
@@ -0,0 +1,156 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"bigswapenergy/internal/presentation/http"
+	apperrors "bigswapenergy/internal/shared/errors"
+	usecases "bigswapenergy/internal/usecases"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/valyala/fasthttp"
+)
+
+func TestReadPools_DedupsRepeatedPools(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(2_000_000))
+	service := createEstimateService(client)
+
+	result, err := service.ReadPools(context.Background(), []string{testPool, testPoolB, strings.ToLower(testPool), testPool})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(result.Pools) != 2 {
+		t.Fatalf("Expected 2 distinct pools, got %d", len(result.Pools))
+	}
+	if result.Pools[0].Pool != common.HexToAddress(testPool) || result.Pools[1].Pool != common.HexToAddress(testPoolB) {
+		t.Errorf("Expected pools in order of first appearance, got %s, %s", result.Pools[0].Pool.Hex(), result.Pools[1].Pool.Hex())
+	}
+	if client.reservesCalls != 2 {
+		t.Errorf("Expected 2 reserve reads, got %d", client.reservesCalls)
+	}
+}
+
+func TestReadPools_AllReadAtOneBlock(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(2_000_000))
+	service := createEstimateService(client)
+
+	pools := make([]string, 20)
+	for i := range pools {
+		pools[i] = common.BigToAddress(big.NewInt(int64(i + 1))).Hex()
+	}
+	result, err := service.ReadPools(context.Background(), pools)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(client.reservesBlocks) != len(pools) {
+		t.Fatalf("Expected %d reserve reads, got %d", len(pools), len(client.reservesBlocks))
+	}
+	for _, block := range client.reservesBlocks {
+		if block != result.BlockNumber {
+			t.Fatalf("Expected every read at block %d, got %v", result.BlockNumber, client.reservesBlocks)
+		}
+	}
+}
+
+func TestReadPools_ErrorsAreInline(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(2_000_000))
+	client.poolReservesErr = map[common.Address]error{
+		common.HexToAddress(testPoolB): errors.New("rpc failure"),
+	}
+	service := createEstimateService(client)
+
+	result, err := service.ReadPools(context.Background(), []string{testPool, testPoolB})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Pools[0].Err != nil || result.Pools[0].Reserve1.Cmp(big.NewInt(2_000_000)) != 0 {
+		t.Errorf("Expected the healthy pool to be read, got %+v", result.Pools[0])
+	}
+	if !errors.Is(result.Pools[1].Err, apperrors.ErrExternalService) {
+		t.Errorf("Expected an inline ErrExternalService, got %v", result.Pools[1].Err)
+	}
+}
+
+func TestReadPools_RejectsInvalidAddress(t *testing.T) {
+	service := createEstimateService(newFakeUniswapV2Client(big.NewInt(1), big.NewInt(1)))
+
+	if _, err := service.ReadPools(context.Background(), []string{testPool, "0x123"}); !errors.Is(err, apperrors.ErrValidation) {
+		t.Fatalf("Expected ErrValidation, got %v", err)
+	}
+}
+
+func runReadPools(handler *http.EstimateHandler, method, body string) *fasthttp.RequestCtx {
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	req.SetRequestURI("/pools")
+	req.Header.SetMethod(method)
+	req.SetBodyString(body)
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(req, nil, nil)
+	handler.ReadPools(ctx)
+	return ctx
+}
+
+func TestReadPoolsHandler_Success(t *testing.T) {
+	mockService := &mockEstimateService{poolsResult: &usecases.PoolsResult{
+		BlockNumber: 20_000_000,
+		Pools: []usecases.PoolState{
+			{Pool: common.HexToAddress(testPool), Token0: testToken0, Token1: testToken1, Reserve0: big.NewInt(10), Reserve1: big.NewInt(20)},
+			{Pool: common.HexToAddress(testPoolB), Err: errors.New("pool not found")},
+		},
+	}}
+	handler := createEstimateHandler(mockService)
+
+	ctx := runReadPools(handler, "POST", `{"pools":["`+testPool+`","`+testPoolB+`"]}`)
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	if len(mockService.lastPools) != 2 {
+		t.Errorf("Expected 2 pools passed to the service, got %v", mockService.lastPools)
+	}
+
+	var resp http.PoolsResponse
+	if err := json.Unmarshal(ctx.Response.Body(), &resp); err != nil {
+		t.Fatalf("invalid JSON response: %v", err)
+	}
+	if resp.BlockNumber != 20_000_000 || len(resp.Pools) != 2 {
+		t.Fatalf("Unexpected response: %+v", resp)
+	}
+	if resp.Pools[0].Reserve0 != "10" || resp.Pools[0].Reserve1 != "20" || resp.Pools[0].Error != "" {
+		t.Errorf("Unexpected first pool: %+v", resp.Pools[0])
+	}
+	if resp.Pools[1].Error != "pool not found" || resp.Pools[1].Reserve0 != "" {
+		t.Errorf("Expected an inline error for the second pool, got %+v", resp.Pools[1])
+	}
+}
+
+func TestReadPoolsHandler_RequiresPost(t *testing.T) {
+	handler := createEstimateHandler(&mockEstimateService{})
+
+	ctx := runReadPools(handler, "GET", "")
+	if ctx.Response.StatusCode() != fasthttp.StatusMethodNotAllowed {
+		t.Fatalf("Expected status 405, got %d", ctx.Response.StatusCode())
+	}
+}
+
+func TestReadPoolsHandler_InvalidBody(t *testing.T) {
+	mockService := &mockEstimateService{}
+	handler := createEstimateHandler(mockService)
+
+	ctx := runReadPools(handler, "POST", `not json`)
+	if ctx.Response.StatusCode() == fasthttp.StatusOK {
+		t.Fatalf("Expected an error status, got 200")
+	}
+	if mockService.lastPools != nil {
+		t.Errorf("Expected the service not to be called")
+	}
+}
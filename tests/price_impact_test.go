@@ -0,0 +1,99 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"bigswapenergy/internal/presentation/http"
+	apperrors "bigswapenergy/internal/shared/errors"
+	"bigswapenergy/internal/shared/utils"
+	usecases "bigswapenergy/internal/usecases"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestPriceImpactBps(t *testing.T) {
+	for _, tc := range []struct {
+		name                                       string
+		amountIn, amountOut, reserveIn, reserveOut int64
+		want                                       int
+	}{
+		{"fee only", 1_000, 997, 1_000_000_000, 1_000_000_000, 30},
+		{"small trade", 1_000, 996, 1_000_000, 1_000_000, 40},
+		{"skewed mid price", 1_000, 1_992, 1_000_000, 2_000_000, 40},
+		{"large trade", 1_000_000, 332_665, 1_000_000, 1_000_000, 6673},
+		{"at mid price", 1_000, 1_000, 1_000_000, 1_000_000, 0},
+	} {
+		got := utils.PriceImpactBps(big.NewInt(tc.amountIn), big.NewInt(tc.amountOut), big.NewInt(tc.reserveIn), big.NewInt(tc.reserveOut))
+		if got != tc.want {
+			t.Errorf("%s: expected %d bps, got %d", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestEstimateService_PriceImpact(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	service := createEstimateService(client)
+	req := usecases.EstimateRequest{
+		PoolAddress: testPool,
+		SrcToken:    testToken0.Hex(),
+		DstToken:    testToken1.Hex(),
+		SrcAmount:   big.NewInt(1_000),
+	}
+
+	plain, err := service.EstimateSwap(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plain.PriceImpactBps != nil {
+		t.Errorf("Expected no price impact unless requested, got %d", *plain.PriceImpactBps)
+	}
+
+	req.PriceImpact = true
+	result, err := service.EstimateSwap(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.PriceImpactBps == nil || *result.PriceImpactBps != 40 {
+		t.Errorf("Expected a 40 bps impact for 1000 -> 996, got %v", result.PriceImpactBps)
+	}
+
+	req.SrcAmount, req.DstAmount = nil, big.NewInt(996)
+	if _, err := service.EstimateSwap(context.Background(), req); !errors.Is(err, apperrors.ErrValidation) {
+		t.Errorf("Expected price_impact with dst_amount to fail validation, got %v", err)
+	}
+}
+
+func TestEstimateHandler_PriceImpactJSON(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	handler := createEstimateHandler(createEstimateService(client))
+	base := "/estimate?pool=" + testPool + "&src=" + testToken0.Hex() + "&dst=" + testToken1.Hex() + "&src_amount=1000"
+
+	ctx := runQuery(handler.EstimateSwapAmount, base+"&price_impact=true")
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	if got := string(ctx.Response.Header.ContentType()); got != "application/json" {
+		t.Errorf("Expected a JSON response, got %q", got)
+	}
+	var resp http.EstimateResponse
+	if err := json.Unmarshal(ctx.Response.Body(), &resp); err != nil {
+		t.Fatalf("invalid JSON response: %v", err)
+	}
+	if resp.AmountOut != "996" || resp.PriceImpactBps == nil || *resp.PriceImpactBps != 40 {
+		t.Errorf("Unexpected response: %s", ctx.Response.Body())
+	}
+
+	plain := runQuery(handler.EstimateSwapAmount, base)
+	if got := string(plain.Response.Header.ContentType()); got != "text/plain" || string(plain.Response.Body()) != "996" {
+		t.Errorf("Expected the default response to stay text/plain, got %q %q", got, plain.Response.Body())
+	}
+
+	multi := runQuery(handler.EstimateSwapAmount, base+"&src_amount=2000&price_impact=true")
+	if multi.Response.StatusCode() != fasthttp.StatusBadRequest {
+		t.Errorf("Expected price_impact with several amounts to fail, got %d", multi.Response.StatusCode())
+	}
+}
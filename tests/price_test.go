@@ -0,0 +1,139 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	apperrors "bigswapenergy/internal/shared/errors"
+	usecases "bigswapenergy/internal/usecases"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestReadPoolPrice_SingleDirectionByDefault(t *testing.T) {
+	service := createEstimateService(newFakeUniswapV2Client(big.NewInt(4_000), big.NewInt(1_000)))
+
+	result, err := service.ReadPoolPrice(context.Background(), usecases.PriceRequest{PoolAddress: testPool})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Price1Per0.Cmp(big.NewRat(1, 4)) != 0 {
+		t.Errorf("Expected reserve1/reserve0 = 1/4, got %s", result.Price1Per0)
+	}
+	if result.Price0Per1 != nil {
+		t.Errorf("Expected no reciprocal without both, got %s", result.Price0Per1)
+	}
+}
+
+func TestReadPoolPrice_BothFromOneRead(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(4_000), big.NewInt(1_000))
+	service := createEstimateService(client)
+
+	result, err := service.ReadPoolPrice(context.Background(), usecases.PriceRequest{PoolAddress: testPool, Both: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if product := new(big.Rat).Mul(result.Price0Per1, result.Price1Per0); product.Cmp(big.NewRat(1, 1)) != 0 {
+		t.Errorf("Expected exact reciprocals, got product %s", product)
+	}
+	if client.reservesCalls != 1 {
+		t.Errorf("Expected a single reserves read, got %d", client.reservesCalls)
+	}
+}
+
+func TestReadPoolPrice_EmptyReserves(t *testing.T) {
+	service := createEstimateService(newFakeUniswapV2Client(big.NewInt(0), big.NewInt(1_000)))
+
+	_, err := service.ReadPoolPrice(context.Background(), usecases.PriceRequest{PoolAddress: testPool})
+	if !errors.Is(err, apperrors.ErrBusinessRule) {
+		t.Fatalf("Expected ErrBusinessRule for empty reserves, got %v", err)
+	}
+}
+
+func TestPriceHandler_BothAreReciprocalsWithinRounding(t *testing.T) {
+	handler := createEstimateHandler(createEstimateService(newFakeUniswapV2Client(big.NewInt(7), big.NewInt(3))))
+
+	ctx := runQuery(handler.GetPoolPrice, "/price?pool="+testPool+"&both=true")
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	var resp map[string]any
+	if err := json.Unmarshal(ctx.Response.Body(), &resp); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+
+	price10, ok10 := new(big.Rat).SetString(resp["price_1_per_0"].(string))
+	price01, ok01 := new(big.Rat).SetString(resp["price_0_per_1"].(string))
+	if !ok10 || !ok01 {
+		t.Fatalf("Expected decimal prices, got %v", resp)
+	}
+	if got := resp["price_1_per_0"]; got != "0.428571428571428571" {
+		t.Errorf("Expected 3/7 rounded to 18 places, got %v", got)
+	}
+	diff := new(big.Rat).Sub(new(big.Rat).Mul(price10, price01), big.NewRat(1, 1))
+	if diff.Abs(diff).Cmp(big.NewRat(1, 1_000_000_000_000_000)) > 0 {
+		t.Errorf("Expected reciprocals within rounding, product differs from 1 by %s", diff.FloatString(20))
+	}
+
+	ctx = runQuery(handler.GetPoolPrice, "/price?pool="+testPool)
+	resp = nil
+	json.Unmarshal(ctx.Response.Body(), &resp)
+	if _, ok := resp["price_0_per_1"]; ok {
+		t.Errorf("Expected a single direction without both, got %v", resp)
+	}
+}
+
+func TestReadPoolPrice_SrcDstOrientsReserves(t *testing.T) {
+	service := createEstimateService(newFakeUniswapV2Client(big.NewInt(4_000), big.NewInt(1_000)))
+
+	result, err := service.ReadPoolPrice(context.Background(), usecases.PriceRequest{PoolAddress: testPool, SrcToken: testToken1.Hex(), DstToken: testToken0.Hex()})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Price.Cmp(big.NewRat(4, 1)) != 0 {
+		t.Errorf("Expected token0 per token1 = 4, got %s", result.Price)
+	}
+	if result.Src != testToken1 || result.Dst != testToken0 {
+		t.Errorf("Expected src/dst echoed, got %s/%s", result.Src.Hex(), result.Dst.Hex())
+	}
+
+	result, err = service.ReadPoolPrice(context.Background(), usecases.PriceRequest{PoolAddress: testPool, SrcToken: testToken0.Hex(), DstToken: testToken1.Hex()})
+	if err != nil || result.Price.Cmp(big.NewRat(1, 4)) != 0 {
+		t.Errorf("Expected token1 per token0 = 1/4, got %v (%v)", result, err)
+	}
+}
+
+func TestReadPoolPrice_SrcDstErrors(t *testing.T) {
+	service := createEstimateService(newFakeUniswapV2Client(big.NewInt(4_000), big.NewInt(1_000)))
+	other := "0x6B175474E89094C44Da98b954EedeAC495271d0F"
+
+	for _, req := range []usecases.PriceRequest{
+		{PoolAddress: testPool, SrcToken: testToken0.Hex()},
+		{PoolAddress: testPool, DstToken: testToken1.Hex()},
+		{PoolAddress: testPool, SrcToken: "0x123", DstToken: testToken1.Hex()},
+		{PoolAddress: testPool, SrcToken: other, DstToken: testToken1.Hex()},
+	} {
+		if _, err := service.ReadPoolPrice(context.Background(), req); !errors.Is(err, apperrors.ErrValidation) {
+			t.Errorf("src=%q dst=%q: expected ErrValidation, got %v", req.SrcToken, req.DstToken, err)
+		}
+	}
+}
+
+func TestPriceHandler_SrcDst(t *testing.T) {
+	handler := createEstimateHandler(createEstimateService(newFakeUniswapV2Client(big.NewInt(7), big.NewInt(3))))
+
+	ctx := runQuery(handler.GetPoolPrice, "/price?pool="+testPool+"&src="+testToken1.Hex()+"&dst="+testToken0.Hex())
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	var resp map[string]any
+	if err := json.Unmarshal(ctx.Response.Body(), &resp); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if resp["price"] != "2.333333333333333333" || resp["src"] != testToken1.Hex() || resp["dst"] != testToken0.Hex() {
+		t.Errorf("Expected 7/3 of token0 per token1, got %v", resp)
+	}
+}
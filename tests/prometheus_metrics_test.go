@@ -0,0 +1,233 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"math/big"
+	"net"
+	nethttp "net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"bigswapenergy/internal/app"
+	"bigswapenergy/internal/infrastructure/ethereum"
+	"bigswapenergy/internal/presentation/http"
+	"bigswapenergy/internal/shared/clock"
+	"bigswapenergy/internal/shared/config"
+	"bigswapenergy/internal/shared/metrics"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// prometheusText scrapes exporter through the /metrics handler
+func prometheusText(t *testing.T, exporter *metrics.Exporter) string {
+	t.Helper()
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/metrics")
+	http.NewMetricsHandler(exporter).GetMetrics(ctx)
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	return string(ctx.Response.Body())
+}
+
+// counterValue reads a Prometheus counter's current value
+func counterValue(t *testing.T, counter prometheus.Counter) float64 {
+	t.Helper()
+	var metric dto.Metric
+	if err := counter.Write(&metric); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return metric.GetCounter().GetValue()
+}
+
+func expectLines(t *testing.T, text string, lines ...string) {
+	t.Helper()
+	for _, line := range lines {
+		if !strings.Contains(text, line+"\n") {
+			t.Errorf("Expected line %q in:\n%s", line, text)
+		}
+	}
+}
+
+func TestMetricsHandler_ServesExporter(t *testing.T) {
+	exporter := metrics.NewExporter()
+	exporter.HTTPRequests.WithLabelValues("200").Add(3)
+	exporter.HTTPRequests.WithLabelValues("500").Inc()
+	exporter.HTTPRequestDuration.WithLabelValues("200").Observe(0.05)
+	exporter.HTTPRequestDuration.WithLabelValues("200").Observe(5)
+	exporter.GaugeFunc("pool_outstanding", "Values outstanding.", func() float64 { return -2 })
+	// A second registration under the same name keeps the first
+	exporter.GaugeFunc("pool_outstanding", "Values outstanding.", func() float64 { return 7 })
+
+	text := prometheusText(t, exporter)
+	expectLines(t, text,
+		"# HELP http_requests_total HTTP responses by status code.",
+		"# TYPE http_requests_total counter",
+		`http_requests_total{code="200"} 3`,
+		`http_requests_total{code="500"} 1`,
+		"# TYPE http_request_duration_seconds histogram",
+		`http_request_duration_seconds_bucket{code="200",le="0.05"} 1`,
+		`http_request_duration_seconds_bucket{code="200",le="+Inf"} 2`,
+		`http_request_duration_seconds_sum{code="200"} 5.05`,
+		`http_request_duration_seconds_count{code="200"} 2`,
+		"# TYPE pool_outstanding gauge",
+		"pool_outstanding -2",
+	)
+	if strings.Count(text, "# TYPE http_requests_total") != 1 {
+		t.Errorf("Expected one TYPE line per family, got:\n%s", text)
+	}
+}
+
+func TestRequestMetricsMiddleware_CountsByStatus(t *testing.T) {
+	exporter := metrics.NewExporter()
+	handler := http.NewRequestMetricsMiddleware(exporter).Apply(func(ctx *fasthttp.RequestCtx) {
+		if string(ctx.Path()) == "/missing" {
+			ctx.SetStatusCode(fasthttp.StatusNotFound)
+		}
+	})
+	for _, path := range []string{"/ok", "/ok", "/missing"} {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI(path)
+		handler(ctx)
+	}
+
+	expectLines(t, prometheusText(t, exporter),
+		`http_requests_total{code="200"} 2`,
+		`http_requests_total{code="404"} 1`,
+		`http_request_duration_seconds_count{code="200"} 2`,
+	)
+}
+
+func TestMeteredEthereumClient_CountsCallsAndErrors(t *testing.T) {
+	exporter := metrics.NewExporter()
+	client := ethereum.NewMeteredEthereumClient(&fakeEthereumClient{}, exporter)
+	client.GetLatestBlockNumber(context.Background())
+	if _, err := client.ReadContractStorage(context.Background(), common.HexToAddress(testPool), common.Hash{}, big.NewInt(1)); err == nil {
+		t.Fatal("Expected the unset slot to fail")
+	}
+
+	text := prometheusText(t, exporter)
+	expectLines(t, text,
+		`rpc_calls_total{method="eth_blockNumber"} 1`,
+		`rpc_calls_total{method="eth_getStorageAt"} 1`,
+		`rpc_errors_total{method="eth_getStorageAt"} 1`,
+	)
+	if strings.Contains(text, `rpc_errors_total{method="eth_blockNumber"}`) {
+		t.Error("Expected no error counted for a successful call")
+	}
+}
+
+func TestRateLimitMiddleware_CountsRejections(t *testing.T) {
+	middleware := http.NewRateLimitMiddleware(http.HTTPRateLimitConfig{RequestsPerMinute: 1}, zap.NewNop(), clock.NewFakeClock(time.Unix(1_700_000_000, 0)))
+	handler := middleware.Apply(okHandler)
+
+	before := counterValue(t, metrics.GlobalExporter.RateLimitRejections)
+	doRequest(handler, "10.0.0.9")
+	doRequest(handler, "10.0.0.9")
+	if got := counterValue(t, metrics.GlobalExporter.RateLimitRejections) - before; got != 1 {
+		t.Errorf("Expected one rejection counted, got %v", got)
+	}
+}
+
+func TestApp_ServesMetricsOnSeparateAddress(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Address:         freeAddress(t),
+			MetricsAddress:  freeAddress(t),
+			ShutdownTimeout: 5 * time.Second,
+		},
+		RateLimit: config.RateLimitConfig{RequestsPerMinute: 100},
+		Cache:     config.CacheConfig{TokenTTL: time.Hour},
+	}
+	application, err := app.New(cfg, &fakeEthereumClient{}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- application.Run(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	status, body := getWhenListening(t, "http://"+cfg.Server.MetricsAddress+"/metrics")
+	if status != nethttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	expectLines(t, body,
+		"# TYPE "+http.MetricBigIntPoolGets+" counter",
+		"# TYPE "+http.MetricBigIntPoolOutstanding+" gauge",
+	)
+
+	if status, _ := getWhenListening(t, "http://"+cfg.Server.Address+"/metrics"); status != nethttp.StatusNotFound {
+		t.Errorf("Expected /metrics not to be served on the API address, got %d", status)
+	}
+}
+
+func TestApp_StopsMetricsServerWhenServerFails(t *testing.T) {
+	taken, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to reserve a port: %v", err)
+	}
+	defer taken.Close()
+
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Address:         taken.Addr().String(),
+			MetricsAddress:  freeAddress(t),
+			ShutdownTimeout: 5 * time.Second,
+		},
+		RateLimit: config.RateLimitConfig{RequestsPerMinute: 100},
+		Cache:     config.CacheConfig{TokenTTL: time.Hour},
+	}
+	application, err := app.New(cfg, &fakeEthereumClient{}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := application.Run(context.Background()); err == nil {
+		t.Fatal("Expected an error for an address already in use")
+	}
+
+	if conn, err := net.Dial("tcp", cfg.Server.MetricsAddress); err == nil {
+		conn.Close()
+		t.Error("Expected the metrics server to be stopped after the server failed")
+	}
+}
+
+// getWhenListening GETs url, retrying until its server accepts connections
+func getWhenListening(t *testing.T, url string) (int, string) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		resp, err := nethttp.Get(url)
+		if err == nil {
+			defer resp.Body.Close()
+			data, _ := io.ReadAll(resp.Body)
+			return resp.StatusCode, string(data)
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("server never started listening: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestLoadConfig_MetricsAddressMustDiffer(t *testing.T) {
+	t.Setenv("ETHEREUM_RPC_URL", "http://localhost:8545")
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("server:\n  address: \":9090\"\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if _, err := config.LoadConfig(path); err == nil || !strings.Contains(err.Error(), "metrics_address") {
+		t.Errorf("Expected the shared address to be rejected, got %v", err)
+	}
+}
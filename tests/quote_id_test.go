@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"bigswapenergy/internal/presentation/http"
+	"bigswapenergy/internal/shared/config"
+	"bigswapenergy/internal/shared/utils"
+	usecases "bigswapenergy/internal/usecases"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func baseQuoteKey() usecases.QuoteKey {
+	return usecases.QuoteKey{
+		Pool:           common.HexToAddress(testPool),
+		Src:            testToken0,
+		Dst:            testToken1,
+		Amounts:        []*big.Int{big.NewInt(1_000)},
+		BlockNumber:    20_000_000,
+		FeeBasisPoints: 30,
+	}
+}
+
+func TestQuoteKey_Stable(t *testing.T) {
+	id := baseQuoteKey().ID()
+	if id == "" || id != baseQuoteKey().ID() {
+		t.Fatalf("Expected identical keys to give the same ID, got %q", id)
+	}
+}
+
+func TestQuoteKey_SensitiveToEachInput(t *testing.T) {
+	base := baseQuoteKey().ID()
+	other := common.HexToAddress("0x3333333333333333333333333333333333333333")
+
+	for name, mutate := range map[string]func(*usecases.QuoteKey){
+		"pool":      func(k *usecases.QuoteKey) { k.Pool = other },
+		"src":       func(k *usecases.QuoteKey) { k.Src = other },
+		"dst":       func(k *usecases.QuoteKey) { k.Dst = other },
+		"swapped":   func(k *usecases.QuoteKey) { k.Src, k.Dst = k.Dst, k.Src },
+		"direction": func(k *usecases.QuoteKey) { k.ExactOut = true },
+		"amount":    func(k *usecases.QuoteKey) { k.Amounts = []*big.Int{big.NewInt(1_001)} },
+		"amounts":   func(k *usecases.QuoteKey) { k.Amounts = append(k.Amounts, big.NewInt(1)) },
+		"block":     func(k *usecases.QuoteKey) { k.BlockNumber++ },
+		"fee":       func(k *usecases.QuoteKey) { k.FeeBasisPoints = 100 },
+		"fee_side":  func(k *usecases.QuoteKey) { k.FeeSide = utils.FeeOnOutput },
+	} {
+		key := baseQuoteKey()
+		mutate(&key)
+		if key.ID() == base {
+			t.Errorf("%s: expected a different ID", name)
+		}
+	}
+}
+
+func TestEstimateService_QuoteIDDeterministic(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	service := createEstimateService(client)
+	req := usecases.EstimateRequest{
+		PoolAddress: testPool,
+		SrcToken:    testToken0.Hex(),
+		DstToken:    testToken1.Hex(),
+		SrcAmount:   big.NewInt(1_000),
+	}
+
+	first, err := service.EstimateSwap(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, _ := service.EstimateSwap(context.Background(), req)
+	if first.QuoteID == "" || first.QuoteID != second.QuoteID {
+		t.Fatalf("Expected the same quote ID for identical requests, got %q and %q", first.QuoteID, second.QuoteID)
+	}
+
+	client.blockNumber++
+	next, _ := service.EstimateSwap(context.Background(), req)
+	if next.QuoteID == first.QuoteID {
+		t.Error("Expected a new block to give a new quote ID")
+	}
+
+	req.SrcAmount, req.DstAmount = nil, big.NewInt(996)
+	exactOut, err := service.EstimateSwap(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if exactOut.QuoteID == "" || exactOut.QuoteID == next.QuoteID {
+		t.Errorf("Expected a distinct exact-out quote ID, got %q", exactOut.QuoteID)
+	}
+}
+
+func TestEstimateHandler_QuoteIDHeaderAndLog(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	service := &mockEstimateService{estimateAmount: big.NewInt(996), quoteID: "abc123"}
+	cfg := &config.Config{RateLimit: config.RateLimitConfig{RequestsPerMinute: 100}}
+	handler := http.NewEstimateHandler(service, zap.New(core), cfg)
+
+	ctx := runQuery(handler.EstimateSwapAmount, "/estimate?pool="+testPool+"&src="+testToken0.Hex()+"&dst="+testToken1.Hex()+"&src_amount=100")
+	if got := string(ctx.Response.Header.Peek("X-Quote-Id")); got != "abc123" {
+		t.Errorf("Expected the quote ID header, got %q", got)
+	}
+
+	completed := logs.FilterMessage("Estimate completed").All()
+	if len(completed) != 1 || completed[0].ContextMap()["quote_id"] != "abc123" {
+		t.Errorf("Expected the completion log to carry the quote ID, got %+v", completed)
+	}
+}
@@ -0,0 +1,135 @@
+package tests
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"bigswapenergy/internal/presentation/http"
+	"bigswapenergy/internal/shared/config"
+	"bigswapenergy/internal/shared/quotesig"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+var testSigningSecret = []byte("test-secret")
+
+func testSignedQuote() quotesig.Quote {
+	return quotesig.Quote{
+		Pool:        testPool,
+		Src:         testToken0.Hex(),
+		Dst:         testToken1.Hex(),
+		AmountIn:    big.NewInt(1_000),
+		AmountOut:   big.NewInt(996),
+		BlockNumber: 12345,
+	}
+}
+
+func TestQuoteSignature_Stable(t *testing.T) {
+	first := quotesig.Sign(testSigningSecret, testSignedQuote())
+	second := quotesig.Sign(testSigningSecret, testSignedQuote())
+	if first != second {
+		t.Fatalf("Expected identical quotes to sign identically, got %s and %s", first, second)
+	}
+	if !quotesig.Verify(testSigningSecret, testSignedQuote(), first) {
+		t.Errorf("Expected the signature to verify")
+	}
+}
+
+func TestQuoteSignature_ChangesWithEveryField(t *testing.T) {
+	base := quotesig.Sign(testSigningSecret, testSignedQuote())
+
+	mutations := map[string]func(q *quotesig.Quote){
+		"pool":       func(q *quotesig.Quote) { q.Pool = testToken0.Hex() },
+		"src":        func(q *quotesig.Quote) { q.Src = testToken1.Hex() },
+		"dst":        func(q *quotesig.Quote) { q.Dst = testToken0.Hex() },
+		"amount_in":  func(q *quotesig.Quote) { q.AmountIn = big.NewInt(1_001) },
+		"amount_out": func(q *quotesig.Quote) { q.AmountOut = big.NewInt(997) },
+		"block":      func(q *quotesig.Quote) { q.BlockNumber++ },
+		// Moving digits between adjacent fields must not produce the same message
+		"field_boundary": func(q *quotesig.Quote) { q.AmountIn, q.AmountOut = big.NewInt(10), big.NewInt(996) },
+	}
+	for name, mutate := range mutations {
+		t.Run(name, func(t *testing.T) {
+			quote := testSignedQuote()
+			mutate(&quote)
+			if quotesig.Sign(testSigningSecret, quote) == base {
+				t.Fatalf("Expected the signature to change")
+			}
+			if quotesig.Verify(testSigningSecret, quote, base) {
+				t.Fatalf("Expected the original signature not to verify the changed quote")
+			}
+		})
+	}
+
+	if quotesig.Sign([]byte("other-secret"), testSignedQuote()) == base {
+		t.Errorf("Expected a different secret to change the signature")
+	}
+}
+
+func signingHandler(service *mockEstimateService, secret string) *http.EstimateHandler {
+	cfg := &config.Config{Server: config.ServerConfig{QuoteSigningSecret: secret}}
+	return http.NewEstimateHandler(service, zap.NewNop(), cfg)
+}
+
+func TestEstimateHandler_SignedQuote(t *testing.T) {
+	service := &mockEstimateService{estimateAmount: big.NewInt(996)}
+	handler := signingHandler(service, string(testSigningSecret))
+
+	ctx := signedQuoteRequest("pool=" + testPool + "&src=" + testToken0.Hex() + "&dst=" + testToken1.Hex() + "&src_amount=1000&sign=true")
+	handler.EstimateSwapAmount(ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	var resp http.SignedQuoteResponse
+	if err := json.Unmarshal(ctx.Response.Body(), &resp); err != nil {
+		t.Fatalf("Expected JSON, got %q: %v", ctx.Response.Body(), err)
+	}
+
+	amountIn, _ := new(big.Int).SetString(resp.AmountIn, 10)
+	amountOut, _ := new(big.Int).SetString(resp.AmountOut, 10)
+	quote := quotesig.Quote{Pool: resp.Pool, Src: resp.Src, Dst: resp.Dst, AmountIn: amountIn, AmountOut: amountOut, BlockNumber: resp.BlockNumber}
+	if quote.AmountIn.Int64() != 1000 || quote.AmountOut.Int64() != 996 || quote.Pool != testPool {
+		t.Errorf("Unexpected quote fields: %+v", resp)
+	}
+	if !quotesig.Verify(testSigningSecret, quote, resp.Signature) {
+		t.Errorf("Expected the returned signature to verify against the returned fields")
+	}
+}
+
+func TestEstimateHandler_SignRejected(t *testing.T) {
+	base := "pool=" + testPool + "&src=" + testToken0.Hex() + "&dst=" + testToken1.Hex()
+	tests := []struct {
+		name   string
+		secret string
+		query  string
+	}{
+		{name: "no_secret", query: base + "&src_amount=1000&sign=true"},
+		{name: "amount_list", secret: "s", query: base + "&src_amount=1000&src_amount=2000&sign=true"},
+		{name: "item_status", secret: "s", query: base + "&src_amount=1000&item_status=true&sign=true"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			service := &mockEstimateService{estimateAmount: big.NewInt(996)}
+			ctx := signedQuoteRequest(tc.query)
+			signingHandler(service, tc.secret).EstimateSwapAmount(ctx)
+
+			if ctx.Response.StatusCode() == fasthttp.StatusOK {
+				t.Fatalf("Expected sign=true to be rejected")
+			}
+		})
+	}
+}
+
+func signedQuoteRequest(query string) *fasthttp.RequestCtx {
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	req.SetRequestURI("/estimate?" + query)
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(req, nil, nil)
+	return ctx
+}
@@ -0,0 +1,94 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"bigswapenergy/internal/presentation/http"
+	"bigswapenergy/internal/shared/config"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+type fakeHealthChecker struct {
+	healthy bool
+}
+
+func (f fakeHealthChecker) CheckConnectionHealth(ctx context.Context) bool {
+	return f.healthy
+}
+
+var testCanary = config.CanaryQuoteConfig{
+	Pool:   testPool,
+	Src:    testToken0.Hex(),
+	Dst:    testToken1.Hex(),
+	Amount: "1000000",
+}
+
+func runReady(t *testing.T, healthy bool, service *mockEstimateService, canary config.CanaryQuoteConfig) (int, http.ReadinessResponse) {
+	t.Helper()
+
+	handler := http.NewReadinessHandler(fakeHealthChecker{healthy: healthy}, service, config.ReadinessConfig{Canary: canary}, zap.NewNop())
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI("/ready")
+	handler.GetReady(ctx)
+
+	var resp http.ReadinessResponse
+	if err := json.Unmarshal(ctx.Response.Body(), &resp); err != nil {
+		t.Fatalf("Expected JSON body, got %q", ctx.Response.Body())
+	}
+	return ctx.Response.StatusCode(), resp
+}
+
+func TestReady_ConnectionOnlyWithoutCanary(t *testing.T) {
+	service := &mockEstimateService{estimateError: errors.New("must not be called")}
+
+	status, resp := runReady(t, true, service, config.CanaryQuoteConfig{})
+	if status != fasthttp.StatusOK || resp.Check != "connection" {
+		t.Errorf("Expected ready via connection check, got %d %+v", status, resp)
+	}
+
+	status, _ = runReady(t, false, service, config.CanaryQuoteConfig{})
+	if status != fasthttp.StatusServiceUnavailable {
+		t.Errorf("Expected 503 for unhealthy connection, got %d", status)
+	}
+}
+
+func TestReady_CanaryQuoteSucceeds(t *testing.T) {
+	service := &mockEstimateService{estimateAmount: big.NewInt(1)}
+
+	status, resp := runReady(t, true, service, testCanary)
+	if status != fasthttp.StatusOK || resp.Check != "canary" {
+		t.Errorf("Expected ready via canary, got %d %+v", status, resp)
+	}
+}
+
+func TestReady_CanaryQuoteFails(t *testing.T) {
+	service := &mockEstimateService{estimateError: errors.New("storage read failed")}
+
+	status, resp := runReady(t, true, service, testCanary)
+	if status != fasthttp.StatusServiceUnavailable || resp.Check != "canary" {
+		t.Errorf("Expected 503 from canary, got %d %+v", status, resp)
+	}
+}
+
+func TestLoadConfig_RejectsInvalidCanaryAmount(t *testing.T) {
+	t.Setenv("ETHEREUM_RPC_URL", "http://localhost:8545")
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	yaml := "readiness:\n  canary:\n    pool: \"" + testPool + "\"\n    src: \"0x1\"\n    dst: \"0x2\"\n    amount: \"abc\"\n"
+	if err := os.WriteFile(path, []byte(yaml), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	if _, err := config.LoadConfig(path); err == nil {
+		t.Error("Expected an error for a non-numeric canary amount")
+	}
+}
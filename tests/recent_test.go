@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"encoding/json"
+	"math/big"
+	"sync"
+	"testing"
+
+	"bigswapenergy/internal/presentation/http"
+	"bigswapenergy/internal/shared/ringbuffer"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestRingBuffer_Wraparound(t *testing.T) {
+	ring := ringbuffer.New[int](3)
+
+	for i := 1; i <= 5; i++ {
+		ring.Add(i)
+	}
+
+	got := ring.Snapshot()
+	want := []int{5, 4, 3}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Expected %v, got %v", want, got)
+		}
+	}
+}
+
+func TestRingBuffer_PartiallyFilled(t *testing.T) {
+	ring := ringbuffer.New[int](4)
+	ring.Add(1)
+	ring.Add(2)
+
+	if got := ring.Snapshot(); len(got) != 2 || got[0] != 2 || got[1] != 1 {
+		t.Errorf("Expected [2 1], got %v", got)
+	}
+	if ring.Len() != 2 {
+		t.Errorf("Expected length 2, got %d", ring.Len())
+	}
+}
+
+func TestRingBuffer_ConcurrentWrites(t *testing.T) {
+	const (
+		size    = 16
+		writers = 8
+		perG    = 1000
+	)
+	ring := ringbuffer.New[int](size)
+
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perG; i++ {
+				ring.Add(w*perG + i)
+				if i%100 == 0 {
+					ring.Snapshot()
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	got := ring.Snapshot()
+	if len(got) != size {
+		t.Fatalf("Expected %d entries, got %d", size, len(got))
+	}
+	seen := make(map[int]bool)
+	for _, v := range got {
+		if v < 0 || v >= writers*perG || seen[v] {
+			t.Fatalf("Unexpected or duplicate entry %d in %v", v, got)
+		}
+		seen[v] = true
+	}
+}
+
+func TestRecentRequestsMiddleware_RecordsRequests(t *testing.T) {
+	ring := ringbuffer.New[http.RecentRequest](2)
+	estimateHandler := createEstimateHandler(&mockEstimateService{estimateAmount: big.NewInt(996)})
+	handler := http.NewRecentRequestsMiddleware(ring).Apply(estimateHandler.EstimateSwapAmount)
+
+	for _, uri := range []string{
+		"/estimate?pool=0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc&src=0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48&dst=0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2&src_amount=1",
+		"/estimate?pool=0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc&src=0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48&dst=0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2&src_amount=2",
+		"/estimate?pool=0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc&src=0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48&dst=0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2&src_amount=3",
+	} {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI(uri)
+		handler(ctx)
+	}
+
+	recent := ring.Snapshot()
+	if len(recent) != 2 {
+		t.Fatalf("Expected 2 recorded requests, got %d", len(recent))
+	}
+	if recent[0].Query != "pool=0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc&src=0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48&dst=0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2&src_amount=3" || recent[0].Response != "996" || recent[0].Status != fasthttp.StatusOK {
+		t.Errorf("Unexpected newest entry: %+v", recent[0])
+	}
+
+	ctx := &fasthttp.RequestCtx{}
+	http.NewRecentRequestsHandler(ring).GetRecent(ctx)
+	var body []map[string]any
+	if err := json.Unmarshal(ctx.Response.Body(), &body); err != nil || len(body) != 2 {
+		t.Errorf("Expected JSON list of 2 entries, got %q", ctx.Response.Body())
+	}
+}
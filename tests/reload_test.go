@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"context"
+	nethttp "net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"bigswapenergy/internal/app"
+	"bigswapenergy/internal/presentation/http"
+	"bigswapenergy/internal/shared/clock"
+	"bigswapenergy/internal/shared/config"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRateLimitMiddleware_UpdateConfig(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Unix(1_700_000_000, 0))
+	middleware := http.NewRateLimitMiddleware(http.HTTPRateLimitConfig{RequestsPerMinute: 1}, zap.NewNop(), fakeClock)
+	handler := middleware.Apply(okHandler)
+
+	doRequest(handler, "10.0.0.1")
+	if status := doRequest(handler, "10.0.0.1"); status != fasthttp.StatusTooManyRequests {
+		t.Fatalf("Expected status %d, got %d", fasthttp.StatusTooManyRequests, status)
+	}
+
+	middleware.UpdateConfig(http.HTTPRateLimitConfig{RequestsPerMinute: 60, Burst: 3})
+
+	// The existing client refills at the new rate
+	fakeClock.Advance(time.Second)
+	if status := doRequest(handler, "10.0.0.1"); status != fasthttp.StatusOK {
+		t.Errorf("Expected a token refilled at the new rate, got %d", status)
+	}
+
+	// A new client starts with the new burst
+	for i := 0; i < 3; i++ {
+		if status := doRequest(handler, "10.0.0.2"); status != fasthttp.StatusOK {
+			t.Fatalf("request %d: expected the new burst, got %d", i, status)
+		}
+	}
+	if status := doRequest(handler, "10.0.0.2"); status != fasthttp.StatusTooManyRequests {
+		t.Errorf("Expected the burst capped at 3, got %d", status)
+	}
+}
+
+func TestApp_ReloadAppliesRateLimitAndLogsChanges(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Address:         freeAddress(t),
+			ShutdownTimeout: 5 * time.Second,
+		},
+		Blockchain: config.BlockchainConfig{EthereumRPCURL: "http://primary"},
+		RateLimit:  config.RateLimitConfig{RequestsPerMinute: 1, CleanupInterval: time.Minute},
+		Cache:      config.CacheConfig{TokenTTL: time.Hour},
+	}
+	core, logs := observer.New(zapcore.InfoLevel)
+	application, err := app.New(cfg, &fakeEthereumClient{}, zap.New(core))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- application.Run(ctx) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	url := "http://" + cfg.Server.Address + "/stats"
+	getWhenListening(t, url)
+	if status, _ := getWhenListening(t, url); status != nethttp.StatusTooManyRequests {
+		t.Fatalf("Expected the one request per minute limit, got %d", status)
+	}
+
+	reloaded := *cfg
+	reloaded.Blockchain.EthereumRPCURL = "http://other"
+	reloaded.RateLimit = config.RateLimitConfig{RequestsPerMinute: 60_000, Burst: 100, CleanupInterval: time.Hour}
+	application.Reload(&reloaded)
+
+	time.Sleep(20 * time.Millisecond)
+	if status, _ := getWhenListening(t, url); status != nethttp.StatusOK {
+		t.Errorf("Expected the reloaded limit applied, got %d", status)
+	}
+
+	reloadLogs := logs.FilterMessage("Configuration reloaded").All()
+	if len(reloadLogs) != 1 {
+		t.Fatalf("Expected one reload log, got %d", len(reloadLogs))
+	}
+	changed, _ := reloadLogs[0].ContextMap()["changed"].([]interface{})
+	want := []interface{}{"rate_limit.requests_per_minute: 1 -> 60000", "rate_limit.burst: 0 -> 100"}
+	if !slices.Equal(changed, want) {
+		t.Errorf("Expected changes %v, got %v", want, changed)
+	}
+
+	restartLogs := logs.FilterMessage("Configuration changes require a restart").All()
+	if len(restartLogs) != 1 {
+		t.Fatalf("Expected one restart warning, got %d", len(restartLogs))
+	}
+	settings, _ := restartLogs[0].ContextMap()["settings"].([]interface{})
+	if want := []interface{}{"blockchain.ethereum_rpc_url", "rate_limit.cleanup_interval"}; !slices.Equal(settings, want) {
+		t.Errorf("Expected restart-only settings %v, got %v", want, settings)
+	}
+}
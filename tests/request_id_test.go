@@ -0,0 +1,100 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"regexp"
+	"strings"
+	"testing"
+
+	"bigswapenergy/internal/presentation/http"
+	"bigswapenergy/internal/shared/config"
+	"bigswapenergy/internal/shared/requestid"
+	usecases "bigswapenergy/internal/usecases"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func doRequestWithID(handler fasthttp.RequestHandler, uri, id string) *fasthttp.RequestCtx {
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	req.SetRequestURI(uri)
+	if id != "" {
+		req.Header.Set("X-Request-ID", id)
+	}
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(req, nil, nil)
+	handler(ctx)
+	return ctx
+}
+
+func TestRequestIDMiddleware_KeepsOrGeneratesID(t *testing.T) {
+	var seen string
+	handler := http.NewRequestIDMiddleware().Apply(func(ctx *fasthttp.RequestCtx) {
+		seen = http.RequestIDFromContext(ctx)
+	})
+
+	ctx := doRequestWithID(handler, "/estimate", "gateway-7f3a:1")
+	if got := string(ctx.Response.Header.Peek("X-Request-ID")); got != "gateway-7f3a:1" || seen != "gateway-7f3a:1" {
+		t.Errorf("Expected the incoming ID kept and echoed, got %q (handler saw %q)", got, seen)
+	}
+
+	for _, id := range []string{"", "bad id\nforged log line", strings.Repeat("a", 129)} {
+		ctx := doRequestWithID(handler, "/estimate", id)
+		got := string(ctx.Response.Header.Peek("X-Request-ID"))
+		if !uuidPattern.MatchString(got) || seen != got {
+			t.Errorf("incoming %q: expected a generated UUID, got %q (handler saw %q)", id, got, seen)
+		}
+	}
+}
+
+func TestRequestID_TagsEstimateLogs(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	log := zap.New(core)
+	cfg := &config.Config{RateLimit: config.RateLimitConfig{RequestsPerMinute: 100}}
+	service := usecases.NewEstimateService(newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000)), nil, log)
+	handler := http.NewRequestIDMiddleware().Apply(http.NewEstimateHandler(service, log, cfg).EstimateSwapAmount)
+
+	ctx := doRequestWithID(handler, "/estimate?pool="+testPool+"&src="+testToken0.Hex()+"&dst="+testToken1.Hex()+"&src_amount=1000", "req-42")
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d", ctx.Response.StatusCode())
+	}
+
+	// Both the usecase's log and the handler's completion log carry the ID
+	for _, msg := range []string{"Processing swap estimation request", "Estimate completed"} {
+		entries := logs.FilterMessage(msg).All()
+		if len(entries) != 1 || entries[0].ContextMap()["request_id"] != "req-42" {
+			t.Errorf("Expected %q tagged with the request ID, got %+v", msg, entries)
+		}
+	}
+}
+
+func TestRequestID_TagsErrorLog(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	service := &mockEstimateService{estimateError: errors.New("boom")}
+	cfg := &config.Config{RateLimit: config.RateLimitConfig{RequestsPerMinute: 100}}
+	handler := http.NewRequestIDMiddleware().Apply(http.NewEstimateHandler(service, zap.New(core), cfg).EstimateSwapAmount)
+
+	doRequestWithID(handler, "/estimate?pool="+testPool+"&src="+testToken0.Hex()+"&dst="+testToken1.Hex()+"&src_amount=100", "req-43")
+
+	entries := logs.FilterMessage("Request error").All()
+	if len(entries) != 1 || entries[0].ContextMap()["request_id"] != "req-43" {
+		t.Errorf("Expected the error log tagged with the request ID, got %+v", entries)
+	}
+}
+
+func TestRequestID_FromContext(t *testing.T) {
+	if id := requestid.FromContext(context.Background()); id != "" {
+		t.Errorf("Expected no ID on a bare context, got %q", id)
+	}
+	if id := requestid.FromContext(requestid.WithID(context.Background(), "req-44")); id != "req-44" {
+		t.Errorf("Expected the attached ID, got %q", id)
+	}
+}
@@ -0,0 +1,73 @@
+package tests
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"bigswapenergy/internal/presentation/http"
+	"bigswapenergy/internal/shared/config"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+func runEstimateWithTimeout(t *testing.T, timeout, delay time.Duration) (*fasthttp.RequestCtx, time.Duration) {
+	t.Helper()
+
+	cfg := &config.Config{
+		Server:    config.ServerConfig{RequestTimeout: timeout},
+		RateLimit: config.RateLimitConfig{RequestsPerMinute: 100},
+	}
+	service := &mockEstimateService{estimateAmount: big.NewInt(996), delay: delay}
+	handler := http.NewEstimateHandler(service, zap.NewNop(), cfg)
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	req.SetRequestURI("/estimate?pool=0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc&src=0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48&dst=0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2&src_amount=1000")
+	req.Header.SetMethod("GET")
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(req, nil, nil)
+
+	start := time.Now()
+	handler.EstimateSwapAmount(ctx)
+	return ctx, time.Since(start)
+}
+
+func TestRequestTimeout_SlowServiceReturns504AtDeadline(t *testing.T) {
+	timeout := 50 * time.Millisecond
+	ctx, elapsed := runEstimateWithTimeout(t, timeout, 5*time.Second)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusGatewayTimeout {
+		t.Fatalf("Expected status 504, got %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	if elapsed < timeout || elapsed > time.Second {
+		t.Errorf("Expected the request to end at the %s deadline, took %s", timeout, elapsed)
+	}
+	if got := string(ctx.Response.Header.Peek("X-Timeout-Ms")); got != "50" {
+		t.Errorf("Expected X-Timeout-Ms 50, got %q", got)
+	}
+}
+
+func TestRequestTimeout_FastServiceSucceeds(t *testing.T) {
+	ctx, _ := runEstimateWithTimeout(t, time.Second, 0)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d", ctx.Response.StatusCode())
+	}
+	if got := string(ctx.Response.Header.Peek("X-Timeout-Ms")); got != "1000" {
+		t.Errorf("Expected X-Timeout-Ms 1000, got %q", got)
+	}
+}
+
+func TestRequestTimeout_DisabledOmitsHeader(t *testing.T) {
+	ctx, _ := runEstimateWithTimeout(t, 0, 0)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d", ctx.Response.StatusCode())
+	}
+	if got := ctx.Response.Header.Peek("X-Timeout-Ms"); len(got) != 0 {
+		t.Errorf("Expected no X-Timeout-Ms header when disabled, got %q", got)
+	}
+}
@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"bigswapenergy/internal/infrastructure/uniswap_v2"
+	"bigswapenergy/internal/presentation/http"
+	"bigswapenergy/internal/shared/clock"
+	"bigswapenergy/internal/shared/config"
+	usecases "bigswapenergy/internal/usecases"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+func newReservesCachedClient(fake *fakeUniswapV2Client, maxAge uint64) *uniswap_v2.CachedUniswapV2Client {
+	cached := uniswap_v2.NewCachedUniswapV2Client(fake, time.Hour, clock.NewFakeClock(time.Unix(1_700_000_000, 0)), zap.NewNop())
+	cached.EnableReservesCache(maxAge)
+	return cached
+}
+
+func reserveAgeRequest() usecases.EstimateRequest {
+	return usecases.EstimateRequest{
+		PoolAddress: testPool,
+		SrcToken:    testToken0.Hex(),
+		DstToken:    testToken1.Hex(),
+		SrcAmount:   big.NewInt(1_000),
+	}
+}
+
+func TestReserveAge_StaleCachedEntry(t *testing.T) {
+	fake := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	service := usecases.NewEstimateService(newReservesCachedClient(fake, 5), nil, zap.NewNop())
+
+	fresh, err := service.EstimateSwap(context.Background(), reserveAgeRequest())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fresh.ReserveAgeBlocks != 0 {
+		t.Errorf("Expected fresh reserves to have age 0, got %d", fresh.ReserveAgeBlocks)
+	}
+
+	fake.blockNumber += 3
+	stale, err := service.EstimateSwap(context.Background(), reserveAgeRequest())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stale.ReserveAgeBlocks != 3 || stale.BlockNumber != fake.blockNumber {
+		t.Errorf("Expected age 3 at head %d, got age %d at %d", fake.blockNumber, stale.ReserveAgeBlocks, stale.BlockNumber)
+	}
+	if fake.reservesCalls != 1 {
+		t.Errorf("Expected the stale entry to be served from cache, got %d reads", fake.reservesCalls)
+	}
+
+	fake.blockNumber += 3
+	reread, _ := service.EstimateSwap(context.Background(), reserveAgeRequest())
+	if reread.ReserveAgeBlocks != 0 || fake.reservesCalls != 2 {
+		t.Errorf("Expected a re-read past the max age, got age %d after %d reads", reread.ReserveAgeBlocks, fake.reservesCalls)
+	}
+}
+
+func TestReserveAge_OlderBlockNotServedFromCache(t *testing.T) {
+	fake := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	cached := newReservesCachedClient(fake, 5)
+	pool := common.HexToAddress(testPool)
+
+	cached.LoadReservesWithBlock(context.Background(), pool, big.NewInt(100))
+	_, _, readAt, err := cached.LoadReservesWithBlock(context.Background(), pool, big.NewInt(99))
+	if err != nil || readAt != 99 || fake.reservesCalls != 2 {
+		t.Fatalf("Expected a read at block 99, got readAt=%d reads=%d err=%v", readAt, fake.reservesCalls, err)
+	}
+	_, _, readAt, _ = cached.LoadReservesWithBlock(context.Background(), pool, big.NewInt(102))
+	if readAt != 100 {
+		t.Errorf("Expected the earlier read not to replace the newer entry, got readAt=%d", readAt)
+	}
+}
+
+func TestEstimateHandler_ReserveAgeHeader(t *testing.T) {
+	fake := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	service := usecases.NewEstimateService(newReservesCachedClient(fake, 5), nil, zap.NewNop())
+	cfg := &config.Config{
+		RateLimit: config.RateLimitConfig{RequestsPerMinute: 100},
+		Cache:     config.CacheConfig{ReservesMaxAgeBlocks: 5},
+	}
+	handler := http.NewEstimateHandler(service, zap.NewNop(), cfg)
+	uri := "/estimate?pool=" + testPool + "&src=" + testToken0.Hex() + "&dst=" + testToken1.Hex() + "&src_amount=1000"
+
+	ctx := runQuery(handler.EstimateSwapAmount, uri)
+	if got := string(ctx.Response.Header.Peek("X-Reserve-Age-Blocks")); got != "0" {
+		t.Errorf("Expected age 0 for a fresh read, got %q", got)
+	}
+
+	fake.blockNumber += 2
+	ctx = runQuery(handler.EstimateSwapAmount, uri)
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d", ctx.Response.StatusCode())
+	}
+	if got := string(ctx.Response.Header.Peek("X-Reserve-Age-Blocks")); got != "2" {
+		t.Errorf("Expected age 2 for the cached read, got %q", got)
+	}
+
+	uncached := createEstimateHandler(createEstimateService(fake))
+	ctx = runQuery(uncached.EstimateSwapAmount, uri)
+	if got := ctx.Response.Header.Peek("X-Reserve-Age-Blocks"); got != nil {
+		t.Errorf("Expected no age header without the reserves cache, got %q", got)
+	}
+}
@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"bigswapenergy/internal/presentation/http"
+	"bigswapenergy/internal/shared/config"
+	apperrors "bigswapenergy/internal/shared/errors"
+	usecases "bigswapenergy/internal/usecases"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+func reserveGuardRequest(maxRatio uint64, warnOnly bool) usecases.EstimateRequest {
+	return usecases.EstimateRequest{
+		PoolAddress:          testPool,
+		SrcToken:             testToken0.Hex(),
+		DstToken:             testToken1.Hex(),
+		SrcAmount:            big.NewInt(100),
+		MaxReserveRatio:      maxRatio,
+		ReserveRatioWarnOnly: warnOnly,
+	}
+}
+
+func TestEstimateService_ReserveRatioBoundary(t *testing.T) {
+	tests := []struct {
+		name     string
+		reserve0 *big.Int
+		reserve1 *big.Int
+		reject   bool
+	}{
+		{name: "at_bound", reserve0: big.NewInt(1_000), reserve1: big.NewInt(1_000_000)},
+		{name: "just_over_bound", reserve0: big.NewInt(1_000), reserve1: big.NewInt(1_000_001), reject: true},
+		// The guard is symmetric: the skew may be on either side of the swap
+		{name: "just_over_bound_reversed", reserve0: big.NewInt(1_000_001), reserve1: big.NewInt(1_000), reject: true},
+		{name: "beyond_uint64", reserve0: big.NewInt(1_000_000), reserve1: new(big.Int).Exp(big.NewInt(10), big.NewInt(24), nil), reject: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			service := createEstimateService(newFakeUniswapV2Client(tc.reserve0, tc.reserve1))
+
+			result, err := service.EstimateSwap(context.Background(), reserveGuardRequest(1_000, false))
+			if !tc.reject {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if len(result.Warnings) != 0 {
+					t.Errorf("Expected no warnings, got %v", result.Warnings)
+				}
+				return
+			}
+			if !errors.Is(err, apperrors.ErrBusinessRule) || !errors.Is(err, usecases.ErrReserveImbalance) {
+				t.Fatalf("Expected a reserve imbalance business rule error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestEstimateService_ReserveRatioDisabledByDefault(t *testing.T) {
+	service := createEstimateService(newFakeUniswapV2Client(big.NewInt(1), new(big.Int).Exp(big.NewInt(10), big.NewInt(24), nil)))
+
+	if _, err := service.EstimateSwap(context.Background(), reserveGuardRequest(0, false)); err != nil {
+		t.Fatalf("Expected no guard without a max ratio, got %v", err)
+	}
+}
+
+func TestEstimateService_ReserveRatioWarnOnly(t *testing.T) {
+	service := createEstimateService(newFakeUniswapV2Client(big.NewInt(1_000), big.NewInt(1_000_001)))
+
+	result, err := service.EstimateSwap(context.Background(), reserveGuardRequest(1_000, true))
+	if err != nil {
+		t.Fatalf("Expected warn-only mode to return the quote, got %v", err)
+	}
+	if result.AmountOut == nil || result.AmountOut.Sign() <= 0 {
+		t.Errorf("Expected a quote, got %v", result.AmountOut)
+	}
+	if len(result.Warnings) != 1 {
+		t.Fatalf("Expected one warning, got %v", result.Warnings)
+	}
+}
+
+func TestEstimateHandler_ReserveGuardFromConfig(t *testing.T) {
+	service := &mockEstimateService{estimateAmount: big.NewInt(996), warnings: []string{"pool reserves are extremely imbalanced"}}
+	cfg := &config.Config{ReserveGuard: config.ReserveGuardConfig{MaxRatio: 1_000, Action: config.ReserveGuardWarn}}
+	handler := http.NewEstimateHandler(service, zap.NewNop(), cfg)
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	req.SetRequestURI("/estimate?pool=" + testPool + "&src=" + testToken0.Hex() + "&dst=" + testToken1.Hex() + "&src_amount=100")
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(req, nil, nil)
+	handler.EstimateSwapAmount(ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d", ctx.Response.StatusCode())
+	}
+	if service.lastRequest.MaxReserveRatio != 1_000 || !service.lastRequest.ReserveRatioWarnOnly {
+		t.Errorf("Expected the configured guard on the request, got ratio=%d warnOnly=%v",
+			service.lastRequest.MaxReserveRatio, service.lastRequest.ReserveRatioWarnOnly)
+	}
+	if got := string(ctx.Response.Header.Peek("X-Pool-Warning")); got != "pool reserves are extremely imbalanced" {
+		t.Errorf("Expected the warning header, got %q", got)
+	}
+}
@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"bigswapenergy/internal/infrastructure/uniswap_v2"
+	"bigswapenergy/internal/shared/clock"
+	usecases "bigswapenergy/internal/usecases"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+// timestampedReservesWord is reservesWord with blockTimestampLast in the top 32 bits
+func timestampedReservesWord(reserve0, reserve1 int64, timestamp uint32) []byte {
+	word := new(big.Int).SetBytes(reservesWord(reserve0, reserve1))
+	word.Or(word, new(big.Int).Lsh(big.NewInt(int64(timestamp)), 224))
+	return word.FillBytes(make([]byte, 32))
+}
+
+func TestLoadPoolState_BlockTimestampLast(t *testing.T) {
+	const timestamp = uint32(1_700_000_000)
+	newBase := func() uniswap_v2.UniswapV2Client {
+		storage := pairStorage(1_000, 2_000)
+		storage[slotKey(uniswap_v2.UniswapV2ReservesStorageSlot)] = timestampedReservesWord(1_000, 2_000, timestamp)
+		return uniswap_v2.NewUniswapV2Client(&fakeEthereumClient{storage: storage}, zap.NewNop())
+	}
+
+	clients := map[string]func() uniswap_v2.UniswapV2Client{
+		"base": newBase,
+		"token cache": func() uniswap_v2.UniswapV2Client {
+			return uniswap_v2.NewCachedUniswapV2Client(newBase(), time.Hour, clock.New(), zap.NewNop())
+		},
+		"reserves cache": func() uniswap_v2.UniswapV2Client {
+			cached := uniswap_v2.NewCachedUniswapV2Client(newBase(), time.Hour, clock.New(), zap.NewNop())
+			cached.EnableReservesCache(2)
+			return cached
+		},
+		"block reserves cache": func() uniswap_v2.UniswapV2Client {
+			return uniswap_v2.NewReservesCachingUniswapV2Client(newBase(), time.Minute, 10, clock.New())
+		},
+		"slot detection": func() uniswap_v2.UniswapV2Client {
+			detecting := uniswap_v2.NewSlotDetectingUniswapV2Client(newBase(), 10, zap.NewNop())
+			return uniswap_v2.NewCachedUniswapV2Client(detecting, time.Hour, clock.New(), zap.NewNop())
+		},
+	}
+	for name, newClient := range clients {
+		t.Run(name, func(t *testing.T) {
+			client := newClient()
+			// The second read is served from whichever cache the client has
+			for i := 0; i < 2; i++ {
+				state, err := loadPoolState(t, client)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if state.BlockTimestampLast != timestamp || state.Reserve0.Int64() != 1_000 || state.Reserve1.Int64() != 2_000 {
+					t.Fatalf("read %d: unexpected state %+v", i, state)
+				}
+			}
+		})
+	}
+}
+
+// timestampedPoolClient reports its reserves as last updated at timestamp
+type timestampedPoolClient struct {
+	*fakeUniswapV2Client
+	timestamp uint32
+}
+
+func (c *timestampedPoolClient) LoadPoolState(ctx context.Context, pool common.Address, blockNum *big.Int) (*uniswap_v2.PoolState, error) {
+	token0, token1, err := c.LoadTokens(ctx, pool, blockNum)
+	if err != nil {
+		return nil, err
+	}
+	reserve0, reserve1, err := c.LoadReserves(ctx, pool, blockNum)
+	if err != nil {
+		return nil, err
+	}
+	return &uniswap_v2.PoolState{Token0: token0, Token1: token1, Reserve0: reserve0, Reserve1: reserve1, ReadAt: blockNum.Uint64(), BlockTimestampLast: c.timestamp}, nil
+}
+
+func TestEstimateService_StaleReservesWarning(t *testing.T) {
+	hoursAgo := func(hours int) uint32 {
+		return uint32(time.Now().Add(-time.Duration(hours) * time.Hour).Unix())
+	}
+	block := uint64(100)
+	tests := []struct {
+		name      string
+		timestamp uint32
+		threshold time.Duration
+		block     *uint64
+		wantWarn  bool
+	}{
+		{"stale", hoursAgo(3), time.Hour, nil, true},
+		{"fresh", hoursAgo(0), time.Hour, nil, false},
+		{"disabled", hoursAgo(3), 0, nil, false},
+		{"unknown timestamp", 0, time.Hour, nil, false},
+		{"explicit block", hoursAgo(3), time.Hour, &block, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &timestampedPoolClient{
+				fakeUniswapV2Client: newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000)),
+				timestamp:           tt.timestamp,
+			}
+			service := usecases.NewEstimateService(client, nil, zap.NewNop())
+
+			result, err := service.EstimateSwap(context.Background(), usecases.EstimateRequest{
+				PoolAddress:         testPool,
+				SrcToken:            testToken0.Hex(),
+				DstToken:            testToken1.Hex(),
+				SrcAmount:           big.NewInt(1_000),
+				Block:               tt.block,
+				MaxReserveStaleness: tt.threshold,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			warned := len(result.Warnings) == 1 && strings.Contains(result.Warnings[0], "last updated 3h0m")
+			if warned != tt.wantWarn || (!tt.wantWarn && len(result.Warnings) > 0) {
+				t.Fatalf("Expected a staleness warning: %v, got %v", tt.wantWarn, result.Warnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"bigswapenergy/internal/infrastructure/ethereum"
+	"bigswapenergy/internal/infrastructure/uniswap_v2"
+	apperrors "bigswapenergy/internal/shared/errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+// fakeEthereumClient serves storage words from memory, keyed by slot hash, and
+// eth_call results keyed by contract. A batch read fails if any slot is unset.
+type fakeEthereumClient struct {
+	storage map[common.Hash][]byte
+	calls   map[common.Address][]byte
+	// batches counts ReadContractStorageBatch calls
+	batches int
+}
+
+func (f *fakeEthereumClient) GetLatestBlockNumber(ctx context.Context) (uint64, error) {
+	return 20_000_000, nil
+}
+
+func (f *fakeEthereumClient) ReadContractStorage(ctx context.Context, contractAddress common.Address, storageKey common.Hash, blockNumber *big.Int) ([]byte, error) {
+	data, ok := f.storage[storageKey]
+	if !ok {
+		return nil, errors.New("slot not set")
+	}
+	return data, nil
+}
+
+func (f *fakeEthereumClient) ReadContractStorageBatch(ctx context.Context, contractAddress common.Address, storageKeys []common.Hash, blockNumber *big.Int) ([][]byte, error) {
+	f.batches++
+	data := make([][]byte, len(storageKeys))
+	for i, key := range storageKeys {
+		word, err := f.ReadContractStorage(ctx, contractAddress, key, blockNumber)
+		if err != nil {
+			return nil, err
+		}
+		data[i] = word
+	}
+	return data, nil
+}
+
+func (f *fakeEthereumClient) CallContract(ctx context.Context, contractAddress common.Address, data []byte, blockNumber *big.Int) ([]byte, error) {
+	result, ok := f.calls[contractAddress]
+	if !ok {
+		return nil, fmt.Errorf("%w: execution reverted", ethereum.ErrExecutionReverted)
+	}
+	return result, nil
+}
+
+func (f *fakeEthereumClient) Close() error {
+	return nil
+}
+
+func (f *fakeEthereumClient) CheckConnectionHealth(ctx context.Context) bool {
+	return true
+}
+
+// reservesWord packs reserves the way UniswapV2Pair stores them: reserve0 in the
+// low 112 bits, reserve1 in the next 112
+func reservesWord(reserve0, reserve1 int64) []byte {
+	word := new(big.Int).Lsh(big.NewInt(reserve1), 112)
+	word.Or(word, big.NewInt(reserve0))
+	return common.LeftPadBytes(word.Bytes(), 32)
+}
+
+func loadReservesFromWord(word []byte) (*big.Int, *big.Int, error) {
+	client := uniswap_v2.NewUniswapV2Client(&fakeEthereumClient{
+		storage: map[common.Hash][]byte{
+			common.BigToHash(big.NewInt(uniswap_v2.UniswapV2ReservesStorageSlot)): word,
+		},
+	}, zap.NewNop())
+	return client.LoadReserves(context.Background(), common.HexToAddress(testPool), big.NewInt(1))
+}
+
+func TestLoadReserves_BothZeroIsNotInitialized(t *testing.T) {
+	_, _, err := loadReservesFromWord(reservesWord(0, 0))
+	if !errors.Is(err, uniswap_v2.ErrPoolNotInitialized) {
+		t.Errorf("Expected ErrPoolNotInitialized, got %v", err)
+	}
+	if errors.Is(err, uniswap_v2.ErrPoolDrained) {
+		t.Errorf("Did not expect ErrPoolDrained, got %v", err)
+	}
+}
+
+func TestLoadReserves_OneZeroIsDrained(t *testing.T) {
+	for _, word := range [][]byte{reservesWord(0, 5000), reservesWord(5000, 0)} {
+		_, _, err := loadReservesFromWord(word)
+		if !errors.Is(err, uniswap_v2.ErrPoolDrained) {
+			t.Errorf("Expected ErrPoolDrained, got %v", err)
+		}
+		if !errors.Is(err, uniswap_v2.ErrInsufficientLiquidity) {
+			t.Errorf("Expected error to still match ErrInsufficientLiquidity, got %v", err)
+		}
+	}
+}
+
+func TestLoadReserves_BothNonZero(t *testing.T) {
+	reserve0, reserve1, err := loadReservesFromWord(reservesWord(1000, 2000))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if reserve0.Int64() != 1000 || reserve1.Int64() != 2000 {
+		t.Errorf("Expected reserves 1000/2000, got %s/%s", reserve0, reserve1)
+	}
+}
+
+func TestEstimateService_MapsReserveStates(t *testing.T) {
+	tests := []struct {
+		name     string
+		loadErr  error
+		expected error
+	}{
+		{"not_initialized", uniswap_v2.ErrPoolNotInitialized, apperrors.ErrPoolNotInitialized},
+		{"drained", uniswap_v2.ErrPoolDrained, apperrors.ErrPoolDrained},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newFakeUniswapV2Client(big.NewInt(1), big.NewInt(1))
+			client.reservesErr = tt.loadErr
+			service := createEstimateService(client)
+
+			_, err := service.EstimateSwapAmount(context.Background(), testPool, testToken0.Hex(), testToken1.Hex(), big.NewInt(1000))
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestLoadReserves_ShortWords(t *testing.T) {
+	full := timestampedReservesWord(1_000, 2_000, 1_700_000_000)
+	tests := []struct {
+		name     string
+		word     []byte
+		reserve0 int64
+		reserve1 int64
+		wantErr  error
+	}{
+		{"0 bytes", []byte{}, 0, 0, uniswap_v2.ErrPoolNotInitialized},
+		{"16 bytes", reservesWord(1_000, 0)[16:], 0, 0, uniswap_v2.ErrPoolDrained},
+		{"31 bytes", reservesWord(1_000, 2_000)[1:], 1_000, 2_000, nil},
+		{"32 bytes", full, 1_000, 2_000, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reserve0, reserve1, err := loadReservesFromWord(tt.word)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Expected %v, got %v", tt.wantErr, err)
+			}
+			if err == nil && (reserve0.Int64() != tt.reserve0 || reserve1.Int64() != tt.reserve1) {
+				t.Errorf("Expected reserves %d/%d, got %s/%s", tt.reserve0, tt.reserve1, reserve0, reserve1)
+			}
+		})
+	}
+}
+
+func TestLoadReserves_EmptySlotNeverSynced(t *testing.T) {
+	_, _, err := loadReservesFromWord(nil)
+	if !errors.Is(err, uniswap_v2.ErrPoolNotInitialized) || !strings.Contains(err.Error(), "never synced") {
+		t.Errorf("Expected an empty slot reported as never synced, got %v", err)
+	}
+
+	// A pair that synced to zero reserves still has its timestamp
+	_, _, err = loadReservesFromWord(timestampedReservesWord(0, 0, 1_700_000_000))
+	if !errors.Is(err, uniswap_v2.ErrPoolNotInitialized) || strings.Contains(err.Error(), "never synced") {
+		t.Errorf("Expected a synced empty pair reported as not initialized, got %v", err)
+	}
+}
+
+func TestLoadPoolState_EmptyStorageIsNotFound(t *testing.T) {
+	empty := map[common.Hash][]byte{
+		slotKey(uniswap_v2.UniswapV2Token0StorageSlot):   {},
+		slotKey(uniswap_v2.UniswapV2Token1StorageSlot):   {},
+		slotKey(uniswap_v2.UniswapV2ReservesStorageSlot): {},
+	}
+	_, err := loadPoolState(t, uniswap_v2.NewUniswapV2Client(&fakeEthereumClient{storage: empty}, zap.NewNop()))
+	if !errors.Is(err, uniswap_v2.ErrPoolNotFound) || errors.Is(err, uniswap_v2.ErrInsufficientLiquidity) {
+		t.Errorf("Expected ErrPoolNotFound for an address without a pair, got %v", err)
+	}
+}
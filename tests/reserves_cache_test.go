@@ -0,0 +1,127 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"bigswapenergy/internal/infrastructure/uniswap_v2"
+	"bigswapenergy/internal/presentation/http"
+	"bigswapenergy/internal/shared/clock"
+	"bigswapenergy/internal/shared/metrics"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestReservesCache_HitWithinTTL(t *testing.T) {
+	fake := newFakeUniswapV2Client(big.NewInt(10), big.NewInt(20))
+	fakeClock := clock.NewFakeClock(time.Unix(1_700_000_000, 0))
+	cache := uniswap_v2.NewReservesCachingUniswapV2Client(fake, 300*time.Millisecond, 100, fakeClock)
+	pool := common.HexToAddress(testPool)
+
+	for i := 0; i < 3; i++ {
+		reserve0, reserve1, err := cache.LoadReserves(context.Background(), pool, big.NewInt(100))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if reserve0.Int64() != 10 || reserve1.Int64() != 20 {
+			t.Fatalf("unexpected reserves: %s %s", reserve0, reserve1)
+		}
+	}
+	if fake.reservesCalls != 1 {
+		t.Fatalf("Expected a single underlying read, got %d", fake.reservesCalls)
+	}
+	if stats := cache.Stats(); stats.Hits != 2 || stats.Misses != 1 || stats.Entries != 1 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+
+	fakeClock.Advance(300 * time.Millisecond)
+	cache.LoadReserves(context.Background(), pool, big.NewInt(100))
+	if fake.reservesCalls != 2 {
+		t.Errorf("Expected the entry to expire after the TTL, got %d reads", fake.reservesCalls)
+	}
+}
+
+func TestReservesCache_NewHeadInvalidates(t *testing.T) {
+	fake := newFakeUniswapV2Client(big.NewInt(10), big.NewInt(20))
+	cache := uniswap_v2.NewReservesCachingUniswapV2Client(fake, time.Minute, 100, clock.NewFakeClock(time.Unix(1_700_000_000, 0)))
+	pool := common.HexToAddress(testPool)
+
+	cache.LoadReserves(context.Background(), pool, big.NewInt(100))
+	cache.LoadReserves(context.Background(), pool, big.NewInt(101))
+	if stats := cache.Stats(); stats.Entries != 1 {
+		t.Errorf("Expected the earlier block to be dropped at the new head, got %+v", stats)
+	}
+
+	cache.LoadReserves(context.Background(), pool, big.NewInt(100))
+	cache.LoadReserves(context.Background(), pool, big.NewInt(100))
+	if fake.reservesCalls != 4 {
+		t.Errorf("Expected blocks behind the head never to be cached, got %d reads", fake.reservesCalls)
+	}
+	if fake.reservesBlocks[2] != 100 {
+		t.Errorf("Expected the historical read at its own block, got %v", fake.reservesBlocks)
+	}
+}
+
+func TestReservesCache_LRUBound(t *testing.T) {
+	fake := newFakeUniswapV2Client(big.NewInt(10), big.NewInt(20))
+	cache := uniswap_v2.NewReservesCachingUniswapV2Client(fake, time.Minute, 2, clock.NewFakeClock(time.Unix(1_700_000_000, 0)))
+	poolA := common.HexToAddress(testPool)
+	poolB := common.HexToAddress(testPoolB)
+	poolC := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	block := big.NewInt(100)
+
+	cache.LoadReserves(context.Background(), poolA, block)
+	cache.LoadReserves(context.Background(), poolB, block)
+	cache.LoadReserves(context.Background(), poolA, block) // A is now most recently used
+	cache.LoadReserves(context.Background(), poolC, block) // evicts B
+	if stats := cache.Stats(); stats.Entries != 2 {
+		t.Fatalf("Expected the cache to stay within its bound, got %+v", stats)
+	}
+
+	calls := fake.reservesCalls
+	cache.LoadReserves(context.Background(), poolA, block)
+	if fake.reservesCalls != calls {
+		t.Error("Expected the recently used pool to stay cached")
+	}
+	cache.LoadReserves(context.Background(), poolB, block)
+	if fake.reservesCalls != calls+1 {
+		t.Error("Expected the least recently used pool to be evicted")
+	}
+}
+
+func TestReservesCache_ErrorsNotCached(t *testing.T) {
+	fake := newFakeUniswapV2Client(big.NewInt(10), big.NewInt(20))
+	fake.reservesErr = errors.New("rpc down")
+	cache := uniswap_v2.NewReservesCachingUniswapV2Client(fake, time.Minute, 100, clock.NewFakeClock(time.Unix(1_700_000_000, 0)))
+	pool := common.HexToAddress(testPool)
+
+	if _, _, err := cache.LoadReserves(context.Background(), pool, big.NewInt(100)); err == nil {
+		t.Fatal("Expected the underlying error")
+	}
+	fake.reservesErr = nil
+	if _, _, err := cache.LoadReserves(context.Background(), pool, big.NewInt(100)); err != nil {
+		t.Fatalf("Expected a fresh read after the error, got %v", err)
+	}
+	if fake.reservesCalls != 2 {
+		t.Errorf("Expected the failed read not to be cached, got %d reads", fake.reservesCalls)
+	}
+}
+
+func TestReservesCache_Metrics(t *testing.T) {
+	fake := newFakeUniswapV2Client(big.NewInt(10), big.NewInt(20))
+	cache := uniswap_v2.NewReservesCachingUniswapV2Client(fake, time.Minute, 100, clock.NewFakeClock(time.Unix(1_700_000_000, 0)))
+	registry := metrics.NewRegistry()
+	http.RegisterReservesCacheMetrics(registry, cache)
+
+	pool := common.HexToAddress(testPool)
+	cache.LoadReserves(context.Background(), pool, big.NewInt(100))
+	cache.LoadReserves(context.Background(), pool, big.NewInt(100))
+
+	snapshot := registry.Snapshot()
+	if snapshot[http.MetricReservesCacheHits] != 1 || snapshot[http.MetricReservesCacheMisses] != 1 || snapshot[http.MetricReservesCacheEntries] != 1 {
+		t.Errorf("Unexpected snapshot: %v", snapshot)
+	}
+}
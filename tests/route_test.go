@@ -0,0 +1,180 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	apperrors "bigswapenergy/internal/shared/errors"
+	usecases "bigswapenergy/internal/usecases"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/valyala/fasthttp"
+)
+
+func routeRequest(partial bool) usecases.RouteRequest {
+	return usecases.RouteRequest{
+		Pools:     []string{testPool, testPoolB},
+		Tokens:    []string{testToken0.Hex(), testToken1.Hex(), testToken0.Hex()},
+		SrcAmount: big.NewInt(1000),
+		Partial:   partial,
+	}
+}
+
+func newRouteClient(failPoolB bool) *fakeUniswapV2Client {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	if failPoolB {
+		client.poolReservesErr = map[common.Address]error{
+			common.HexToAddress(testPoolB): errors.New("rpc unavailable"),
+		}
+	}
+	return client
+}
+
+func TestEstimateRoute_Complete(t *testing.T) {
+	client := newRouteClient(false)
+	service := createEstimateService(client)
+
+	result, err := service.EstimateRoute(context.Background(), routeRequest(false))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !result.Complete || len(result.Hops) != 2 {
+		t.Fatalf("Expected a complete 2-hop route, got %+v", result)
+	}
+	if result.Hops[0].AmountOut.Cmp(big.NewInt(996)) != 0 {
+		t.Errorf("Expected first hop output 996, got %s", result.Hops[0].AmountOut)
+	}
+	if result.Hops[1].AmountIn.Cmp(result.Hops[0].AmountOut) != 0 {
+		t.Errorf("Expected second hop to consume the first hop's output")
+	}
+	if result.AmountOut.Cmp(big.NewInt(992)) != 0 {
+		t.Errorf("Expected final output 992, got %s", result.AmountOut)
+	}
+	for _, block := range client.reservesBlocks {
+		if block != result.BlockNumber {
+			t.Errorf("Expected every hop read at block %d, got %d", result.BlockNumber, block)
+		}
+	}
+}
+
+func TestEstimateRoute_MidRouteFailureAllOrNothing(t *testing.T) {
+	service := createEstimateService(newRouteClient(true))
+
+	_, err := service.EstimateRoute(context.Background(), routeRequest(false))
+	if !errors.Is(err, apperrors.ErrExternalService) {
+		t.Errorf("Expected the hop error to fail the request, got %v", err)
+	}
+}
+
+func TestEstimateRoute_MidRouteFailurePartial(t *testing.T) {
+	service := createEstimateService(newRouteClient(true))
+
+	result, err := service.EstimateRoute(context.Background(), routeRequest(true))
+	if err != nil {
+		t.Fatalf("Expected partial result, got error %v", err)
+	}
+
+	if result.Complete || result.AmountOut != nil {
+		t.Errorf("Expected an incomplete route without a final amount, got %+v", result)
+	}
+	if len(result.Hops) != 2 {
+		t.Fatalf("Expected the successful hop plus the failing hop, got %d hops", len(result.Hops))
+	}
+	if result.Hops[0].Err != nil || result.Hops[0].AmountOut.Cmp(big.NewInt(996)) != 0 {
+		t.Errorf("Expected first hop to succeed with 996, got %+v", result.Hops[0])
+	}
+	if !errors.Is(result.Hops[1].Err, apperrors.ErrExternalService) {
+		t.Errorf("Expected failing hop to carry its error, got %v", result.Hops[1].Err)
+	}
+}
+
+func TestEstimateRoute_ValidatesShape(t *testing.T) {
+	service := createEstimateService(newRouteClient(false))
+
+	req := routeRequest(true)
+	req.Tokens = req.Tokens[:2]
+	if _, err := service.EstimateRoute(context.Background(), req); !errors.Is(err, apperrors.ErrValidation) {
+		t.Errorf("Expected validation error for mismatched tokens, got %v", err)
+	}
+
+	req = routeRequest(false)
+	req.Pools = nil
+	if _, err := service.EstimateRoute(context.Background(), req); !errors.Is(err, apperrors.ErrValidation) {
+		t.Errorf("Expected validation error for empty route, got %v", err)
+	}
+}
+
+func TestEstimateRouteHandler_PartialResponse(t *testing.T) {
+	mockService := &mockEstimateService{
+		routeResult: &usecases.RouteResult{
+			Hops: []usecases.RouteHop{
+				{AmountIn: big.NewInt(1000), AmountOut: big.NewInt(996)},
+				{AmountIn: big.NewInt(996), Err: errors.New("hop 1: rpc unavailable")},
+			},
+		},
+	}
+	handler := createEstimateHandler(mockService)
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	req.SetRequestURI("/estimate/route?pool=0x1&pool=0x2&token=0xa&token=0xb&token=0xa&src_amount=1000&partial=true")
+	req.Header.SetMethod("GET")
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(req, nil, nil)
+	handler.EstimateRoute(ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d", ctx.Response.StatusCode())
+	}
+	if !mockService.lastRoute.Partial || len(mockService.lastRoute.Pools) != 2 || len(mockService.lastRoute.Tokens) != 3 {
+		t.Errorf("Unexpected parsed request: %+v", mockService.lastRoute)
+	}
+
+	var body struct {
+		Complete bool `json:"complete"`
+		Hops     []struct {
+			AmountOut string `json:"amount_out"`
+			Error     string `json:"error"`
+		} `json:"hops"`
+	}
+	if err := json.Unmarshal(ctx.Response.Body(), &body); err != nil {
+		t.Fatalf("Expected JSON body, got %q", ctx.Response.Body())
+	}
+	if body.Complete || len(body.Hops) != 2 || body.Hops[0].AmountOut != "996" || body.Hops[1].Error == "" {
+		t.Errorf("Unexpected response body: %s", ctx.Response.Body())
+	}
+}
+
+func TestEstimateSwapAmountPath(t *testing.T) {
+	service := createEstimateService(newRouteClient(false))
+	req := routeRequest(false)
+
+	amountOut, hopAmounts, err := service.EstimateSwapAmountPath(context.Background(), req.Pools, req.Tokens, req.SrcAmount)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if amountOut.Cmp(big.NewInt(992)) != 0 {
+		t.Errorf("Expected final output 992, got %s", amountOut)
+	}
+	if len(hopAmounts) != 2 || hopAmounts[0].Cmp(big.NewInt(996)) != 0 || hopAmounts[1].Cmp(amountOut) != 0 {
+		t.Errorf("Expected hop outputs [996 992], got %v", hopAmounts)
+	}
+
+	if _, _, err := service.EstimateSwapAmountPath(context.Background(), nil, []string{testToken0.Hex()}, req.SrcAmount); !errors.Is(err, apperrors.ErrValidation) {
+		t.Errorf("Expected validation error for an empty path, got %v", err)
+	}
+	if _, _, err := service.EstimateSwapAmountPath(context.Background(), req.Pools, req.Tokens[:2], req.SrcAmount); !errors.Is(err, apperrors.ErrValidation) {
+		t.Errorf("Expected validation error when len(pools) != len(tokens)-1, got %v", err)
+	}
+
+	// A failing hop fails the whole path rather than returning partial amounts
+	_, _, err = createEstimateService(newRouteClient(true)).EstimateSwapAmountPath(context.Background(), req.Pools, req.Tokens, req.SrcAmount)
+	if err == nil {
+		t.Error("Expected a failing hop to fail the path")
+	}
+}
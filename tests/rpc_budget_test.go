@@ -0,0 +1,137 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"bigswapenergy/internal/infrastructure/ethereum"
+	"bigswapenergy/internal/infrastructure/uniswap_v2"
+	"bigswapenergy/internal/presentation/http"
+	"bigswapenergy/internal/shared/config"
+	"bigswapenergy/internal/shared/rpcbudget"
+	usecases "bigswapenergy/internal/usecases"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// createBudgetedService builds a service over the real Uniswap V2 client, reading
+// storage from memory through the budget-enforcing Ethereum client
+func createBudgetedService() usecases.EstimateService {
+	eth := ethereum.NewBudgetedEthereumClient(&fakeEthereumClient{
+		storage: map[common.Hash][]byte{
+			common.BigToHash(big.NewInt(uniswap_v2.UniswapV2Token0StorageSlot)):   common.LeftPadBytes(testToken0.Bytes(), 32),
+			common.BigToHash(big.NewInt(uniswap_v2.UniswapV2Token1StorageSlot)):   common.LeftPadBytes(testToken1.Bytes(), 32),
+			common.BigToHash(big.NewInt(uniswap_v2.UniswapV2ReservesStorageSlot)): reservesWord(1_000_000_000, 1_000_000_000),
+		},
+	})
+	return usecases.NewEstimateService(uniswap_v2.NewUniswapV2Client(eth, zap.NewNop()), nil, zap.NewNop())
+}
+
+// longRoute bounces between token0 and token1 for hops hops. Each hop costs three
+// calls (token0, token1, reserves) on top of the one block number lookup.
+func longRoute(hops int) usecases.RouteRequest {
+	req := usecases.RouteRequest{SrcAmount: big.NewInt(1000), Tokens: []string{testToken0.Hex()}}
+	for i := 0; i < hops; i++ {
+		req.Pools = append(req.Pools, testPool)
+		if i%2 == 0 {
+			req.Tokens = append(req.Tokens, testToken1.Hex())
+		} else {
+			req.Tokens = append(req.Tokens, testToken0.Hex())
+		}
+	}
+	return req
+}
+
+func TestRPCBudget_WithinLimit(t *testing.T) {
+	budget := rpcbudget.New(13)
+	ctx := rpcbudget.WithBudget(context.Background(), budget)
+
+	if _, err := createBudgetedService().EstimateRoute(ctx, longRoute(4)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if budget.Used() != 13 || budget.Exceeded() {
+		t.Errorf("Expected exactly 13 calls within budget, got %d", budget.Used())
+	}
+}
+
+func TestRPCBudget_LongRouteExceeds(t *testing.T) {
+	budget := rpcbudget.New(10)
+	ctx := rpcbudget.WithBudget(context.Background(), budget)
+
+	if _, err := createBudgetedService().EstimateRoute(ctx, longRoute(4)); err == nil {
+		t.Fatal("Expected the route to fail once the budget is spent")
+	}
+	if !budget.Exceeded() {
+		t.Errorf("Expected the budget to be exceeded, used %d of %d", budget.Used(), budget.Limit())
+	}
+	// The failing call stops the route, so no further calls are attempted
+	if budget.Used() != 11 {
+		t.Errorf("Expected the route to stop at the first refused call, got %d calls", budget.Used())
+	}
+}
+
+func TestRPCBudget_NoBudgetIsUnlimited(t *testing.T) {
+	if _, err := createBudgetedService().EstimateRoute(context.Background(), longRoute(10)); err != nil {
+		t.Fatalf("Expected no error without a budget, got %v", err)
+	}
+}
+
+func TestRPCBudgetHandler_BusinessRuleError(t *testing.T) {
+	cfg := &config.Config{Server: config.ServerConfig{MaxRPCCallsPerRequest: 10}}
+	handler := http.NewEstimateHandler(createBudgetedService(), zap.NewNop(), cfg)
+
+	route := longRoute(4)
+	query := "src_amount=1000"
+	for _, pool := range route.Pools {
+		query += "&pool=" + pool
+	}
+	for _, token := range route.Tokens {
+		query += "&token=" + token
+	}
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	req.SetRequestURI("/estimate/route?" + query)
+	req.Header.SetMethod("GET")
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(req, nil, nil)
+	handler.EstimateRoute(ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	var body map[string]map[string]string
+	if err := json.Unmarshal(ctx.Response.Body(), &body); err != nil {
+		t.Fatalf("Expected JSON body, got %q", ctx.Response.Body())
+	}
+	if body["error"]["code"] != "BUSINESS_RULE_VIOLATION" {
+		t.Errorf("Expected BUSINESS_RULE_VIOLATION, got %s", ctx.Response.Body())
+	}
+}
+
+func TestRPCBudgetHandler_WithinBudget(t *testing.T) {
+	cfg := &config.Config{Server: config.ServerConfig{MaxRPCCallsPerRequest: 10}}
+	handler := http.NewEstimateHandler(createBudgetedService(), zap.NewNop(), cfg)
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	req.SetRequestURI("/estimate?pool=" + testPool + "&src=" + testToken0.Hex() + "&dst=" + testToken1.Hex() + "&src_amount=1000")
+	req.Header.SetMethod("GET")
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(req, nil, nil)
+	handler.EstimateSwapAmount(ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	if strings.TrimSpace(string(ctx.Response.Body())) != "996" {
+		t.Errorf("Expected 996, got %s", ctx.Response.Body())
+	}
+}
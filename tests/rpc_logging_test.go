@@ -0,0 +1,73 @@
+package tests
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"bigswapenergy/internal/infrastructure/ethereum"
+	"bigswapenergy/internal/shared/logger"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLoggingEthereumClient_LogsEachCall(t *testing.T) {
+	// Info level: the client must still emit its Debug entries once enabled
+	core, logs := observer.New(zapcore.InfoLevel)
+	eth := &fakeEthereumClient{storage: map[common.Hash][]byte{{31: 8}: reservesWord(1_000, 2_000)}}
+	client := ethereum.NewLoggingEthereumClient(eth, zap.New(core))
+
+	if _, err := client.GetLatestBlockNumber(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pool := common.HexToAddress(testPool)
+	if _, err := client.ReadContractStorage(context.Background(), pool, common.Hash{31: 8}, big.NewInt(19_999_999)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client.ReadContractStorage(context.Background(), pool, common.Hash{31: 6}, big.NewInt(19_999_999))
+
+	entries := logs.FilterMessage("RPC call").All()
+	if len(entries) != 3 {
+		t.Fatalf("Expected one entry per call, got %d", len(entries))
+	}
+
+	block := entries[0].ContextMap()
+	if block["method"] != "eth_blockNumber" || block["block"] != uint64(20_000_000) {
+		t.Errorf("Unexpected block number fields: %v", block)
+	}
+	if _, ok := block["latency"]; !ok {
+		t.Errorf("Expected a latency field, got %v", block)
+	}
+
+	storage := entries[1].ContextMap()
+	if storage["method"] != "eth_getStorageAt" || storage["address"] != testPool ||
+		storage["slot"] != (common.Hash{31: 8}).Hex() || storage["block"] != "19999999" {
+		t.Errorf("Unexpected storage fields: %v", storage)
+	}
+	if _, ok := storage["latency"]; !ok {
+		t.Errorf("Expected a latency field, got %v", storage)
+	}
+	if _, ok := storage["error"]; ok {
+		t.Errorf("Expected no error field on a successful call, got %v", storage)
+	}
+
+	if failed := entries[2].ContextMap(); failed["error"] == nil {
+		t.Errorf("Expected the failed read to log its error, got %v", failed)
+	}
+}
+
+func TestLoggingEthereumClient_UsesRequestLogger(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	requestLog := zap.New(core).With(zap.String("request_id", "abc"))
+	client := ethereum.NewLoggingEthereumClient(&fakeEthereumClient{}, zap.NewNop())
+
+	client.GetLatestBlockNumber(logger.WithContext(context.Background(), requestLog))
+
+	entries := logs.FilterMessage("RPC call").All()
+	if len(entries) != 1 || entries[0].ContextMap()["request_id"] != "abc" {
+		t.Fatalf("Expected the entry to carry the request's fields, got %v", entries)
+	}
+}
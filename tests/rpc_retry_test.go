@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"bigswapenergy/internal/infrastructure/ethereum"
+	"bigswapenergy/internal/shared/config"
+	"bigswapenergy/internal/shared/rpcbudget"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+// failingThenOKClient fails its first storage reads, up to failures, with err
+type failingThenOKClient struct {
+	fakeEthereumClient
+	err      error
+	failures int
+	calls    int
+}
+
+func (f *failingThenOKClient) ReadContractStorage(ctx context.Context, contractAddress common.Address, storageKey common.Hash, blockNumber *big.Int) ([]byte, error) {
+	f.calls++
+	if f.calls <= f.failures {
+		return nil, f.err
+	}
+	return []byte{1}, nil
+}
+
+var testRetryPolicy = config.RetryConfig{MaxAttempts: 3, BaseDelay: time.Millisecond, Jitter: 0.2}
+
+func readWithRetry(ctx context.Context, inner ethereum.EthereumClient, policy config.RetryConfig) error {
+	client := ethereum.NewRetryingEthereumClient(inner, policy, zap.NewNop())
+	_, err := client.ReadContractStorage(ctx, common.HexToAddress(testPool), common.Hash{}, nil)
+	return err
+}
+
+func TestRetryingEthereumClient_RetriesTransientErrors(t *testing.T) {
+	for _, transient := range []error{ethereum.ErrConnectionFailed, ethereum.ErrRPCTimeout} {
+		inner := &failingThenOKClient{err: transient, failures: 2}
+		if err := readWithRetry(context.Background(), inner, testRetryPolicy); err != nil {
+			t.Errorf("%v: expected the third attempt to succeed, got %v", transient, err)
+		}
+		if inner.calls != 3 {
+			t.Errorf("%v: expected 3 attempts, got %d", transient, inner.calls)
+		}
+	}
+}
+
+func TestRetryingEthereumClient_StopsAtMaxAttempts(t *testing.T) {
+	inner := &failingThenOKClient{err: ethereum.ErrConnectionFailed, failures: 10}
+	if err := readWithRetry(context.Background(), inner, testRetryPolicy); !errors.Is(err, ethereum.ErrConnectionFailed) {
+		t.Errorf("Expected the last error, got %v", err)
+	}
+	if inner.calls != 3 {
+		t.Errorf("Expected 3 attempts, got %d", inner.calls)
+	}
+}
+
+func TestRetryingEthereumClient_PermanentErrorsNotRetried(t *testing.T) {
+	for _, permanent := range []error{ethereum.ErrStorageReadFailed, rpcbudget.ErrExceeded} {
+		inner := &failingThenOKClient{err: permanent, failures: 1}
+		if err := readWithRetry(context.Background(), inner, testRetryPolicy); !errors.Is(err, permanent) {
+			t.Errorf("Expected %v, got %v", permanent, err)
+		}
+		if inner.calls != 1 {
+			t.Errorf("%v: expected a single attempt, got %d", permanent, inner.calls)
+		}
+	}
+}
+
+func TestRetryingEthereumClient_RespectsDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	inner := &failingThenOKClient{err: ethereum.ErrConnectionFailed, failures: 10}
+
+	start := time.Now()
+	err := readWithRetry(ctx, inner, config.RetryConfig{MaxAttempts: 3, BaseDelay: time.Second})
+	if !errors.Is(err, ethereum.ErrConnectionFailed) || inner.calls != 1 {
+		t.Errorf("Expected no retry past the deadline, got %v after %d attempts", err, inner.calls)
+	}
+	if elapsed := time.Since(start); elapsed > 40*time.Millisecond {
+		t.Errorf("Expected to give up without waiting, took %v", elapsed)
+	}
+}
+
+func TestOptimizedEthereumClient_UnreachableNodeIsConnectionFailure(t *testing.T) {
+	var batches [][]jsonRPCRequest
+	server := newStorageRPCServer(t, nil, &batches)
+	eth, err := ethereum.NewEthereumClient(server.URL, zap.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer eth.Close()
+	server.Close()
+
+	if _, err := eth.ReadContractStorage(context.Background(), common.HexToAddress(testPool), common.Hash{}, nil); !errors.Is(err, ethereum.ErrConnectionFailed) {
+		t.Errorf("Expected ErrConnectionFailed for an unreachable node, got %v", err)
+	}
+	if _, err := eth.CallContract(context.Background(), common.HexToAddress(testPool), nil, nil); !errors.Is(err, ethereum.ErrConnectionFailed) {
+		t.Errorf("Expected ErrConnectionFailed from eth_call to an unreachable node, got %v", err)
+	}
+}
+
+func TestLoadConfig_RetryPolicy(t *testing.T) {
+	t.Setenv("ETHEREUM_RPC_URL", "http://localhost:8545")
+
+	cfg, err := config.LoadConfig("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Blockchain.Retry.MaxAttempts != 1 {
+		t.Errorf("Expected retries off by default, got %+v", cfg.Blockchain.Retry)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("blockchain:\n  retry:\n    max_attempts: 0\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if _, err := config.LoadConfig(path); err == nil {
+		t.Error("Expected an error for zero max attempts")
+	}
+}
@@ -0,0 +1,188 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"bigswapenergy/internal/presentation/http"
+	"bigswapenergy/internal/shared/config"
+	apperrors "bigswapenergy/internal/shared/errors"
+	"bigswapenergy/internal/shared/utils"
+	usecases "bigswapenergy/internal/usecases"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+func TestEstimateService_ShowMath(t *testing.T) {
+	service := createEstimateService(newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000)))
+
+	result, err := service.EstimateSwap(context.Background(), usecases.EstimateRequest{
+		PoolAddress: testPool,
+		SrcToken:    testToken0.Hex(),
+		DstToken:    testToken1.Hex(),
+		SrcAmount:   big.NewInt(1_000),
+		ShowMath:    true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Math == nil {
+		t.Fatalf("Expected math intermediates")
+	}
+
+	// amountInWithFee = 1000 * 9970; numerator = 9970000 * 1e6; denominator = 1e6 * 10000 + 9970000
+	checks := map[string][2]*big.Int{
+		"amountInWithFee": {result.Math.AmountInWithFee, big.NewInt(9_970_000)},
+		"numerator":       {result.Math.Numerator, big.NewInt(9_970_000_000_000)},
+		"denominator":     {result.Math.Denominator, big.NewInt(10_009_970_000)},
+	}
+	for name, pair := range checks {
+		if pair[0].Cmp(pair[1]) != 0 {
+			t.Errorf("Expected %s %s, got %s", name, pair[1], pair[0])
+		}
+	}
+	if result.AmountOut.Cmp(big.NewInt(996)) != 0 {
+		t.Errorf("Expected 996, got %s", result.AmountOut)
+	}
+}
+
+func TestEstimateService_ShowMathIncludesProtocolCut(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000_000), big.NewInt(1_000_000_000))
+	service := usecases.NewEstimateService(client, cutFactoryRegistry(t), zap.NewNop())
+
+	result, err := service.EstimateSwap(context.Background(), usecases.EstimateRequest{
+		Factory:   "cutswap",
+		SrcToken:  testToken0.Hex(),
+		DstToken:  testToken1.Hex(),
+		SrcAmount: big.NewInt(1_000_000),
+		ShowMath:  true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Math == nil || result.Math.AmountOutBeforeCut == nil || result.Math.ProtocolCut == nil {
+		t.Fatalf("Expected the protocol cut step, got %+v", result.Math)
+	}
+
+	// numerator / denominator = 996_006; the protocol takes 996_006 * 30 / 60000 = 498
+	beforeCut := new(big.Int).Quo(result.Math.Numerator, result.Math.Denominator)
+	if beforeCut.Cmp(result.Math.AmountOutBeforeCut) != 0 || beforeCut.Cmp(big.NewInt(996_006)) != 0 {
+		t.Errorf("Expected amountOutBeforeCut 996006 = numerator / denominator, got %s", result.Math.AmountOutBeforeCut)
+	}
+	if result.Math.ProtocolCut.Cmp(big.NewInt(498)) != 0 {
+		t.Errorf("Expected a protocol cut of 498, got %s", result.Math.ProtocolCut)
+	}
+	if net := new(big.Int).Sub(beforeCut, result.Math.ProtocolCut); net.Cmp(result.AmountOut) != 0 {
+		t.Errorf("Expected the math to reproduce amountOut %s, got %s", result.AmountOut, net)
+	}
+}
+
+func TestEstimateService_ShowMathRejectsFeeOnOutput(t *testing.T) {
+	service := createEstimateService(newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000)))
+
+	_, err := service.EstimateSwap(context.Background(), usecases.EstimateRequest{
+		PoolAddress: testPool,
+		SrcToken:    testToken0.Hex(),
+		DstToken:    testToken1.Hex(),
+		SrcAmount:   big.NewInt(1_000),
+		FeeSide:     utils.FeeOnOutput,
+		ShowMath:    true,
+	})
+	if !errors.Is(err, apperrors.ErrValidation) {
+		t.Fatalf("Expected ErrValidation, got %v", err)
+	}
+}
+
+func runShowMath(t *testing.T, debug bool, service usecases.EstimateService) *fasthttp.RequestCtx {
+	t.Helper()
+	cfg := &config.Config{
+		RateLimit: config.RateLimitConfig{RequestsPerMinute: 100},
+		Debug:     config.DebugConfig{Enabled: debug},
+	}
+	handler := http.NewEstimateHandler(service, zap.NewNop(), cfg)
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	req.SetRequestURI("/estimate?pool=0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc&src=0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48&dst=0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2&src_amount=1000&show_math=true")
+	req.Header.SetMethod("GET")
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(req, nil, nil)
+	handler.EstimateSwapAmount(ctx)
+	return ctx
+}
+
+func TestEstimateHandler_ShowMathRequiresDebug(t *testing.T) {
+	service := &mockEstimateService{estimateAmount: big.NewInt(996)}
+
+	ctx := runShowMath(t, false, service)
+	if ctx.Response.StatusCode() == fasthttp.StatusOK {
+		t.Fatalf("Expected show_math to be rejected outside debug mode")
+	}
+	if service.lastRequest.ShowMath {
+		t.Errorf("Expected the service not to be asked for math")
+	}
+}
+
+func TestEstimateHandler_ShowMathResponse(t *testing.T) {
+	ctx := runShowMath(t, true, &showMathService{})
+
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	var resp http.SwapMathResponse
+	if err := json.Unmarshal(ctx.Response.Body(), &resp); err != nil {
+		t.Fatalf("invalid JSON response: %v", err)
+	}
+	if resp.AmountOut != "996" || resp.Math.AmountInWithFee != "9970000" ||
+		resp.Math.Numerator != "9970000000000" || resp.Math.Denominator != "10009970000" {
+		t.Errorf("Unexpected show_math response: %s", ctx.Response.Body())
+	}
+	if resp.Math.AmountOutBeforeCut != "" || resp.Math.ProtocolCut != "" {
+		t.Errorf("Expected no protocol cut step without a cut, got %s", ctx.Response.Body())
+	}
+}
+
+func TestEstimateHandler_ShowMathResponseWithProtocolCut(t *testing.T) {
+	service := &showMathService{cut: true}
+	ctx := runShowMath(t, true, service)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	var resp http.SwapMathResponse
+	if err := json.Unmarshal(ctx.Response.Body(), &resp); err != nil {
+		t.Fatalf("invalid JSON response: %v", err)
+	}
+	if resp.AmountOut != "995" || resp.Math.AmountOutBeforeCut != "996" || resp.Math.ProtocolCut != "1" {
+		t.Errorf("Unexpected show_math response: %s", ctx.Response.Body())
+	}
+}
+
+// showMathService returns fixed math intermediates for a ShowMath request, with
+// a protocol cut of 1 when cut is set
+type showMathService struct {
+	mockEstimateService
+	cut bool
+}
+
+func (s *showMathService) EstimateSwap(ctx context.Context, req usecases.EstimateRequest) (*usecases.EstimateResult, error) {
+	result := &usecases.EstimateResult{AmountOut: big.NewInt(996)}
+	if req.ShowMath {
+		result.Math = &utils.SwapMath{
+			AmountInWithFee: big.NewInt(9_970_000),
+			Numerator:       big.NewInt(9_970_000_000_000),
+			Denominator:     big.NewInt(10_009_970_000),
+		}
+		if s.cut {
+			result.AmountOut = big.NewInt(995)
+			result.Math.AmountOutBeforeCut = big.NewInt(996)
+			result.Math.ProtocolCut = big.NewInt(1)
+		}
+	}
+	return result, nil
+}
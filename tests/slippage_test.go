@@ -0,0 +1,116 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	apperrors "bigswapenergy/internal/shared/errors"
+	"bigswapenergy/internal/shared/utils"
+	usecases "bigswapenergy/internal/usecases"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestMinAmountOut(t *testing.T) {
+	for _, tc := range []struct {
+		amountOut int64
+		bps       int
+		want      int64
+	}{
+		{996, 0, 996},
+		{996, 50, 991},
+		{10_000, 50, 9_950},
+		{996, 10000, 0},
+		{1, 1, 0},
+	} {
+		if got := utils.MinAmountOut(big.NewInt(tc.amountOut), tc.bps); got.Cmp(big.NewInt(tc.want)) != 0 {
+			t.Errorf("%d at %d bps: expected %d, got %s", tc.amountOut, tc.bps, tc.want, got)
+		}
+	}
+}
+
+func TestEstimateService_SlippageBps(t *testing.T) {
+	service := createEstimateService(newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000)))
+	slippage := 50
+	req := usecases.EstimateRequest{
+		PoolAddress: testPool,
+		SrcToken:    testToken0.Hex(),
+		DstToken:    testToken1.Hex(),
+		SrcAmount:   big.NewInt(10_000),
+		SlippageBps: &slippage,
+	}
+
+	result, err := service.EstimateSwap(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := utils.MinAmountOut(result.AmountOut, slippage); result.AmountOutMin == nil || result.AmountOutMin.Cmp(want) != 0 {
+		t.Errorf("Expected amount_out_min %s, got %v", want, result.AmountOutMin)
+	}
+
+	req.SlippageBps = nil
+	if result, err = service.EstimateSwap(context.Background(), req); err != nil || result.AmountOutMin != nil {
+		t.Errorf("Expected no minimum without slippage_bps, got %v (%v)", result.AmountOutMin, err)
+	}
+}
+
+func TestEstimateService_SlippageBpsValidation(t *testing.T) {
+	service := createEstimateService(newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000)))
+	base := usecases.EstimateRequest{PoolAddress: testPool, SrcToken: testToken0.Hex(), DstToken: testToken1.Hex()}
+
+	for _, tc := range []struct {
+		name      string
+		slippage  int
+		amount    *big.Int
+		amounts   []*big.Int
+		dstAmount *big.Int
+	}{
+		{name: "negative", slippage: -1, amount: big.NewInt(1_000)},
+		{name: "above_10000", slippage: 10001, amount: big.NewInt(1_000)},
+		{name: "amount_list", slippage: 50, amounts: []*big.Int{big.NewInt(1_000), big.NewInt(2_000)}},
+		{name: "exact_out", slippage: 50, dstAmount: big.NewInt(1_000)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := base
+			req.SrcAmount, req.SrcAmounts, req.DstAmount = tc.amount, tc.amounts, tc.dstAmount
+			req.SlippageBps = &tc.slippage
+			if _, err := service.EstimateSwap(context.Background(), req); !errors.Is(err, apperrors.ErrValidation) {
+				t.Errorf("Expected ErrValidation, got %v", err)
+			}
+		})
+	}
+}
+
+func TestEstimateHandler_SlippageBps(t *testing.T) {
+	handler := createEstimateHandler(createEstimateService(newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))))
+	base := "/estimate?pool=" + testPool + "&src=" + testToken0.Hex() + "&dst=" + testToken1.Hex() + "&src_amount=10000"
+	uri := base + "&slippage_bps=50"
+
+	ctx := runQuery(handler.EstimateSwapAmount, uri+"&format=json")
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	var resp map[string]any
+	if err := json.Unmarshal(ctx.Response.Body(), &resp); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	amountOut, _ := new(big.Int).SetString(resp["amount_out"].(string), 10)
+	if want := utils.MinAmountOut(amountOut, 50).String(); resp["amount_out_min"] != want {
+		t.Errorf("Expected amount_out_min %s, got %v", want, resp)
+	}
+
+	ctx = runQuery(handler.EstimateSwapAmount, uri)
+	if got := string(ctx.Response.Body()); got != amountOut.String() {
+		t.Errorf("Expected the plain-text body to be only the estimate %s, got %q", amountOut, got)
+	}
+
+	for _, value := range []string{"10001", "-1", "abc"} {
+		ctx = runQuery(handler.EstimateSwapAmount, base+"&slippage_bps="+value)
+		if ctx.Response.StatusCode() != fasthttp.StatusBadRequest {
+			t.Errorf("slippage_bps=%s: expected status 400, got %d", value, ctx.Response.StatusCode())
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"bigswapenergy/internal/infrastructure/uniswap_v2"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+// countingEthereumClient counts storage reads on top of fakeEthereumClient
+type countingEthereumClient struct {
+	fakeEthereumClient
+	reads int
+}
+
+func (c *countingEthereumClient) ReadContractStorage(ctx context.Context, contractAddress common.Address, storageKey common.Hash, blockNumber *big.Int) ([]byte, error) {
+	c.reads++
+	return c.fakeEthereumClient.ReadContractStorage(ctx, contractAddress, storageKey, blockNumber)
+}
+
+// syncedReservesWord is reservesWord with blockTimestampLast set, as in a synced pair
+func syncedReservesWord(reserve0, reserve1 int64) []byte {
+	word := new(big.Int).SetBytes(reservesWord(reserve0, reserve1))
+	word.Or(word, new(big.Int).Lsh(big.NewInt(1_700_000_000), 224))
+	return common.LeftPadBytes(word.Bytes(), 32)
+}
+
+func slotKey(slot int64) common.Hash {
+	return common.BigToHash(big.NewInt(slot))
+}
+
+// forkStorage lays out a pair with tokens at slots 6/7 but reserves at reservesSlot;
+// every other probed slot is zero
+func forkStorage(reservesSlot int64) map[common.Hash][]byte {
+	storage := make(map[common.Hash][]byte)
+	for slot := int64(0); slot <= 15; slot++ {
+		storage[slotKey(slot)] = make([]byte, 32)
+	}
+	storage[slotKey(6)] = common.LeftPadBytes(testToken0.Bytes(), 32)
+	storage[slotKey(7)] = common.LeftPadBytes(testToken1.Bytes(), 32)
+	storage[slotKey(reservesSlot)] = syncedReservesWord(4_000, 9_000)
+	return storage
+}
+
+func TestSlotDetection_FindsNonDefaultSlotAndCachesIt(t *testing.T) {
+	eth := &countingEthereumClient{fakeEthereumClient: fakeEthereumClient{storage: forkStorage(12)}}
+	client := uniswap_v2.NewSlotDetectingUniswapV2Client(uniswap_v2.NewUniswapV2Client(eth, zap.NewNop()), 15, zap.NewNop())
+	pool := common.HexToAddress(testPool)
+
+	reserve0, reserve1, err := client.LoadReserves(context.Background(), pool, big.NewInt(1))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reserve0.Cmp(big.NewInt(4_000)) != 0 || reserve1.Cmp(big.NewInt(9_000)) != 0 {
+		t.Fatalf("Expected reserves 4000/9000, got %s/%s", reserve0, reserve1)
+	}
+
+	eth.reads = 0
+	if _, _, err := client.LoadReserves(context.Background(), pool, big.NewInt(2)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if eth.reads != 1 {
+		t.Errorf("Expected the detected slot to be cached (1 read), got %d reads", eth.reads)
+	}
+}
+
+func TestSlotDetection_DefaultSlotNeedsOneRead(t *testing.T) {
+	eth := &countingEthereumClient{fakeEthereumClient: fakeEthereumClient{storage: forkStorage(uniswap_v2.UniswapV2ReservesStorageSlot)}}
+	client := uniswap_v2.NewSlotDetectingUniswapV2Client(uniswap_v2.NewUniswapV2Client(eth, zap.NewNop()), 15, zap.NewNop())
+
+	if _, _, err := client.LoadReserves(context.Background(), common.HexToAddress(testPool), big.NewInt(1)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if eth.reads != 1 {
+		t.Errorf("Expected a single read for a standard pair, got %d", eth.reads)
+	}
+}
+
+func TestSlotDetection_SkipsTokenAddressSlots(t *testing.T) {
+	// Reserves beyond the probe range: the token slots must not be mistaken for reserves
+	storage := forkStorage(20)
+	client := uniswap_v2.NewSlotDetectingUniswapV2Client(
+		uniswap_v2.NewUniswapV2Client(&fakeEthereumClient{storage: storage}, zap.NewNop()), 15, zap.NewNop())
+
+	_, _, err := client.LoadReserves(context.Background(), common.HexToAddress(testPool), big.NewInt(1))
+	if !errors.Is(err, uniswap_v2.ErrPoolNotInitialized) {
+		t.Fatalf("Expected fallback to the default slot (ErrPoolNotInitialized), got %v", err)
+	}
+}
+
+func TestSlotDetection_UndetectedPoolIsProbedAgain(t *testing.T) {
+	storage := forkStorage(12)
+	storage[slotKey(12)] = make([]byte, 32)
+	client := uniswap_v2.NewSlotDetectingUniswapV2Client(
+		uniswap_v2.NewUniswapV2Client(&fakeEthereumClient{storage: storage}, zap.NewNop()), 15, zap.NewNop())
+	pool := common.HexToAddress(testPool)
+
+	if _, _, err := client.LoadReserves(context.Background(), pool, big.NewInt(1)); err == nil {
+		t.Fatalf("Expected an error for an unfunded pool")
+	}
+
+	// Once funded, the pool's real slot is found
+	storage[slotKey(12)] = syncedReservesWord(4_000, 9_000)
+	reserve0, _, err := client.LoadReserves(context.Background(), pool, big.NewInt(2))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reserve0.Cmp(big.NewInt(4_000)) != 0 {
+		t.Errorf("Expected reserve0 4000, got %s", reserve0)
+	}
+}
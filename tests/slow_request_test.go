@@ -0,0 +1,69 @@
+package tests
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"bigswapenergy/internal/presentation/http"
+	"bigswapenergy/internal/shared/config"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func runEstimateWithThreshold(t *testing.T, threshold time.Duration) *observer.ObservedLogs {
+	t.Helper()
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	cfg := &config.Config{
+		Server:    config.ServerConfig{SlowRequestThreshold: threshold},
+		RateLimit: config.RateLimitConfig{RequestsPerMinute: 100},
+	}
+	handler := http.NewEstimateHandler(&mockEstimateService{estimateAmount: big.NewInt(996)}, zap.New(core), cfg)
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	req.SetRequestURI("/estimate?pool=0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc&src=0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48&dst=0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2&src_amount=1000")
+	req.Header.SetMethod("GET")
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(req, nil, nil)
+	handler.EstimateSwapAmount(ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d", ctx.Response.StatusCode())
+	}
+	return logs
+}
+
+func TestSlowRequest_LogsWarnWithPhases(t *testing.T) {
+	logs := runEstimateWithThreshold(t, time.Nanosecond)
+
+	warnings := logs.FilterLevelExact(zapcore.WarnLevel).All()
+	if len(warnings) != 1 {
+		t.Fatalf("Expected 1 warning, got %d", len(warnings))
+	}
+	phases, ok := warnings[0].ContextMap()["phases"].(map[string]any)
+	if !ok {
+		t.Fatalf("Expected phase timings on slow request log, got %v", warnings[0].ContextMap())
+	}
+	for _, phase := range []string{"parse", "estimate"} {
+		if _, ok := phases[phase]; !ok {
+			t.Errorf("Expected %q phase timing, got %v", phase, phases)
+		}
+	}
+}
+
+func TestSlowRequest_FastRequestLogsInfo(t *testing.T) {
+	logs := runEstimateWithThreshold(t, time.Hour)
+
+	if n := logs.FilterLevelExact(zapcore.WarnLevel).Len(); n != 0 {
+		t.Errorf("Expected no warnings, got %d", n)
+	}
+	if n := logs.FilterMessage("Estimate completed").FilterLevelExact(zapcore.InfoLevel).Len(); n != 1 {
+		t.Errorf("Expected 1 info completion log, got %d", n)
+	}
+}
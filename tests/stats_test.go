@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"bigswapenergy/internal/presentation/http"
+	"bigswapenergy/internal/shared/metrics"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+func TestRecoveryMiddleware_CountsPanics(t *testing.T) {
+	before := metrics.Global.Counter(http.MetricPanicsRecovered).Value()
+
+	handler := http.NewRecoveryMiddleware(zap.NewNop()).Apply(func(ctx *fasthttp.RequestCtx) {
+		panic("boom")
+	})
+
+	if status := doRequest(handler, ""); status != fasthttp.StatusInternalServerError {
+		t.Fatalf("Expected status %d, got %d", fasthttp.StatusInternalServerError, status)
+	}
+
+	if after := metrics.Global.Counter(http.MetricPanicsRecovered).Value(); after != before+1 {
+		t.Errorf("Expected panic counter to increase by one, got %d -> %d", before, after)
+	}
+}
+
+func TestHandleError_CountsUnmappedErrors(t *testing.T) {
+	before := metrics.Global.Counter(http.MetricUnmappedErrors).Value()
+
+	mockService := &mockEstimateService{
+		estimateAmount: big.NewInt(996),
+		estimateError:  errors.New("unexpected failure"),
+	}
+	handler := createEstimateHandler(mockService)
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	req.SetRequestURI("/estimate?pool=0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc&src=0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48&dst=0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2&src_amount=1000")
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(req, nil, nil)
+	handler.EstimateSwapAmount(ctx)
+
+	if after := metrics.Global.Counter(http.MetricUnmappedErrors).Value(); after != before+1 {
+		t.Errorf("Expected unmapped error counter to increase by one, got %d -> %d", before, after)
+	}
+}
+
+func TestStatsHandler_ExposesCounters(t *testing.T) {
+	registry := metrics.NewRegistry()
+	registry.Counter(http.MetricPanicsRecovered).Add(2)
+	registry.Gauge("clients_tracked", func() int64 { return 7 })
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	req.SetRequestURI("/stats")
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(req, nil, nil)
+	http.NewStatsHandler(registry).GetStats(ctx)
+
+	var stats map[string]int64
+	if err := json.Unmarshal(ctx.Response.Body(), &stats); err != nil {
+		t.Fatalf("invalid stats JSON: %v", err)
+	}
+	if stats[http.MetricPanicsRecovered] != 2 {
+		t.Errorf("Expected %s=2, got %d", http.MetricPanicsRecovered, stats[http.MetricPanicsRecovered])
+	}
+	if stats["clients_tracked"] != 7 {
+		t.Errorf("Expected clients_tracked=7, got %d", stats["clients_tracked"])
+	}
+}
+
+func TestRouter_UnknownRoute(t *testing.T) {
+	router := http.NewRouter()
+	router.Handle("/estimate", okHandler)
+
+	if status := doRequest(router.Handler, ""); status != fasthttp.StatusOK {
+		t.Fatalf("Expected status %d, got %d", fasthttp.StatusOK, status)
+	}
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	req.SetRequestURI("/missing")
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(req, nil, nil)
+	router.Handler(ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusNotFound {
+		t.Errorf("Expected status %d, got %d", fasthttp.StatusNotFound, ctx.Response.StatusCode())
+	}
+}
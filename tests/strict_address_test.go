@@ -0,0 +1,80 @@
+package tests
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"bigswapenergy/internal/presentation/http"
+	"bigswapenergy/internal/shared/config"
+	apperrors "bigswapenergy/internal/shared/errors"
+	usecases "bigswapenergy/internal/usecases"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+func TestValidateChecksumAddress(t *testing.T) {
+	if err := usecases.ValidateChecksumAddress("pool", testPool); err != nil {
+		t.Errorf("Expected the checksummed address accepted, got %v", err)
+	}
+
+	tests := []struct {
+		name, address, reason string
+	}{
+		{"short", "0x123", "40 hex digit"},
+		{"no prefix", strings.TrimPrefix(testPool, "0x"), "40 hex digit"},
+		{"lowercase", strings.ToLower(testPool), "EIP-55 checksum, expected " + testPool},
+		{"one letter flipped", "0xb4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc", "EIP-55 checksum"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := usecases.ValidateChecksumAddress("pool", tc.address)
+			if !errors.Is(err, apperrors.ErrValidation) || !strings.Contains(err.Error(), "pool ") || !strings.Contains(err.Error(), tc.reason) {
+				t.Errorf("Expected a validation error naming pool and %q, got %v", tc.reason, err)
+			}
+		})
+	}
+}
+
+func TestEstimateHandler_StrictAddresses(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	cfg := &config.Config{RateLimit: config.RateLimitConfig{RequestsPerMinute: 100}}
+	estimateHandler := http.NewEstimateHandler(createEstimateService(client), zap.NewNop(), cfg)
+	handler := estimateHandler.StrictAddresses(estimateHandler.EstimateSwapAmount)
+
+	query := "/estimate?pool=" + testPool + "&src=" + testToken0.Hex() + "&dst=" + testToken1.Hex() + "&src_amount=1000"
+	if ctx := runQuery(handler, query); ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected checksummed addresses accepted, got %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+
+	lowered := "/estimate?pool=" + testPool + "&src=" + testToken0.Hex() + "&dst=" + strings.ToLower(testToken1.Hex()) + "&src_amount=1000"
+	ctx := runQuery(handler, lowered)
+	if ctx.Response.StatusCode() != fasthttp.StatusBadRequest || !strings.Contains(string(ctx.Response.Body()), "dst fails its EIP-55 checksum") {
+		t.Errorf("Expected 400 naming dst, got %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+
+	// The lenient default still accepts it
+	if ctx := runQuery(estimateHandler.EstimateSwapAmount, lowered); ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Errorf("Expected a lowercase address accepted without strict mode, got %d", ctx.Response.StatusCode())
+	}
+}
+
+func TestEstimateBatch_StrictAddresses(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	cfg := &config.Config{
+		Server:    config.ServerConfig{BatchWorkers: 2, StrictAddresses: true},
+		RateLimit: config.RateLimitConfig{RequestsPerMinute: 100},
+	}
+	handler := http.NewEstimateHandler(createEstimateService(client), zap.NewNop(), cfg)
+
+	body := "[" + batchItem(testToken0.Hex(), testToken1.Hex(), "1000") + "," + batchItem(strings.ToLower(testToken0.Hex()), testToken1.Hex(), "1000") + "]"
+	results := decodeBatch(t, runBatch(handler, "POST", body))
+	if len(results) != 2 || results[0].AmountOut != "996" {
+		t.Fatalf("Expected the checksummed item quoted, got %+v", results)
+	}
+	if !strings.Contains(results[1].Error, "src fails its EIP-55 checksum") {
+		t.Errorf("Expected the lowercase item rejected naming src, got %+v", results[1])
+	}
+}
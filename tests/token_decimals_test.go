@@ -0,0 +1,331 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"bigswapenergy/internal/infrastructure/uniswap_v2"
+	"bigswapenergy/internal/shared/clock"
+	apperrors "bigswapenergy/internal/shared/errors"
+	usecases "bigswapenergy/internal/usecases"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/zap"
+)
+
+var (
+	// USDC-like 6-decimal and WETH-like 18-decimal destination tokens
+	token6  = testToken0
+	token18 = testToken1
+)
+
+func decimalsWord(decimals int64) []byte {
+	return common.LeftPadBytes(big.NewInt(decimals).Bytes(), 32)
+}
+
+func TestLoadTokenDecimals_ReadsDecimalsCall(t *testing.T) {
+	client := uniswap_v2.NewUniswapV2Client(&fakeEthereumClient{calls: map[common.Address][]byte{
+		token6:  decimalsWord(6),
+		token18: decimalsWord(18),
+	}}, zap.NewNop())
+
+	for token, want := range map[common.Address]uint8{token6: 6, token18: 18} {
+		got, err := client.LoadTokenDecimals(context.Background(), token)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != want {
+			t.Errorf("%s: got %d decimals, want %d", token.Hex(), got, want)
+		}
+	}
+}
+
+func TestLoadTokenDecimals_RejectsNonConformingTokens(t *testing.T) {
+	client := uniswap_v2.NewUniswapV2Client(&fakeEthereumClient{calls: map[common.Address][]byte{
+		token6:  decimalsWord(256),
+		token18: {0x12},
+	}}, zap.NewNop())
+
+	for _, token := range []common.Address{token6, token18} {
+		if _, err := client.LoadTokenDecimals(context.Background(), token); !errors.Is(err, uniswap_v2.ErrInvalidDecimals) {
+			t.Errorf("%s: expected ErrInvalidDecimals, got %v", token.Hex(), err)
+		}
+	}
+}
+
+func TestLoadTokenDecimals_MissingMethod(t *testing.T) {
+	client := uniswap_v2.NewUniswapV2Client(&fakeEthereumClient{calls: map[common.Address][]byte{
+		token6: {},
+	}}, zap.NewNop())
+
+	// token6 returns no data; token18 has no canned result, so the call reverts
+	for _, token := range []common.Address{token6, token18} {
+		if _, err := client.LoadTokenDecimals(context.Background(), token); !errors.Is(err, uniswap_v2.ErrNoDecimals) {
+			t.Errorf("%s: expected ErrNoDecimals, got %v", token.Hex(), err)
+		}
+	}
+}
+
+func TestCachedUniswapV2Client_CachesDecimals(t *testing.T) {
+	fake := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	fake.decimals = map[common.Address]uint8{token6: 6}
+	fakeClock := clock.NewFakeClock(time.Unix(1_700_000_000, 0))
+	cached := uniswap_v2.NewCachedUniswapV2Client(fake, time.Hour, fakeClock, zap.NewNop())
+
+	for i := 0; i < 3; i++ {
+		if decimals, err := cached.LoadTokenDecimals(context.Background(), token6); err != nil || decimals != 6 {
+			t.Fatalf("got %d, %v", decimals, err)
+		}
+		// decimals() is immutable, so entries outlive the token TTL
+		fakeClock.Advance(2 * time.Hour)
+	}
+	if fake.decimalsCalls != 1 {
+		t.Fatalf("Expected a single decimals() call, got %d", fake.decimalsCalls)
+	}
+
+	// A token without decimals() is remembered as such
+	for i := 0; i < 2; i++ {
+		if _, err := cached.LoadTokenDecimals(context.Background(), token18); !errors.Is(err, uniswap_v2.ErrNoDecimals) {
+			t.Fatalf("expected ErrNoDecimals, got %v", err)
+		}
+	}
+	if fake.decimalsCalls != 2 {
+		t.Fatalf("Expected the missing method to be cached, got %d calls", fake.decimalsCalls)
+	}
+}
+
+func TestCachedUniswapV2Client_DecimalsErrorsNotCached(t *testing.T) {
+	fake := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	fake.decimalsErr = errors.New("connection refused")
+	cached := uniswap_v2.NewCachedUniswapV2Client(fake, time.Hour, clock.New(), zap.NewNop())
+
+	cached.LoadTokenDecimals(context.Background(), token6)
+	fake.decimalsErr = nil
+	fake.decimals = map[common.Address]uint8{token6: 6}
+	if decimals, err := cached.LoadTokenDecimals(context.Background(), token6); err != nil || decimals != 6 {
+		t.Fatalf("Expected the read to be retried after an RPC failure, got %d, %v", decimals, err)
+	}
+}
+
+func TestEstimateService_HumanAmountsReadsDecimals(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	client.decimals = map[common.Address]uint8{token18: 18}
+	service := createEstimateService(client)
+
+	result, err := service.EstimateSwap(context.Background(), usecases.EstimateRequest{
+		PoolAddress:  testPool,
+		SrcToken:     token6.Hex(),
+		DstToken:     token18.Hex(),
+		SrcAmount:    big.NewInt(1_000),
+		HumanAmounts: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.DstDecimals == nil || *result.DstDecimals != 18 {
+		t.Fatalf("Expected decimals read from the destination token, got %v", result.DstDecimals)
+	}
+}
+
+func TestEstimateService_HumanAmountsUsesSuppliedDecimals(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	client.decimals = map[common.Address]uint8{token6: 8}
+	service := createEstimateService(client)
+
+	six := uint8(6)
+	result, err := service.EstimateSwap(context.Background(), usecases.EstimateRequest{
+		PoolAddress:  testPool,
+		SrcToken:     token18.Hex(),
+		DstToken:     token6.Hex(),
+		SrcAmount:    big.NewInt(1_000),
+		HumanAmounts: true,
+		DstDecimals:  &six,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *result.DstDecimals != 6 || client.decimalsCalls != 0 {
+		t.Fatalf("Expected the supplied decimals without a decimals() call, got %d after %d calls", *result.DstDecimals, client.decimalsCalls)
+	}
+}
+
+func TestEstimateService_HumanAmountsWithoutDecimalsFallsBackToRaw(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	service := createEstimateService(client)
+
+	result, err := service.EstimateSwap(context.Background(), usecases.EstimateRequest{
+		PoolAddress:  testPool,
+		SrcToken:     token6.Hex(),
+		DstToken:     token18.Hex(),
+		SrcAmount:    big.NewInt(1_000),
+		HumanAmounts: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.DstDecimals != nil {
+		t.Fatalf("Expected raw amounts, got %d decimals", *result.DstDecimals)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "decimals()") {
+		t.Fatalf("Expected a warning explaining the raw output, got %v", result.Warnings)
+	}
+}
+
+func TestEstimateService_HumanAmountsDecimalsRPCFailure(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+	client.decimalsErr = errors.New("connection refused")
+	service := createEstimateService(client)
+
+	_, err := service.EstimateSwap(context.Background(), usecases.EstimateRequest{
+		PoolAddress:  testPool,
+		SrcToken:     token6.Hex(),
+		DstToken:     token18.Hex(),
+		SrcAmount:    big.NewInt(1_000),
+		HumanAmounts: true,
+	})
+	if !errors.Is(err, apperrors.ErrExternalService) {
+		t.Fatalf("Expected ErrExternalService, got %v", err)
+	}
+}
+
+func TestEstimateHandler_HumanAmounts(t *testing.T) {
+	huge, _ := new(big.Int).SetString("1234567890123456789", 10)
+	pair := "/estimate?pool=" + testPool + "&src=" + testToken0.Hex() + "&dst=" + testToken1.Hex()
+	tests := []struct {
+		name   string
+		amount *big.Int
+		uri    string
+		want   string
+	}{
+		{"6 decimals", big.NewInt(1_500_000), pair + "&src_amount=1000&format=human&dst_decimals=6", "1.500000"},
+		{"18 decimals", huge, pair + "&src_amount=1000&format=human&dst_decimals=18", "1.234567890123456789"},
+		{"list", big.NewInt(996), pair + "&src_amount=1000&src_amount=2000&format=human&dst_decimals=6", "0.000996\n0.000996"},
+		{"raw", big.NewInt(996), pair + "&src_amount=1000&format=raw", "996"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := createEstimateHandler(&mockEstimateService{estimateAmount: tt.amount})
+			ctx := runQuery(handler.EstimateSwapAmount, tt.uri)
+			if ctx.Response.StatusCode() != 200 {
+				t.Fatalf("Expected 200, got %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+			}
+			if got := string(ctx.Response.Body()); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEstimateHandler_HumanAmountsRejected(t *testing.T) {
+	pair := "/estimate?pool=" + testPool + "&src=" + testToken0.Hex() + "&dst=" + testToken1.Hex()
+	for _, uri := range []string{
+		pair + "&src_amount=1000&format=pretty",
+		pair + "&src_amount=1000&format=human&dst_decimals=256",
+		pair + "&src_amount=1000&format=human&dst_decimals=-1",
+		pair + "&src_amount=1000&dst_decimals=6",
+		pair + "&dst_amount=1000&format=human",
+		pair + "&src_amount=1000&format=human&item_status=true",
+	} {
+		mockService := &mockEstimateService{estimateAmount: big.NewInt(996)}
+		ctx := runQuery(createEstimateHandler(mockService).EstimateSwapAmount, uri)
+		if ctx.Response.StatusCode() == 200 {
+			t.Errorf("%s: expected an error, got 200", uri)
+		}
+	}
+}
+
+func TestEstimateService_SrcTokenAmountScaledByDecimals(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000_000), big.NewInt(1_000_000_000))
+	client.decimals = map[common.Address]uint8{token6: 6, token18: 18}
+	service := createEstimateService(client)
+
+	want, err := service.EstimateSwapAmount(context.Background(), testPool, token6.Hex(), token18.Hex(), big.NewInt(1_500_000))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result, err := service.EstimateSwap(context.Background(), usecases.EstimateRequest{
+		PoolAddress:    testPool,
+		SrcToken:       token6.Hex(),
+		DstToken:       token18.Hex(),
+		SrcTokenAmount: "1.5",
+		HumanAmounts:   true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.AmountOut.Cmp(want) != 0 {
+		t.Fatalf("Expected 1.5 tokens to quote as 1500000 base units (%s), got %s", want, result.AmountOut)
+	}
+	if result.DstDecimals == nil || *result.DstDecimals != 18 {
+		t.Fatalf("Expected the output in destination tokens, got %v", result.DstDecimals)
+	}
+}
+
+func TestEstimateService_SrcTokenAmountRejected(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000_000), big.NewInt(1_000_000_000))
+	client.decimals = map[common.Address]uint8{token6: 6}
+	service := createEstimateService(client)
+
+	for _, tt := range []struct {
+		name   string
+		src    common.Address
+		amount string
+	}{
+		{"too precise", token6, "1.0000001"},
+		{"not a number", token6, "1.5e3"},
+		{"zero", token6, "0.000"},
+		{"negative", token6, "-1"},
+		{"no decimals()", token18, "1"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := token18
+			if tt.src == token18 {
+				dst = token6
+			}
+			_, err := service.EstimateSwap(context.Background(), usecases.EstimateRequest{
+				PoolAddress:    testPool,
+				SrcToken:       tt.src.Hex(),
+				DstToken:       dst.Hex(),
+				SrcTokenAmount: tt.amount,
+			})
+			if !errors.Is(err, apperrors.ErrValidation) {
+				t.Fatalf("Expected ErrValidation, got %v", err)
+			}
+		})
+	}
+}
+
+func TestEstimateHandler_DecimalsParam(t *testing.T) {
+	mockService := &mockEstimateService{estimateAmount: big.NewInt(1_500_000)}
+	handler := createEstimateHandler(mockService)
+	pair := "/estimate?pool=" + testPool + "&src=" + testToken0.Hex() + "&dst=" + testToken1.Hex()
+
+	ctx := runQuery(handler.EstimateSwapAmount, pair+"&src_amount=1.5&decimals=true&dst_decimals=6")
+	if ctx.Response.StatusCode() != 200 {
+		t.Fatalf("Expected 200, got %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	if got := string(ctx.Response.Body()); got != "1.500000" {
+		t.Errorf("Expected the output in whole tokens, got %q", got)
+	}
+	req := mockService.lastRequest
+	if req.SrcTokenAmount != "1.5" || req.SrcAmount != nil || !req.HumanAmounts {
+		t.Errorf("Expected src_amount passed on in tokens with human output, got %+v", req)
+	}
+
+	for _, uri := range []string{
+		pair + "&decimals=true",
+		pair + "&src_amount=1&src_amount=2&decimals=true",
+		pair + "&src_amount=1&decimals=true&item_status=true",
+		pair + "&dst_amount=1&decimals=true",
+		pair + "&start=1&factor=2&count=3&decimals=true",
+	} {
+		ctx := runQuery(createEstimateHandler(&mockEstimateService{estimateAmount: big.NewInt(996)}).EstimateSwapAmount, uri)
+		if ctx.Response.StatusCode() == 200 {
+			t.Errorf("%s: expected an error, got 200", uri)
+		}
+	}
+}
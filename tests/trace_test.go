@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"bigswapenergy/internal/presentation/http"
+	"bigswapenergy/internal/shared/config"
+	"bigswapenergy/internal/shared/logger"
+	usecases "bigswapenergy/internal/usecases"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestSampled_HonorsRateStatistically(t *testing.T) {
+	const requests = 100_000
+
+	for _, rate := range []float64{0.01, 0.1, 0.5} {
+		sampled := 0
+		for id := uint64(1); id <= requests; id++ {
+			if logger.Sampled(id, rate) {
+				sampled++
+			}
+		}
+
+		got := float64(sampled) / requests
+		// Sequential ids must still spread evenly; allow 10% relative error
+		if math.Abs(got-rate) > rate*0.1 {
+			t.Errorf("rate %v: sampled fraction %v", rate, got)
+		}
+	}
+}
+
+func TestSampled_BoundsAndDeterminism(t *testing.T) {
+	for id := uint64(0); id < 1000; id++ {
+		if logger.Sampled(id, 0) {
+			t.Fatalf("id %d sampled at rate 0", id)
+		}
+		if !logger.Sampled(id, 1) {
+			t.Fatalf("id %d not sampled at rate 1", id)
+		}
+		if logger.Sampled(id, 0.3) != logger.Sampled(id, 0.3) {
+			t.Fatalf("id %d sampled inconsistently", id)
+		}
+	}
+}
+
+func TestWithDebug_EmitsBelowConfiguredLevel(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	base := zap.New(core)
+
+	base.Debug("dropped")
+	logger.WithDebug(base).Debug("kept")
+
+	if logs.Len() != 1 || logs.All()[0].Message != "kept" {
+		t.Errorf("Expected only the forced debug entry, got %v", logs.All())
+	}
+}
+
+func runTracedEstimate(t *testing.T, rate float64) *observer.ObservedLogs {
+	t.Helper()
+
+	core, logs := observer.New(zapcore.InfoLevel)
+	log := zap.New(core)
+	cfg := &config.Config{
+		RateLimit: config.RateLimitConfig{RequestsPerMinute: 100},
+		Logging:   config.LoggingConfig{TraceSampleRate: rate},
+	}
+	service := usecases.NewEstimateService(newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000)), nil, log)
+	handler := http.NewEstimateHandler(service, log, cfg)
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	req.SetRequestURI("/estimate?pool=" + testPool + "&src=" + testToken0.Hex() + "&dst=" + testToken1.Hex() + "&src_amount=1000")
+	req.Header.SetMethod("GET")
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(req, nil, nil)
+	handler.EstimateSwapAmount(ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d", ctx.Response.StatusCode())
+	}
+	return logs
+}
+
+func TestTracing_SampledRequestLogsServiceDetails(t *testing.T) {
+	logs := runTracedEstimate(t, 1)
+
+	for _, msg := range []string{"Tracing request", "Loaded pool state", "Computed quote", "Request phase timings"} {
+		if logs.FilterMessage(msg).FilterLevelExact(zapcore.DebugLevel).Len() != 1 {
+			t.Errorf("Expected debug entry %q", msg)
+		}
+	}
+}
+
+func TestTracing_UnsampledRequestStaysAtInfo(t *testing.T) {
+	logs := runTracedEstimate(t, 0)
+
+	if n := logs.FilterLevelExact(zapcore.DebugLevel).Len(); n != 0 {
+		t.Errorf("Expected no debug entries, got %d", n)
+	}
+}
@@ -0,0 +1,80 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	apperrors "bigswapenergy/internal/shared/errors"
+	usecases "bigswapenergy/internal/usecases"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestEstimateTwoWayQuote_SingleSnapshot(t *testing.T) {
+	client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(2_000_000))
+	service := createEstimateService(client)
+
+	result, err := service.EstimateTwoWayQuote(context.Background(), usecases.TwoWayQuoteRequest{
+		PoolAddress: testPool,
+		BaseToken:   testToken0.Hex(),
+		QuoteToken:  testToken1.Hex(),
+		Amount:      big.NewInt(1000),
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result.Sell.Cmp(big.NewInt(1992)) != 0 {
+		t.Errorf("Expected sell 1992, got %s", result.Sell)
+	}
+	if result.Buy.Cmp(big.NewInt(498)) != 0 {
+		t.Errorf("Expected buy 498, got %s", result.Buy)
+	}
+	if client.reservesCalls != 1 || client.tokensCalls != 1 {
+		t.Errorf("Expected both legs from one read, got %d reserve and %d token reads", client.reservesCalls, client.tokensCalls)
+	}
+	if result.BlockNumber != client.blockNumber {
+		t.Errorf("Expected block %d, got %d", client.blockNumber, result.BlockNumber)
+	}
+}
+
+func TestEstimateTwoWayQuote_SameTokens(t *testing.T) {
+	service := createEstimateService(newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(2_000_000)))
+
+	_, err := service.EstimateTwoWayQuote(context.Background(), usecases.TwoWayQuoteRequest{
+		PoolAddress: testPool,
+		BaseToken:   testToken0.Hex(),
+		QuoteToken:  testToken0.Hex(),
+		Amount:      big.NewInt(1000),
+	})
+	if !errors.Is(err, apperrors.ErrBusinessRule) {
+		t.Errorf("Expected business rule error, got %v", err)
+	}
+}
+
+func TestEstimateTwoWayQuoteHandler(t *testing.T) {
+	handler := createEstimateHandler(&mockEstimateService{estimateAmount: big.NewInt(42)})
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	req.SetRequestURI("/estimate/quote?pool=0x1&base=0x2&quote=0x3&amount=1000")
+	req.Header.SetMethod("GET")
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(req, nil, nil)
+	handler.EstimateTwoWayQuote(ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d", ctx.Response.StatusCode())
+	}
+	var body map[string]any
+	if err := json.Unmarshal(ctx.Response.Body(), &body); err != nil {
+		t.Fatalf("Expected JSON body, got %q", ctx.Response.Body())
+	}
+	if body["sell"] != "42" || body["buy"] != "42" {
+		t.Errorf("Unexpected response body: %s", ctx.Response.Body())
+	}
+}
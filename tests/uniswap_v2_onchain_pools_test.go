@@ -0,0 +1,157 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"go.uber.org/zap"
+
+	infraeth "bigswapenergy/internal/infrastructure/ethereum"
+	"bigswapenergy/internal/infrastructure/uniswap_v2"
+	"bigswapenergy/internal/shared/utils"
+)
+
+// defaultOnchainConcurrency bounds parallel pool checks unless ONCHAIN_TEST_CONCURRENCY is set
+const defaultOnchainConcurrency = 4
+
+// onchainAmountDivisors size the quoted amounts as fractions of reserveIn
+var onchainAmountDivisors = []int64{1_000_000, 10_000, 100, 10}
+
+type onchainPoolFixture struct {
+	Name string `json:"name"`
+	Pool string `json:"pool"`
+}
+
+func loadOnchainPoolFixtures(t *testing.T) []onchainPoolFixture {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("fixtures", "onchain_pools.json"))
+	if err != nil {
+		t.Fatalf("read pool fixtures: %v", err)
+	}
+	var pools []onchainPoolFixture
+	if err := json.Unmarshal(data, &pools); err != nil {
+		t.Fatalf("parse pool fixtures: %v", err)
+	}
+	for _, p := range pools {
+		if p.Name == "" || !common.IsHexAddress(p.Pool) {
+			t.Fatalf("invalid pool fixture: %+v", p)
+		}
+	}
+	return pools
+}
+
+func onchainConcurrency(t *testing.T) int {
+	t.Helper()
+
+	raw := os.Getenv("ONCHAIN_TEST_CONCURRENCY")
+	if raw == "" {
+		return defaultOnchainConcurrency
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n <= 0 {
+		t.Fatalf("ONCHAIN_TEST_CONCURRENCY must be a positive integer, got %q", raw)
+	}
+	return n
+}
+
+// TestGetAmountOut_OnchainPools reads reserves for each fixture pool via our storage
+// path and compares our quotes against the router's getAmountsOut at the same block
+func TestGetAmountOut_OnchainPools(t *testing.T) {
+	rpcURL := os.Getenv("ETHEREUM_RPC_URL")
+	if rpcURL == "" {
+		t.Skip("ETHEREUM_RPC_URL not set; skipping on-chain pool comparison test")
+	}
+
+	pools := loadOnchainPoolFixtures(t)
+	contractABI := loadRouterABI(t, "getAmountsOut")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	rpcClient, err := ethclient.DialContext(ctx, rpcURL)
+	if err != nil {
+		t.Fatalf("dial eth rpc: %v", err)
+	}
+	defer rpcClient.Close()
+
+	ethClient, err := infraeth.NewEthereumClient(rpcURL, zap.NewNop())
+	if err != nil {
+		t.Fatalf("create ethereum client: %v", err)
+	}
+	defer ethClient.Close()
+	uniswapClient := uniswap_v2.NewUniswapV2Client(ethClient, zap.NewNop())
+
+	blockNumber, err := uniswapClient.GetLatestBlockNumber(ctx)
+	if err != nil {
+		t.Fatalf("latest block: %v", err)
+	}
+	blockNum := new(big.Int).SetUint64(blockNumber)
+
+	sem := make(chan struct{}, onchainConcurrency(t))
+
+	// The group subtest only returns once every parallel pool check has finished,
+	// so the deferred client teardown above runs after them
+	t.Run("pools", func(t *testing.T) {
+		for _, pc := range pools {
+			t.Run(pc.Name, func(t *testing.T) {
+				t.Parallel()
+
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				pool := common.HexToAddress(pc.Pool)
+				token0, token1, err := uniswapClient.LoadTokens(ctx, pool, blockNum)
+				if err != nil {
+					t.Fatalf("load tokens: %v", err)
+				}
+				reserve0, reserve1, err := uniswapClient.LoadReserves(ctx, pool, blockNum)
+				if err != nil {
+					t.Fatalf("load reserves: %v", err)
+				}
+
+				for _, divisor := range onchainAmountDivisors {
+					amountIn := new(big.Int).Div(reserve0, big.NewInt(divisor))
+					if amountIn.Sign() == 0 {
+						continue
+					}
+
+					amountOut := utils.GlobalBigIntPool.Get()
+					utils.CalculateUniswapV2SwapAmount(amountIn, reserve0, reserve1, amountOut, utils.GlobalBigIntPool)
+
+					input, err := contractABI.Pack("getAmountsOut", amountIn, []common.Address{token0, token1})
+					if err != nil {
+						t.Fatalf("abi pack: %v", err)
+					}
+					out, err := rpcClient.CallContract(ctx, ethereum.CallMsg{To: &uniswapV2Router, Data: input}, blockNum)
+					if err != nil {
+						t.Fatalf("eth_call getAmountsOut: %v", err)
+					}
+					values, err := contractABI.Unpack("getAmountsOut", out)
+					if err != nil {
+						t.Fatalf("abi unpack: %v", err)
+					}
+					amounts, ok := values[0].([]*big.Int)
+					if !ok || len(amounts) != 2 {
+						t.Fatalf("unexpected getAmountsOut output: %v", values)
+					}
+
+					if amountOut.Cmp(amounts[1]) != 0 {
+						t.Errorf("mismatch at block %d: local=%s onchain=%s (in=%s r0=%s r1=%s)",
+							blockNumber, amountOut, amounts[1], amountIn, reserve0, reserve1)
+					}
+					utils.GlobalBigIntPool.Put(amountOut)
+				}
+			})
+		}
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"math/big"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 	"time"
 
@@ -32,34 +33,9 @@ func TestGetAmountOut_Onchain(t *testing.T) {
 		t.Fatalf("dial eth rpc: %v", err)
 	}
 
-	abiPath := filepath.Join("abi", "abi.json")
-	data, err := os.ReadFile(abiPath)
-	if err != nil {
-		t.Fatalf("read abi: %v", err)
-	}
-	var arr []map[string]any
-	if err := json.Unmarshal(data, &arr); err != nil {
-		t.Fatalf("parse abi json: %v", err)
-	}
-	var methodEntries []map[string]any
-	for _, e := range arr {
-		if name, _ := e["name"].(string); name == "getAmountOut" {
-			methodEntries = append(methodEntries, e)
-		}
-	}
-	if len(methodEntries) == 0 {
-		t.Fatalf("getAmountOut not found in ABI file")
-	}
-	minimalJSON, err := json.Marshal(methodEntries)
-	if err != nil {
-		t.Fatalf("marshal minimal abi: %v", err)
-	}
-	contractABI, err := gethabi.JSON(bytes.NewReader(minimalJSON))
-	if err != nil {
-		t.Fatalf("parse abi: %v", err)
-	}
+	contractABI := loadRouterABI(t, "getAmountOut")
 
-	router := common.HexToAddress("0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D")
+	router := uniswapV2Router
 
 	cases := []struct {
 		name       string
@@ -107,3 +83,39 @@ func TestGetAmountOut_Onchain(t *testing.T) {
 		})
 	}
 }
+
+// uniswapV2Router is the mainnet Uniswap V2 Router02
+var uniswapV2Router = common.HexToAddress("0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D")
+
+// loadRouterABI parses the router ABI fixture, keeping only the named methods
+func loadRouterABI(t *testing.T, methods ...string) gethabi.ABI {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("abi", "abi.json"))
+	if err != nil {
+		t.Fatalf("read abi: %v", err)
+	}
+	var arr []map[string]any
+	if err := json.Unmarshal(data, &arr); err != nil {
+		t.Fatalf("parse abi json: %v", err)
+	}
+	var methodEntries []map[string]any
+	for _, e := range arr {
+		name, _ := e["name"].(string)
+		if slices.Contains(methods, name) {
+			methodEntries = append(methodEntries, e)
+		}
+	}
+	if len(methodEntries) != len(methods) {
+		t.Fatalf("methods %v not found in ABI file", methods)
+	}
+	minimalJSON, err := json.Marshal(methodEntries)
+	if err != nil {
+		t.Fatalf("marshal minimal abi: %v", err)
+	}
+	contractABI, err := gethabi.JSON(bytes.NewReader(minimalJSON))
+	if err != nil {
+		t.Fatalf("parse abi: %v", err)
+	}
+	return contractABI
+}
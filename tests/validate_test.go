@@ -0,0 +1,163 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	apperrors "bigswapenergy/internal/shared/errors"
+	usecases "bigswapenergy/internal/usecases"
+
+	"github.com/valyala/fasthttp"
+)
+
+// invalidEstimateRequests pairs each invalid request with its /estimate query, so
+// both entry points can be checked against the same cases
+var invalidEstimateRequests = []struct {
+	name  string
+	req   usecases.EstimateRequest
+	query string
+	want  error
+}{
+	{
+		name:  "missing_pool",
+		req:   usecases.EstimateRequest{SrcToken: testToken0.Hex(), DstToken: testToken1.Hex(), SrcAmount: big.NewInt(1)},
+		query: "src=" + testToken0.Hex() + "&dst=" + testToken1.Hex() + "&src_amount=1",
+		want:  apperrors.ErrValidation,
+	},
+	{
+		name:  "malformed_src",
+		req:   usecases.EstimateRequest{PoolAddress: testPool, SrcToken: "0x456", DstToken: testToken1.Hex(), SrcAmount: big.NewInt(1)},
+		query: "pool=" + testPool + "&src=0x456&dst=" + testToken1.Hex() + "&src_amount=1",
+		want:  apperrors.ErrValidation,
+	},
+	{
+		name:  "same_tokens",
+		req:   usecases.EstimateRequest{PoolAddress: testPool, SrcToken: testToken0.Hex(), DstToken: strings.ToLower(testToken0.Hex()), SrcAmount: big.NewInt(1)},
+		query: "pool=" + testPool + "&src=" + testToken0.Hex() + "&dst=" + strings.ToLower(testToken0.Hex()) + "&src_amount=1",
+		want:  apperrors.ErrBusinessRule,
+	},
+	{
+		name:  "zero_amount",
+		req:   usecases.EstimateRequest{PoolAddress: testPool, SrcToken: testToken0.Hex(), DstToken: testToken1.Hex(), SrcAmount: big.NewInt(0)},
+		query: "pool=" + testPool + "&src=" + testToken0.Hex() + "&dst=" + testToken1.Hex() + "&src_amount=0",
+		want:  apperrors.ErrValidation,
+	},
+	{
+		name:  "missing_amount",
+		req:   usecases.EstimateRequest{PoolAddress: testPool, SrcToken: testToken0.Hex(), DstToken: testToken1.Hex()},
+		query: "pool=" + testPool + "&src=" + testToken0.Hex() + "&dst=" + testToken1.Hex(),
+		want:  apperrors.ErrValidation,
+	},
+	{
+		name:  "src_and_dst_amount",
+		req:   usecases.EstimateRequest{PoolAddress: testPool, SrcToken: testToken0.Hex(), DstToken: testToken1.Hex(), SrcAmount: big.NewInt(1), DstAmount: big.NewInt(1)},
+		query: "pool=" + testPool + "&src=" + testToken0.Hex() + "&dst=" + testToken1.Hex() + "&src_amount=1&dst_amount=1",
+		want:  apperrors.ErrValidation,
+	},
+	{
+		name:  "zero_dst_amount",
+		req:   usecases.EstimateRequest{PoolAddress: testPool, SrcToken: testToken0.Hex(), DstToken: testToken1.Hex(), DstAmount: big.NewInt(0)},
+		query: "pool=" + testPool + "&src=" + testToken0.Hex() + "&dst=" + testToken1.Hex() + "&dst_amount=0",
+		want:  apperrors.ErrValidation,
+	},
+}
+
+func TestValidateAndNormalize_ServiceRejects(t *testing.T) {
+	for _, tc := range invalidEstimateRequests {
+		t.Run(tc.name, func(t *testing.T) {
+			client := newFakeUniswapV2Client(big.NewInt(1_000_000), big.NewInt(1_000_000))
+			service := createEstimateService(client)
+
+			if _, err := service.EstimateSwap(context.Background(), tc.req); !errors.Is(err, tc.want) {
+				t.Fatalf("Expected %v, got %v", tc.want, err)
+			}
+			if client.reservesCalls != 0 {
+				t.Errorf("Expected no RPC reads for an invalid request")
+			}
+		})
+	}
+}
+
+func TestValidateAndNormalize_HandlerRejects(t *testing.T) {
+	for _, tc := range invalidEstimateRequests {
+		t.Run(tc.name, func(t *testing.T) {
+			service := &mockEstimateService{estimateAmount: big.NewInt(996)}
+			handler := createEstimateHandler(service)
+
+			req := fasthttp.AcquireRequest()
+			defer fasthttp.ReleaseRequest(req)
+			req.SetRequestURI("/estimate?" + tc.query)
+			ctx := &fasthttp.RequestCtx{}
+			ctx.Init(req, nil, nil)
+			handler.EstimateSwapAmount(ctx)
+
+			if ctx.Response.StatusCode() == fasthttp.StatusOK {
+				t.Fatalf("Expected the handler to reject the request")
+			}
+			if service.lastRequest.SrcToken != "" {
+				t.Errorf("Expected the service not to be called, got %+v", service.lastRequest)
+			}
+		})
+	}
+}
+
+func TestValidateAndNormalize_Normalizes(t *testing.T) {
+	req := usecases.EstimateRequest{
+		PoolAddress: " " + strings.ToLower(testPool) + " ",
+		SrcToken:    strings.ToLower(testToken0.Hex()),
+		DstToken:    strings.ToUpper(testToken1.Hex()[2:]),
+		SrcAmounts:  []*big.Int{big.NewInt(5), big.NewInt(10)},
+	}
+	if err := usecases.ValidateAndNormalize(&req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if req.PoolAddress != testPool || req.SrcToken != testToken0.Hex() || req.DstToken != testToken1.Hex() {
+		t.Errorf("Expected checksummed addresses, got %q %q %q", req.PoolAddress, req.SrcToken, req.DstToken)
+	}
+	if req.SrcAmount == nil || req.SrcAmount.Cmp(big.NewInt(5)) != 0 {
+		t.Errorf("Expected SrcAmount to be the first of SrcAmounts, got %v", req.SrcAmount)
+	}
+}
+
+func TestEstimateHandler_DstAmountQuotesExactOut(t *testing.T) {
+	service := &mockEstimateService{estimateAmount: big.NewInt(1004)}
+	handler := createEstimateHandler(service)
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	req.SetRequestURI("/estimate?pool=" + testPool + "&src=" + testToken0.Hex() + "&dst=" + testToken1.Hex() + "&dst_amount=1000")
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(req, nil, nil)
+	handler.EstimateSwapAmount(ctx)
+
+	if ctx.Response.StatusCode() != fasthttp.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", ctx.Response.StatusCode(), ctx.Response.Body())
+	}
+	if service.lastRequest.DstAmount == nil || service.lastRequest.DstAmount.Cmp(big.NewInt(1000)) != 0 {
+		t.Errorf("Expected dst_amount to reach the service, got %v", service.lastRequest.DstAmount)
+	}
+	if service.lastRequest.SrcAmount != nil {
+		t.Errorf("Expected no source amount on an exact-out request, got %v", service.lastRequest.SrcAmount)
+	}
+	if body := string(ctx.Response.Body()); body != "1004" {
+		t.Errorf("Expected the required input in the body, got %q", body)
+	}
+}
+
+func TestValidateAndNormalize_AmountErrorsNameBothParams(t *testing.T) {
+	neither := usecases.EstimateRequest{PoolAddress: testPool, SrcToken: testToken0.Hex(), DstToken: testToken1.Hex()}
+	err := usecases.ValidateAndNormalize(&neither)
+	if !errors.Is(err, apperrors.ErrValidation) || !strings.Contains(err.Error(), "src_amount") || !strings.Contains(err.Error(), "dst_amount") {
+		t.Errorf("Expected an error naming both amount params, got %v", err)
+	}
+
+	both := usecases.EstimateRequest{PoolAddress: testPool, SrcToken: testToken0.Hex(), DstToken: testToken1.Hex(), SrcAmount: big.NewInt(1), DstAmount: big.NewInt(1)}
+	err = usecases.ValidateAndNormalize(&both)
+	if !errors.Is(err, apperrors.ErrValidation) || !strings.Contains(err.Error(), "mutually exclusive") {
+		t.Errorf("Expected a mutual exclusivity error, got %v", err)
+	}
+}